@@ -0,0 +1,141 @@
+package ascon
+
+import (
+    "strconv"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// PrecomputedAAD holds additional data that has already been
+// padded and split into rate-sized words for one variant.
+//
+// The additional data is absorbed after the nonce-dependent
+// initialization, so the absorbed state itself cannot be
+// cached. PrecomputedAAD instead removes the per-call byte
+// loading, bounds checks and padding. The permutation calls
+// remain, and they account for most of the cost, so expect a
+// modest saving rather than a large one.
+//
+// A PrecomputedAAD does not depend on the key and may be
+// shared by AEADs of the same variant and used concurrently.
+type PrecomputedAAD struct {
+    iv    uint64
+    words []uint64
+}
+
+// PrecomputeAAD prepares additionalData for use with
+// SealWithPrecomputedAAD and OpenWithPrecomputedAAD on AEADs of
+// the same variant as a.
+func (a *AEAD) PrecomputeAAD(additionalData []byte) *PrecomputedAAD {
+    p := &PrecomputedAAD{iv: a.iv}
+    if len(additionalData) == 0 {
+        return p
+    }
+
+    bs := a.blockSize()
+    n := (len(additionalData) + bs) &^ (bs - 1)
+    buf := make([]byte, n)
+    copy(buf, additionalData)
+    p.words = make([]uint64, n/8)
+    if a.iv == ivAEAD128 {
+        buf[len(additionalData)] = 0x01
+        for i := range p.words {
+            p.words[i] = binary.LittleEndian.Uint64(buf[8*i:])
+        }
+    } else {
+        buf[len(additionalData)] = 0x80
+        for i := range p.words {
+            p.words[i] = binary.BigEndian.Uint64(buf[8*i:])
+        }
+    }
+    return p
+}
+
+// SealWithPrecomputedAAD is like Seal, but takes the additional
+// data from ad.
+//
+// It panics if ad was precomputed for a different variant.
+func (a *AEAD) SealWithPrecomputedAAD(dst, nonce, plaintext []byte, ad *PrecomputedAAD) []byte {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    n, err := SealedLen(len(plaintext), a.tagSize)
+    if err != nil {
+        panic(err)
+    }
+    ret, out := subtle.SliceForAppend(dst, n)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    var s state
+    a.init(&s, nonce)
+    a.precomputedAdditionalData(&s, ad)
+    a.sealMessage(&s, out[:len(plaintext)], out[len(plaintext):], plaintext)
+
+    return ret
+}
+
+// OpenWithPrecomputedAAD is like Open, but takes the additional
+// data from ad.
+//
+// It panics if ad was precomputed for a different variant.
+func (a *AEAD) OpenWithPrecomputedAAD(dst, nonce, ciphertext []byte, ad *PrecomputedAAD) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
+    }
+
+    n, err := OpenedLen(len(ciphertext), a.tagSize)
+    if err != nil {
+        return nil, err
+    }
+
+    tag := ciphertext[n:]
+    ciphertext = ciphertext[:n]
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    var s state
+    a.init(&s, nonce)
+    a.precomputedAdditionalData(&s, ad)
+    if err := a.openMessage(&s, out, ciphertext, tag); err != nil {
+        return nil, err
+    }
+
+    return ret, nil
+}
+
+func (a *AEAD) precomputedAdditionalData(s *state, ad *PrecomputedAAD) {
+    if ad.iv != a.iv {
+        panic("ascon: PrecomputedAAD is for a different variant")
+    }
+
+    w := ad.words
+    switch a.iv {
+    case iv128a:
+        for ; len(w) >= 2; w = w[2:] {
+            s[0] ^= w[0]
+            s[1] ^= w[1]
+            p8(s)
+        }
+        s[4] ^= 1
+    case ivAEAD128:
+        for ; len(w) >= 2; w = w[2:] {
+            s[0] ^= w[0]
+            s[1] ^= w[1]
+            p8(s)
+        }
+        s[4] ^= 1 << 63
+    default:
+        for _, x := range w {
+            s[0] ^= x
+            p6(s)
+        }
+        s[4] ^= 1
+    }
+}
@@ -0,0 +1,98 @@
+package ascon
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestPrecomputedAAD(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            c, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            aead := c.(*AEAD)
+            nonce := make([]byte, NonceSize)
+            pt := []byte("a record body")
+
+            for n := 0; n < 40; n++ {
+                ad := make([]byte, n)
+                for i := range ad {
+                    ad[i] = byte(i)
+                }
+                p := aead.PrecomputeAAD(ad)
+
+                want := aead.Seal(nil, nonce, pt, ad)
+                got := aead.SealWithPrecomputedAAD(nil, nonce, pt, p)
+                if !bytes.Equal(got, want) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+                }
+                out, err := aead.OpenWithPrecomputedAAD(nil, nonce, want, p)
+                if err != nil {
+                    t.Fatalf("%d: %v", n, err)
+                }
+                if !bytes.Equal(out, pt) {
+                    t.Fatalf("%d: expected %q, got %q", n, pt, out)
+                }
+
+                other := aead.PrecomputeAAD(append(ad, 0))
+                if _, err := aead.OpenWithPrecomputedAAD(nil, nonce, want, other); err != ErrAuthentication {
+                    t.Fatalf("%d: expected %v, got %v", n, ErrAuthentication, err)
+                }
+            }
+        })
+    }
+}
+
+func TestPrecomputedAADVariant(t *testing.T) {
+    c128, _ := New128(make([]byte, KeySize))
+    c128a, _ := New128a(make([]byte, KeySize))
+    p := c128.(*AEAD).PrecomputeAAD([]byte("header"))
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expected a panic")
+        }
+    }()
+    c128a.(*AEAD).SealWithPrecomputedAAD(nil, make([]byte, NonceSize), nil, p)
+}
+
+func BenchmarkSealAAD256_128a(b *testing.B) {
+    benchmarkSealAAD(b, 256, false)
+}
+
+func BenchmarkSealPrecomputedAAD256_128a(b *testing.B) {
+    benchmarkSealAAD(b, 256, true)
+}
+
+func BenchmarkSealAAD128_128a(b *testing.B) {
+    benchmarkSealAAD(b, 128, false)
+}
+
+func BenchmarkSealPrecomputedAAD128_128a(b *testing.B) {
+    benchmarkSealAAD(b, 128, true)
+}
+
+func benchmarkSealAAD(b *testing.B, adLen int, precomputed bool) {
+    c, err := New128a(make([]byte, KeySize))
+    if err != nil {
+        b.Fatal(err)
+    }
+    aead := c.(*AEAD)
+    nonce := make([]byte, NonceSize)
+    ad := make([]byte, adLen)
+    pt := make([]byte, 64)
+    p := aead.PrecomputeAAD(ad)
+    b.SetBytes(int64(len(pt) + len(ad)))
+    var out []byte
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if precomputed {
+            out = aead.SealWithPrecomputedAAD(out[:0], nonce, pt, p)
+        } else {
+            out = aead.Seal(out[:0], nonce, pt, ad)
+        }
+    }
+}
@@ -0,0 +1,337 @@
+// Package armor implements an ASCII armor for encrypted data,
+// for pasting it into emails, tickets and configuration files.
+//
+// Armored data looks like
+//
+//    -----BEGIN ASCON MESSAGE-----
+//    QVNDT04BBBABAAAAEAABAgMEBQYHCAkKlK3bZ8RhA3aAYBVSkRN0TQ
+//    =AAAAAAAAACjjagIA
+//    -----END ASCON MESSAGE-----
+//
+// The data is in base64, without padding, in lines of 64
+// characters. The trailer, starting with "=", is the base64 of
+// the 64-bit big-endian length of the data followed by its
+// CRC-32 (IEEE), so that data cut or mangled in transit is
+// detected before anyone tries to decrypt it. The CRC is not a
+// MAC: it detects accidents, not tampering.
+//
+// The decoder ignores whitespace anywhere between the BEGIN and
+// END lines, and around them, but rejects anything else.
+package armor
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "hash/crc32"
+    "io"
+    "encoding/base64"
+    "encoding/binary"
+)
+
+const (
+    begin = "-----BEGIN ASCON MESSAGE-----"
+    end   = "-----END ASCON MESSAGE-----"
+
+    // lineLength is the number of base64 characters per line.
+    lineLength = 64
+    // trailerSize is the size of the decoded trailer: the
+    // length and the CRC.
+    trailerSize = 8 + 4
+)
+
+var (
+    // ErrMalformed is returned for data that is not armored,
+    // or has characters outside the base64 alphabet, or
+    // misses its trailer.
+    ErrMalformed = errors.New("armor: malformed armored data")
+    // ErrChecksum is returned when the length or CRC of the
+    // data do not match its trailer.
+    ErrChecksum = errors.New("armor: data does not match its checksum")
+)
+
+var encoding = base64.RawStdEncoding.Strict()
+
+// Encode returns the armor of data.
+func Encode(data []byte) []byte {
+    var buf bytes.Buffer
+    w := NewEncoder(&buf)
+    w.Write(data)
+    w.Close()
+    return buf.Bytes()
+}
+
+// Decode returns the data armored in b. It checks the trailer
+// before returning anything.
+func Decode(b []byte) ([]byte, error) {
+    data, err := io.ReadAll(NewDecoder(bytes.NewReader(b)))
+    if err != nil {
+        return nil, err
+    }
+    return data, nil
+}
+
+type encoder struct {
+    w       io.Writer
+    started bool
+    // pending holds the bytes short of a group of three, and
+    // line the characters of the current line.
+    pending []byte
+    line    []byte
+    buf     []byte
+    n       uint64
+    crc     uint32
+    err     error
+}
+
+// NewEncoder returns a writer that armors the data written to
+// it and writes it to w. Close must be called to write the
+// trailer and END line; it does not close w.
+func NewEncoder(w io.Writer) io.WriteCloser {
+    return &encoder{
+        w:       w,
+        pending: make([]byte, 0, 3),
+        line:    make([]byte, 0, lineLength+1),
+    }
+}
+
+func (e *encoder) start() {
+    if !e.started {
+        e.started = true
+        e.write([]byte(begin + "\n"))
+    }
+}
+
+func (e *encoder) write(b []byte) {
+    if e.err == nil {
+        _, e.err = e.w.Write(b)
+    }
+}
+
+// encode appends the base64 of b, a whole number of groups of
+// three bytes unless it is the last, to the lines.
+func (e *encoder) encode(b []byte) {
+    if size := encoding.EncodedLen(len(b)); cap(e.buf) < size {
+        e.buf = make([]byte, size)
+    }
+    chars := e.buf[:encoding.EncodedLen(len(b))]
+    encoding.Encode(chars, b)
+    for len(chars) > 0 {
+        n := copy(e.line[len(e.line):lineLength], chars)
+        e.line = e.line[:len(e.line)+n]
+        chars = chars[n:]
+        if len(e.line) == lineLength {
+            e.write(append(e.line, '\n'))
+            e.line = e.line[:0]
+        }
+    }
+}
+
+func (e *encoder) Write(p []byte) (int, error) {
+    e.start()
+    if e.err != nil {
+        return 0, e.err
+    }
+    e.n += uint64(len(p))
+    e.crc = crc32.Update(e.crc, crc32.IEEETable, p)
+
+    b := p
+    if len(e.pending) > 0 {
+        k := copy(e.pending[len(e.pending):3], b)
+        e.pending = e.pending[:len(e.pending)+k]
+        b = b[k:]
+        if len(e.pending) < 3 {
+            return len(p), nil
+        }
+        e.encode(e.pending)
+        e.pending = e.pending[:0]
+    }
+    whole := len(b) - len(b)%3
+    e.encode(b[:whole])
+    e.pending = append(e.pending, b[whole:]...)
+    if e.err != nil {
+        return 0, e.err
+    }
+    return len(p), nil
+}
+
+func (e *encoder) Close() error {
+    e.start()
+    e.encode(e.pending)
+    e.pending = e.pending[:0]
+    if len(e.line) > 0 {
+        e.write(append(e.line, '\n'))
+        e.line = e.line[:0]
+    }
+    var trailer [trailerSize]byte
+    binary.BigEndian.PutUint64(trailer[:], e.n)
+    binary.BigEndian.PutUint32(trailer[8:], e.crc)
+    e.write([]byte("=" + base64.StdEncoding.EncodeToString(trailer[:]) + "\n" + end + "\n"))
+    return e.err
+}
+
+type decoder struct {
+    r       *bufio.Reader
+    started bool
+    // chars holds base64 characters short of a group of four,
+    // and out the decoded data not yet read.
+    chars []byte
+    out   []byte
+    buf   []byte
+    n     uint64
+    crc   uint32
+    err   error
+}
+
+// NewDecoder returns a reader that decodes the armored data
+// read from r.
+//
+// The data is returned as it is decoded, and the trailer is
+// only checked at the end: Read returns io.EOF if it matches,
+// and ErrChecksum if not. Read returns io.ErrUnexpectedEOF if
+// the END line is missing. To check the trailer before using
+// any of the data, use Decode, or read everything before
+// using it.
+func NewDecoder(r io.Reader) io.Reader {
+    return &decoder{r: bufio.NewReader(r), chars: make([]byte, 0, 4*256)}
+}
+
+func isSpace(c byte) bool {
+    switch c {
+    case ' ', '\t', '\n', '\v', '\f', '\r':
+        return true
+    }
+    return false
+}
+
+// next returns the next byte that is not whitespace.
+func (d *decoder) next() (byte, error) {
+    for {
+        c, err := d.r.ReadByte()
+        if err != nil || !isSpace(c) {
+            return c, err
+        }
+    }
+}
+
+// expect reads the marker s, after any whitespace.
+func (d *decoder) expect(s string) error {
+    c, err := d.next()
+    if err != nil {
+        return eof(err)
+    }
+    b := make([]byte, len(s)-1)
+    if _, err := io.ReadFull(d.r, b); err != nil {
+        return eof(err)
+    }
+    if c != s[0] || string(b) != s[1:] {
+        return ErrMalformed
+    }
+    return nil
+}
+
+// eof turns the end of the input into io.ErrUnexpectedEOF.
+func eof(err error) error {
+    if err == io.EOF {
+        return io.ErrUnexpectedEOF
+    }
+    return err
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+    for len(d.out) == 0 && d.err == nil {
+        d.err = d.fill()
+    }
+    if len(d.out) > 0 {
+        n := copy(p, d.out)
+        d.out = d.out[n:]
+        return n, nil
+    }
+    return 0, d.err
+}
+
+// fill decodes more data into out, or returns the final error.
+func (d *decoder) fill() error {
+    if !d.started {
+        d.started = true
+        if err := d.expect(begin); err != nil {
+            return err
+        }
+    }
+    for len(d.chars) < cap(d.chars) {
+        c, err := d.next()
+        if err != nil {
+            return eof(err)
+        }
+        if c == '=' {
+            if err := d.decode(d.chars, true); err != nil {
+                return err
+            }
+            return d.trailer()
+        }
+        if c == '-' {
+            // The END line without a trailer.
+            return ErrMalformed
+        }
+        d.chars = append(d.chars, c)
+    }
+    return d.decode(d.chars, false)
+}
+
+// decode decodes the characters in chars into out. Unless last
+// is set, it keeps the characters short of a group of four.
+func (d *decoder) decode(chars []byte, last bool) error {
+    n := len(chars)
+    if !last {
+        n -= n % 4
+    }
+    if size := encoding.DecodedLen(n); cap(d.buf) < size {
+        d.buf = make([]byte, size)
+    }
+    m, err := encoding.Decode(d.buf[:cap(d.buf)], chars[:n])
+    if err != nil {
+        return ErrMalformed
+    }
+    d.out = d.buf[:m]
+    d.n += uint64(m)
+    d.crc = crc32.Update(d.crc, crc32.IEEETable, d.out)
+    d.chars = append(d.chars[:0], chars[n:]...)
+    return nil
+}
+
+// trailer reads the trailer and END line, and returns io.EOF if
+// the trailer matches the data.
+func (d *decoder) trailer() error {
+    var chars []byte
+    for {
+        c, err := d.next()
+        if err != nil {
+            return eof(err)
+        }
+        if c == '-' {
+            d.r.UnreadByte()
+            break
+        }
+        if len(chars) == base64.StdEncoding.EncodedLen(trailerSize) {
+            return ErrMalformed
+        }
+        chars = append(chars, c)
+    }
+    trailer, err := base64.StdEncoding.Strict().DecodeString(string(chars))
+    if err != nil || len(trailer) != trailerSize {
+        return ErrMalformed
+    }
+    if err := d.expect(end); err != nil {
+        return err
+    }
+    if _, err := d.next(); err != io.EOF {
+        if err == nil {
+            err = ErrMalformed
+        }
+        return err
+    }
+    if binary.BigEndian.Uint64(trailer) != d.n || binary.BigEndian.Uint32(trailer[8:]) != d.crc {
+        return ErrChecksum
+    }
+    return io.EOF
+}
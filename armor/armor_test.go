@@ -0,0 +1,167 @@
+package armor
+
+import (
+    "bytes"
+    "encoding/hex"
+    "io"
+    "math/rand"
+    "strings"
+    "testing"
+    "testing/iotest"
+)
+
+// golden is the armor of the first container of the container
+// specification, as in the package documentation.
+const golden = `-----BEGIN ASCON MESSAGE-----
+QVNDT04BBBABAAAAEAABAgMEBQYHCAkKlK3bZ8RhA3aAYBVSkRN0TQ
+=AAAAAAAAACjjagIA
+-----END ASCON MESSAGE-----
+`
+
+func TestGolden(t *testing.T) {
+    data, _ := hex.DecodeString("4153434f4e0104100100000010000102030405060708090a94addb67c4610376806015529113744d")
+    if got := Encode(data); string(got) != golden {
+        t.Errorf("expected\n%s\ngot\n%s", golden, got)
+    }
+    if got, err := Decode([]byte(golden)); err != nil || !bytes.Equal(got, data) {
+        t.Errorf("got %x, %v", got, err)
+    }
+}
+
+func TestRoundTrip(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    for n := 0; n < 300; n++ {
+        data := make([]byte, n)
+        rng.Read(data)
+        armored := Encode(data)
+
+        lines := strings.Split(strings.TrimSuffix(string(armored), "\n"), "\n")
+        if lines[0] != begin || lines[len(lines)-1] != end || !strings.HasPrefix(lines[len(lines)-2], "=") {
+            t.Fatalf("%d bytes: bad layout:\n%s", n, armored)
+        }
+        body := lines[1 : len(lines)-2]
+        for i, line := range body {
+            if len(line) > lineLength || i < len(body)-1 && len(line) != lineLength || len(line) == 0 {
+                t.Fatalf("%d bytes: line %d has %d characters", n, i, len(line))
+            }
+        }
+
+        if got, err := Decode(armored); err != nil || !bytes.Equal(got, data) {
+            t.Fatalf("%d bytes: got %x, %v", n, got, err)
+        }
+        got, err := io.ReadAll(NewDecoder(iotest.OneByteReader(bytes.NewReader(armored))))
+        if err != nil || !bytes.Equal(got, data) {
+            t.Fatalf("%d bytes: one byte at a time: got %x, %v", n, got, err)
+        }
+    }
+}
+
+func TestStreaming(t *testing.T) {
+    rng := rand.New(rand.NewSource(2))
+    data := make([]byte, 1<<20+5)
+    rng.Read(data)
+
+    var buf bytes.Buffer
+    w := NewEncoder(&buf)
+    for p := data; len(p) > 0; {
+        n := rng.Intn(200)
+        if n > len(p) {
+            n = len(p)
+        }
+        w.Write(p[:n])
+        p = p[n:]
+    }
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(buf.Bytes(), Encode(data)) {
+        t.Fatal("the encoder depends on the sizes of the writes")
+    }
+    if err := iotest.TestReader(NewDecoder(bytes.NewReader(buf.Bytes())), data); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestWhitespace(t *testing.T) {
+    data := bytes.Repeat([]byte("armor"), 40)
+    armored := Encode(data)
+    rng := rand.New(rand.NewSource(3))
+    spaces := []string{" ", "\t", "\r\n", "\n\n", "  \n  "}
+
+    // Whitespace anywhere but inside the BEGIN and END lines.
+    var b strings.Builder
+    b.WriteString("\n  \n")
+    for _, line := range strings.SplitAfter(string(armored), "\n") {
+        if strings.HasPrefix(line, "-----") {
+            b.WriteString(strings.TrimSuffix(line, "\n") + "\r\n")
+            continue
+        }
+        for _, c := range strings.TrimSuffix(line, "\n") {
+            b.WriteRune(c)
+            if rng.Intn(4) == 0 {
+                b.WriteString(spaces[rng.Intn(len(spaces))])
+            }
+        }
+        b.WriteString("\r\n")
+    }
+    b.WriteString("\t\n")
+    if got, err := Decode([]byte(b.String())); err != nil || !bytes.Equal(got, data) {
+        t.Fatalf("got %q, %v:\n%s", got, err, b.String())
+    }
+}
+
+func TestCorrupted(t *testing.T) {
+    data := bytes.Repeat([]byte{0xa5}, 100)
+    armored := Encode(data)
+    trailer := bytes.IndexByte(armored, '=')
+
+    // Every modification of a character of the body, the
+    // trailer or the markers.
+    for i, c := range armored {
+        if c == '\n' {
+            continue
+        }
+        for _, r := range []byte{'A', 'z', '+', '0', '=', '-', '!'} {
+            if r == c {
+                continue
+            }
+            b := append([]byte(nil), armored...)
+            b[i] = r
+            if _, err := Decode(b); err == nil {
+                t.Errorf("byte %d changed from %q to %q: accepted", i, c, r)
+            } else if i > trailer && err != ErrMalformed && err != ErrChecksum && err != io.ErrUnexpectedEOF {
+                t.Errorf("byte %d: unexpected error %v", i, err)
+            }
+        }
+    }
+
+    // Every truncation.
+    for n := 0; n < len(armored)-1; n++ {
+        if _, err := Decode(armored[:n]); err == nil {
+            t.Errorf("truncated to %d bytes: accepted", n)
+        }
+    }
+
+    // A dropped line.
+    lines := bytes.SplitAfter(armored, []byte("\n"))
+    dropped := append(append([]byte{}, lines[0]...), bytes.Join(lines[2:], nil)...)
+    if _, err := Decode(dropped); err != ErrChecksum {
+        t.Errorf("dropped line: expected %v, got %v", ErrChecksum, err)
+    }
+
+    for name, b := range map[string]string{
+        "empty":         "",
+        "no armor":      "hello",
+        "other type":    strings.Replace(string(armored), "ASCON MESSAGE", "PGP MESSAGE", 2),
+        "no trailer":    strings.Replace(string(armored), string(armored[trailer:trailer+18]), "", 1),
+        "no end":        string(armored[:len(armored)-len(end)-1]),
+        "text after":    string(armored) + "x",
+        "second armor":  string(armored) + string(armored),
+        "long trailer":  strings.Replace(string(armored), "=", "=AAAA", 1),
+        "short trailer": strings.Replace(string(armored), "=AAAA", "=", 1),
+    } {
+        if _, err := Decode([]byte(b)); err == nil {
+            t.Errorf("%s: accepted", name)
+        }
+    }
+}
@@ -1,8 +1,18 @@
 // Package ascon implements the ASCON AEAD cipher.
 //
+// On small targets such as microcontrollers, use the *AEAD of
+// New128aKey and the other New*Key functions with SealTo and
+// OpenTo. They work in caller-provided buffers and use no maps,
+// reflection or interface conversions, and the AEAD itself
+// only goes to the heap if it escapes. Seal and Open keep the
+// state and at most one padded block on the stack: with the gc
+// compiler on 32-bit arm, their deepest call chain uses less
+// than 600 bytes of it, and the AEAD code is about 26 KB.
+//
 // References:
 //
 //    [ascon]: https://ascon.iaik.tugraz.at
+//    [sp800232]: https://doi.org/10.6028/NIST.SP.800-232
 //
 package ascon
 
@@ -19,9 +29,57 @@ import (
 const (
     iv128  uint64 = 0x80400c0600000000 // Ascon-128
     iv128a uint64 = 0x80800c0800000000 // Ascon-128a
+    iv80pq uint64 = 0xa0400c0600000000 // Ascon-80pq
+
+    ivAEAD128 uint64 = 0x00001000808c0001 // Ascon-AEAD128 (SP 800-232)
+)
+
+var (
+    // ErrAuthentication is returned by Open and the other
+    // opening functions when a message cannot be opened. Its
+    // message says nothing of the reason, and there is no other
+    // way to tell a forged message from a corrupted one.
+    // Malformed messages also match ErrInvalidNonceSize or
+    // ErrCiphertextTooShort with errors.Is, since their lengths
+    // are public anyway.
+    ErrAuthentication = errors.New("ascon: message authentication failed")
+
+    // ErrInvalidKeySize is matched by the KeySizeError of every
+    // constructor that takes a key, with errors.Is.
+    ErrInvalidKeySize = errors.New("ascon: invalid key size")
 )
 
-var errOpen = errors.New("ascon: message authentication failed")
+// KeySizeError is returned for a key of the wrong length, which
+// it holds.
+type KeySizeError int
+
+func (k KeySizeError) Error() string {
+    return "ascon: invalid key size " + strconv.Itoa(int(k))
+}
+
+// Is reports whether target is ErrInvalidKeySize.
+func (k KeySizeError) Is(target error) bool {
+    return target == ErrInvalidKeySize
+}
+
+// openError is the error of a malformed message. It reads like
+// ErrAuthentication and matches it, and also matches reason.
+type openError struct {
+    reason error
+}
+
+func (e *openError) Error() string {
+    return ErrAuthentication.Error()
+}
+
+func (e *openError) Is(target error) bool {
+    return target == ErrAuthentication || target == e.reason
+}
+
+var (
+    errOpenNonce = &openError{ErrInvalidNonceSize}
+    errOpenShort = &openError{ErrCiphertextTooShort}
+)
 
 const (
     // BlockSize128a is the size in bytes of an ASCON-128a block.
@@ -31,20 +89,91 @@ const (
     // KeySize is the size in bytes of ASCON-128 and ASCON-128a
     // keys.
     KeySize = 16
-    // NonceSize is the size in bytes of ASCON-128 and ASCON-128a
-    // nonces.
+    // KeySize80pq is the size in bytes of ASCON-80pq keys.
+    KeySize80pq = 20
+    // NonceSize is the size in bytes of ASCON-128, ASCON-128a
+    // and ASCON-80pq nonces.
     NonceSize = 16
-    // TagSize is the size in bytes of ASCON-128 and ASCON-128a
-    // authenticators.
+    // TagSize is the size in bytes of ASCON-128, ASCON-128a and
+    // ASCON-80pq authenticators.
     TagSize = 16
+    // MinTagSize is the smallest truncated authenticator size
+    // accepted by New128WithTagSize and New128aWithTagSize.
+    MinTagSize = 8
 )
 
-type ascon struct {
+// MaxPlaintextSize and MaxAADSize are the largest plaintext and
+// additional data of a single message, 2^61 bytes each.
+// Together they stay far below the 2^64 blocks that a key may
+// process at the 8-byte rate of ASCON-128, and the length of a
+// sealed message cannot overflow an int. No 32-bit platform,
+// nor any current 64-bit one, can allocate that much; the
+// limits are checked so that this stays true.
+const (
+    MaxPlaintextSize = 1 << 61
+    MaxAADSize       = 1 << 61
+)
+
+// AEAD is the concrete type of the AEADs returned by New128,
+// New128a, New80pq, NewAEAD128 and their truncated tag
+// variants.
+//
+// In addition to cipher.AEAD and DetachedAEAD, it provides
+// SealTo and OpenTo, which write into a caller-provided buffer
+// instead of appending to a slice.
+//
+// Seal and Open do not allocate if dst has the capacity for
+// the output, which is len(plaintext)+Overhead() or
+// len(ciphertext)-Overhead() bytes past len(dst). Otherwise,
+// for example with a nil dst, they allocate the returned slice
+// and nothing else.
+//
+// Seal and Open work in place. Seal(buf[:0], nonce, buf, ad)
+// overwrites the plaintext in buf with the ciphertext and
+// appends the authenticator in the spare capacity of buf; if
+// there is not enough, the output goes to a new slice and buf
+// is left as is. Open(buf[:0], nonce, buf, ad) overwrites the
+// start of buf with the plaintext, and leaves the authenticator
+// after it. Opening in place destroys a message that fails to
+// authenticate, since the output is zeroed on failure.
+//
+// Apart from that exact overlap, the output must not share
+// memory with the input, nor, for the opening methods, with
+// the authenticator; the methods panic if it does. The same
+// rules hold for every method of AEAD with an output buffer:
+// SealDetached, OpenDetached, SealTo, OpenTo, the batch and
+// precomputed additional data methods.
+//
+// An AEAD is safe for concurrent use by multiple goroutines.
+// Nothing but Wipe modifies it after it is created, so
+// concurrent calls only share the key. Features that need
+// mutable state are separate wrappers, such as Limited for a
+// usage budget and ReuseGuard for detecting nonce reuse.
+//
+// The sealing methods panic if the nonce is not NonceSize
+// bytes long, since the caller generates it. Open, OpenDetached
+// and OpenTo return an error instead, like for any other
+// malformed message, so that a nonce read off the wire cannot
+// crash the program. Every AEAD in this module does the same.
+//
+// The sealing methods also panic if the plaintext or additional
+// data exceed MaxPlaintextSize or MaxAADSize, and the opening
+// methods fail for such messages.
+type AEAD struct {
+    // The fields are only set by the constructors, and by Wipe.
+    // TestAEADImmutable fails if a field that could hold
+    // mutable state, such as a pointer or a counter, is added.
     k0, k1 uint64
-    iv     uint64
+    // k2 holds the low 64 bits of an ASCON-80pq key, in which
+    // case k0 only holds the top 32 bits.
+    k2 uint64
+    iv uint64
+    // tagSize is the number of authenticator bytes emitted by
+    // Seal and checked by Open.
+    tagSize int
 }
 
-var _ cipher.AEAD = (*ascon)(nil)
+var _ cipher.AEAD = (*AEAD)(nil)
 
 // New128 creates a 128-bit ASCON-128 AEAD.
 //
@@ -61,15 +190,47 @@ var _ cipher.AEAD = (*ascon)(nil)
 //
 // Refer to ASCON's documentation for more information.
 func New128(key []byte) (cipher.AEAD, error) {
+    return New128WithTagSize(key, TagSize)
+}
+
+// New128WithTagSize is like New128, but the authenticator
+// is truncated to tagSize bytes.
+//
+// tagSize must be in the range [MinTagSize, TagSize]. Shorter
+// authenticators make forgeries proportionally easier, so only
+// use them when the message format cannot afford the full tag.
+func New128WithTagSize(key []byte, tagSize int) (cipher.AEAD, error) {
     if len(key) != KeySize {
-        return nil, errors.New("ascon: bad key length")
+        return nil, KeySizeError(len(key))
+    }
+    if tagSize < MinTagSize || tagSize > TagSize {
+        return nil, errBadTagSize
     }
 
-    return &ascon{
-        k0: binary.BigEndian.Uint64(key[0:]),
-        k1: binary.BigEndian.Uint64(key[8:]),
-        iv: iv128,
-    }, nil
+    a := New128Key((*[KeySize]byte)(key))
+    a.tagSize = tagSize
+    return a, nil
+}
+
+// New128Key is like New128, but takes the key as an array so
+// that its length is checked at compile time. It cannot fail.
+func New128Key(key *[KeySize]byte) *AEAD {
+    a := newKey(key, iv128)
+    return &a
+}
+
+// newKey returns the AEAD of an ASCON v1.2 variant with a
+// 128-bit key. The New*Key functions only call it so that they
+// stay cheap enough to be inlined on 32-bit targets, where the
+// key loads are not: the AEAD then only goes to the heap if the
+// caller lets it escape.
+func newKey(key *[KeySize]byte, iv uint64) AEAD {
+    return AEAD{
+        k0:      binary.BigEndian.Uint64(key[0:]),
+        k1:      binary.BigEndian.Uint64(key[8:]),
+        iv:      iv,
+        tagSize: TagSize,
+    }
 }
 
 // New128a creates a 128-bit ASCON-128a AEAD.
@@ -87,116 +248,597 @@ func New128(key []byte) (cipher.AEAD, error) {
 //
 // Refer to ASCON's documentation for more information.
 func New128a(key []byte) (cipher.AEAD, error) {
+    return New128aWithTagSize(key, TagSize)
+}
+
+// New128aWithTagSize is like New128a, but the authenticator
+// is truncated to tagSize bytes.
+//
+// tagSize must be in the range [MinTagSize, TagSize]. Shorter
+// authenticators make forgeries proportionally easier, so only
+// use them when the message format cannot afford the full tag.
+func New128aWithTagSize(key []byte, tagSize int) (cipher.AEAD, error) {
     if len(key) != KeySize {
-        return nil, errors.New("ascon: bad key length")
+        return nil, KeySizeError(len(key))
     }
+    if tagSize < MinTagSize || tagSize > TagSize {
+        return nil, errBadTagSize
+    }
+
+    a := New128aKey((*[KeySize]byte)(key))
+    a.tagSize = tagSize
+    return a, nil
+}
 
-    return &ascon{
-        k0: binary.BigEndian.Uint64(key[0:]),
-        k1: binary.BigEndian.Uint64(key[8:]),
-        iv: iv128a,
+// New128aKey is like New128a, but takes the key as an array so
+// that its length is checked at compile time. It cannot fail.
+func New128aKey(key *[KeySize]byte) *AEAD {
+    a := newKey(key, iv128a)
+    return &a
+}
+
+// New80pq creates a 160-bit ASCON-80pq AEAD.
+//
+// ASCON-80pq shares the structure of ASCON-128 but uses
+// a 160-bit key, which provides additional protection
+// against quantum key search.
+//
+// Each unique key can encrypt a maximum 2^68 bytes (i.e., 2^64
+// plaintext and associated data blocks). Nonces must never be
+// reused with the same key. Violating either of these
+// constraints compromises the security of the algorithm.
+//
+// There are no other constraints on the composition of the
+// nonce. For example, the nonce can be a counter.
+//
+// Refer to ASCON's documentation for more information.
+func New80pq(key []byte) (cipher.AEAD, error) {
+    if len(key) != KeySize80pq {
+        return nil, KeySizeError(len(key))
+    }
+
+    return New80pqKey((*[KeySize80pq]byte)(key)), nil
+}
+
+// New80pqKey is like New80pq, but takes the key as an array so
+// that its length is checked at compile time. It cannot fail.
+func New80pqKey(key *[KeySize80pq]byte) *AEAD {
+    a := newKey80pq(key)
+    return &a
+}
+
+// newKey80pq is newKey for ASCON-80pq.
+func newKey80pq(key *[KeySize80pq]byte) AEAD {
+    return AEAD{
+        k0:      uint64(binary.BigEndian.Uint32(key[0:])),
+        k1:      binary.BigEndian.Uint64(key[4:]),
+        k2:      binary.BigEndian.Uint64(key[12:]),
+        iv:      iv80pq,
+        tagSize: TagSize,
+    }
+}
+
+// KeyFromSlice copies b into a key array for New128Key or
+// New128aKey. It returns an error if b is not exactly KeySize
+// bytes long.
+func KeyFromSlice(b []byte) ([KeySize]byte, error) {
+    var key [KeySize]byte
+    if len(b) != KeySize {
+        return key, KeySizeError(len(b))
+    }
+    copy(key[:], b)
+    return key, nil
+}
+
+// KeyFromSlice80pq is like KeyFromSlice, but for New80pqKey.
+func KeyFromSlice80pq(b []byte) ([KeySize80pq]byte, error) {
+    var key [KeySize80pq]byte
+    if len(b) != KeySize80pq {
+        return key, KeySizeError(len(b))
+    }
+    copy(key[:], b)
+    return key, nil
+}
+
+// NewAEAD128 creates a 128-bit Ascon-AEAD128 AEAD as
+// standardized by NIST SP 800-232.
+//
+// Ascon-AEAD128 is derived from ASCON-128a, but loads the key,
+// nonce and data into the state in little-endian byte order
+// and uses different IV and domain separation constants. It
+// does not interoperate with New128a.
+//
+// Each unique key can encrypt a maximum 2^54 bytes. Nonces
+// must never be reused with the same key. Violating either of
+// these constraints compromises the security of the algorithm.
+//
+// There are no other constraints on the composition of the
+// nonce. For example, the nonce can be a counter.
+//
+// Refer to NIST SP 800-232 for more information.
+func NewAEAD128(key []byte) (cipher.AEAD, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+
+    return &AEAD{
+        k0:      binary.LittleEndian.Uint64(key[0:]),
+        k1:      binary.LittleEndian.Uint64(key[8:]),
+        iv:      ivAEAD128,
+        tagSize: TagSize,
     }, nil
 }
 
-func (a *ascon) NonceSize() int {
+func (a *AEAD) NonceSize() int {
     return NonceSize
 }
 
-func (a *ascon) Overhead() int {
-    return TagSize
+func (a *AEAD) Overhead() int {
+    return a.tagSize
 }
 
-func (a *ascon) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+func (a *AEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
     if len(nonce) != NonceSize {
         panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
     }
 
-    n0 := binary.BigEndian.Uint64(nonce[0:])
-    n1 := binary.BigEndian.Uint64(nonce[8:])
+    n, err := SealedLen(len(plaintext), a.tagSize)
+    if err != nil {
+        panic(err)
+    }
+    ret, out := subtle.SliceForAppend(dst, n)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
 
-    var s state
-    s.init(a.iv, a.k0, a.k1, n0, n1)
+    a.seal(out[:len(plaintext)], out[len(plaintext):], nonce, plaintext, additionalData)
 
-    if a.iv == iv128a {
-        s.additionalData128a(additionalData)
-    } else {
-        s.additionalData128(additionalData)
+    return ret
+}
+
+func (a *AEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
+    }
+
+    n, err := OpenedLen(len(ciphertext), a.tagSize)
+    if err != nil {
+        return nil, err
+    }
+
+    tag := ciphertext[n:]
+    ciphertext = ciphertext[:n]
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    if err := a.open(out, nonce, ciphertext, tag, additionalData); err != nil {
+        return nil, err
     }
 
-    ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+    return ret, nil
+}
+
+// DetachedAEAD is a cipher.AEAD that can also keep the
+// authenticator apart from the ciphertext.
+//
+// The AEADs returned by New128, New128a, New80pq, NewAEAD128
+// and their truncated tag variants implement DetachedAEAD.
+type DetachedAEAD interface {
+    cipher.AEAD
+
+    // SealDetached is like Seal, but appends the ciphertext to
+    // dst and the Overhead() byte authenticator to tag, and
+    // returns both updated slices.
+    //
+    // To reuse plaintext's storage for the ciphertext, use
+    // plaintext[:0] as dst. tag must not overlap plaintext or
+    // dst.
+    SealDetached(dst, tag, nonce, plaintext, additionalData []byte) ([]byte, []byte)
+
+    // OpenDetached is like Open, but reads the authenticator
+    // from tag, which must be exactly Overhead() bytes long.
+    //
+    // To reuse ciphertext's storage for the plaintext, use
+    // ciphertext[:0] as dst. The output must not overlap tag.
+    OpenDetached(dst, nonce, ciphertext, tag, additionalData []byte) ([]byte, error)
+}
+
+var _ DetachedAEAD = (*AEAD)(nil)
+
+func (a *AEAD) SealDetached(dst, tag, nonce, plaintext, additionalData []byte) ([]byte, []byte) {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    ret, out := subtle.SliceForAppend(dst, len(plaintext))
     if subtle.InexactOverlap(out, plaintext) {
         panic("ascon: invalid buffer overlap")
     }
+    retTag, outTag := subtle.SliceForAppend(tag, a.tagSize)
+    if subtle.AnyOverlap(outTag, plaintext) || subtle.AnyOverlap(outTag, out) {
+        panic("ascon: invalid buffer overlap")
+    }
 
-    if a.iv == iv128a {
-        s.encrypt128a(out[:len(plaintext)], plaintext)
-    } else {
-        s.encrypt128(out[:len(plaintext)], plaintext)
+    a.seal(out, outTag, nonce, plaintext, additionalData)
+
+    return ret, retTag
+}
+
+func (a *AEAD) OpenDetached(dst, nonce, ciphertext, tag, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
     }
 
-    if a.iv == iv128a {
-        s.finalize128a(a.k0, a.k1)
-    } else {
-        s.finalize128(a.k0, a.k1)
+    if len(tag) != a.tagSize {
+        return nil, ErrAuthentication
     }
 
-    s.tag(out[len(out)-TagSize:])
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        panic("ascon: invalid buffer overlap")
+    }
 
-    return ret
+    if err := a.open(out, nonce, ciphertext, tag, additionalData); err != nil {
+        return nil, err
+    }
+
+    return ret, nil
 }
 
-func (a *ascon) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+var errShortBuffer = errors.New("ascon: output buffer too small")
+
+// SealTo is like Seal, but writes the ciphertext and
+// authenticator to the start of out and returns the number of
+// bytes written. It returns an error, and writes nothing, if
+// out is shorter than len(plaintext)+Overhead().
+//
+// To encrypt in place, pass a buffer that starts with
+// plaintext as out.
+func (a *AEAD) SealTo(out, nonce, plaintext, additionalData []byte) (int, error) {
     if len(nonce) != NonceSize {
         panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
     }
 
-    if len(ciphertext) < TagSize {
-        return nil, errOpen
+    n, err := SealedLen(len(plaintext), a.tagSize)
+    if err != nil {
+        panic(err)
+    }
+    if len(out) < n {
+        return 0, errShortBuffer
+    }
+    out = out[:n]
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
     }
 
-    tag := ciphertext[len(ciphertext)-TagSize:]
-    ciphertext = ciphertext[:len(ciphertext)-TagSize]
+    a.seal(out[:len(plaintext)], out[len(plaintext):], nonce, plaintext, additionalData)
 
-    n0 := binary.BigEndian.Uint64(nonce[0:])
-    n1 := binary.BigEndian.Uint64(nonce[8:])
+    return n, nil
+}
 
-    var s state
-    s.init(a.iv, a.k0, a.k1, n0, n1)
+// OpenTo is like Open, but writes the plaintext to the start
+// of out and returns the number of bytes written. It returns
+// an error if out is shorter than len(ciphertext)-Overhead().
+//
+// To decrypt in place, pass ciphertext as out.
+func (a *AEAD) OpenTo(out, nonce, ciphertext, additionalData []byte) (int, error) {
+    if len(nonce) != NonceSize {
+        return 0, errOpenNonce
+    }
 
-    if a.iv == iv128a {
-        s.additionalData128a(additionalData)
-    } else {
-        s.additionalData128(additionalData)
+    n, err := OpenedLen(len(ciphertext), a.tagSize)
+    if err != nil {
+        return 0, err
     }
 
-    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
-    if subtle.InexactOverlap(out, ciphertext) {
+    tag := ciphertext[n:]
+    ciphertext = ciphertext[:n]
+
+    if len(out) < len(ciphertext) {
+        return 0, errShortBuffer
+    }
+    out = out[:len(ciphertext)]
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
         panic("ascon: invalid buffer overlap")
     }
 
-    if a.iv == iv128a {
-        s.decrypt128a(out, ciphertext)
+    if err := a.open(out, nonce, ciphertext, tag, additionalData); err != nil {
+        return 0, err
+    }
+
+    return len(out), nil
+}
+
+// seal encrypts plaintext into dst and writes the truncated
+// authenticator to tag.
+func (a *AEAD) seal(dst, tag, nonce, plaintext, additionalData []byte) {
+    if a.iv == 0 {
+        panic(ErrWiped)
+    }
+    if tooLarge(uint64(len(plaintext)), uint64(len(additionalData))) {
+        panic("ascon: message too large")
+    }
+    switch a.iv {
+    case iv128:
+        (*ascon128)(a).seal(dst, tag, nonce, plaintext, additionalData)
+    case iv128a:
+        (*ascon128a)(a).seal(dst, tag, nonce, plaintext, additionalData)
+    default:
+        var s state
+        a.init(&s, nonce)
+        a.additionalData(&s, additionalData)
+        a.sealMessage(&s, dst, tag, plaintext)
+    }
+}
+
+// open decrypts ciphertext into dst and verifies tag, zeroing
+// dst if the authenticator is invalid.
+func (a *AEAD) open(dst, nonce, ciphertext, tag, additionalData []byte) error {
+    if a.iv == 0 {
+        return ErrWiped
+    }
+    if tooLarge(uint64(len(ciphertext)), uint64(len(additionalData))) {
+        return ErrAuthentication
+    }
+    switch a.iv {
+    case iv128:
+        return (*ascon128)(a).open(dst, nonce, ciphertext, tag, additionalData)
+    case iv128a:
+        return (*ascon128a)(a).open(dst, nonce, ciphertext, tag, additionalData)
+    default:
+        var s state
+        a.init(&s, nonce)
+        a.additionalData(&s, additionalData)
+        return a.openMessage(&s, dst, ciphertext, tag)
+    }
+}
+
+// tooLarge reports whether a message of n bytes with adLen
+// bytes of additional data exceeds MaxPlaintextSize or
+// MaxAADSize.
+func tooLarge(n, adLen uint64) bool {
+    return n > MaxPlaintextSize || adLen > MaxAADSize
+}
+
+// sealMessage is the part of seal that runs after the
+// additional data has been absorbed.
+func (a *AEAD) sealMessage(s *state, dst, tag, plaintext []byte) {
+    if a.iv == 0 {
+        panic(ErrWiped)
+    }
+    a.encrypt(s, dst, plaintext)
+    a.finalize(s)
+
+    var t [TagSize]byte
+    a.tag(s, t[:])
+    copy(tag, t[:a.tagSize])
+}
+
+// openMessage is the part of open that runs after the
+// additional data has been absorbed.
+func (a *AEAD) openMessage(s *state, dst, ciphertext, tag []byte) error {
+    if a.iv == 0 {
+        return ErrWiped
+    }
+    a.decrypt(s, dst, ciphertext)
+    a.finalize(s)
+
+    var expectedTag [TagSize]byte
+    a.tag(s, expectedTag[:])
+    return verifyTag(dst, &expectedTag, tag)
+}
+
+// verifyTag compares tag with the start of expectedTag in
+// constant time, zeroing dst if they differ.
+func verifyTag(dst []byte, expectedTag *[TagSize]byte, tag []byte) error {
+    if subtle.ConstantTimeCompare(expectedTag[:len(tag)], tag) != 1 {
+        for i := range dst {
+            dst[i] = 0
+        }
+
+        runtime.KeepAlive(dst)
+        return ErrAuthentication
+    }
+
+    return nil
+}
+
+// ascon128 and ascon128a are an AEAD of a fixed variant. Their
+// seal and open run straight through the block functions of
+// that variant instead of dispatching on the IV at every step,
+// which matters for short messages.
+type (
+    ascon128  AEAD
+    ascon128a AEAD
+)
+
+func (a *ascon128) seal(dst, tag, nonce, plaintext, additionalData []byte) {
+    var s state
+    s.init(iv128, a.k0, a.k1,
+        binary.BigEndian.Uint64(nonce[0:]), binary.BigEndian.Uint64(nonce[8:]))
+    s.additionalData128(additionalData)
+    s.encrypt128(dst, plaintext)
+    s.finalize128(a.k0, a.k1)
+    s.truncatedTag(tag)
+}
+
+func (a *ascon128) open(dst, nonce, ciphertext, tag, additionalData []byte) error {
+    var s state
+    s.init(iv128, a.k0, a.k1,
+        binary.BigEndian.Uint64(nonce[0:]), binary.BigEndian.Uint64(nonce[8:]))
+    s.additionalData128(additionalData)
+    s.decrypt128(dst, ciphertext)
+    s.finalize128(a.k0, a.k1)
+
+    var expectedTag [TagSize]byte
+    s.tag(expectedTag[:])
+    return verifyTag(dst, &expectedTag, tag)
+}
+
+func (a *ascon128a) seal(dst, tag, nonce, plaintext, additionalData []byte) {
+    var s state
+    s.init(iv128a, a.k0, a.k1,
+        binary.BigEndian.Uint64(nonce[0:]), binary.BigEndian.Uint64(nonce[8:]))
+    s.additionalData128a(additionalData)
+    if len(plaintext) <= BlockSize128a {
+        s.encryptShort128a(dst, plaintext)
     } else {
-        s.decrypt128(out, ciphertext)
+        s.encrypt128a(dst, plaintext)
     }
+    s.finalize128a(a.k0, a.k1)
+    s.truncatedTag(tag)
+}
 
-    if a.iv == iv128a {
-        s.finalize128a(a.k0, a.k1)
+func (a *ascon128a) open(dst, nonce, ciphertext, tag, additionalData []byte) error {
+    var s state
+    s.init(iv128a, a.k0, a.k1,
+        binary.BigEndian.Uint64(nonce[0:]), binary.BigEndian.Uint64(nonce[8:]))
+    s.additionalData128a(additionalData)
+    if len(ciphertext) <= BlockSize128a {
+        s.decryptShort128a(dst, ciphertext)
     } else {
-        s.finalize128(a.k0, a.k1)
+        s.decrypt128a(dst, ciphertext)
     }
+    s.finalize128a(a.k0, a.k1)
 
-    expectedTag := make([]byte, TagSize)
-    s.tag(expectedTag)
+    var expectedTag [TagSize]byte
+    s.tag(expectedTag[:])
+    return verifyTag(dst, &expectedTag, tag)
+}
 
-    if subtle.ConstantTimeCompare(expectedTag, tag) != 1 {
-        for i := range out {
-            out[i] = 0
-        }
+// asASCON returns the concrete AEAD behind aead and checks the
+// nonce length, for APIs that drive the state directly.
+func asASCON(aead cipher.AEAD, nonce []byte) (*AEAD, error) {
+    a, ok := aead.(*AEAD)
+    if !ok {
+        return nil, errors.New("ascon: unsupported AEAD")
+    }
+    if a.iv == 0 {
+        return nil, ErrWiped
+    }
+    if len(nonce) != NonceSize {
+        return nil, ErrInvalidNonceSize
+    }
+    return a, nil
+}
 
-        runtime.KeepAlive(out)
-        return nil, errOpen
+func (a *AEAD) init(s *state, nonce []byte) {
+    switch a.iv {
+    case ivAEAD128:
+        n0 := binary.LittleEndian.Uint64(nonce[0:])
+        n1 := binary.LittleEndian.Uint64(nonce[8:])
+        s.init(a.iv, a.k0, a.k1, n0, n1)
+    case iv80pq:
+        n0 := binary.BigEndian.Uint64(nonce[0:])
+        n1 := binary.BigEndian.Uint64(nonce[8:])
+        s.init80pq(a.iv, a.k0, a.k1, a.k2, n0, n1)
+    default:
+        n0 := binary.BigEndian.Uint64(nonce[0:])
+        n1 := binary.BigEndian.Uint64(nonce[8:])
+        s.init(a.iv, a.k0, a.k1, n0, n1)
     }
+}
 
-    return ret, nil
+func (a *AEAD) additionalData(s *state, ad []byte) {
+    switch a.iv {
+    case iv128a:
+        s.additionalData128a(ad)
+    case ivAEAD128:
+        s.additionalDataAEAD128(ad)
+    default:
+        s.additionalData128(ad)
+    }
+}
+
+// blockSize returns the rate of the variant in bytes.
+func (a *AEAD) blockSize() int {
+    switch a.iv {
+    case iv128a, ivAEAD128:
+        return BlockSize128a
+    default:
+        return BlockSize128
+    }
+}
+
+// additionalDataBlocks absorbs ad, which must be a multiple of
+// blockSize bytes, without padding or domain separation.
+func (a *AEAD) additionalDataBlocks(s *state, ad []byte) {
+    switch a.iv {
+    case iv128a:
+        additionalData128a(s, ad)
+    case ivAEAD128:
+        s.additionalDataBlocksAEAD128(ad)
+    default:
+        s.additionalDataBlocks128(ad)
+    }
+}
+
+func (a *AEAD) encrypt(s *state, dst, src []byte) {
+    switch a.iv {
+    case iv128a:
+        s.encrypt128a(dst, src)
+    case ivAEAD128:
+        s.encryptAEAD128(dst, src)
+    default:
+        s.encrypt128(dst, src)
+    }
+}
+
+func (a *AEAD) decrypt(s *state, dst, src []byte) {
+    switch a.iv {
+    case iv128a:
+        s.decrypt128a(dst, src)
+    case ivAEAD128:
+        s.decryptAEAD128(dst, src)
+    default:
+        s.decrypt128(dst, src)
+    }
+}
+
+// encryptBlocks encrypts src, which must be a multiple of
+// blockSize bytes, without padding.
+func (a *AEAD) encryptBlocks(s *state, dst, src []byte) {
+    switch a.iv {
+    case iv128a:
+        encryptBlocks128a(s, dst, src)
+    case ivAEAD128:
+        s.encryptBlocksAEAD128(dst, src)
+    default:
+        s.encryptBlocks128(dst, src)
+    }
+}
+
+// decryptBlocks decrypts src, which must be a multiple of
+// blockSize bytes, without padding.
+func (a *AEAD) decryptBlocks(s *state, dst, src []byte) {
+    switch a.iv {
+    case iv128a:
+        decryptBlocks128a(s, dst, src)
+    case ivAEAD128:
+        s.decryptBlocksAEAD128(dst, src)
+    default:
+        s.decryptBlocks128(dst, src)
+    }
+}
+
+func (a *AEAD) finalize(s *state) {
+    switch a.iv {
+    case iv128a, ivAEAD128:
+        s.finalize128a(a.k0, a.k1)
+    case iv80pq:
+        s.finalize80pq(a.k0, a.k1, a.k2)
+    default:
+        s.finalize128(a.k0, a.k1)
+    }
+}
+
+func (a *AEAD) tag(s *state, dst []byte) {
+    if a.iv == ivAEAD128 {
+        s.tagAEAD128(dst)
+    } else {
+        s.tag(dst)
+    }
 }
@@ -0,0 +1,207 @@
+//go:build amd64 && gc && !purego && !ascon_interleaved
+
+#include "textflag.h"
+
+// The state x0..x4 is kept in R8..R12. AX, BX, CX, DX and
+// R13 are scratch registers, and the block loops keep their
+// pointers and length in SI, R14 and R15.
+
+#define LOAD_STATE(s) \
+    MOVQ 0(s), R8;  \
+    MOVQ 8(s), R9;  \
+    MOVQ 16(s), R10; \
+    MOVQ 24(s), R11; \
+    MOVQ 32(s), R12
+
+#define STORE_STATE(s) \
+    MOVQ R8, 0(s);  \
+    MOVQ R9, 8(s);  \
+    MOVQ R10, 16(s); \
+    MOVQ R11, 24(s); \
+    MOVQ R12, 32(s)
+
+// LAYERS applies the substitution and linear diffusion layers
+// of a round: the Keccak-style S-box, with the x0 ^= x4,
+// x4 ^= x3, x2 ^= x1 input and t1 ^= t0, t0 ^= t4, t3 ^= t2,
+// t2 = ^t2 output steps, followed by
+//
+//    x0 ^= (x0 >>> 19) ^ (x0 >>> 28)
+//    x1 ^= (x1 >>> 61) ^ (x1 >>> 39)
+//    x2 ^= (x2 >>>  1) ^ (x2 >>>  6)
+//    x3 ^= (x3 >>> 10) ^ (x3 >>> 17)
+//    x4 ^= (x4 >>>  7) ^ (x4 >>> 41)
+#define LAYERS \
+    XORQ R12, R8;  \
+    XORQ R11, R12; \
+    XORQ R9, R10;  \
+    MOVQ R9, AX;  NOTQ AX;  ANDQ R10, AX; \
+    MOVQ R10, BX; NOTQ BX;  ANDQ R11, BX; \
+    MOVQ R11, CX; NOTQ CX;  ANDQ R12, CX; \
+    MOVQ R12, DX; NOTQ DX;  ANDQ R8, DX;  \
+    MOVQ R8, R13; NOTQ R13; ANDQ R9, R13; \
+    XORQ AX, R8;   \
+    XORQ BX, R9;   \
+    XORQ CX, R10;  \
+    XORQ DX, R11;  \
+    XORQ R13, R12; \
+    XORQ R8, R9;   \
+    XORQ R12, R8;  \
+    XORQ R10, R11; \
+    NOTQ R10;      \
+    MOVQ R8, AX;  RORQ $19, AX; MOVQ R8, BX;  RORQ $28, BX; XORQ AX, R8;  XORQ BX, R8;  \
+    MOVQ R9, AX;  RORQ $61, AX; MOVQ R9, BX;  RORQ $39, BX; XORQ AX, R9;  XORQ BX, R9;  \
+    MOVQ R10, AX; RORQ $1, AX;  MOVQ R10, BX; RORQ $6, BX;  XORQ AX, R10; XORQ BX, R10; \
+    MOVQ R11, AX; RORQ $10, AX; MOVQ R11, BX; RORQ $17, BX; XORQ AX, R11; XORQ BX, R11; \
+    MOVQ R12, AX; RORQ $7, AX;  MOVQ R12, BX; RORQ $41, BX; XORQ AX, R12; XORQ BX, R12
+
+// ROUND applies one round with the round constant C.
+#define ROUND(C) \
+    XORQ $C, R10; \
+    LAYERS
+
+#define P6 \
+    ROUND(0x96); \
+    ROUND(0x87); \
+    ROUND(0x78); \
+    ROUND(0x69); \
+    ROUND(0x5a); \
+    ROUND(0x4b)
+
+#define P8 \
+    ROUND(0xb4); \
+    ROUND(0xa5); \
+    P6
+
+#define P12 \
+    ROUND(0xf0); \
+    ROUND(0xe1); \
+    ROUND(0xd2); \
+    ROUND(0xc3); \
+    P8
+
+// func round(s *state, c uint64)
+TEXT ·round(SB), NOSPLIT, $0-16
+    MOVQ s+0(FP), DI
+    MOVQ c+8(FP), SI
+    LOAD_STATE(DI)
+    XORQ SI, R10
+    LAYERS
+    STORE_STATE(DI)
+    RET
+
+// func p12(s *state)
+TEXT ·p12(SB), NOSPLIT, $0-8
+    MOVQ s+0(FP), DI
+    LOAD_STATE(DI)
+    P12
+    STORE_STATE(DI)
+    RET
+
+// func p8(s *state)
+TEXT ·p8(SB), NOSPLIT, $0-8
+    MOVQ s+0(FP), DI
+    LOAD_STATE(DI)
+    P8
+    STORE_STATE(DI)
+    RET
+
+// func p6(s *state)
+TEXT ·p6(SB), NOSPLIT, $0-8
+    MOVQ s+0(FP), DI
+    LOAD_STATE(DI)
+    P6
+    STORE_STATE(DI)
+    RET
+
+// func additionalData128a(s *state, ad []byte)
+TEXT ·additionalData128a(SB), NOSPLIT, $0-32
+    MOVQ s+0(FP), DI
+    MOVQ ad_base+8(FP), SI
+    MOVQ ad_len+16(FP), R14
+    LOAD_STATE(DI)
+
+adLoop:
+    CMPQ R14, $16
+    JB   adDone
+    MOVQ 0(SI), AX
+    BSWAPQ AX
+    XORQ AX, R8
+    MOVQ 8(SI), AX
+    BSWAPQ AX
+    XORQ AX, R9
+    P8
+    ADDQ $16, SI
+    SUBQ $16, R14
+    JMP  adLoop
+
+adDone:
+    STORE_STATE(DI)
+    RET
+
+// func encryptBlocks128a(s *state, dst, src []byte)
+TEXT ·encryptBlocks128a(SB), NOSPLIT, $0-56
+    MOVQ s+0(FP), DI
+    MOVQ dst_base+8(FP), R15
+    MOVQ src_base+32(FP), SI
+    MOVQ src_len+40(FP), R14
+    LOAD_STATE(DI)
+
+encLoop:
+    CMPQ R14, $16
+    JB   encDone
+    MOVQ 0(SI), AX
+    BSWAPQ AX
+    XORQ AX, R8
+    MOVQ 8(SI), AX
+    BSWAPQ AX
+    XORQ AX, R9
+    MOVQ R8, AX
+    BSWAPQ AX
+    MOVQ AX, 0(R15)
+    MOVQ R9, AX
+    BSWAPQ AX
+    MOVQ AX, 8(R15)
+    P8
+    ADDQ $16, SI
+    ADDQ $16, R15
+    SUBQ $16, R14
+    JMP  encLoop
+
+encDone:
+    STORE_STATE(DI)
+    RET
+
+// func decryptBlocks128a(s *state, dst, src []byte)
+TEXT ·decryptBlocks128a(SB), NOSPLIT, $0-56
+    MOVQ s+0(FP), DI
+    MOVQ dst_base+8(FP), R15
+    MOVQ src_base+32(FP), SI
+    MOVQ src_len+40(FP), R14
+    LOAD_STATE(DI)
+
+decLoop:
+    CMPQ R14, $16
+    JB   decDone
+    MOVQ 0(SI), AX
+    BSWAPQ AX
+    MOVQ R8, BX
+    XORQ AX, BX
+    BSWAPQ BX
+    MOVQ BX, 0(R15)
+    MOVQ AX, R8
+    MOVQ 8(SI), AX
+    BSWAPQ AX
+    MOVQ R9, BX
+    XORQ AX, BX
+    BSWAPQ BX
+    MOVQ BX, 8(R15)
+    MOVQ AX, R9
+    P8
+    ADDQ $16, SI
+    ADDQ $16, R15
+    SUBQ $16, R14
+    JMP  decLoop
+
+decDone:
+    STORE_STATE(DI)
+    RET
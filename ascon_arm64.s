@@ -0,0 +1,194 @@
+//go:build arm64 && ascon_asm_arm64 && gc && !purego && !ascon_interleaved
+
+#include "textflag.h"
+
+// The state x0..x4 is kept in R0..R4. R5..R10 are scratch
+// registers, and the block loops keep their pointers and
+// length in R12, R13 and R14. R11 holds the state pointer.
+
+#define LOAD_STATE(s) \
+    LDP  0(s), (R0, R1); \
+    LDP  16(s), (R2, R3); \
+    MOVD 32(s), R4
+
+#define STORE_STATE(s) \
+    STP  (R0, R1), 0(s); \
+    STP  (R2, R3), 16(s); \
+    MOVD R4, 32(s)
+
+// LAYERS applies the substitution and linear diffusion layers
+// of a round, as in the amd64 code. BIC computes the
+// ^x[i+1] & x[i+2] terms of the S-box in one instruction, and
+// the rotations of the linear layer are folded into EOR.
+#define LAYERS \
+    EOR  R4, R0, R0; \
+    EOR  R3, R4, R4; \
+    EOR  R1, R2, R2; \
+    BIC  R1, R2, R5; \
+    BIC  R2, R3, R6; \
+    BIC  R3, R4, R7; \
+    BIC  R4, R0, R8; \
+    BIC  R0, R1, R9; \
+    EOR  R5, R0, R0; \
+    EOR  R6, R1, R1; \
+    EOR  R7, R2, R2; \
+    EOR  R8, R3, R3; \
+    EOR  R9, R4, R4; \
+    EOR  R0, R1, R1; \
+    EOR  R4, R0, R0; \
+    EOR  R2, R3, R3; \
+    MVN  R2, R2; \
+    EOR  R0@>19, R0, R5; \
+    EOR  R0@>28, R5, R0; \
+    EOR  R1@>61, R1, R5; \
+    EOR  R1@>39, R5, R1; \
+    EOR  R2@>1, R2, R5; \
+    EOR  R2@>6, R5, R2; \
+    EOR  R3@>10, R3, R5; \
+    EOR  R3@>17, R5, R3; \
+    EOR  R4@>7, R4, R5; \
+    EOR  R4@>41, R5, R4
+
+// ROUND applies one round with the round constant C.
+#define ROUND(C) \
+    MOVD $C, R10; \
+    EOR  R10, R2, R2; \
+    LAYERS
+
+#define P6 \
+    ROUND(0x96); \
+    ROUND(0x87); \
+    ROUND(0x78); \
+    ROUND(0x69); \
+    ROUND(0x5a); \
+    ROUND(0x4b)
+
+#define P8 \
+    ROUND(0xb4); \
+    ROUND(0xa5); \
+    P6
+
+#define P12 \
+    ROUND(0xf0); \
+    ROUND(0xe1); \
+    ROUND(0xd2); \
+    ROUND(0xc3); \
+    P8
+
+// func round(s *state, c uint64)
+TEXT ·round(SB), NOSPLIT, $0-16
+    MOVD s+0(FP), R11
+    MOVD c+8(FP), R10
+    LOAD_STATE(R11)
+    EOR  R10, R2, R2
+    LAYERS
+    STORE_STATE(R11)
+    RET
+
+// func p12(s *state)
+TEXT ·p12(SB), NOSPLIT, $0-8
+    MOVD s+0(FP), R11
+    LOAD_STATE(R11)
+    P12
+    STORE_STATE(R11)
+    RET
+
+// func p8(s *state)
+TEXT ·p8(SB), NOSPLIT, $0-8
+    MOVD s+0(FP), R11
+    LOAD_STATE(R11)
+    P8
+    STORE_STATE(R11)
+    RET
+
+// func p6(s *state)
+TEXT ·p6(SB), NOSPLIT, $0-8
+    MOVD s+0(FP), R11
+    LOAD_STATE(R11)
+    P6
+    STORE_STATE(R11)
+    RET
+
+// func additionalData128a(s *state, ad []byte)
+TEXT ·additionalData128a(SB), NOSPLIT, $0-32
+    MOVD s+0(FP), R11
+    MOVD ad_base+8(FP), R12
+    MOVD ad_len+16(FP), R14
+    LOAD_STATE(R11)
+
+adLoop:
+    CMP  $16, R14
+    BLO  adDone
+    LDP  (R12), (R5, R6)
+    REV  R5, R5
+    REV  R6, R6
+    EOR  R5, R0, R0
+    EOR  R6, R1, R1
+    P8
+    ADD  $16, R12
+    SUB  $16, R14
+    B    adLoop
+
+adDone:
+    STORE_STATE(R11)
+    RET
+
+// func encryptBlocks128a(s *state, dst, src []byte)
+TEXT ·encryptBlocks128a(SB), NOSPLIT, $0-56
+    MOVD s+0(FP), R11
+    MOVD dst_base+8(FP), R13
+    MOVD src_base+32(FP), R12
+    MOVD src_len+40(FP), R14
+    LOAD_STATE(R11)
+
+encLoop:
+    CMP  $16, R14
+    BLO  encDone
+    LDP  (R12), (R5, R6)
+    REV  R5, R5
+    REV  R6, R6
+    EOR  R5, R0, R0
+    EOR  R6, R1, R1
+    REV  R0, R5
+    REV  R1, R6
+    STP  (R5, R6), (R13)
+    P8
+    ADD  $16, R12
+    ADD  $16, R13
+    SUB  $16, R14
+    B    encLoop
+
+encDone:
+    STORE_STATE(R11)
+    RET
+
+// func decryptBlocks128a(s *state, dst, src []byte)
+TEXT ·decryptBlocks128a(SB), NOSPLIT, $0-56
+    MOVD s+0(FP), R11
+    MOVD dst_base+8(FP), R13
+    MOVD src_base+32(FP), R12
+    MOVD src_len+40(FP), R14
+    LOAD_STATE(R11)
+
+decLoop:
+    CMP  $16, R14
+    BLO  decDone
+    LDP  (R12), (R5, R6)
+    REV  R5, R5
+    REV  R6, R6
+    EOR  R5, R0, R7
+    EOR  R6, R1, R8
+    REV  R7, R7
+    REV  R8, R8
+    STP  (R7, R8), (R13)
+    MOVD R5, R0
+    MOVD R6, R1
+    P8
+    ADD  $16, R12
+    ADD  $16, R13
+    SUB  $16, R14
+    B    decLoop
+
+decDone:
+    STORE_STATE(R11)
+    RET
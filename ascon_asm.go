@@ -0,0 +1,39 @@
+//go:build (amd64 || (arm64 && ascon_asm_arm64)) && gc && !purego && !ascon_interleaved
+
+package ascon
+
+// The assembly only uses baseline x86-64 and ARMv8.0
+// instructions, so it is used on every amd64 CPU. Only the
+// four-way permutation of the batch APIs, in ascon_x4_amd64.s,
+// needs AVX2, which is checked at run time. Build with the
+// purego tag to use the generic code, or with the
+// ascon_interleaved tag to use the 32-bit code, instead.
+//
+// The arm64 assembly has not yet been run on arm64 hardware or
+// under an emulator, so it is only built with the
+// ascon_asm_arm64 tag, and arm64 uses the generic code by
+// default.
+
+// implementation names the code selected by the build tags.
+const implementation = "asm"
+
+//go:noescape
+func additionalData128a(s *state, ad []byte)
+
+//go:noescape
+func encryptBlocks128a(s *state, dst, src []byte)
+
+//go:noescape
+func decryptBlocks128a(s *state, dst, src []byte)
+
+//go:noescape
+func round(s *state, c uint64)
+
+//go:noescape
+func p12(s *state)
+
+//go:noescape
+func p8(s *state)
+
+//go:noescape
+func p6(s *state)
@@ -0,0 +1,34 @@
+//go:build !(arm || mips || mipsle || ascon_interleaved) && (!(amd64 || (arm64 && ascon_asm_arm64) || (riscv64 && ascon_asm_riscv64)) || !gc || purego)
+
+package ascon
+
+// implementation names the code selected by the build tags.
+const implementation = "generic"
+
+func additionalData128a(s *state, ad []byte) {
+    additionalData128aGeneric(s, ad)
+}
+
+func encryptBlocks128a(s *state, dst, src []byte) {
+    encryptBlocks128aGeneric(s, dst, src)
+}
+
+func decryptBlocks128a(s *state, dst, src []byte) {
+    decryptBlocks128aGeneric(s, dst, src)
+}
+
+func round(s *state, C uint64) {
+    roundGeneric(s, C)
+}
+
+func p12(s *state) {
+    p12Generic(s)
+}
+
+func p8(s *state) {
+    p8Generic(s)
+}
+
+func p6(s *state) {
+    p6Generic(s)
+}
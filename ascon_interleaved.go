@@ -0,0 +1,240 @@
+//go:build arm || mips || mipsle || ascon_interleaved
+
+package ascon
+
+import (
+    "math/bits"
+)
+
+// On 32-bit targets the permutation uses the bit-interleaved
+// representation of the state: each 64-bit word is split into
+// the 32-bit words of its even and odd bits. The S-box works on
+// the halves independently, and every 64-bit rotation becomes
+// two 32-bit rotations, which 32-bit CPUs have natively while
+// the 64-bit ones are emulated with several shifts.
+//
+// The state outside of these functions is canonical. The block
+// loops keep the state interleaved across blocks and only
+// convert the data words that enter and leave the rate.
+//
+// Build with the ascon_interleaved tag to use this code on a
+// 64-bit host, for example to test it against the generic
+// code. 386 is not selected by default: the compiler rotates
+// 64-bit words there with SHLD and SHRD, and the conversions
+// cost more than the interleaved rounds save.
+
+// implementation names the code selected by the build tags.
+const implementation = "interleaved"
+
+// istate is the interleaved state: the even and odd halves of
+// x0, then of x1, and so on.
+type istate [10]uint32
+
+// even returns the 16 even bits of x, packed.
+func even(x uint32) uint32 {
+    x &= 0x55555555
+    x = (x | x>>1) & 0x33333333
+    x = (x | x>>2) & 0x0f0f0f0f
+    x = (x | x>>4) & 0x00ff00ff
+    x = (x | x>>8) & 0x0000ffff
+    return x
+}
+
+// spread is the inverse of even: it moves the 16 low bits of x
+// to the even bit positions.
+func spread(x uint32) uint32 {
+    x &= 0x0000ffff
+    x = (x | x<<8) & 0x00ff00ff
+    x = (x | x<<4) & 0x0f0f0f0f
+    x = (x | x<<2) & 0x33333333
+    x = (x | x<<1) & 0x55555555
+    return x
+}
+
+// interleave splits x into its even and odd bits.
+func interleave(x uint64) (e, o uint32) {
+    hi, lo := uint32(x>>32), uint32(x)
+    e = even(hi)<<16 | even(lo)
+    o = even(hi>>1)<<16 | even(lo>>1)
+    return e, o
+}
+
+// deinterleave is the inverse of interleave.
+func deinterleave(e, o uint32) uint64 {
+    hi := spread(e>>16) | spread(o>>16)<<1
+    lo := spread(e) | spread(o)<<1
+    return uint64(hi)<<32 | uint64(lo)
+}
+
+func (is *istate) load(s *state) {
+    for i := range s {
+        is[2*i], is[2*i+1] = interleave(s[i])
+    }
+}
+
+func (is *istate) store(s *state) {
+    for i := range s {
+        s[i] = deinterleave(is[2*i], is[2*i+1])
+    }
+}
+
+// iroundConstants are the round constants of p12, interleaved.
+var iroundConstants = func() (c [12][2]uint32) {
+    for i := range c {
+        c[i][0], c[i][1] = interleave(uint64(0xf0 - 0x0f*i))
+    }
+    return c
+}()
+
+func rotr32(x uint32, n int) uint32 {
+    return bits.RotateLeft32(x, -n)
+}
+
+// iround applies one round with the interleaved round constant
+// (ce, co).
+func iround(is *istate, ce, co uint32) {
+    e0, o0, e1, o1, e2, o2, e3, o3, e4, o4 := is[0], is[1], is[2], is[3], is[4], is[5], is[6], is[7], is[8], is[9]
+    e0, o0, e1, o1, e2, o2, e3, o3, e4, o4 = iroundWords(e0, o0, e1, o1, e2, o2, e3, o3, e4, o4, ce, co)
+    is[0], is[1], is[2], is[3], is[4], is[5], is[6], is[7], is[8], is[9] = e0, o0, e1, o1, e2, o2, e3, o3, e4, o4
+}
+
+// ipermute applies the last n rounds of p12.
+func ipermute(is *istate, n int) {
+    e0, o0, e1, o1, e2, o2, e3, o3, e4, o4 := is[0], is[1], is[2], is[3], is[4], is[5], is[6], is[7], is[8], is[9]
+    for _, c := range iroundConstants[12-n:] {
+        e0, o0, e1, o1, e2, o2, e3, o3, e4, o4 = iroundWords(e0, o0, e1, o1, e2, o2, e3, o3, e4, o4, c[0], c[1])
+    }
+    is[0], is[1], is[2], is[3], is[4], is[5], is[6], is[7], is[8], is[9] = e0, o0, e1, o1, e2, o2, e3, o3, e4, o4
+}
+
+// iroundWords is a round on the interleaved state held in
+// local variables, which lets the compiler keep it in
+// registers as far as the target allows.
+func iroundWords(e0, o0, e1, o1, e2, o2, e3, o3, e4, o4, ce, co uint32) (uint32, uint32, uint32, uint32, uint32, uint32, uint32, uint32, uint32, uint32) {
+    e2 ^= ce
+    o2 ^= co
+
+    // The S-box applies to the even and odd halves alike.
+    e0 ^= e4
+    e4 ^= e3
+    e2 ^= e1
+    t0 := e0 ^ (^e1 & e2)
+    t1 := e1 ^ (^e2 & e3)
+    t2 := e2 ^ (^e3 & e4)
+    t3 := e3 ^ (^e4 & e0)
+    t4 := e4 ^ (^e0 & e1)
+    e1 = t1 ^ t0
+    e0 = t0 ^ t4
+    e3 = t3 ^ t2
+    e2 = ^t2
+    e4 = t4
+
+    o0 ^= o4
+    o4 ^= o3
+    o2 ^= o1
+    t0 = o0 ^ (^o1 & o2)
+    t1 = o1 ^ (^o2 & o3)
+    t2 = o2 ^ (^o3 & o4)
+    t3 = o3 ^ (^o4 & o0)
+    t4 = o4 ^ (^o0 & o1)
+    o1 = t1 ^ t0
+    o0 = t0 ^ t4
+    o3 = t3 ^ t2
+    o2 = ^t2
+    o4 = t4
+
+    // A rotation by 2k rotates both halves by k. A rotation by
+    // 2k+1 swaps them, rotating the odd half by k and the even
+    // half by k+1.
+    //
+    //    x0: 19 = 2*9+1,  28 = 2*14
+    //    x1: 61 = 2*30+1, 39 = 2*19+1
+    //    x2:  1 = 2*0+1,   6 = 2*3
+    //    x3: 10 = 2*5,    17 = 2*8+1
+    //    x4:  7 = 2*3+1,  41 = 2*20+1
+    e0, o0 = e0^rotr32(o0, 9)^rotr32(e0, 14), o0^rotr32(e0, 10)^rotr32(o0, 14)
+    e1, o1 = e1^rotr32(o1, 30)^rotr32(o1, 19), o1^rotr32(e1, 31)^rotr32(e1, 20)
+    e2, o2 = e2^o2^rotr32(e2, 3), o2^rotr32(e2, 1)^rotr32(o2, 3)
+    e3, o3 = e3^rotr32(e3, 5)^rotr32(o3, 8), o3^rotr32(o3, 5)^rotr32(e3, 9)
+    e4, o4 = e4^rotr32(o4, 3)^rotr32(o4, 20), o4^rotr32(e4, 4)^rotr32(e4, 21)
+    return e0, o0, e1, o1, e2, o2, e3, o3, e4, o4
+}
+
+func permute(s *state, n int) {
+    var is istate
+    is.load(s)
+    ipermute(&is, n)
+    is.store(s)
+}
+
+func additionalData128a(s *state, ad []byte) {
+    var is istate
+    is.load(s)
+    for len(ad) >= BlockSize128a {
+        e0, o0 := interleave(be64(ad[0:8]))
+        e1, o1 := interleave(be64(ad[8:16]))
+        is[0] ^= e0
+        is[1] ^= o0
+        is[2] ^= e1
+        is[3] ^= o1
+        ipermute(&is, 8)
+        ad = ad[BlockSize128a:]
+    }
+    is.store(s)
+}
+
+func encryptBlocks128a(s *state, dst, src []byte) {
+    var is istate
+    is.load(s)
+    for len(src) >= BlockSize128a {
+        e0, o0 := interleave(be64(src[0:8]))
+        e1, o1 := interleave(be64(src[8:16]))
+        is[0] ^= e0
+        is[1] ^= o0
+        is[2] ^= e1
+        is[3] ^= o1
+        putbe64(dst[0:8], deinterleave(is[0], is[1]))
+        putbe64(dst[8:16], deinterleave(is[2], is[3]))
+        ipermute(&is, 8)
+        src = src[BlockSize128a:]
+        dst = dst[BlockSize128a:]
+    }
+    is.store(s)
+}
+
+func decryptBlocks128a(s *state, dst, src []byte) {
+    var is istate
+    is.load(s)
+    for len(src) >= BlockSize128a {
+        c0 := be64(src[0:8])
+        c1 := be64(src[8:16])
+        putbe64(dst[0:8], deinterleave(is[0], is[1])^c0)
+        putbe64(dst[8:16], deinterleave(is[2], is[3])^c1)
+        is[0], is[1] = interleave(c0)
+        is[2], is[3] = interleave(c1)
+        ipermute(&is, 8)
+        src = src[BlockSize128a:]
+        dst = dst[BlockSize128a:]
+    }
+    is.store(s)
+}
+
+func round(s *state, C uint64) {
+    var is istate
+    is.load(s)
+    ce, co := interleave(C)
+    iround(&is, ce, co)
+    is.store(s)
+}
+
+func p12(s *state) {
+    permute(s, 12)
+}
+
+func p8(s *state) {
+    permute(s, 8)
+}
+
+func p6(s *state) {
+    permute(s, 6)
+}
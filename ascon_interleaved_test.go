@@ -0,0 +1,24 @@
+//go:build arm || mips || mipsle || ascon_interleaved
+
+package ascon
+
+import (
+    "math/rand"
+    "testing"
+)
+
+func TestInterleave(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for i := 0; i < 1000; i++ {
+        x := rng.Uint64()
+        e, o := interleave(x)
+        for j := 0; j < 32; j++ {
+            if uint64(e>>j&1) != x>>(2*j)&1 || uint64(o>>j&1) != x>>(2*j+1)&1 {
+                t.Fatalf("%#x: bit %d: got %#x, %#x", x, j, e, o)
+            }
+        }
+        if got := deinterleave(e, o); got != x {
+            t.Fatalf("expected %#x, got %#x", x, got)
+        }
+    }
+}
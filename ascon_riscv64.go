@@ -0,0 +1,40 @@
+//go:build riscv64 && ascon_asm_riscv64 && gc && !purego && !ascon_interleaved
+
+package ascon
+
+// On riscv64 only the permutation is written in assembly. The
+// block functions are the generic ones, which call it: loading
+// the big-endian words takes REV8, which the baseline RV64GC
+// CPUs lack, and the permutation is most of the work anyway.
+//
+// The assembly has not yet been run on riscv64 hardware or
+// under an emulator, so it is only built with the
+// ascon_asm_riscv64 tag, and riscv64 uses the generic code by
+// default.
+
+// implementation names the code selected by the build tags.
+const implementation = "asm"
+
+func additionalData128a(s *state, ad []byte) {
+    additionalData128aGeneric(s, ad)
+}
+
+func encryptBlocks128a(s *state, dst, src []byte) {
+    encryptBlocks128aGeneric(s, dst, src)
+}
+
+func decryptBlocks128a(s *state, dst, src []byte) {
+    decryptBlocks128aGeneric(s, dst, src)
+}
+
+//go:noescape
+func round(s *state, c uint64)
+
+//go:noescape
+func p12(s *state)
+
+//go:noescape
+func p8(s *state)
+
+//go:noescape
+func p6(s *state)
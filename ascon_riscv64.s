@@ -0,0 +1,117 @@
+//go:build riscv64 && ascon_asm_riscv64 && gc && !purego && !ascon_interleaved
+
+#include "textflag.h"
+
+// The state x0..x4 is kept in X10..X14, and X15..X21 are
+// scratch registers. X5 holds the state pointer. X31 is left
+// alone: the assembler uses it to expand RORI and ANDN into
+// base instructions on CPUs without Zbb, that is unless
+// GORISCV64 is rva22u64 or later.
+
+#define LOAD_STATE(s) \
+    MOV 0(s), X10; \
+    MOV 8(s), X11; \
+    MOV 16(s), X12; \
+    MOV 24(s), X13; \
+    MOV 32(s), X14
+
+#define STORE_STATE(s) \
+    MOV X10, 0(s); \
+    MOV X11, 8(s); \
+    MOV X12, 16(s); \
+    MOV X13, 24(s); \
+    MOV X14, 32(s)
+
+// SIGMA applies x ^= (x >>> a) ^ (x >>> b).
+#define SIGMA(x, a, b) \
+    RORI $a, x, X20; \
+    RORI $b, x, X21; \
+    XOR  X20, x; \
+    XOR  X21, x
+
+// LAYERS applies the substitution and linear diffusion layers
+// of a round. ANDN computes the ^x[i+1] & x[i+2] terms of the
+// S-box.
+#define LAYERS \
+    XOR  X14, X10; \
+    XOR  X13, X14; \
+    XOR  X11, X12; \
+    ANDN X11, X12, X15; \
+    ANDN X12, X13, X16; \
+    ANDN X13, X14, X17; \
+    ANDN X14, X10, X18; \
+    ANDN X10, X11, X19; \
+    XOR  X15, X10; \
+    XOR  X16, X11; \
+    XOR  X17, X12; \
+    XOR  X18, X13; \
+    XOR  X19, X14; \
+    XOR  X10, X11; \
+    XOR  X14, X10; \
+    XOR  X12, X13; \
+    NOT  X12, X12; \
+    SIGMA(X10, 19, 28); \
+    SIGMA(X11, 61, 39); \
+    SIGMA(X12, 1, 6); \
+    SIGMA(X13, 10, 17); \
+    SIGMA(X14, 7, 41)
+
+// ROUND applies one round with the round constant C, which
+// fits in the immediate of XORI.
+#define ROUND(C) \
+    XOR  $C, X12; \
+    LAYERS
+
+#define P6 \
+    ROUND(0x96); \
+    ROUND(0x87); \
+    ROUND(0x78); \
+    ROUND(0x69); \
+    ROUND(0x5a); \
+    ROUND(0x4b)
+
+#define P8 \
+    ROUND(0xb4); \
+    ROUND(0xa5); \
+    P6
+
+#define P12 \
+    ROUND(0xf0); \
+    ROUND(0xe1); \
+    ROUND(0xd2); \
+    ROUND(0xc3); \
+    P8
+
+// func round(s *state, c uint64)
+TEXT ·round(SB), NOSPLIT, $0-16
+    MOV  s+0(FP), X5
+    MOV  c+8(FP), X15
+    LOAD_STATE(X5)
+    XOR  X15, X12
+    LAYERS
+    STORE_STATE(X5)
+    RET
+
+// func p12(s *state)
+TEXT ·p12(SB), NOSPLIT, $0-8
+    MOV  s+0(FP), X5
+    LOAD_STATE(X5)
+    P12
+    STORE_STATE(X5)
+    RET
+
+// func p8(s *state)
+TEXT ·p8(SB), NOSPLIT, $0-8
+    MOV  s+0(FP), X5
+    LOAD_STATE(X5)
+    P8
+    STORE_STATE(X5)
+    RET
+
+// func p6(s *state)
+TEXT ·p6(SB), NOSPLIT, $0-8
+    MOV  s+0(FP), X5
+    LOAD_STATE(X5)
+    P6
+    STORE_STATE(X5)
+    RET
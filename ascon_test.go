@@ -5,14 +5,16 @@ import (
     "bytes"
     "crypto/cipher"
     "encoding/hex"
+    "errors"
     "fmt"
     "math/rand"
     "os"
-    "path/filepath"
     "reflect"
     "strings"
     "testing"
     "testing/quick"
+
+    "github.com/pedroalbanese/go-ascon/ascontest"
 )
 
 var stateType = reflect.TypeOf([5]uint64{})
@@ -24,11 +26,11 @@ func randState(rng *rand.Rand) state {
     }
     x := v.Interface().([5]uint64)
     return state{
-        x0: x[0],
-        x1: x[1],
-        x2: x[2],
-        x3: x[3],
-        x4: x[4],
+        0: x[0],
+        1: x[1],
+        2: x[2],
+        3: x[3],
+        4: x[4],
     }
 }
 
@@ -71,38 +73,189 @@ func TestPermute(t *testing.T) {
     }
 }
 
+func TestBlocks128a(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for i := 0; i < 200; i++ {
+        s := randState(rng)
+        src := make([]byte, rng.Intn(8*BlockSize128a))
+        rng.Read(src)
+        n := len(src) &^ (BlockSize128a - 1)
+
+        want, got := s, s
+        additionalData128aGeneric(&want, src)
+        additionalData128a(&got, src)
+        if want != got {
+            t.Fatalf("#%d: additional data: expected %v, got %v", i, want, got)
+        }
+
+        want, got = s, s
+        wantDst, gotDst := make([]byte, n), make([]byte, n)
+        encryptBlocks128aGeneric(&want, wantDst, src[:n])
+        encryptBlocks128a(&got, gotDst, src[:n])
+        if want != got || !bytes.Equal(wantDst, gotDst) {
+            t.Fatalf("#%d: encrypt: expected %v %x, got %v %x", i, want, wantDst, got, gotDst)
+        }
+
+        want, got = s, s
+        decryptBlocks128aGeneric(&want, wantDst, src[:n])
+        decryptBlocks128a(&got, gotDst, src[:n])
+        if want != got || !bytes.Equal(wantDst, gotDst) {
+            t.Fatalf("#%d: decrypt: expected %v %x, got %v %x", i, want, wantDst, got, gotDst)
+        }
+    }
+}
+
+func BenchmarkPermute(b *testing.B) {
+    for _, tc := range []struct {
+        name string
+        fn   func(*state)
+    }{
+        {"p12", p12},
+        {"p12Generic", p12Generic},
+        {"p8", p8},
+        {"p8Generic", p8Generic},
+    } {
+        b.Run(tc.name, func(b *testing.B) {
+            var s state
+            for i := 0; i < b.N; i++ {
+                tc.fn(&s)
+            }
+        })
+    }
+}
+
 func TestVectors128(t *testing.T) {
-    testVectors(t, New128, filepath.Join("testdata", "vectors_128.txt"))
+    ascontest.RunKAT(t, New128, ascontest.KAT(ascontest.Ascon128))
 }
 
 func TestVectors128a(t *testing.T) {
-    testVectors(t, New128a, filepath.Join("testdata", "vectors_128a.txt"))
+    ascontest.RunKAT(t, New128a, ascontest.KAT(ascontest.Ascon128a))
 }
 
+func TestVectors80pq(t *testing.T) {
+    ascontest.RunKAT(t, New80pq, ascontest.KAT(ascontest.Ascon80pq))
+}
+
+func TestVectorsAEAD128(t *testing.T) {
+    ascontest.RunKAT(t, NewAEAD128, ascontest.KAT(ascontest.AsconAEAD128))
+}
+
+func TestWycheproof(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        fn   func([]byte) (cipher.AEAD, error)
+        v    ascontest.Variant
+    }{
+        {"128", New128, ascontest.Ascon128},
+        {"128a", New128a, ascontest.Ascon128a},
+        {"80pq", New80pq, ascontest.Ascon80pq},
+        {"AEAD128", NewAEAD128, ascontest.AsconAEAD128},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            ascontest.RunWycheproof(t, tc.fn, ascontest.Wycheproof(tc.v))
+        })
+    }
+}
+
+// testVectors runs the known answer tests in the file at path
+// with ascontest.RunKAT.
 func testVectors(t *testing.T, fn func([]byte) (cipher.AEAD, error), path string) {
-    vecs, err := readVecs(path)
+    data, err := os.ReadFile(path)
     if err != nil {
         t.Fatal(err)
     }
-    for i, v := range vecs {
-        c, err := fn(v.key)
-        if err != nil {
-            t.Fatalf("#%d: %v", i+1, err)
-        }
-        ciphertext := c.Seal(nil, v.nonce, v.pt, v.ad)
-        if !bytes.Equal(ciphertext, v.ct) {
-            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ciphertext)
-        }
-        plaintext, err := c.Open(nil, v.nonce, v.ct, v.ad)
-        if err != nil {
-            t.Fatalf("#%d: %v", i+1, err)
-        }
-        if !bytes.Equal(plaintext, v.pt) {
-            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, plaintext)
-        }
+    ascontest.RunKAT(t, fn, data)
+}
+
+func TestTruncatedTag(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        fn   func([]byte) (cipher.AEAD, error)
+        fnT  func([]byte, int) (cipher.AEAD, error)
+    }{
+        {"128", New128, New128WithTagSize},
+        {"128a", New128a, New128aWithTagSize},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            key := make([]byte, KeySize)
+            nonce := make([]byte, NonceSize)
+            pt := []byte("a short radio frame")
+            ad := []byte("header")
+
+            full, err := tc.fn(key)
+            if err != nil {
+                t.Fatal(err)
+            }
+            want := full.Seal(nil, nonce, pt, ad)
+
+            for _, n := range []int{0, 1, MinTagSize - 1, TagSize + 1} {
+                if _, err := tc.fnT(key, n); err == nil {
+                    t.Fatalf("tag size %d: expected an error", n)
+                }
+            }
+
+            for n := MinTagSize; n <= TagSize; n++ {
+                c, err := tc.fnT(key, n)
+                if err != nil {
+                    t.Fatalf("tag size %d: %v", n, err)
+                }
+                if c.Overhead() != n {
+                    t.Fatalf("tag size %d: expected overhead %d, got %d",
+                        n, n, c.Overhead())
+                }
+                ct := c.Seal(nil, nonce, pt, ad)
+                if !bytes.Equal(ct, want[:len(pt)+n]) {
+                    t.Fatalf("tag size %d: expected %#x, got %#x",
+                        n, want[:len(pt)+n], ct)
+                }
+                got, err := c.Open(nil, nonce, ct, ad)
+                if err != nil {
+                    t.Fatalf("tag size %d: %v", n, err)
+                }
+                if !bytes.Equal(got, pt) {
+                    t.Fatalf("tag size %d: expected %#x, got %#x", n, pt, got)
+                }
+
+                ct[len(ct)-1] ^= 1
+                if _, err := c.Open(nil, nonce, ct, ad); err == nil {
+                    t.Fatalf("tag size %d: expected an error", n)
+                }
+                if _, err := c.Open(nil, nonce, ct[:n-1], ad); err == nil {
+                    t.Fatalf("tag size %d: expected an error", n)
+                }
+            }
+        })
     }
 }
 
+func BenchmarkSeal8_128a(b *testing.B) {
+    benchmarkSeal(b, New128a, make([]byte, 8))
+}
+
+func BenchmarkOpen8_128a(b *testing.B) {
+    benchmarkOpen(b, New128a, make([]byte, 8))
+}
+
+func BenchmarkSeal16_128a(b *testing.B) {
+    benchmarkSeal(b, New128a, make([]byte, 16))
+}
+
+func BenchmarkOpen16_128a(b *testing.B) {
+    benchmarkOpen(b, New128a, make([]byte, 16))
+}
+
+func BenchmarkSeal16NoAD_128a(b *testing.B) {
+    benchmarkSealAD(b, New128a, make([]byte, 16), nil)
+}
+
+func BenchmarkSeal64_128a(b *testing.B) {
+    benchmarkSeal(b, New128a, make([]byte, 64))
+}
+
+func BenchmarkOpen64_128a(b *testing.B) {
+    benchmarkOpen(b, New128a, make([]byte, 64))
+}
+
 func BenchmarkSeal1K_128a(b *testing.B) {
     benchmarkSeal(b, New128a, make([]byte, 1024))
 }
@@ -119,6 +272,42 @@ func BenchmarkOpen8K_128a(b *testing.B) {
     benchmarkOpen(b, New128a, make([]byte, 8*1024))
 }
 
+func BenchmarkSeal64K_128a(b *testing.B) {
+    benchmarkSeal(b, New128a, make([]byte, 64*1024))
+}
+
+func BenchmarkOpen64K_128a(b *testing.B) {
+    benchmarkOpen(b, New128a, make([]byte, 64*1024))
+}
+
+func BenchmarkSeal8_128(b *testing.B) {
+    benchmarkSeal(b, New128, make([]byte, 8))
+}
+
+func BenchmarkOpen8_128(b *testing.B) {
+    benchmarkOpen(b, New128, make([]byte, 8))
+}
+
+func BenchmarkSeal16_128(b *testing.B) {
+    benchmarkSeal(b, New128, make([]byte, 16))
+}
+
+func BenchmarkOpen16_128(b *testing.B) {
+    benchmarkOpen(b, New128, make([]byte, 16))
+}
+
+func BenchmarkSeal16NoAD_128(b *testing.B) {
+    benchmarkSealAD(b, New128, make([]byte, 16), nil)
+}
+
+func BenchmarkSeal64_128(b *testing.B) {
+    benchmarkSeal(b, New128, make([]byte, 64))
+}
+
+func BenchmarkOpen64_128(b *testing.B) {
+    benchmarkOpen(b, New128, make([]byte, 64))
+}
+
 func BenchmarkSeal1K_128(b *testing.B) {
     benchmarkSeal(b, New128, make([]byte, 1024))
 }
@@ -135,12 +324,23 @@ func BenchmarkOpen8K_128(b *testing.B) {
     benchmarkOpen(b, New128, make([]byte, 8*1024))
 }
 
+func BenchmarkSeal64K_128(b *testing.B) {
+    benchmarkSeal(b, New128, make([]byte, 64*1024))
+}
+
+func BenchmarkOpen64K_128(b *testing.B) {
+    benchmarkOpen(b, New128, make([]byte, 64*1024))
+}
+
 func benchmarkSeal(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf []byte) {
+    benchmarkSealAD(b, fn, buf, make([]byte, 13))
+}
+
+func benchmarkSealAD(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf, ad []byte) {
     b.SetBytes(int64(len(buf)))
 
     key := make([]byte, KeySize)
     nonce := make([]byte, NonceSize)
-    ad := make([]byte, 13)
     aead, err := fn(key)
     if err != nil {
         b.Fatal(err)
@@ -181,6 +381,10 @@ type vector struct {
     pt    []byte
     ad    []byte
     ct    []byte
+    msg   []byte
+    md    []byte
+    z     []byte
+    tag   []byte
 }
 
 func (v *vector) set(field string, p []byte) bool {
@@ -195,6 +399,14 @@ func (v *vector) set(field string, p []byte) bool {
         v.ad = p
     case "CT":
         v.ct = p
+    case "Msg":
+        v.msg = p
+    case "MD":
+        v.md = p
+    case "Z":
+        v.z = p
+    case "Tag":
+        v.tag = p
     default:
         return false
     }
@@ -236,3 +448,478 @@ func readVecs(path string) ([]vector, error) {
     }
     return vecs, nil
 }
+
+func TestDetached(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        fn   func([]byte) (cipher.AEAD, error)
+    }{
+        {"128", New128},
+        {"128a", New128a},
+        {"80pq", New80pq},
+        {"AEAD128", NewAEAD128},
+        {"128a/8", func(key []byte) (cipher.AEAD, error) {
+            return New128aWithTagSize(key, MinTagSize)
+        }},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            key := make([]byte, KeySize)
+            if tc.name == "80pq" {
+                key = make([]byte, KeySize80pq)
+            }
+            c, err := tc.fn(key)
+            if err != nil {
+                t.Fatal(err)
+            }
+            aead := c.(DetachedAEAD)
+            nonce := make([]byte, NonceSize)
+            ad := []byte("header")
+
+            for n := 0; n < 40; n++ {
+                pt := make([]byte, n)
+                for i := range pt {
+                    pt[i] = byte(i)
+                }
+                want := aead.Seal(nil, nonce, pt, ad)
+
+                ct, tag := aead.SealDetached(nil, nil, nonce, pt, ad)
+                if len(tag) != aead.Overhead() {
+                    t.Fatalf("%d: expected %d byte tag, got %d", n, aead.Overhead(), len(tag))
+                }
+                if !bytes.Equal(append(ct, tag...), want) {
+                    t.Fatalf("%d: expected %#x, got %#x || %#x", n, want, ct, tag)
+                }
+
+                // In place.
+                buf := append([]byte(nil), pt...)
+                buf, tag = aead.SealDetached(buf[:0], tag[:0], nonce, buf, ad)
+                if !bytes.Equal(buf, ct) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, ct, buf)
+                }
+                buf, err = aead.OpenDetached(buf[:0], nonce, buf, tag, ad)
+                if err != nil {
+                    t.Fatalf("%d: %v", n, err)
+                }
+                if !bytes.Equal(buf, pt) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, pt, buf)
+                }
+
+                if _, err := aead.OpenDetached(nil, nonce, ct, tag[:len(tag)-1], ad); err == nil {
+                    t.Fatalf("%d: expected an error", n)
+                }
+
+                tag[0] ^= 1
+                out := make([]byte, n)
+                for i := range out {
+                    out[i] = 0xff
+                }
+                if _, err := aead.OpenDetached(out[:0], nonce, ct, tag, ad); err == nil {
+                    t.Fatalf("%d: expected an error", n)
+                }
+                for i, b := range out {
+                    if b != 0 {
+                        t.Fatalf("%d: output byte %d not zeroed", n, i)
+                    }
+                }
+            }
+        })
+    }
+}
+
+func TestSealToOpenTo(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            c, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            aead := c.(*AEAD)
+            nonce := make([]byte, NonceSize)
+            ad := []byte("header")
+
+            for n := 0; n < 40; n++ {
+                pt := make([]byte, n)
+                for i := range pt {
+                    pt[i] = byte(i)
+                }
+                want := aead.Seal(nil, nonce, pt, ad)
+
+                out := make([]byte, len(want)+3)
+                if _, err := aead.SealTo(out[:len(want)-1], nonce, pt, ad); err != errShortBuffer {
+                    t.Fatalf("%d: expected %v, got %v", n, errShortBuffer, err)
+                }
+                m, err := aead.SealTo(out, nonce, pt, ad)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(out[:m], want) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, want, out[:m])
+                }
+
+                if n > 0 {
+                    if _, err := aead.OpenTo(out[:n-1], nonce, want, ad); err != errShortBuffer {
+                        t.Fatalf("%d: expected %v, got %v", n, errShortBuffer, err)
+                    }
+                }
+                m, err = aead.OpenTo(out, nonce, want, ad)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(out[:m], pt) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, pt, out[:m])
+                }
+
+                // In place.
+                buf := append([]byte(nil), pt...)
+                buf = append(buf, make([]byte, aead.Overhead())...)
+                if _, err := aead.SealTo(buf, nonce, buf[:n], ad); err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(buf, want) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, want, buf)
+                }
+                if _, err := aead.OpenTo(buf, nonce, buf, ad); err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(buf[:n], pt) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, pt, buf[:n])
+                }
+
+                bad := append([]byte(nil), want...)
+                bad[0] ^= 1
+                if _, err := aead.OpenTo(out, nonce, bad, ad); !errors.Is(err, ErrAuthentication) {
+                    t.Fatalf("%d: expected %v, got %v", n, ErrAuthentication, err)
+                }
+            }
+        })
+    }
+}
+
+func TestSealToAllocs(t *testing.T) {
+    c, err := New128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    aead := c.(*AEAD)
+    nonce := make([]byte, NonceSize)
+    ad := make([]byte, 13)
+    pt := make([]byte, 1024)
+    out := make([]byte, len(pt)+TagSize)
+
+    allocs := testing.AllocsPerRun(100, func() {
+        if _, err := aead.SealTo(out, nonce, pt, ad); err != nil {
+            t.Fatal(err)
+        }
+    })
+    if allocs != 0 {
+        t.Fatalf("SealTo: expected 0 allocations, got %v", allocs)
+    }
+    allocs = testing.AllocsPerRun(100, func() {
+        if _, err := aead.OpenTo(pt, nonce, out, ad); err != nil {
+            t.Fatal(err)
+        }
+    })
+    if allocs != 0 {
+        t.Fatalf("OpenTo: expected 0 allocations, got %v", allocs)
+    }
+}
+
+// TestAllocs checks that Seal and Open do not allocate when dst
+// has enough capacity, for messages and additional data of
+// every alignment to the rate.
+func TestAllocs(t *testing.T) {
+    for _, v := range []struct {
+        name    string
+        new     func([]byte) (cipher.AEAD, error)
+        keySize int
+    }{
+        {"128", New128, KeySize},
+        {"128a", New128a, KeySize},
+        {"80pq", New80pq, KeySize80pq},
+        {"AEAD128", NewAEAD128, KeySize},
+    } {
+        t.Run(v.name, func(t *testing.T) {
+            aead, err := v.new(make([]byte, v.keySize))
+            if err != nil {
+                t.Fatal(err)
+            }
+            nonce := make([]byte, NonceSize)
+            for _, size := range []int{0, 1, 7, 8, 15, 16, 17, 100} {
+                for _, adSize := range []int{0, 13, 32} {
+                    pt := make([]byte, size)
+                    ad := make([]byte, adSize)
+                    ct := aead.Seal(nil, nonce, pt, ad)
+                    sealDst := make([]byte, 0, len(ct))
+                    openDst := make([]byte, 0, len(pt))
+
+                    allocs := testing.AllocsPerRun(50, func() {
+                        aead.Seal(sealDst, nonce, pt, ad)
+                    })
+                    if allocs != 0 {
+                        t.Fatalf("%d/%d: Seal: expected 0 allocations, got %v", size, adSize, allocs)
+                    }
+                    allocs = testing.AllocsPerRun(50, func() {
+                        if _, err := aead.Open(openDst, nonce, ct, ad); err != nil {
+                            t.Fatal(err)
+                        }
+                    })
+                    if allocs != 0 {
+                        t.Fatalf("%d/%d: Open: expected 0 allocations, got %v", size, adSize, allocs)
+                    }
+
+                    bad := append([]byte(nil), ct...)
+                    bad[len(bad)-1] ^= 1
+                    allocs = testing.AllocsPerRun(50, func() {
+                        if _, err := aead.Open(openDst, nonce, bad, ad); err == nil {
+                            t.Fatal("Open accepted a bad tag")
+                        }
+                    })
+                    if allocs != 0 {
+                        t.Fatalf("%d/%d: failed Open: expected 0 allocations, got %v", size, adSize, allocs)
+                    }
+                }
+            }
+        })
+    }
+}
+
+// TestNewKeyAllocs checks that an AEAD from a New*Key function
+// stays off the heap if it does not escape, for targets without
+// a heap to spare.
+func TestNewKeyAllocs(t *testing.T) {
+    var key [KeySize80pq]byte
+    nonce := make([]byte, NonceSize)
+    buf := make([]byte, 64+TagSize)
+    for name, fn := range map[string]func(){
+        "128":  func() { New128Key((*[KeySize]byte)(key[:])).SealTo(buf, nonce, buf[:64], nil) },
+        "128a": func() { New128aKey((*[KeySize]byte)(key[:])).SealTo(buf, nonce, buf[:64], nil) },
+        "80pq": func() { New80pqKey(&key).SealTo(buf, nonce, buf[:64], nil) },
+    } {
+        if allocs := testing.AllocsPerRun(50, fn); allocs != 0 {
+            t.Errorf("%s: expected 0 allocations, got %v", name, allocs)
+        }
+    }
+}
+
+func BenchmarkSealTo1K_128a(b *testing.B) {
+    benchmarkSealTo(b, New128a, make([]byte, 1024))
+}
+
+func BenchmarkOpenTo1K_128a(b *testing.B) {
+    benchmarkOpenTo(b, New128a, make([]byte, 1024))
+}
+
+func BenchmarkSealTo64_128a(b *testing.B) {
+    benchmarkSealTo(b, New128a, make([]byte, 64))
+}
+
+func BenchmarkOpenTo64_128a(b *testing.B) {
+    benchmarkOpenTo(b, New128a, make([]byte, 64))
+}
+
+func benchmarkSealTo(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf []byte) {
+    b.SetBytes(int64(len(buf)))
+    b.ReportAllocs()
+
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    ad := make([]byte, 13)
+    aead, err := fn(key)
+    if err != nil {
+        b.Fatal(err)
+    }
+    out := make([]byte, len(buf)+aead.Overhead())
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := aead.(*AEAD).SealTo(out, nonce, buf, ad); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
+
+func benchmarkOpenTo(b *testing.B, fn func([]byte) (cipher.AEAD, error), buf []byte) {
+    b.SetBytes(int64(len(buf)))
+    b.ReportAllocs()
+
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    ad := make([]byte, 13)
+    aead, err := fn(key)
+    if err != nil {
+        b.Fatal(err)
+    }
+    out := aead.Seal(nil, nonce, buf, ad)
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := aead.(*AEAD).OpenTo(buf, nonce, out, ad); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
+
+func TestArrayKey(t *testing.T) {
+    key := []byte("0123456789abcdefghij")
+    nonce := make([]byte, NonceSize)
+    pt := []byte("plaintext")
+    ad := []byte("ad")
+
+    k, err := KeyFromSlice(key[:KeySize])
+    if err != nil {
+        t.Fatal(err)
+    }
+    k80, err := KeyFromSlice80pq(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, tc := range []struct {
+        name string
+        fn   func([]byte) (cipher.AEAD, error)
+        key  []byte
+        a    *AEAD
+    }{
+        {"128", New128, key[:KeySize], New128Key(&k)},
+        {"128a", New128a, key[:KeySize], New128aKey(&k)},
+        {"80pq", New80pq, key, New80pqKey(&k80)},
+    } {
+        c, err := tc.fn(tc.key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        want := c.Seal(nil, nonce, pt, ad)
+        if got := tc.a.Seal(nil, nonce, pt, ad); !bytes.Equal(got, want) {
+            t.Fatalf("%s: expected %#x, got %#x", tc.name, want, got)
+        }
+    }
+
+    if _, err := KeyFromSlice(key); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := KeyFromSlice80pq(key[:KeySize]); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+// TestNonceLength checks that the opening methods reject a
+// nonce of the wrong length with an error, and that the sealing
+// methods still panic.
+func TestNonceLength(t *testing.T) {
+    key := make([]byte, KeySizeSIV)
+    aeads := map[string]cipher.AEAD{}
+    add := func(name string, aead cipher.AEAD, err error) {
+        if err != nil {
+            t.Fatalf("%s: %v", name, err)
+        }
+        aeads[name] = aead
+    }
+    for _, tc := range sessionTests {
+        aead, err := tc.fn(key[:tc.key])
+        add(tc.name, aead, err)
+    }
+    aead, err := NewSIV(key[:KeySizeSIV])
+    add("SIV", aead, err)
+    aead, err = NewCommitting128(key[:KeySize])
+    add("committing", aead, err)
+    aead, err = NewRekeying128(key[:KeySize])
+    add("rekeying", aead, err)
+    aead, err = NewX128a(key[:KeySize])
+    add("X128a", aead, err)
+
+    ct := make([]byte, 64)
+    for name, aead := range aeads {
+        for _, n := range []int{0, 15, 17} {
+            nonce := make([]byte, n)
+            if _, err := aead.Open(nil, nonce, ct, nil); !errors.Is(err, ErrInvalidNonceSize) {
+                t.Fatalf("%s: Open: expected %v for a %d-byte nonce, got %v", name, ErrInvalidNonceSize, n, err)
+            }
+            func() {
+                defer func() {
+                    if recover() == nil {
+                        t.Fatalf("%s: Seal accepted a %d-byte nonce", name, n)
+                    }
+                }()
+                aead.Seal(nil, nonce, nil, nil)
+            }()
+
+            a, ok := aead.(*AEAD)
+            if !ok {
+                continue
+            }
+            if _, err := a.OpenDetached(nil, nonce, ct[:16], ct[16:16+a.tagSize], nil); !errors.Is(err, ErrInvalidNonceSize) {
+                t.Fatalf("%s: OpenDetached: expected %v, got %v", name, ErrInvalidNonceSize, err)
+            }
+            if _, err := a.OpenTo(make([]byte, len(ct)), nonce, ct, nil); !errors.Is(err, ErrInvalidNonceSize) {
+                t.Fatalf("%s: OpenTo: expected %v, got %v", name, ErrInvalidNonceSize, err)
+            }
+            if _, err := a.OpenWithPrecomputedAAD(nil, nonce, ct, a.PrecomputeAAD(nil)); !errors.Is(err, ErrInvalidNonceSize) {
+                t.Fatalf("%s: OpenWithPrecomputedAAD: expected %v, got %v", name, ErrInvalidNonceSize, err)
+            }
+
+            nonces, _, _ := batchInputs(8, 0)
+            cts := make([][]byte, len(nonces))
+            a.SealBatch(cts, nonces, make([][]byte, len(nonces)), nil)
+            nonces[5] = nonce
+            out := make([][]byte, len(cts))
+            if i, err := a.OpenBatch(out, nonces, cts, nil); i != 5 || !errors.Is(err, ErrInvalidNonceSize) {
+                t.Fatalf("%s: OpenBatch: expected 5, %v, got %d, %v", name, ErrInvalidNonceSize, i, err)
+            }
+        }
+    }
+}
+
+func TestErrors(t *testing.T) {
+    short := make([]byte, 15)
+    for name, fn := range map[string]func() error{
+        "New128":     func() error { _, err := New128(short); return err },
+        "New128a":    func() error { _, err := New128a(short); return err },
+        "New80pq":    func() error { _, err := New80pq(short); return err },
+        "NewAEAD128": func() error { _, err := NewAEAD128(short); return err },
+        "NewSIV":     func() error { _, err := NewSIV(short); return err },
+        "NewX128a":   func() error { _, err := NewX128a(short); return err },
+        "NewMAC":     func() error { _, err := NewMAC(short); return err },
+        "DeriveKey":  func() error { _, err := DeriveKey(short); return err },
+        "Wrap":       func() error { _, err := Wrap(short, short); return err },
+    } {
+        err := fn()
+        if !errors.Is(err, ErrInvalidKeySize) {
+            t.Fatalf("%s: expected %v, got %v", name, ErrInvalidKeySize, err)
+        }
+        var ks KeySizeError
+        if !errors.As(fmt.Errorf("wrapped: %w", err), &ks) || ks != 15 {
+            t.Fatalf("%s: expected KeySizeError(15), got %v", name, err)
+        }
+    }
+
+    aead, err := New128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    nonce := make([]byte, NonceSize)
+    ct := aead.Seal(nil, nonce, []byte("plaintext"), nil)
+    ct[0] ^= 1
+    for _, tc := range []struct {
+        name   string
+        nonce  []byte
+        ct     []byte
+        reason error
+    }{
+        {"forged", nonce, ct, nil},
+        {"short", nonce, ct[:TagSize-1], ErrCiphertextTooShort},
+        {"nonce", nonce[:15], ct, ErrInvalidNonceSize},
+    } {
+        _, err := aead.Open(nil, tc.nonce, tc.ct, nil)
+        if err == nil || err.Error() != ErrAuthentication.Error() {
+            t.Fatalf("%s: expected %q, got %v", tc.name, ErrAuthentication, err)
+        }
+        wrapped := fmt.Errorf("record 7: %w", err)
+        if !errors.Is(wrapped, ErrAuthentication) {
+            t.Fatalf("%s: %v does not match %v", tc.name, wrapped, ErrAuthentication)
+        }
+        for _, other := range []error{ErrCiphertextTooShort, ErrInvalidNonceSize, ErrInvalidKeySize} {
+            if errors.Is(wrapped, other) != (other == tc.reason) {
+                t.Fatalf("%s: errors.Is(%v) = %v", tc.name, other, !(other == tc.reason))
+            }
+        }
+    }
+}
@@ -0,0 +1,33 @@
+//go:build amd64 && gc && !purego && !ascon_interleaved
+
+package ascon
+
+// With AVX2, p12x4 and p8x4 permute four states at once, with
+// word i of all four in one 256-bit register. Other CPUs
+// permute the states one after the other.
+
+func p12x4(s *[4]state) {
+    if useAVX2 {
+        p12x4AVX2(s)
+        return
+    }
+    for i := range s {
+        p12(&s[i])
+    }
+}
+
+func p8x4(s *[4]state) {
+    if useAVX2 {
+        p8x4AVX2(s)
+        return
+    }
+    for i := range s {
+        p8(&s[i])
+    }
+}
+
+//go:noescape
+func p12x4AVX2(s *[4]state)
+
+//go:noescape
+func p8x4AVX2(s *[4]state)
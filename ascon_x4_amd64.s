@@ -0,0 +1,163 @@
+//go:build amd64 && gc && !purego && !ascon_interleaved
+
+#include "textflag.h"
+
+// The four-way permutation keeps word i of the four states in
+// Yi, with state j in lane j. Y5..Y9 are scratch registers and
+// Y15 is all ones.
+
+// x4Constants holds every round constant in all four lanes.
+DATA x4Constants<>+0x0(SB)/8, $0xf0
+DATA x4Constants<>+0x8(SB)/8, $0xf0
+DATA x4Constants<>+0x10(SB)/8, $0xf0
+DATA x4Constants<>+0x18(SB)/8, $0xf0
+DATA x4Constants<>+0x20(SB)/8, $0xe1
+DATA x4Constants<>+0x28(SB)/8, $0xe1
+DATA x4Constants<>+0x30(SB)/8, $0xe1
+DATA x4Constants<>+0x38(SB)/8, $0xe1
+DATA x4Constants<>+0x40(SB)/8, $0xd2
+DATA x4Constants<>+0x48(SB)/8, $0xd2
+DATA x4Constants<>+0x50(SB)/8, $0xd2
+DATA x4Constants<>+0x58(SB)/8, $0xd2
+DATA x4Constants<>+0x60(SB)/8, $0xc3
+DATA x4Constants<>+0x68(SB)/8, $0xc3
+DATA x4Constants<>+0x70(SB)/8, $0xc3
+DATA x4Constants<>+0x78(SB)/8, $0xc3
+DATA x4Constants<>+0x80(SB)/8, $0xb4
+DATA x4Constants<>+0x88(SB)/8, $0xb4
+DATA x4Constants<>+0x90(SB)/8, $0xb4
+DATA x4Constants<>+0x98(SB)/8, $0xb4
+DATA x4Constants<>+0xa0(SB)/8, $0xa5
+DATA x4Constants<>+0xa8(SB)/8, $0xa5
+DATA x4Constants<>+0xb0(SB)/8, $0xa5
+DATA x4Constants<>+0xb8(SB)/8, $0xa5
+DATA x4Constants<>+0xc0(SB)/8, $0x96
+DATA x4Constants<>+0xc8(SB)/8, $0x96
+DATA x4Constants<>+0xd0(SB)/8, $0x96
+DATA x4Constants<>+0xd8(SB)/8, $0x96
+DATA x4Constants<>+0xe0(SB)/8, $0x87
+DATA x4Constants<>+0xe8(SB)/8, $0x87
+DATA x4Constants<>+0xf0(SB)/8, $0x87
+DATA x4Constants<>+0xf8(SB)/8, $0x87
+DATA x4Constants<>+0x100(SB)/8, $0x78
+DATA x4Constants<>+0x108(SB)/8, $0x78
+DATA x4Constants<>+0x110(SB)/8, $0x78
+DATA x4Constants<>+0x118(SB)/8, $0x78
+DATA x4Constants<>+0x120(SB)/8, $0x69
+DATA x4Constants<>+0x128(SB)/8, $0x69
+DATA x4Constants<>+0x130(SB)/8, $0x69
+DATA x4Constants<>+0x138(SB)/8, $0x69
+DATA x4Constants<>+0x140(SB)/8, $0x5a
+DATA x4Constants<>+0x148(SB)/8, $0x5a
+DATA x4Constants<>+0x150(SB)/8, $0x5a
+DATA x4Constants<>+0x158(SB)/8, $0x5a
+DATA x4Constants<>+0x160(SB)/8, $0x4b
+DATA x4Constants<>+0x168(SB)/8, $0x4b
+DATA x4Constants<>+0x170(SB)/8, $0x4b
+DATA x4Constants<>+0x178(SB)/8, $0x4b
+GLOBL x4Constants<>(SB), RODATA|NOPTR, $384
+
+// LOAD_WORD loads word i of the four states at s, which are
+// 40 bytes apart, into y, using x as the low half of y and X5
+// as scratch.
+#define LOAD_WORD(s, i, x, y) \
+    VMOVQ i(s), x;  VPINSRQ $1, 40+i(s), x, x;   \
+    VMOVQ 80+i(s), X5; VPINSRQ $1, 120+i(s), X5, X5; \
+    VINSERTI128 $1, X5, y, y
+
+#define STORE_WORD(s, i, x, y) \
+    VMOVQ x, i(s); VPEXTRQ $1, x, 40+i(s);  \
+    VEXTRACTI128 $1, y, X5; \
+    VMOVQ X5, 80+i(s); VPEXTRQ $1, X5, 120+i(s)
+
+#define LOAD_X4(s) \
+    LOAD_WORD(s, 0, X0, Y0);  \
+    LOAD_WORD(s, 8, X1, Y1);  \
+    LOAD_WORD(s, 16, X2, Y2); \
+    LOAD_WORD(s, 24, X3, Y3); \
+    LOAD_WORD(s, 32, X4, Y4)
+
+#define STORE_X4(s) \
+    STORE_WORD(s, 0, X0, Y0);  \
+    STORE_WORD(s, 8, X1, Y1);  \
+    STORE_WORD(s, 16, X2, Y2); \
+    STORE_WORD(s, 24, X3, Y3); \
+    STORE_WORD(s, 32, X4, Y4)
+
+// LINEAR_X4 computes x ^= (x >>> r0) ^ (x >>> r1) in every
+// lane of x, with l0 = 64-r0 and l1 = 64-r1.
+#define LINEAR_X4(x, r0, l0, r1, l1) \
+    VPSRLQ $r0, x, Y5; \
+    VPSLLQ $l0, x, Y6; \
+    VPSRLQ $r1, x, Y7; \
+    VPSLLQ $l1, x, Y8; \
+    VPXOR  Y5, Y6, Y5; \
+    VPXOR  Y7, Y8, Y7; \
+    VPXOR  Y5, x, x;   \
+    VPXOR  Y7, x, x
+
+// ROUND_X4 applies one round with the round constant at
+// offset c of x4Constants. The layers are those of LAYERS in
+// ascon_amd64.s.
+#define ROUND_X4(c) \
+    VPXOR  x4Constants<>+c(SB), Y2, Y2; \
+    VPXOR  Y4, Y0, Y0; \
+    VPXOR  Y3, Y4, Y4; \
+    VPXOR  Y1, Y2, Y2; \
+    VPANDN Y2, Y1, Y5; \
+    VPANDN Y3, Y2, Y6; \
+    VPANDN Y4, Y3, Y7; \
+    VPANDN Y0, Y4, Y8; \
+    VPANDN Y1, Y0, Y9; \
+    VPXOR  Y5, Y0, Y0; \
+    VPXOR  Y6, Y1, Y1; \
+    VPXOR  Y7, Y2, Y2; \
+    VPXOR  Y8, Y3, Y3; \
+    VPXOR  Y9, Y4, Y4; \
+    VPXOR  Y0, Y1, Y1; \
+    VPXOR  Y4, Y0, Y0; \
+    VPXOR  Y2, Y3, Y3; \
+    VPXOR  Y15, Y2, Y2; \
+    LINEAR_X4(Y0, 19, 45, 28, 36); \
+    LINEAR_X4(Y1, 61, 3, 39, 25);  \
+    LINEAR_X4(Y2, 1, 63, 6, 58);   \
+    LINEAR_X4(Y3, 10, 54, 17, 47); \
+    LINEAR_X4(Y4, 7, 57, 41, 23)
+
+// func p12x4AVX2(s *[4]state)
+TEXT ·p12x4AVX2(SB), NOSPLIT, $0-8
+    MOVQ s+0(FP), DI
+    VPCMPEQQ Y15, Y15, Y15
+    LOAD_X4(DI)
+    ROUND_X4(0x0)
+    ROUND_X4(0x20)
+    ROUND_X4(0x40)
+    ROUND_X4(0x60)
+    ROUND_X4(0x80)
+    ROUND_X4(0xa0)
+    ROUND_X4(0xc0)
+    ROUND_X4(0xe0)
+    ROUND_X4(0x100)
+    ROUND_X4(0x120)
+    ROUND_X4(0x140)
+    ROUND_X4(0x160)
+    STORE_X4(DI)
+    VZEROUPPER
+    RET
+
+// func p8x4AVX2(s *[4]state)
+TEXT ·p8x4AVX2(SB), NOSPLIT, $0-8
+    MOVQ s+0(FP), DI
+    VPCMPEQQ Y15, Y15, Y15
+    LOAD_X4(DI)
+    ROUND_X4(0x80)
+    ROUND_X4(0xa0)
+    ROUND_X4(0xc0)
+    ROUND_X4(0xe0)
+    ROUND_X4(0x100)
+    ROUND_X4(0x120)
+    ROUND_X4(0x140)
+    ROUND_X4(0x160)
+    STORE_X4(DI)
+    VZEROUPPER
+    RET
@@ -0,0 +1,15 @@
+//go:build !amd64 || !gc || purego || ascon_interleaved
+
+package ascon
+
+func p12x4(s *[4]state) {
+    for i := range s {
+        p12(&s[i])
+    }
+}
+
+func p8x4(s *[4]state) {
+    for i := range s {
+        p8(&s[i])
+    }
+}
@@ -0,0 +1,53 @@
+package ascon
+
+import (
+    "testing"
+    "math/rand"
+)
+
+func TestPermute4(t *testing.T) {
+    rng := rand.New(rand.NewSource(2))
+    for i := 0; i < 100; i++ {
+        var s [4]state
+        for j := range s {
+            for k := range s[j] {
+                s[j][k] = rng.Uint64()
+            }
+        }
+
+        want, got := s, s
+        for j := range want {
+            p12Generic(&want[j])
+        }
+        p12x4(&got)
+        if got != want {
+            t.Fatalf("#%d: p12x4: expected %v, got %v", i, want, got)
+        }
+
+        want, got = s, s
+        for j := range want {
+            p8Generic(&want[j])
+        }
+        p8x4(&got)
+        if got != want {
+            t.Fatalf("#%d: p8x4: expected %v, got %v", i, want, got)
+        }
+    }
+}
+
+func BenchmarkPermute4(b *testing.B) {
+    b.Run("p12x4", func(b *testing.B) {
+        var s [4]state
+        for i := 0; i < b.N; i++ {
+            p12x4(&s)
+        }
+    })
+    b.Run("p12", func(b *testing.B) {
+        var s [4]state
+        for i := 0; i < b.N; i++ {
+            for j := range s {
+                p12(&s[j])
+            }
+        }
+    })
+}
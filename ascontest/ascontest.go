@@ -0,0 +1,324 @@
+// Package ascontest provides a reference implementation of the
+// ASCON permutation and AEADs for differential testing.
+//
+// The code follows the specifications as literally as
+// possible and is meant to be obviously correct, not fast: the
+// S-box is the 5-bit lookup table of the specification applied
+// column by column, and the modes work on the state as 40
+// bytes, absorbing padded blocks one at a time. It shares no
+// code with package ascon, so tests can compare the two, and
+// libraries that embed their own ASCON can check it against
+// SealReference and OpenReference.
+//
+// References:
+//
+//    [ascon]: https://ascon.iaik.tugraz.at
+//    [SP 800-232]: https://csrc.nist.gov/pubs/sp/800/232/final
+//
+package ascontest
+
+import (
+    "errors"
+    "strconv"
+    "crypto/cipher"
+    "crypto/subtle"
+    "encoding/binary"
+)
+
+// Variant identifies an ASCON AEAD variant, with the values of
+// ascon.Variant.
+type Variant uint8
+
+const (
+    // Ascon128 is ASCON-128.
+    Ascon128 Variant = 1 + iota
+    // Ascon128a is ASCON-128a.
+    Ascon128a
+    // Ascon80pq is ASCON-80pq.
+    Ascon80pq
+    // AsconAEAD128 is Ascon-AEAD128 from NIST SP 800-232.
+    AsconAEAD128
+)
+
+// NonceSize and TagSize are the nonce and authenticator sizes
+// of every variant.
+const (
+    NonceSize = 16
+    TagSize   = 16
+)
+
+// sbox is the ASCON S-box. Bit 4 of the input and output is
+// the bit of x0, and bit 0 that of x4.
+var sbox = [32]uint64{
+    0x04, 0x0b, 0x1f, 0x14, 0x1a, 0x15, 0x09, 0x02,
+    0x1b, 0x05, 0x08, 0x12, 0x1d, 0x03, 0x06, 0x1c,
+    0x1e, 0x13, 0x07, 0x0e, 0x00, 0x0d, 0x11, 0x18,
+    0x10, 0x0c, 0x01, 0x19, 0x16, 0x0a, 0x0f, 0x17,
+}
+
+// rotations are the rotation amounts of the linear layer of
+// each word.
+var rotations = [5][2]int{{19, 28}, {61, 39}, {1, 6}, {10, 17}, {7, 41}}
+
+func rotr(x uint64, n int) uint64 {
+    return x>>n | x<<(64-n)
+}
+
+// Permute applies the last rounds rounds of p12 to x, so
+// Permute(x, 12) is p12 and Permute(x, 6) is p6.
+func Permute(x *[5]uint64, rounds int) {
+    if rounds < 1 || rounds > 12 {
+        panic("ascontest: invalid number of rounds: " + strconv.Itoa(rounds))
+    }
+    for r := 12 - rounds; r < 12; r++ {
+        // Constant addition.
+        x[2] ^= uint64(0xf-r)<<4 | uint64(r)
+
+        // Substitution layer, one column of five bits at a
+        // time.
+        var y [5]uint64
+        for j := 0; j < 64; j++ {
+            var in uint64
+            for i := 0; i < 5; i++ {
+                in |= (x[i] >> j & 1) << (4 - i)
+            }
+            out := sbox[in]
+            for i := 0; i < 5; i++ {
+                y[i] |= (out >> (4 - i) & 1) << j
+            }
+        }
+
+        // Linear diffusion layer.
+        for i := range x {
+            x[i] = y[i] ^ rotr(y[i], rotations[i][0]) ^ rotr(y[i], rotations[i][1])
+        }
+    }
+}
+
+// params describes a variant on the state as 40 bytes, each
+// word of which is stored in the byte order of the variant.
+type params struct {
+    littleEndian bool
+    iv           []byte // the first bytes of the state
+    keySize      int
+    rate         int
+    a, b         int  // rounds of p^a and p^b
+    pad          byte // the byte that follows the last input byte
+    domain       byte // XORed into the last byte of the state
+}
+
+// variants are the parameters of each variant.
+var variants = map[Variant]params{
+    Ascon128: {
+        iv: []byte{0x80, 0x40, 0x0c, 0x06, 0, 0, 0, 0}, keySize: 16, rate: 8,
+        a: 12, b: 6, pad: 0x80, domain: 0x01,
+    },
+    Ascon128a: {
+        iv: []byte{0x80, 0x80, 0x0c, 0x08, 0, 0, 0, 0}, keySize: 16, rate: 16,
+        a: 12, b: 8, pad: 0x80, domain: 0x01,
+    },
+    Ascon80pq: {
+        iv: []byte{0xa0, 0x40, 0x0c, 0x06}, keySize: 20, rate: 8,
+        a: 12, b: 6, pad: 0x80, domain: 0x01,
+    },
+    // SP 800-232 numbers bits from the least significant one,
+    // so its padding bit and domain separation bit are the low
+    // and high bit of a byte.
+    AsconAEAD128: {
+        littleEndian: true,
+        iv:           []byte{0x01, 0x00, 0x8c, 0x80, 0x00, 0x10, 0x00, 0x00}, keySize: 16, rate: 16,
+        a: 12, b: 8, pad: 0x01, domain: 0x80,
+    },
+}
+
+func lookup(v Variant) params {
+    p, ok := variants[v]
+    if !ok {
+        panic("ascontest: unknown variant " + strconv.Itoa(int(v)))
+    }
+    return p
+}
+
+// KeySize returns the key size in bytes of v.
+func KeySize(v Variant) int {
+    return lookup(v).keySize
+}
+
+// Rate returns the block size in bytes of v.
+func Rate(v Variant) int {
+    return lookup(v).rate
+}
+
+// permute applies rounds rounds to the state as bytes.
+func (p *params) permute(s *[40]byte, rounds int) {
+    order := binary.ByteOrder(binary.BigEndian)
+    if p.littleEndian {
+        order = binary.LittleEndian
+    }
+    var x [5]uint64
+    for i := range x {
+        x[i] = order.Uint64(s[8*i:])
+    }
+    Permute(&x, rounds)
+    for i := range x {
+        order.PutUint64(s[8*i:], x[i])
+    }
+}
+
+func xor(dst, src []byte) {
+    for i := range src {
+        dst[i] ^= src[i]
+    }
+}
+
+// blocks pads in and splits it into blocks of the rate.
+func (p *params) blocks(in []byte) [][]byte {
+    padded := append(append([]byte(nil), in...), p.pad)
+    for len(padded)%p.rate != 0 {
+        padded = append(padded, 0)
+    }
+    var blocks [][]byte
+    for len(padded) > 0 {
+        blocks = append(blocks, padded[:p.rate])
+        padded = padded[p.rate:]
+    }
+    return blocks
+}
+
+// start initializes the state and absorbs the additional data.
+func (p *params) start(key, nonce, ad []byte) *[40]byte {
+    if len(key) != p.keySize {
+        panic("ascontest: bad key length: " + strconv.Itoa(len(key)))
+    }
+    if len(nonce) != NonceSize {
+        panic("ascontest: bad nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    // S = IV || K || N, then S ^= 0* || K.
+    s := new([40]byte)
+    copy(s[:], p.iv)
+    copy(s[len(p.iv):], key)
+    copy(s[40-NonceSize:], nonce)
+    p.permute(s, p.a)
+    xor(s[40-p.keySize:], key)
+
+    if len(ad) > 0 {
+        for _, block := range p.blocks(ad) {
+            xor(s[:p.rate], block)
+            p.permute(s, p.b)
+        }
+    }
+    s[39] ^= p.domain
+    return s
+}
+
+// finish computes the authenticator: S ^= 0^r || K || 0*, then
+// the last bytes of the permuted state XOR the last bytes of K.
+func (p *params) finish(s *[40]byte, key []byte) []byte {
+    xor(s[p.rate:], key)
+    p.permute(s, p.a)
+    tag := append([]byte(nil), s[40-TagSize:]...)
+    xor(tag, key[len(key)-TagSize:])
+    return tag
+}
+
+// SealReference encrypts and authenticates plaintext with the
+// variant v, and returns the ciphertext followed by the
+// authenticator. It panics if the key or nonce has the wrong
+// length.
+func SealReference(v Variant, key, nonce, additionalData, plaintext []byte) []byte {
+    p := lookup(v)
+    s := p.start(key, nonce, additionalData)
+
+    var out []byte
+    blocks := p.blocks(plaintext)
+    for i, block := range blocks {
+        xor(s[:p.rate], block)
+        if i < len(blocks)-1 {
+            out = append(out, s[:p.rate]...)
+            p.permute(s, p.b)
+        } else {
+            out = append(out, s[:len(plaintext)%p.rate]...)
+        }
+    }
+    return append(out, p.finish(s, key)...)
+}
+
+var errOpen = errors.New("ascontest: message authentication failed")
+
+// OpenReference decrypts and authenticates ciphertext, which
+// must end with the authenticator, with the variant v. It
+// panics under the same conditions as SealReference.
+func OpenReference(v Variant, key, nonce, additionalData, ciphertext []byte) ([]byte, error) {
+    p := lookup(v)
+    if len(ciphertext) < TagSize {
+        return nil, errOpen
+    }
+    tag := ciphertext[len(ciphertext)-TagSize:]
+    ciphertext = ciphertext[:len(ciphertext)-TagSize]
+    s := p.start(key, nonce, additionalData)
+
+    var out []byte
+    for len(ciphertext) >= p.rate {
+        block := ciphertext[:p.rate]
+        for i := range block {
+            out = append(out, s[i]^block[i])
+        }
+        copy(s[:p.rate], block)
+        p.permute(s, p.b)
+        ciphertext = ciphertext[p.rate:]
+    }
+    // The last block: the plaintext is the state XOR the
+    // ciphertext, and the state absorbs the padded plaintext.
+    last := make([]byte, len(ciphertext))
+    for i := range ciphertext {
+        last[i] = s[i] ^ ciphertext[i]
+    }
+    out = append(out, last...)
+    xor(s[:p.rate], p.blocks(last)[0])
+
+    if subtle.ConstantTimeCompare(p.finish(s, key), tag) != 1 {
+        return nil, errOpen
+    }
+    return out, nil
+}
+
+// reference is a cipher.AEAD on top of SealReference and
+// OpenReference.
+type reference struct {
+    v   Variant
+    key []byte
+}
+
+// NewReference returns a cipher.AEAD of the variant v that uses
+// SealReference and OpenReference, for code that takes an AEAD.
+// It is as slow as they are, and allocates on every call.
+func NewReference(v Variant, key []byte) (cipher.AEAD, error) {
+    if len(key) != lookup(v).keySize {
+        return nil, errors.New("ascontest: bad key length: " + strconv.Itoa(len(key)))
+    }
+    return &reference{v, append([]byte(nil), key...)}, nil
+}
+
+func (r *reference) NonceSize() int {
+    return NonceSize
+}
+
+func (r *reference) Overhead() int {
+    return TagSize
+}
+
+func (r *reference) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    return append(dst, SealReference(r.v, r.key, nonce, additionalData, plaintext)...)
+}
+
+func (r *reference) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpen
+    }
+    out, err := OpenReference(r.v, r.key, nonce, additionalData, ciphertext)
+    if err != nil {
+        return nil, err
+    }
+    return append(dst, out...), nil
+}
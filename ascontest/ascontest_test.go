@@ -0,0 +1,118 @@
+package ascontest
+
+import (
+    "bytes"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "testing"
+    "crypto/cipher"
+)
+
+// TestKAT checks the reference against the known answer tests
+// of each variant.
+func TestKAT(t *testing.T) {
+    for v := range katNames {
+        v := v
+        RunKAT(t, func(key []byte) (cipher.AEAD, error) {
+            return NewReference(v, key)
+        }, KAT(v))
+    }
+}
+
+func TestParseKAT(t *testing.T) {
+    vecs, err := ParseKAT([]byte("Count = 7\r\nKey = 00\r\nPT = \r\n\r\nCount = 8\nCT = ABcd\n"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) != 2 || vecs[0].Count != 7 || len(vecs[0].Key) != 1 ||
+        vecs[1].Count != 8 || string(vecs[1].CT) != "\xab\xcd" {
+        t.Fatalf("unexpected vectors %+v", vecs)
+    }
+    for _, bad := range []string{
+        "Key = 00\n",
+        "Count = x\n",
+        "Count = 1\nKey = 0\n",
+        "Count = 1\nTag = 00\n",
+        "Count = 1\nKey 00\n",
+    } {
+        if _, err := ParseKAT([]byte(bad)); err == nil {
+            t.Errorf("%q: expected an error", bad)
+        }
+    }
+}
+
+// failingAEAD is an AEAD that accepts any ciphertext.
+type failingAEAD struct {
+    cipher.AEAD
+}
+
+func (f failingAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if out, err := f.AEAD.Open(dst, nonce, ciphertext, additionalData); err == nil || len(ciphertext) < TagSize {
+        return out, err
+    }
+    return append(dst, ciphertext[:len(ciphertext)-TagSize]...), nil
+}
+
+func TestRunFails(t *testing.T) {
+    newAEAD := func(key []byte) (cipher.AEAD, error) {
+        aead, err := NewReference(Ascon128, key)
+        return failingAEAD{aead}, err
+    }
+    var r recorder
+    RunKAT(&r, newAEAD, KAT(Ascon128))
+    if r.errors == 0 {
+        t.Fatal("RunKAT accepted an AEAD that does not authenticate")
+    }
+    r.errors = 0
+    RunWycheproof(&r, newAEAD, Wycheproof(Ascon128))
+    if r.errors == 0 {
+        t.Fatal("RunWycheproof accepted an AEAD that does not authenticate")
+    }
+}
+
+// recorder is a testing.TB that counts errors.
+type recorder struct {
+    testing.TB
+    errors int
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+    r.errors++
+}
+
+func TestWycheproof(t *testing.T) {
+    for v := range wycheproofNames {
+        v := v
+        RunWycheproof(t, func(key []byte) (cipher.AEAD, error) {
+            return NewReference(v, key)
+        }, Wycheproof(v))
+    }
+}
+
+// TestWycheproofGenerated checks that the Wycheproof files are
+// what gen_wycheproof.go generates.
+func TestWycheproofGenerated(t *testing.T) {
+    if testing.Short() {
+        t.Skip("runs the go command")
+    }
+    goCmd, err := exec.LookPath("go")
+    if err != nil {
+        t.Skip("go command not found")
+    }
+    dir := t.TempDir()
+    if out, err := exec.Command(goCmd, "run", "gen_wycheproof.go", "-dir", dir).CombinedOutput(); err != nil {
+        t.Fatalf("gen_wycheproof.go: %v\n%s", err, out)
+    }
+    for v, name := range wycheproofNames {
+        want, err := os.ReadFile(filepath.Join(dir, filepath.Base(name)))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(Wycheproof(v), want) {
+            t.Fatalf("%s is out of date, run go generate", name)
+        }
+    }
+}
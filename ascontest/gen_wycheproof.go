@@ -0,0 +1,245 @@
+//go:build ignore
+
+// This program generates the Wycheproof vector files in the
+// wycheproof directory from the reference implementation. Run
+// it with go generate.
+//
+// The vectors are pseudorandom, from a fixed seed per variant,
+// so the files only change with the program.
+package main
+
+import (
+    "flag"
+    "fmt"
+    "log"
+    "math/rand"
+    "os"
+    "path/filepath"
+    "encoding/json"
+
+    "github.com/pedroalbanese/go-ascon/ascontest"
+)
+
+var dir = flag.String("dir", "wycheproof", "output directory")
+
+var variants = []struct {
+    v         ascontest.Variant
+    algorithm string
+    file      string
+}{
+    {ascontest.Ascon128, "ASCON-128", "ascon128_test.json"},
+    {ascontest.Ascon128a, "ASCON-128a", "ascon128a_test.json"},
+    {ascontest.Ascon80pq, "ASCON-80pq", "ascon80pq_test.json"},
+    {ascontest.AsconAEAD128, "Ascon-AEAD128", "asconaead128_test.json"},
+}
+
+var notes = map[string]ascontest.WycheproofNote{
+    "Pseudorandom": {
+        BugType:     "FUNCTIONALITY",
+        Description: "The key, nonce, additional data and message are pseudorandom, with lengths around multiples of the rate.",
+    },
+    "EdgeCase": {
+        BugType:     "EDGE_CASE",
+        Description: "The key, nonce and data consist of a single repeated byte.",
+    },
+    "LongMessage": {
+        BugType:     "FUNCTIONALITY",
+        Description: "The message or additional data spans many blocks.",
+    },
+    "ModifiedTag": {
+        BugType:     "AUTH_BYPASS",
+        Description: "The tag of a valid message is modified. Open must reject it.",
+    },
+    "TruncatedTag": {
+        BugType:     "AUTH_BYPASS",
+        Description: "The tag of a valid message is truncated. Open must reject it, since the tag size is fixed.",
+    },
+    "ModifiedCiphertext": {
+        BugType:     "AUTH_BYPASS",
+        Description: "The ciphertext of a valid message is modified, truncated or extended. Open must reject it.",
+    },
+    "ModifiedNonce": {
+        BugType:     "AUTH_BYPASS",
+        Description: "A valid message is opened with a modified nonce. Open must reject it.",
+    },
+    "ModifiedAad": {
+        BugType:     "AUTH_BYPASS",
+        Description: "A valid message is opened with modified, extended or missing additional data. Open must reject it.",
+    },
+    "InvalidNonceSize": {
+        BugType:     "MODIFIED_PARAMETER",
+        Description: "The nonce does not have the size of the algorithm. Open must reject the message without failing otherwise.",
+    },
+}
+
+func main() {
+    flag.Parse()
+    for _, tc := range variants {
+        f := generate(tc.v, tc.algorithm)
+        b, err := json.MarshalIndent(f, "", "  ")
+        if err != nil {
+            log.Fatal(err)
+        }
+        if err := os.WriteFile(filepath.Join(*dir, tc.file), append(b, '\n'), 0o644); err != nil {
+            log.Fatal(err)
+        }
+    }
+}
+
+// generator accumulates the tests of a file.
+type generator struct {
+    v    ascontest.Variant
+    rng  *rand.Rand
+    next int
+}
+
+func (g *generator) random(n int) []byte {
+    b := make([]byte, n)
+    g.rng.Read(b)
+    return b
+}
+
+// valid returns the valid test of a message.
+func (g *generator) valid(comment string, flags []string, key, nonce, ad, msg []byte) ascontest.WycheproofTest {
+    sealed := ascontest.SealReference(g.v, key, nonce, ad, msg)
+    return g.test(comment, flags, "valid", key, nonce, ad, msg,
+        sealed[:len(msg)], sealed[len(msg):])
+}
+
+func (g *generator) test(comment string, flags []string, result string, key, nonce, ad, msg, ct, tag []byte) ascontest.WycheproofTest {
+    g.next++
+    return ascontest.WycheproofTest{
+        TcID:    g.next,
+        Comment: comment,
+        Flags:   flags,
+        Key:     key,
+        Iv:      nonce,
+        Aad:     ad,
+        Msg:     msg,
+        Ct:      ct,
+        Tag:     tag,
+        Result:  result,
+    }
+}
+
+// flip returns a copy of b with bit j of b[i] flipped.
+func flip(b []byte, i, j int) []byte {
+    b = append([]byte(nil), b...)
+    b[i] ^= 1 << j
+    return b
+}
+
+func generate(v ascontest.Variant, algorithm string) *ascontest.WycheproofFile {
+    g := &generator{v: v, rng: rand.New(rand.NewSource(int64(v)))}
+    keySize, rate := ascontest.KeySize(v), ascontest.Rate(v)
+
+    var tests []ascontest.WycheproofTest
+    sizes := []int{0, 1, rate - 1, rate, rate + 1, 2*rate - 1, 2 * rate, 2*rate + 1, 3*rate + 5}
+    for _, adLen := range []int{0, 1, rate - 1, rate, rate + 1, 2*rate + 1} {
+        for _, msgLen := range sizes {
+            tests = append(tests, g.valid(fmt.Sprintf("%d bytes of additional data, %d of message", adLen, msgLen),
+                []string{"Pseudorandom"}, g.random(keySize), g.random(ascontest.NonceSize),
+                g.random(adLen), g.random(msgLen)))
+        }
+    }
+    for _, c := range []byte{0x00, 0xff} {
+        fill := func(n int) []byte {
+            b := make([]byte, n)
+            for i := range b {
+                b[i] = c
+            }
+            return b
+        }
+        tests = append(tests, g.valid(fmt.Sprintf("every byte %#02x", c), []string{"EdgeCase"},
+            fill(keySize), fill(ascontest.NonceSize), fill(rate+1), fill(2*rate+3)))
+    }
+    tests = append(tests,
+        g.valid("long message", []string{"LongMessage"}, g.random(keySize), g.random(ascontest.NonceSize), nil, g.random(1027)),
+        g.valid("long additional data", []string{"LongMessage"}, g.random(keySize), g.random(ascontest.NonceSize), g.random(1027), nil))
+
+    // Every modification of a few valid messages.
+    for _, lens := range [][2]int{{0, 0}, {rate + 1, 0}, {0, rate}, {rate + 1, 2*rate + 1}} {
+        key, nonce := g.random(keySize), g.random(ascontest.NonceSize)
+        ad, msg := g.random(lens[0]), g.random(lens[1])
+        sealed := ascontest.SealReference(v, key, nonce, ad, msg)
+        ct, tag := sealed[:len(msg)], sealed[len(msg):]
+        invalid := func(comment, flag string, nonce, ad, ct, tag []byte) {
+            comment = fmt.Sprintf("%s (%d bytes of additional data, %d of message)", comment, lens[0], lens[1])
+            tests = append(tests, g.test(comment, []string{flag}, "invalid", key, nonce, ad, msg, ct, tag))
+        }
+
+        for _, bit := range [][2]int{{0, 0}, {0, 7}, {7, 3}, {ascontest.TagSize - 1, 0}, {ascontest.TagSize - 1, 7}} {
+            invalid(fmt.Sprintf("tag bit %d of byte %d flipped", bit[1], bit[0]), "ModifiedTag",
+                nonce, ad, ct, flip(tag, bit[0], bit[1]))
+        }
+        invalid("tag of zeros", "ModifiedTag", nonce, ad, ct, make([]byte, ascontest.TagSize))
+        complement := make([]byte, len(tag))
+        for i := range tag {
+            complement[i] = ^tag[i]
+        }
+        invalid("tag complemented", "ModifiedTag", nonce, ad, ct, complement)
+        for _, n := range []int{ascontest.TagSize - 1, 8, 0} {
+            invalid(fmt.Sprintf("tag truncated to %d bytes", n), "TruncatedTag", nonce, ad, ct, tag[:n])
+        }
+
+        if len(ct) > 0 {
+            invalid("ciphertext bit 0 of byte 0 flipped", "ModifiedCiphertext", nonce, ad, flip(ct, 0, 0), tag)
+            invalid("ciphertext bit 7 of the last byte flipped", "ModifiedCiphertext", nonce, ad, flip(ct, len(ct)-1, 7), tag)
+            invalid("ciphertext truncated by one byte", "ModifiedCiphertext", nonce, ad, ct[:len(ct)-1], tag)
+        }
+        invalid("ciphertext extended by one byte", "ModifiedCiphertext", nonce, ad, append(ct[:len(ct):len(ct)], 0), tag)
+
+        invalid("nonce bit 0 of byte 0 flipped", "ModifiedNonce", flip(nonce, 0, 0), ad, ct, tag)
+        invalid("nonce bit 7 of the last byte flipped", "ModifiedNonce", flip(nonce, len(nonce)-1, 7), ad, ct, tag)
+
+        if len(ad) > 0 {
+            invalid("additional data bit 0 of byte 0 flipped", "ModifiedAad", nonce, flip(ad, 0, 0), ct, tag)
+            invalid("additional data truncated by one byte", "ModifiedAad", nonce, ad[:len(ad)-1], ct, tag)
+            invalid("additional data missing", "ModifiedAad", nonce, nil, ct, tag)
+        }
+        invalid("additional data extended by a zero byte", "ModifiedAad", nonce, append(ad[:len(ad):len(ad)], 0), ct, tag)
+    }
+
+    f := &ascontest.WycheproofFile{
+        Algorithm:        algorithm,
+        Schema:           "aead_test_schema.json",
+        GeneratorVersion: "1",
+        Header: []string{
+            "Test vectors of type AeadTest test authenticated encryption with additional data.",
+            "Generated by gen_wycheproof.go from the reference implementation in package ascontest.",
+        },
+        Notes: notes,
+        TestGroups: []ascontest.WycheproofGroup{{
+            Type:    "AeadTest",
+            KeySize: 8 * keySize,
+            IvSize:  8 * ascontest.NonceSize,
+            TagSize: 8 * ascontest.TagSize,
+            Tests:   tests,
+        }},
+    }
+
+    // One group per wrong nonce size, with a message sealed
+    // under the nonce cut or zero extended to the right size.
+    key, ad, msg := g.random(keySize), g.random(rate+1), g.random(rate+1)
+    for _, n := range []int{0, 8, 12, ascontest.NonceSize - 1, ascontest.NonceSize + 1, 32} {
+        nonce := g.random(n)
+        fitted := make([]byte, ascontest.NonceSize)
+        copy(fitted, nonce)
+        sealed := ascontest.SealReference(v, key, fitted, ad, msg)
+        f.TestGroups = append(f.TestGroups, ascontest.WycheproofGroup{
+            Type:    "AeadTest",
+            KeySize: 8 * keySize,
+            IvSize:  8 * n,
+            TagSize: 8 * ascontest.TagSize,
+            Tests: []ascontest.WycheproofTest{
+                g.test(fmt.Sprintf("%d byte nonce", n), []string{"InvalidNonceSize"}, "invalid",
+                    key, nonce, ad, msg, sealed[:len(msg)], sealed[len(msg):]),
+            },
+        })
+    }
+
+    for _, group := range f.TestGroups {
+        f.NumberOfTests += len(group.Tests)
+    }
+    return f
+}
@@ -0,0 +1,181 @@
+package ascontest
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "strconv"
+    "testing"
+    "crypto/cipher"
+    "encoding/hex"
+    "encoding/json"
+)
+
+// The vector files follow the aead_test_schema.json schema of
+// Project Wycheproof, so any Wycheproof AEAD test harness can
+// load them. gen_wycheproof.go generates them from the reference
+// implementation; each file covers one variant.
+//
+//go:generate go run gen_wycheproof.go
+
+var wycheproofNames = map[Variant]string{
+    Ascon128:     "wycheproof/ascon128_test.json",
+    Ascon128a:    "wycheproof/ascon128a_test.json",
+    Ascon80pq:    "wycheproof/ascon80pq_test.json",
+    AsconAEAD128: "wycheproof/asconaead128_test.json",
+}
+
+// Wycheproof returns the Wycheproof vector file of v, in the
+// format read by ParseWycheproof.
+func Wycheproof(v Variant) []byte {
+    name, ok := wycheproofNames[v]
+    if !ok {
+        panic("ascontest: unknown variant " + strconv.Itoa(int(v)))
+    }
+    b, err := vectorFiles.ReadFile(name)
+    if err != nil {
+        panic(err)
+    }
+    return b
+}
+
+// WycheproofFile is a file of Wycheproof AEAD test vectors.
+type WycheproofFile struct {
+    Algorithm        string                    `json:"algorithm"`
+    Schema           string                    `json:"schema"`
+    GeneratorVersion string                    `json:"generatorVersion"`
+    NumberOfTests    int                       `json:"numberOfTests"`
+    Header           []string                  `json:"header"`
+    Notes            map[string]WycheproofNote `json:"notes"`
+    TestGroups       []WycheproofGroup         `json:"testGroups"`
+}
+
+// WycheproofNote describes a flag of the test vectors.
+type WycheproofNote struct {
+    BugType     string `json:"bugType"`
+    Description string `json:"description"`
+}
+
+// WycheproofGroup is a group of test vectors with the same key,
+// nonce and authenticator sizes, in bits. The vectors of a
+// group with a nonce size other than NonceSize are all
+// invalid.
+type WycheproofGroup struct {
+    Type    string           `json:"type"`
+    KeySize int              `json:"keySize"`
+    IvSize  int              `json:"ivSize"`
+    TagSize int              `json:"tagSize"`
+    Tests   []WycheproofTest `json:"tests"`
+}
+
+// WycheproofTest is a test vector. Result is "valid" if Seal
+// returns Ct followed by Tag and Open accepts them, or
+// "invalid" if Open must reject them.
+type WycheproofTest struct {
+    TcID    int      `json:"tcId"`
+    Comment string   `json:"comment"`
+    Flags   []string `json:"flags"`
+    Key     HexBytes `json:"key"`
+    Iv      HexBytes `json:"iv"`
+    Aad     HexBytes `json:"aad"`
+    Msg     HexBytes `json:"msg"`
+    Ct      HexBytes `json:"ct"`
+    Tag     HexBytes `json:"tag"`
+    Result  string   `json:"result"`
+}
+
+// HexBytes is a byte slice that is a hexadecimal string in
+// JSON.
+type HexBytes []byte
+
+func (h HexBytes) MarshalJSON() ([]byte, error) {
+    return json.Marshal(hex.EncodeToString(h))
+}
+
+func (h *HexBytes) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return err
+    }
+    b, err := hex.DecodeString(s)
+    if err != nil {
+        return err
+    }
+    *h = b
+    return nil
+}
+
+// ParseWycheproof parses a Wycheproof vector file. It returns an
+// error if numberOfTests does not match the vectors, or if a
+// result is neither "valid" nor "invalid".
+func ParseWycheproof(data []byte) (*WycheproofFile, error) {
+    var f WycheproofFile
+    if err := json.Unmarshal(data, &f); err != nil {
+        return nil, fmt.Errorf("ascontest: %v", err)
+    }
+    n := 0
+    for _, g := range f.TestGroups {
+        for _, tc := range g.Tests {
+            if tc.Result != "valid" && tc.Result != "invalid" {
+                return nil, fmt.Errorf("ascontest: tcId %d: unknown result %q", tc.TcID, tc.Result)
+            }
+            n++
+        }
+    }
+    if n != f.NumberOfTests {
+        return nil, fmt.Errorf("ascontest: %d tests, numberOfTests is %d", n, f.NumberOfTests)
+    }
+    if n == 0 {
+        return nil, errors.New("ascontest: no tests")
+    }
+    return &f, nil
+}
+
+// RunWycheproof checks the AEADs created by newAEAD against the
+// Wycheproof vectors in data, as parsed by ParseWycheproof. For
+// a valid vector, Seal must return the ciphertext and tag, and
+// Open the message. For an invalid one, Open must fail. Only
+// Open sees the nonces of the wrong size, since Seal panics on
+// them. Groups with a tag size other than the Overhead of the
+// AEAD are skipped. RunWycheproof reports failures with
+// t.Errorf.
+func RunWycheproof(t testing.TB, newAEAD func(key []byte) (cipher.AEAD, error), data []byte) {
+    t.Helper()
+    f, err := ParseWycheproof(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ran := 0
+    for _, g := range f.TestGroups {
+        for _, tc := range g.Tests {
+            aead, err := newAEAD(tc.Key)
+            if err != nil {
+                t.Errorf("tcId %d: %v", tc.TcID, err)
+                continue
+            }
+            if 8*aead.Overhead() != g.TagSize {
+                continue
+            }
+            ran++
+
+            sealed := append(append([]byte(nil), tc.Ct...), tc.Tag...)
+            if tc.Result == "valid" {
+                if got := aead.Seal(nil, tc.Iv, tc.Msg, tc.Aad); !bytes.Equal(got, sealed) {
+                    t.Errorf("tcId %d (%s): Seal: expected %x, got %x", tc.TcID, tc.Comment, sealed, got)
+                }
+            }
+            pt, err := aead.Open(nil, tc.Iv, sealed, tc.Aad)
+            switch {
+            case tc.Result == "valid" && err != nil:
+                t.Errorf("tcId %d (%s): Open: %v", tc.TcID, tc.Comment, err)
+            case tc.Result == "valid" && !bytes.Equal(pt, tc.Msg):
+                t.Errorf("tcId %d (%s): Open: expected %x, got %x", tc.TcID, tc.Comment, tc.Msg, pt)
+            case tc.Result == "invalid" && (err == nil || pt != nil):
+                t.Errorf("tcId %d (%s): %v: Open accepted an invalid message", tc.TcID, tc.Comment, tc.Flags)
+            }
+        }
+    }
+    if ran == 0 {
+        t.Fatal("ascontest: no tests for the tag size of the AEAD")
+    }
+}
@@ -0,0 +1,301 @@
+package ascon
+
+import (
+    "strconv"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// SealBatch seals many messages with the same key, which saves
+// the per-call overhead of Seal for short messages. For each i,
+// it sets dst[i] to the result of
+//
+//    Seal(dst[i], nonces[i], plaintexts[i], ads[i])
+//
+// ads may be nil if no message has additional data. SealBatch
+// panics if nonces, plaintexts or a non-nil ads do not have
+// the length of dst, or if a nonce is not NonceSize bytes
+// long, before sealing any message.
+//
+// On amd64 CPUs with AVX2, ASCON-128a seals four consecutive
+// messages at once if their plaintexts and additional data
+// have the same lengths, as is typical for fixed-size records.
+// Because of that, the output of a message may only overlap
+// its own plaintext, to encrypt in place.
+func (a *AEAD) SealBatch(dst [][]byte, nonces, plaintexts, ads [][]byte) {
+    a.checkBatch(dst, nonces, plaintexts, ads, true)
+
+    for i := 0; i < len(plaintexts); {
+        if a.iv == iv128a && useAVX2 && sameLengths(i, plaintexts, ads) {
+            var outs [4][]byte
+            for j := range outs {
+                dst[i+j], outs[j] = a.sliceForSeal(dst[i+j], plaintexts[i+j])
+            }
+            (*ascon128a)(a).seal4(&outs, nonces[i:i+4], plaintexts[i:i+4], batchAD(ads, i))
+            i += 4
+            continue
+        }
+
+        var out []byte
+        dst[i], out = a.sliceForSeal(dst[i], plaintexts[i])
+        plaintext := plaintexts[i]
+        a.seal(out[:len(plaintext)], out[len(plaintext):], nonces[i], plaintext, batchAD(ads, i)[0])
+        i++
+    }
+}
+
+// OpenBatch is the inverse of SealBatch. For each i, it sets
+// dst[i] to the result of
+//
+//    Open(dst[i], nonces[i], ciphertexts[i], ads[i])
+//
+// or to nil if that message fails to authenticate. It opens
+// every message even if some fail, and returns the index of
+// the first failure along with the error, or -1 and nil if all
+// messages are authentic. A message with a nonce that is not
+// NonceSize bytes long fails like one that does not
+// authenticate. OpenBatch panics if the lengths of the slices
+// do not match, and uses four-way decryption in the same cases
+// as SealBatch.
+func (a *AEAD) OpenBatch(dst [][]byte, nonces, ciphertexts, ads [][]byte) (int, error) {
+    a.checkBatch(dst, nonces, ciphertexts, ads, false)
+
+    first, firstErr := -1, error(nil)
+    fail := func(i int, err error) {
+        dst[i] = nil
+        if first < 0 {
+            first, firstErr = i, err
+        }
+    }
+    for i := 0; i < len(ciphertexts); {
+        if a.iv == iv128a && useAVX2 && sameLengths(i, ciphertexts, ads) && len(ciphertexts[i]) >= a.tagSize &&
+            validNonces(nonces[i:i+4]) {
+            var outs, cts, tags [4][]byte
+            rets := dst[i : i+4]
+            for j := range outs {
+                ct := ciphertexts[i+j]
+                cts[j] = ct[:len(ct)-a.tagSize]
+                tags[j] = ct[len(ct)-a.tagSize:]
+                rets[j], outs[j] = subtle.SliceForAppend(rets[j], len(cts[j]))
+                if subtle.InexactOverlap(outs[j], cts[j]) || subtle.AnyOverlap(outs[j], tags[j]) {
+                    panic("ascon: invalid buffer overlap")
+                }
+            }
+            errs := (*ascon128a)(a).open4(&outs, &cts, &tags, nonces[i:i+4], batchAD(ads, i))
+            for j, err := range errs {
+                if err != nil {
+                    fail(i+j, err)
+                }
+            }
+            i += 4
+            continue
+        }
+
+        ciphertext := ciphertexts[i]
+        if len(nonces[i]) != NonceSize {
+            fail(i, errOpenNonce)
+            i++
+            continue
+        }
+        n, err := OpenedLen(len(ciphertext), a.tagSize)
+        if err != nil {
+            fail(i, err)
+            i++
+            continue
+        }
+        tag := ciphertext[n:]
+        ciphertext = ciphertext[:n]
+
+        ret, out := subtle.SliceForAppend(dst[i], len(ciphertext))
+        if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+            panic("ascon: invalid buffer overlap")
+        }
+        if err := a.open(out, nonces[i], ciphertext, tag, batchAD(ads, i)[0]); err != nil {
+            fail(i, err)
+        } else {
+            dst[i] = ret
+        }
+        i++
+    }
+    return first, firstErr
+}
+
+// checkBatch panics if the lengths of the slices do not match,
+// and if checkNonces is set, if a nonce has the wrong length.
+func (a *AEAD) checkBatch(dst, nonces, texts, ads [][]byte, checkNonces bool) {
+    if len(nonces) != len(dst) || len(texts) != len(dst) ||
+        (ads != nil && len(ads) != len(dst)) {
+        panic("ascon: batch length mismatch")
+    }
+    if !checkNonces {
+        return
+    }
+    for _, nonce := range nonces {
+        if len(nonce) != NonceSize {
+            panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+        }
+    }
+}
+
+// validNonces reports whether every nonce is NonceSize bytes
+// long.
+func validNonces(nonces [][]byte) bool {
+    for _, nonce := range nonces {
+        if len(nonce) != NonceSize {
+            return false
+        }
+    }
+    return true
+}
+
+// sliceForSeal extends dst by the size of the sealed plaintext,
+// like Seal.
+func (a *AEAD) sliceForSeal(dst, plaintext []byte) (ret, out []byte) {
+    n, err := SealedLen(len(plaintext), a.tagSize)
+    if err != nil {
+        panic(err)
+    }
+    ret, out = subtle.SliceForAppend(dst, n)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+    return ret, out
+}
+
+// noAD is the additional data of batches without any.
+var noAD [4][]byte
+
+// batchAD returns the additional data of the messages from i
+// on.
+func batchAD(ads [][]byte, i int) [][]byte {
+    if ads == nil {
+        return noAD[:]
+    }
+    return ads[i:]
+}
+
+// sameLengths reports whether the four messages from i on
+// exist and have the same lengths of text and additional data.
+func sameLengths(i int, texts, ads [][]byte) bool {
+    if i+4 > len(texts) {
+        return false
+    }
+    for j := i + 1; j < i+4; j++ {
+        if len(texts[j]) != len(texts[i]) || ads != nil && len(ads[j]) != len(ads[i]) {
+            return false
+        }
+    }
+    return true
+}
+
+// init4 initializes four ASCON-128a states for the nonces and
+// absorbs the additional data, which must have equal lengths.
+func (a *ascon128a) init4(s *[4]state, nonces, ads [][]byte) {
+    for j := range s {
+        s[j] = state{iv128a, a.k0, a.k1,
+            binary.BigEndian.Uint64(nonces[j][0:]), binary.BigEndian.Uint64(nonces[j][8:])}
+    }
+    p12x4(s)
+    for j := range s {
+        s[j][3] ^= a.k0
+        s[j][4] ^= a.k1
+    }
+
+    if n := len(ads[0]); n > 0 {
+        off := 0
+        for ; off+BlockSize128a <= n; off += BlockSize128a {
+            for j := range s {
+                ad := ads[j][off:]
+                s[j][0] ^= binary.BigEndian.Uint64(ad[0:8])
+                s[j][1] ^= binary.BigEndian.Uint64(ad[8:16])
+            }
+            p8x4(s)
+        }
+        for j := range s {
+            s[j].padBlock128a(ads[j][off:])
+        }
+        p8x4(s)
+    }
+    for j := range s {
+        s[j][4] ^= 1
+    }
+}
+
+// finalize4 is finalize128a for four states.
+func (a *ascon128a) finalize4(s *[4]state) {
+    for j := range s {
+        s[j][2] ^= a.k0
+        s[j][3] ^= a.k1
+    }
+    p12x4(s)
+    for j := range s {
+        s[j][3] ^= a.k0
+        s[j][4] ^= a.k1
+    }
+}
+
+// seal4 is seal for four messages with equal lengths of
+// plaintext and additional data. Each out must have room for
+// the ciphertext and authenticator.
+func (a *ascon128a) seal4(outs *[4][]byte, nonces, plaintexts, ads [][]byte) {
+    var s [4]state
+    a.init4(&s, nonces, ads)
+
+    n := len(plaintexts[0])
+    off := 0
+    for ; off+BlockSize128a <= n; off += BlockSize128a {
+        for j := range s {
+            src, dst := plaintexts[j][off:], outs[j][off:]
+            s[j][0] ^= binary.BigEndian.Uint64(src[0:8])
+            s[j][1] ^= binary.BigEndian.Uint64(src[8:16])
+            binary.BigEndian.PutUint64(dst[0:8], s[j][0])
+            binary.BigEndian.PutUint64(dst[8:16], s[j][1])
+        }
+        p8x4(&s)
+    }
+    for j := range s {
+        s[j].encryptTail128a(outs[j][off:n], plaintexts[j][off:])
+    }
+    a.finalize4(&s)
+
+    for j := range s {
+        var t [TagSize]byte
+        s[j].tag(t[:])
+        copy(outs[j][n:], t[:a.tagSize])
+    }
+}
+
+// open4 is open for four messages with equal lengths of
+// ciphertext and additional data.
+func (a *ascon128a) open4(outs, ciphertexts, tags *[4][]byte, nonces, ads [][]byte) [4]error {
+    var s [4]state
+    a.init4(&s, nonces, ads)
+
+    n := len(ciphertexts[0])
+    off := 0
+    for ; off+BlockSize128a <= n; off += BlockSize128a {
+        for j := range s {
+            src, dst := ciphertexts[j][off:], outs[j][off:]
+            c0 := binary.BigEndian.Uint64(src[0:8])
+            c1 := binary.BigEndian.Uint64(src[8:16])
+            binary.BigEndian.PutUint64(dst[0:8], s[j][0]^c0)
+            binary.BigEndian.PutUint64(dst[8:16], s[j][1]^c1)
+            s[j][0] = c0
+            s[j][1] = c1
+        }
+        p8x4(&s)
+    }
+    for j := range s {
+        s[j].decryptTail128a(outs[j][off:], ciphertexts[j][off:])
+    }
+    a.finalize4(&s)
+
+    var errs [4]error
+    for j := range s {
+        var expectedTag [TagSize]byte
+        s[j].tag(expectedTag[:])
+        errs[j] = verifyTag(outs[j], &expectedTag, tags[j])
+    }
+    return errs
+}
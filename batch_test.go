@@ -0,0 +1,324 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "testing"
+    "encoding/binary"
+)
+
+// batchInputs returns n nonces, plaintexts of size bytes and
+// additional data.
+func batchInputs(n, size int) (nonces, pts, ads [][]byte) {
+    for i := 0; i < n; i++ {
+        nonce := make([]byte, NonceSize)
+        binary.BigEndian.PutUint64(nonce[8:], uint64(i))
+        nonces = append(nonces, nonce)
+        pts = append(pts, bytes.Repeat([]byte{byte(i)}, size+i%3))
+        ads = append(ads, seq(0, i%20))
+    }
+    return nonces, pts, ads
+}
+
+func TestBatch(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            c, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            aead := c.(*AEAD)
+            nonces, pts, ads := batchInputs(50, 16)
+
+            for _, ads := range [][][]byte{ads, nil} {
+                cts := make([][]byte, len(pts))
+                cts[1] = []byte("prefix")
+                aead.SealBatch(cts, nonces, pts, ads)
+                for i := range pts {
+                    var ad []byte
+                    if ads != nil {
+                        ad = ads[i]
+                    }
+                    want := aead.Seal(nil, nonces[i], pts[i], ad)
+                    got := cts[i]
+                    if i == 1 {
+                        got = got[6:]
+                    }
+                    if !bytes.Equal(got, want) {
+                        t.Fatalf("#%d: expected %#x, got %#x", i, want, got)
+                    }
+                }
+                cts[1] = cts[1][6:]
+
+                out := make([][]byte, len(cts))
+                if i, err := aead.OpenBatch(out, nonces, cts, ads); err != nil {
+                    t.Fatalf("#%d: %v", i, err)
+                }
+                for i := range pts {
+                    if !bytes.Equal(out[i], pts[i]) {
+                        t.Fatalf("#%d: expected %#x, got %#x", i, pts[i], out[i])
+                    }
+                }
+
+                // Failures are reported per message.
+                cts[7][0] ^= 1
+                cts[3] = cts[3][:aead.Overhead()-1]
+                out = make([][]byte, len(cts))
+                i, err := aead.OpenBatch(out, nonces, cts, ads)
+                if i != 3 || !errors.Is(err, ErrAuthentication) {
+                    t.Fatalf("expected 3, %v, got %d, %v", ErrAuthentication, i, err)
+                }
+                for i := range pts {
+                    if i == 3 || i == 7 {
+                        if out[i] != nil {
+                            t.Fatalf("#%d: expected nil, got %#x", i, out[i])
+                        }
+                    } else if !bytes.Equal(out[i], pts[i]) {
+                        t.Fatalf("#%d: expected %#x, got %#x", i, pts[i], out[i])
+                    }
+                }
+            }
+        })
+    }
+}
+
+func TestBatchPanics(t *testing.T) {
+    aead := New128aKey(&[KeySize]byte{})
+    nonces, pts, ads := batchInputs(4, 16)
+    for name, fn := range map[string]func(){
+        "dst":   func() { aead.SealBatch(make([][]byte, 3), nonces, pts, ads) },
+        "ads":   func() { aead.SealBatch(make([][]byte, 4), nonces, pts, ads[:2]) },
+        "nonce": func() { aead.SealBatch(make([][]byte, 4), append(nonces[:3:3], nil), pts, ads) },
+    } {
+        t.Run(name, func(t *testing.T) {
+            defer func() {
+                if recover() == nil {
+                    t.Fatal("expected a panic")
+                }
+            }()
+            fn()
+        })
+    }
+}
+
+func TestBatch4(t *testing.T) {
+    aead := New128aKey((*[KeySize]byte)(seq(0, KeySize)))
+    for _, size := range []int{0, 1, 8, 15, 16, 17, 40} {
+        for _, adSize := range []int{-1, 0, 5, 16, 33} {
+            nonces, pts, ads := batchInputs(9, 0)
+            for i := range pts {
+                pts[i] = bytes.Repeat([]byte{byte(i)}, size)
+                if adSize >= 0 {
+                    ads[i] = bytes.Repeat([]byte{byte(i + 1)}, adSize)
+                }
+            }
+            if adSize < 0 {
+                ads = nil
+            }
+
+            want := make([][]byte, len(pts))
+            for i := range pts {
+                want[i] = aead.Seal(nil, nonces[i], pts[i], batchAD(ads, i)[0])
+            }
+
+            // seal4 and open4 run on every platform, with the
+            // scalar permutations where AVX2 is not available.
+            var outs [4][]byte
+            for j := range outs {
+                outs[j] = make([]byte, size+TagSize)
+            }
+            (*ascon128a)(aead).seal4(&outs, nonces, pts, batchAD(ads, 0))
+            for j := range outs {
+                if !bytes.Equal(outs[j], want[j]) {
+                    t.Fatalf("%d/%d: seal4 #%d: expected %#x, got %#x", size, adSize, j, want[j], outs[j])
+                }
+            }
+            var cts, tags [4][]byte
+            for j := range cts {
+                cts[j], tags[j] = want[j][:size], want[j][size:]
+                outs[j] = make([]byte, size)
+            }
+            tags[2] = append([]byte{^tags[2][0]}, tags[2][1:]...)
+            errs := (*ascon128a)(aead).open4(&outs, &cts, &tags, nonces, batchAD(ads, 0))
+            for j := range outs {
+                if j == 2 {
+                    if !errors.Is(errs[j], ErrAuthentication) || !bytes.Equal(outs[j], make([]byte, size)) {
+                        t.Fatalf("%d/%d: open4 accepted a bad tag", size, adSize)
+                    }
+                } else if errs[j] != nil || !bytes.Equal(outs[j], pts[j]) {
+                    t.Fatalf("%d/%d: open4 #%d: %v, %#x", size, adSize, j, errs[j], outs[j])
+                }
+            }
+
+            got := make([][]byte, len(pts))
+            aead.SealBatch(got, nonces, pts, ads)
+            for i := range got {
+                if !bytes.Equal(got[i], want[i]) {
+                    t.Fatalf("%d/%d: SealBatch #%d: expected %#x, got %#x", size, adSize, i, want[i], got[i])
+                }
+            }
+            got[5] = append([]byte(nil), got[5]...)
+            got[5][0] ^= 1
+            out := make([][]byte, len(pts))
+            if i, err := aead.OpenBatch(out, nonces, got, ads); i != 5 || !errors.Is(err, ErrAuthentication) {
+                t.Fatalf("%d/%d: expected 5, %v, got %d, %v", size, adSize, ErrAuthentication, i, err)
+            }
+            for i := range out {
+                if i == 5 {
+                    if out[i] != nil {
+                        t.Fatalf("%d/%d: #5: expected nil, got %#x", size, adSize, out[i])
+                    }
+                } else if !bytes.Equal(out[i], pts[i]) {
+                    t.Fatalf("%d/%d: #%d: expected %#x, got %#x", size, adSize, i, pts[i], out[i])
+                }
+            }
+        }
+    }
+}
+
+func TestHash256Batch(t *testing.T) {
+    for _, sizes := range [][]int{
+        {0, 0, 0, 0},
+        {7, 7, 7, 7, 7},
+        {8, 8, 8, 8},
+        {100, 100, 100, 100, 100, 100, 100, 100, 3},
+        {1, 20, 300, 4, 9, 9, 9, 200},
+    } {
+        msgs := make([][]byte, len(sizes))
+        for i, n := range sizes {
+            msgs[i] = bytes.Repeat([]byte{byte(i)}, n)
+        }
+        digests := make([][HashSize]byte, len(msgs))
+        Hash256Batch(digests, msgs)
+
+        var x4 [4][HashSize]byte
+        hash256x4(&x4, (*[4][]byte)(msgs))
+        for i, msg := range msgs {
+            h := NewHash256()
+            h.Write(msg)
+            want := h.Sum(nil)
+            if !bytes.Equal(digests[i][:], want) {
+                t.Fatalf("%v #%d: expected %x, got %x", sizes, i, want, digests[i])
+            }
+            if i < 4 && !bytes.Equal(x4[i][:], want) {
+                t.Fatalf("%v #%d: hash256x4: expected %x, got %x", sizes, i, want, x4[i])
+            }
+        }
+    }
+}
+
+// The single sub-benchmarks pass one message at a time, which
+// takes the scalar path, for comparison.
+
+func BenchmarkSealBatch16_128a(b *testing.B) {
+    benchmarkSealBatch(b, 16)
+}
+
+func BenchmarkOpenBatch16_128a(b *testing.B) {
+    benchmarkOpenBatch(b, 16)
+}
+
+func BenchmarkSealBatch64_128a(b *testing.B) {
+    benchmarkSealBatch(b, 64)
+}
+
+func BenchmarkOpenBatch64_128a(b *testing.B) {
+    benchmarkOpenBatch(b, 64)
+}
+
+func BenchmarkHash256Batch64(b *testing.B) {
+    benchmarkHash256Batch(b, 64)
+}
+
+func BenchmarkHash256Batch1K(b *testing.B) {
+    benchmarkHash256Batch(b, 1024)
+}
+
+// batchSize is the number of messages in a benchmark batch.
+const batchSize = 1024
+
+// batchSteps are the numbers of messages per call of the batch
+// and single sub-benchmarks.
+var batchSteps = []struct {
+    name string
+    n    int
+}{
+    {"batch", batchSize},
+    {"single", 1},
+}
+
+func benchmarkSealBatch(b *testing.B, size int) {
+    for _, step := range batchSteps {
+        b.Run(step.name, func(b *testing.B) {
+            aead := New128aKey(&[KeySize]byte{})
+            nonces := make([][]byte, batchSize)
+            pts := make([][]byte, batchSize)
+            cts := make([][]byte, batchSize)
+            for i := range pts {
+                nonces[i] = make([]byte, NonceSize)
+                pts[i] = make([]byte, size)
+                cts[i] = make([]byte, 0, size+TagSize)
+            }
+            b.SetBytes(int64(size * batchSize))
+
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                for j := range cts {
+                    cts[j] = cts[j][:0]
+                }
+                for j := 0; j < batchSize; j += step.n {
+                    aead.SealBatch(cts[j:j+step.n], nonces[j:j+step.n], pts[j:j+step.n], nil)
+                }
+            }
+        })
+    }
+}
+
+func benchmarkOpenBatch(b *testing.B, size int) {
+    for _, step := range batchSteps {
+        b.Run(step.name, func(b *testing.B) {
+            aead := New128aKey(&[KeySize]byte{})
+            nonces := make([][]byte, batchSize)
+            pts := make([][]byte, batchSize)
+            cts := make([][]byte, batchSize)
+            for i := range pts {
+                nonces[i] = make([]byte, NonceSize)
+                pts[i] = make([]byte, 0, size)
+                cts[i] = aead.Seal(nil, nonces[i], make([]byte, size), nil)
+            }
+            b.SetBytes(int64(size * batchSize))
+
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                for j := range pts {
+                    pts[j] = pts[j][:0]
+                }
+                for j := 0; j < batchSize; j += step.n {
+                    if _, err := aead.OpenBatch(pts[j:j+step.n], nonces[j:j+step.n], cts[j:j+step.n], nil); err != nil {
+                        b.Fatal(err)
+                    }
+                }
+            }
+        })
+    }
+}
+
+func benchmarkHash256Batch(b *testing.B, size int) {
+    for _, step := range batchSteps {
+        b.Run(step.name, func(b *testing.B) {
+            msgs := make([][]byte, batchSize)
+            for i := range msgs {
+                msgs[i] = make([]byte, size)
+            }
+            digests := make([][HashSize]byte, batchSize)
+            b.SetBytes(int64(size * batchSize))
+
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                for j := 0; j < batchSize; j += step.n {
+                    Hash256Batch(digests[j:j+step.n], msgs[j:j+step.n])
+                }
+            }
+        })
+    }
+}
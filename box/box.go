@@ -0,0 +1,148 @@
+// Package box encrypts messages to the X25519 public key of a
+// recipient, in the manner of ECIES and of HPKE's base mode.
+//
+// SealX25519 generates an ephemeral X25519 key pair (e, E) for
+// every message, and computes, with R the public key of the
+// recipient,
+//
+//    shared = X25519(e, R)
+//    info   = "go-ascon box X25519 v1" || E || R
+//    key    = kdf.Key(shared, nil, info, 16)
+//    blob   = E || nonce || Ascon-AEAD128(key, nonce, plaintext, ad)
+//
+// where kdf.Key is the key derivation function of package kdf,
+// with no salt, nonce is 16 random bytes, and the ciphertext
+// ends with the 16-byte tag. The recipient computes shared as
+// X25519(r, E). A blob is thus Overhead bytes longer than its
+// plaintext. The tests read testdata/vectors.txt, which has
+// vectors of blobs with their shared secrets and keys.
+//
+// The public keys are 32-byte strings, as in RFC 7748. If either
+// is a point of low order, shared is all zeros, and both
+// functions return an error instead. Otherwise, binding both
+// public keys in info means that a blob only opens with the key
+// pair of its intended recipient.
+//
+// The sender is anonymous: anyone with the public key of the
+// recipient can seal a blob to it.
+//
+// References:
+//
+//    [rfc7748]: https://www.rfc-editor.org/rfc/rfc7748
+//    [rfc9180]: https://www.rfc-editor.org/rfc/rfc9180
+//
+package box
+
+import (
+    "errors"
+    "io"
+    "crypto/ecdh"
+    "crypto/rand"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/kdf"
+)
+
+const (
+    // KeySize is the size in bytes of X25519 public and private
+    // keys.
+    KeySize = 32
+    // Overhead is the difference in bytes between the size of a
+    // blob and of its plaintext: an ephemeral public key, a
+    // nonce and a tag.
+    Overhead = KeySize + ascon.NonceSize + ascon.TagSize
+)
+
+// info divides the key derivation of this package from other
+// uses of package kdf.
+const info = "go-ascon box X25519 v1"
+
+var (
+    // ErrLowOrder is returned for a public key that is a point
+    // of low order, with which X25519 gives an all-zero output.
+    ErrLowOrder = errors.New("box: low order X25519 public key")
+
+    // errShort is returned by OpenX25519 for a blob shorter
+    // than Overhead.
+    errShort = errors.New("box: blob too short")
+)
+
+// randReader is the source of ephemeral keys and nonces,
+// replaced in tests.
+var randReader io.Reader = rand.Reader
+
+// deriveKey returns the AEAD key of the shared secret of priv and
+// peer, where ephemeral and recipient are the public keys of the
+// message.
+func deriveKey(priv *ecdh.PrivateKey, peer, ephemeral, recipient []byte) ([]byte, error) {
+    pub, err := ecdh.X25519().NewPublicKey(peer)
+    if err != nil {
+        return nil, err
+    }
+    // crypto/ecdh rejects exactly the all-zero outputs of low
+    // order points.
+    shared, err := priv.ECDH(pub)
+    if err != nil {
+        return nil, ErrLowOrder
+    }
+    in := make([]byte, 0, len(info)+2*KeySize)
+    in = append(in, info...)
+    in = append(in, ephemeral...)
+    in = append(in, recipient...)
+    return kdf.Key(shared, nil, in, ascon.KeySize)
+}
+
+// SealX25519 encrypts plaintext to the recipient with the public
+// key recipientPub, authenticating ad, and returns the blob.
+func SealX25519(recipientPub [KeySize]byte, plaintext, ad []byte) ([]byte, error) {
+    eph, err := ecdh.X25519().GenerateKey(randReader)
+    if err != nil {
+        return nil, err
+    }
+    var nonce [ascon.NonceSize]byte
+    if _, err := io.ReadFull(randReader, nonce[:]); err != nil {
+        return nil, err
+    }
+    return seal(eph, nonce[:], recipientPub[:], plaintext, ad)
+}
+
+// seal is SealX25519 with the ephemeral key eph and nonce.
+func seal(eph *ecdh.PrivateKey, nonce, recipientPub, plaintext, ad []byte) ([]byte, error) {
+    ephPub := eph.PublicKey().Bytes()
+    key, err := deriveKey(eph, recipientPub, ephPub, recipientPub)
+    if err != nil {
+        return nil, err
+    }
+    aead, err := ascon.NewAEAD128(key)
+    if err != nil {
+        return nil, err
+    }
+    out := make([]byte, 0, len(plaintext)+Overhead)
+    out = append(out, ephPub...)
+    out = append(out, nonce...)
+    return aead.Seal(out, nonce, plaintext, ad), nil
+}
+
+// OpenX25519 decrypts the blob sealed to the recipient with the
+// private key recipientPriv and the additional data ad. It
+// returns ascon.ErrAuthentication if the blob or ad were
+// modified, or the blob was sealed to another key.
+func OpenX25519(recipientPriv [KeySize]byte, blob, ad []byte) ([]byte, error) {
+    if len(blob) < Overhead {
+        return nil, errShort
+    }
+    priv, err := ecdh.X25519().NewPrivateKey(recipientPriv[:])
+    if err != nil {
+        return nil, err
+    }
+    ephPub, nonce := blob[:KeySize], blob[KeySize:KeySize+ascon.NonceSize]
+    key, err := deriveKey(priv, ephPub, ephPub, priv.PublicKey().Bytes())
+    if err != nil {
+        return nil, err
+    }
+    aead, err := ascon.NewAEAD128(key)
+    if err != nil {
+        return nil, err
+    }
+    return aead.Open(nil, nonce, blob[KeySize+ascon.NonceSize:], ad)
+}
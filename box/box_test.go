@@ -0,0 +1,216 @@
+package box
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/ecdh"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+type vector struct {
+    recipientPriv, recipientPub []byte
+    ephemeralPriv, ephemeralPub []byte
+    shared, key, nonce          []byte
+    pt, ad, blob                []byte
+}
+
+func readVecs(path string) ([]vector, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var vecs []vector
+
+    s := bufio.NewScanner(f)
+    for n := 1; s.Scan(); n++ {
+        t := s.Text()
+        if t == "" {
+            continue
+        }
+        if strings.HasPrefix(t, "Count = ") {
+            vecs = append(vecs, vector{})
+            continue
+        }
+        i := strings.IndexByte(t, '=')
+        if i < 0 || len(vecs) == 0 {
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+        buf, err := hex.DecodeString(strings.TrimSpace(t[i+1:]))
+        if err != nil {
+            return nil, fmt.Errorf("malformed line %d: %v", n, err)
+        }
+        v := &vecs[len(vecs)-1]
+        switch strings.TrimSpace(t[:i]) {
+        case "RecipientPriv":
+            v.recipientPriv = buf
+        case "RecipientPub":
+            v.recipientPub = buf
+        case "EphemeralPriv":
+            v.ephemeralPriv = buf
+        case "EphemeralPub":
+            v.ephemeralPub = buf
+        case "Shared":
+            v.shared = buf
+        case "Key":
+            v.key = buf
+        case "Nonce":
+            v.nonce = buf
+        case "PT":
+            v.pt = buf
+        case "AD":
+            v.ad = buf
+        case "Blob":
+            v.blob = buf
+        default:
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+    }
+    return vecs, s.Err()
+}
+
+func TestVectors(t *testing.T) {
+    vecs, err := readVecs("testdata/vectors.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) == 0 {
+        t.Fatal("no vectors")
+    }
+    for i, v := range vecs {
+        r, err := ecdh.X25519().NewPrivateKey(v.recipientPriv)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        e, err := ecdh.X25519().NewPrivateKey(v.ephemeralPriv)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(r.PublicKey().Bytes(), v.recipientPub) || !bytes.Equal(e.PublicKey().Bytes(), v.ephemeralPub) {
+            t.Fatalf("#%d: wrong public keys", i+1)
+        }
+        if shared, _ := e.ECDH(r.PublicKey()); !bytes.Equal(shared, v.shared) {
+            t.Fatalf("#%d: expected shared secret %#x, got %#x", i+1, v.shared, shared)
+        }
+        key, err := deriveKey(e, v.recipientPub, v.ephemeralPub, v.recipientPub)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(key, v.key) {
+            t.Fatalf("#%d: expected key %#x, got %#x", i+1, v.key, key)
+        }
+
+        blob, err := seal(e, v.nonce, v.recipientPub, v.pt, v.ad)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(blob, v.blob) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.blob, blob)
+        }
+        pt, err := OpenX25519([KeySize]byte(v.recipientPriv), v.blob, v.ad)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(pt, v.pt) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, pt)
+        }
+    }
+}
+
+func newKey(t *testing.T) (priv, pub [KeySize]byte) {
+    k, err := ecdh.X25519().GenerateKey(randReader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return [KeySize]byte(k.Bytes()), [KeySize]byte(k.PublicKey().Bytes())
+}
+
+func TestRoundTrip(t *testing.T) {
+    priv, pub := newKey(t)
+    pt := []byte("a firmware update key")
+    ad := []byte("device 7")
+    blob, err := SealX25519(pub, pt, ad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(blob) != len(pt)+Overhead {
+        t.Fatalf("expected %d bytes, got %d", len(pt)+Overhead, len(blob))
+    }
+    again, err := SealX25519(pub, pt, ad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if bytes.Equal(blob, again) {
+        t.Fatal("two blobs of the same message are equal")
+    }
+    got, err := OpenX25519(priv, blob, ad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(got, pt) {
+        t.Fatalf("expected %q, got %q", pt, got)
+    }
+
+    for i := range blob {
+        bad := append([]byte(nil), blob...)
+        bad[i] ^= 1
+        if _, err := OpenX25519(priv, bad, ad); err == nil {
+            t.Fatalf("byte %d: modified blob opened", i)
+        }
+    }
+    if _, err := OpenX25519(priv, blob, []byte("device 8")); err != ascon.ErrAuthentication {
+        t.Fatalf("expected %v, got %v", ascon.ErrAuthentication, err)
+    }
+    other, _ := newKey(t)
+    if _, err := OpenX25519(other, blob, ad); err != ascon.ErrAuthentication {
+        t.Fatalf("expected %v, got %v", ascon.ErrAuthentication, err)
+    }
+    if _, err := OpenX25519(priv, blob[:Overhead-1], ad); err != errShort {
+        t.Fatalf("expected %v, got %v", errShort, err)
+    }
+}
+
+// lowOrder are encodings of points of low order, some of them
+// non-canonical or with the ignored top bit set.
+var lowOrder = []string{
+    "0000000000000000000000000000000000000000000000000000000000000000",
+    "0100000000000000000000000000000000000000000000000000000000000000",
+    "e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800",
+    "5f9c95bca3508c24b1d0b1559c83ef5b04445cc4581c8e86d8224eddd09f1157",
+    "ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+    "edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+    "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+    "e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b880",
+    "5f9c95bca3508c24b1d0b1559c83ef5b04445cc4581c8e86d8224eddd09f11d7",
+    "ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+    "edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+    "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+}
+
+func TestLowOrder(t *testing.T) {
+    priv, pub := newKey(t)
+    blob, err := SealX25519(pub, []byte("message"), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, s := range lowOrder {
+        p, err := hex.DecodeString(s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if _, err := SealX25519([KeySize]byte(p), []byte("message"), nil); err != ErrLowOrder {
+            t.Fatalf("%s: expected %v, got %v", s, ErrLowOrder, err)
+        }
+        bad := append(append([]byte(nil), p...), blob[KeySize:]...)
+        if _, err := OpenX25519(priv, bad, nil); err != ErrLowOrder {
+            t.Fatalf("%s: expected %v, got %v", s, ErrLowOrder, err)
+        }
+    }
+}
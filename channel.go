@@ -0,0 +1,131 @@
+package ascon
+
+import (
+    "sync"
+    "math"
+    "errors"
+    "crypto/cipher"
+    "encoding/binary"
+)
+
+// ChannelSecretSize is the size in bytes of the shared secret
+// of a Channel.
+const ChannelSecretSize = 32
+
+// channelLabel is the Ascon-CXOF128 customization string of
+// the Channel key derivation.
+const channelLabel = "go-ascon channel v1"
+
+// Channel encrypts ordered messages in both directions between
+// two peers that share a secret. It is safe for concurrent use,
+// although messages sent concurrently are numbered in an
+// unspecified order.
+//
+// The two directions use independent keys, derived as
+//
+//    k_ir || k_ri = Ascon-CXOF128("go-ascon channel v1", secret)
+//
+// with 16 bytes each, where k_ir encrypts messages from the
+// initiator to the responder and k_ri those in the other
+// direction. The n-th message in a direction, counting from
+// zero, is sealed with Ascon-AEAD128 under that direction's
+// key and the nonce
+//
+//    0x00 (8 bytes) || BE64(n)
+//
+// The counter is not sent: a message only decrypts as the
+// next one expected, so reordered, replayed, dropped and
+// reflected messages are all rejected as authentication
+// failures. A failed Decrypt does not change the state, so the
+// next genuine message still decrypts. After 2^64-1 messages
+// in a direction, Encrypt or Decrypt fails with
+// ErrNonceExhausted for that direction, permanently.
+type Channel struct {
+    send, recv channelDir
+}
+
+type channelDir struct {
+    mu   sync.Mutex
+    aead cipher.AEAD
+    n    uint64
+}
+
+// NewChannel returns the end of a Channel for the shared
+// secret, which must be ChannelSecretSize bytes of secret,
+// uniformly random data, such as the output of a key exchange
+// passed through a KDF. Exactly one of the two peers must set
+// initiator.
+func NewChannel(secret []byte, initiator bool) (*Channel, error) {
+    if len(secret) != ChannelSecretSize {
+        return nil, errors.New("ascon: bad channel secret length")
+    }
+    x, err := NewCXOF128([]byte(channelLabel))
+    if err != nil {
+        return nil, err
+    }
+    x.Write(secret)
+    // The initiator sends with k_ir and the responder with k_ri.
+    var send, recv [KeySize]byte
+    x.Read(send[:])
+    x.Read(recv[:])
+    if !initiator {
+        send, recv = recv, send
+    }
+    c := new(Channel)
+    c.send.aead, _ = NewAEAD128(send[:])
+    c.recv.aead, _ = NewAEAD128(recv[:])
+    clear(send[:])
+    clear(recv[:])
+    return c, nil
+}
+
+func (d *channelDir) nonce() ([NonceSize]byte, error) {
+    var nonce [NonceSize]byte
+    if d.n == math.MaxUint64 {
+        return nonce, ErrNonceExhausted
+    }
+    binary.BigEndian.PutUint64(nonce[8:], d.n)
+    return nonce, nil
+}
+
+// Encrypt seals the next message to the peer, authenticating
+// the additional data ad along with it, and returns the
+// ciphertext, which is Overhead bytes longer than plaintext.
+func (c *Channel) Encrypt(plaintext, ad []byte) ([]byte, error) {
+    d := &c.send
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    nonce, err := d.nonce()
+    if err != nil {
+        return nil, err
+    }
+    out := d.aead.Seal(nil, nonce[:], plaintext, ad)
+    d.n++
+    return out, nil
+}
+
+// Decrypt opens the next message from the peer with the same
+// additional data ad that it was sealed with.
+func (c *Channel) Decrypt(ciphertext, ad []byte) ([]byte, error) {
+    d := &c.recv
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    nonce, err := d.nonce()
+    if err != nil {
+        return nil, err
+    }
+    out, err := d.aead.Open(nil, nonce[:], ciphertext, ad)
+    if err != nil {
+        return nil, err
+    }
+    d.n++
+    return out, nil
+}
+
+// Overhead returns the difference between the lengths of a
+// ciphertext and its plaintext.
+func (c *Channel) Overhead() int {
+    return TagSize
+}
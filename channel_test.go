@@ -0,0 +1,113 @@
+package ascon
+
+import (
+    "bytes"
+    "math"
+    "testing"
+    "encoding/hex"
+)
+
+func newTestChannels(t *testing.T) (initiator, responder *Channel) {
+    var err error
+    if initiator, err = NewChannel(seq(0, 32), true); err != nil {
+        t.Fatal(err)
+    }
+    if responder, err = NewChannel(seq(0, 32), false); err != nil {
+        t.Fatal(err)
+    }
+    return initiator, responder
+}
+
+func TestChannelVectors(t *testing.T) {
+    i, r := newTestChannels(t)
+    for n, tc := range []struct {
+        from, to *Channel
+        pt, ad   string
+        ct       string
+    }{
+        {i, r, "hello", "ad", "f79c7239eb121f7c5c6b0473eb718919ced1257924"},
+        {i, r, "", "", "097d89b9a3f83265b4e0346c0ef05408"},
+        {r, i, "reply", "", "b3c636c9b1498cc78ed0c7dfd341b9416155cdb51c"},
+    } {
+        ct, err := tc.from.Encrypt([]byte(tc.pt), []byte(tc.ad))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := hex.EncodeToString(ct); got != tc.ct {
+            t.Fatalf("#%d: expected %s, got %s", n, tc.ct, got)
+        }
+        pt, err := tc.to.Decrypt(ct, []byte(tc.ad))
+        if err != nil {
+            t.Fatalf("#%d: %v", n, err)
+        }
+        if string(pt) != tc.pt {
+            t.Fatalf("#%d: expected %q, got %q", n, tc.pt, pt)
+        }
+    }
+
+    if _, err := NewChannel(make([]byte, ChannelSecretSize-1), true); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+func TestChannelReject(t *testing.T) {
+    i, r := newTestChannels(t)
+    m0, _ := i.Encrypt([]byte("zero"), nil)
+    m1, _ := i.Encrypt([]byte("one"), nil)
+
+    // Out of order, reflected and with the wrong additional
+    // data.
+    if _, err := r.Decrypt(m1, nil); err == nil {
+        t.Fatal("reordered message accepted")
+    }
+    if _, err := i.Decrypt(m0, nil); err == nil {
+        t.Fatal("reflected message accepted")
+    }
+    if _, err := r.Decrypt(m0, []byte("ad")); err == nil {
+        t.Fatal("wrong additional data accepted")
+    }
+
+    // Failures do not advance the counter.
+    for _, m := range [][]byte{m0, m1} {
+        if _, err := r.Decrypt(m, nil); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if _, err := r.Decrypt(m1, nil); err == nil {
+        t.Fatal("replayed message accepted")
+    }
+
+    if got := len(m1) - len("one"); got != i.Overhead() {
+        t.Fatalf("expected overhead %d, got %d", i.Overhead(), got)
+    }
+}
+
+func TestChannelExhausted(t *testing.T) {
+    i, r := newTestChannels(t)
+    i.send.n = math.MaxUint64 - 1
+    r.recv.n = math.MaxUint64 - 1
+    m, err := i.Encrypt([]byte("last"), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if pt, err := r.Decrypt(m, nil); err != nil || !bytes.Equal(pt, []byte("last")) {
+        t.Fatalf("expected %q, got %q, %v", "last", pt, err)
+    }
+    for j := 0; j < 2; j++ {
+        if _, err := i.Encrypt(nil, nil); err != ErrNonceExhausted {
+            t.Fatalf("expected ErrNonceExhausted, got %v", err)
+        }
+        if _, err := r.Decrypt(m, nil); err != ErrNonceExhausted {
+            t.Fatalf("expected ErrNonceExhausted, got %v", err)
+        }
+    }
+
+    // The other direction is unaffected.
+    m, err = r.Encrypt(nil, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := i.Decrypt(m, nil); err != nil {
+        t.Fatal(err)
+    }
+}
@@ -0,0 +1,25 @@
+// Command ascon-genkat writes the known answer tests of the
+// ASCON AEADs, computed by package ascon.
+//
+// Usage:
+//
+//    ascon-genkat [-dir dir]
+//    ascon-genkat -variant name
+//
+// The files have the LWC_AEAD_KAT format and names of the
+// genkat_aead program of the reference implementation, such as
+// ascon128/LWC_AEAD_KAT_128_128.txt, so they can be compared
+// byte for byte with its output, or with that of another
+// implementation. With -variant, the tests of one variant, such
+// as Ascon-AEAD128, are written to the standard output.
+package main
+
+import (
+    "os"
+
+    "github.com/pedroalbanese/go-ascon/internal/cli"
+)
+
+func main() {
+    os.Exit(cli.GenKAT(os.Args[1:], cli.Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}))
+}
@@ -0,0 +1,38 @@
+// Command ascon encrypts and decrypts files with an ASCON AEAD.
+//
+// Usage:
+//
+//    ascon seal [-k key | -p] [-variant name] [-a] [-in file] [-out file] [-f]
+//    ascon open [-k key | -p] [-a] [-in file] [-out file] [-f]
+//    ascon keygen [-variant name]
+//
+// keygen prints a new random key, and seal and open use one.
+// The key, in hexadecimal or base64 as accepted by
+// ascon.ParseKey, is either given to -k, read from the file
+// named by -k file:PATH, or taken from the ASCON_KEY
+// environment variable without -k. The variant is a name such as
+// Ascon-AEAD128, the default, or Ascon-128a. The output is a
+// container of package container, which records the variant,
+// so open does not need it. With -a, the container is in the
+// ASCII armor of package armor, for pasting into emails or
+// tickets. Files are encrypted in chunks, so they are never
+// held in memory, and open only writes authenticated data. It
+// exits with status 1, and removes the output, if the file
+// fails to authenticate.
+//
+// With -p, the file is encrypted with a password instead, read
+// from the terminal or from the ASCON_PASSWORD environment
+// variable, as by ascon.EncryptWithPassword. The output records
+// the scrypt parameters and salt, but unlike a container, the
+// file is held in memory.
+package main
+
+import (
+    "os"
+
+    "github.com/pedroalbanese/go-ascon/internal/cli"
+)
+
+func main() {
+    os.Exit(cli.Ascon(os.Args[1:], cli.Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}))
+}
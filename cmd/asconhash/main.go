@@ -0,0 +1,27 @@
+// Command asconhash prints ASCON digests of files.
+//
+// Usage:
+//
+//    asconhash [-a algorithm] [-n size] [-0] [file ...]
+//
+// It prints a line with the hexadecimal digest and the name of
+// each file, in the layout of sha256sum, or hashes the standard
+// input if there are no files. The algorithm is hash, hasha,
+// hash256 (the default), or one of the XOFs xof, xofa and
+// xof128, whose output size in bytes is set by -n. With -0,
+// lines end with a NUL byte instead of a newline, for names
+// that contain newlines.
+//
+// A file that cannot be read is reported and skipped, and
+// asconhash then exits with status 1.
+package main
+
+import (
+    "os"
+
+    "github.com/pedroalbanese/go-ascon/internal/cli"
+)
+
+func main() {
+    os.Exit(cli.AsconHash(os.Args[1:], cli.Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}))
+}
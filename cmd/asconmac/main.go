@@ -0,0 +1,30 @@
+// Command asconmac computes and checks Ascon-Mac tags of files.
+//
+// Usage:
+//
+//    asconmac [-k key] [-t size] [-prf] [file ...]
+//    asconmac [-k key] [-prf] -verify tag [file]
+//
+// It prints a line with the hexadecimal tag and the name of
+// each file, in the layout of sha256sum, or of the standard
+// input if there are no files. The 16-byte key, in hexadecimal
+// or base64, is either given to -k, read from the file named by
+// -k file:PATH, or taken from the ASCON_KEY environment
+// variable without -k, so it need not appear on the command
+// line. -t truncates the tags, to no fewer than 8 bytes, and
+// -prf computes Ascon-Prf outputs, which may also be longer.
+//
+// With -verify, asconmac exits with status 0 if tag is the tag
+// of the file and 1 if it is not, comparing them in constant
+// time.
+package main
+
+import (
+    "os"
+
+    "github.com/pedroalbanese/go-ascon/internal/cli"
+)
+
+func main() {
+    os.Exit(cli.AsconMAC(os.Args[1:], cli.Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}))
+}
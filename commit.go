@@ -0,0 +1,134 @@
+package ascon
+
+import (
+    "strconv"
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// CommitmentSize is the size in bytes of the key commitment
+// prepended by key-committing AEADs.
+const CommitmentSize = 32
+
+type committing struct {
+    k0, k1 uint64
+}
+
+var _ cipher.AEAD = (*committing)(nil)
+
+// NewCommitting128 creates a key-committing AEAD built on
+// ASCON-128.
+//
+// Plain ASCON is not key-committing: a ciphertext can be
+// crafted that decrypts successfully under two different keys.
+// NewCommitting128 prevents this by deriving a per-message
+// encryption key and commitment from the 16-byte key K and
+// nonce N:
+//
+//    Kenc || Com = Ascon-Prf(K, N), truncated to 48 bytes
+//
+// where Kenc is the first 16 bytes and Com the remaining 32.
+// Seal returns Com || ASCON-128(Kenc, N, A, P) and Open checks
+// Com in constant time before authenticating the rest, so each
+// message carries CommitmentSize extra bytes of overhead.
+//
+// The same nonce and data limits as New128 apply.
+func NewCommitting128(key []byte) (cipher.AEAD, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+
+    return &committing{
+        k0: binary.BigEndian.Uint64(key[0:]),
+        k1: binary.BigEndian.Uint64(key[8:]),
+    }, nil
+}
+
+func (c *committing) NonceSize() int {
+    return NonceSize
+}
+
+func (c *committing) Overhead() int {
+    return CommitmentSize + TagSize
+}
+
+func (c *committing) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    var com [CommitmentSize]byte
+    a := c.derive(com[:], nonce)
+
+    ret, out := subtle.SliceForAppend(dst, CommitmentSize+len(plaintext)+TagSize)
+    if subtle.AnyOverlap(out, plaintext) {
+        // Sealing in place: shift the plaintext past the
+        // commitment and encrypt it there.
+        copy(out[CommitmentSize:], plaintext)
+        plaintext = out[CommitmentSize : CommitmentSize+len(plaintext)]
+    }
+
+    a.Seal(out[CommitmentSize:CommitmentSize], nonce, plaintext, additionalData)
+    copy(out, com[:])
+
+    return ret
+}
+
+func (c *committing) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
+    }
+
+    if len(ciphertext) < CommitmentSize+TagSize {
+        return nil, errOpenShort
+    }
+
+    var com [CommitmentSize]byte
+    a := c.derive(com[:], nonce)
+
+    if subtle.ConstantTimeCompare(com[:], ciphertext[:CommitmentSize]) != 1 {
+        return nil, ErrAuthentication
+    }
+    ciphertext = ciphertext[CommitmentSize:]
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext)-TagSize)
+    if subtle.AnyOverlap(out, ciphertext) {
+        // Opening in place: decrypt where the ciphertext is
+        // and shift the plaintext down afterwards.
+        pt, err := a.Open(ciphertext[:0], nonce, ciphertext, additionalData)
+        if err != nil {
+            return nil, err
+        }
+        copy(out, pt)
+        return ret, nil
+    }
+
+    if _, err := a.Open(out[:0], nonce, ciphertext, additionalData); err != nil {
+        return nil, err
+    }
+    return ret, nil
+}
+
+// derive writes the commitment for nonce to com and returns
+// the ASCON-128 instance keyed with the per-message key.
+func (c *committing) derive(com, nonce []byte) *AEAD {
+    var m macState
+    m.init(ivPRF, c.k0, c.k1)
+    m.write(nonce)
+    m.finish()
+
+    var buf [3 * macOutRate]byte
+    m.squeeze(buf[0:])
+    m.squeeze(buf[16:])
+    m.squeeze(buf[32:])
+    copy(com, buf[16:])
+
+    return &AEAD{
+        k0:      binary.BigEndian.Uint64(buf[0:]),
+        k1:      binary.BigEndian.Uint64(buf[8:]),
+        iv:      iv128,
+        tagSize: TagSize,
+    }
+}
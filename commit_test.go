@@ -0,0 +1,102 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "testing"
+)
+
+func TestCommitting128(t *testing.T) {
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    for i := range key {
+        key[i] = byte(i)
+        nonce[i] = byte(i)
+    }
+    aead, err := NewCommitting128(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if aead.Overhead() != CommitmentSize+TagSize {
+        t.Fatalf("expected overhead %d, got %d",
+            CommitmentSize+TagSize, aead.Overhead())
+    }
+
+    for _, tc := range []struct {
+        pt, ad []byte
+        ct     string
+    }{
+        {
+            nil, nil,
+            "7d66c42fe60b3f07c07155947ed2797c7e24dc04407f4a6fc998d7f14365a538" +
+                "6c8ec4bd93524d06632ba007fed2e585",
+        },
+        {
+            []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18},
+            []byte{0, 1, 2, 3, 4},
+            "7d66c42fe60b3f07c07155947ed2797c7e24dc04407f4a6fc998d7f14365a538" +
+                "af49d46c7356814a7bb0bdab5901071c757e58" +
+                "0ed718c4317c6d50de8064da77d81989",
+        },
+    } {
+        want, _ := hex.DecodeString(tc.ct)
+        got := aead.Seal(nil, nonce, tc.pt, tc.ad)
+        if !bytes.Equal(got, want) {
+            t.Fatalf("expected %#x, got %#x", want, got)
+        }
+        pt, err := aead.Open(nil, nonce, got, tc.ad)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(pt, tc.pt) {
+            t.Fatalf("expected %#x, got %#x", tc.pt, pt)
+        }
+
+        // In place.
+        buf := make([]byte, len(tc.pt), len(tc.pt)+aead.Overhead())
+        copy(buf, tc.pt)
+        buf = aead.Seal(buf[:0], nonce, buf, tc.ad)
+        if !bytes.Equal(buf, want) {
+            t.Fatalf("expected %#x, got %#x", want, buf)
+        }
+        buf, err = aead.Open(buf[:0], nonce, buf, tc.ad)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(buf, tc.pt) {
+            t.Fatalf("expected %#x, got %#x", tc.pt, buf)
+        }
+    }
+}
+
+func TestCommitting128Mismatch(t *testing.T) {
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    aead, err := NewCommitting128(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ct := aead.Seal(nil, nonce, []byte("plaintext"), nil)
+
+    // The inner ciphertext and tag are untouched, so only the
+    // commitment check can reject this.
+    for i := 0; i < CommitmentSize; i++ {
+        c := append([]byte(nil), ct...)
+        c[i] ^= 1
+        if _, err := aead.Open(nil, nonce, c, nil); err == nil {
+            t.Fatalf("#%d: expected an error", i)
+        }
+    }
+
+    key[0] ^= 1
+    other, err := NewCommitting128(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := other.Open(nil, nonce, ct, nil); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := aead.Open(nil, nonce, ct[:CommitmentSize+TagSize-1], nil); err == nil {
+        t.Fatal("expected an error")
+    }
+}
@@ -0,0 +1,108 @@
+package ascon
+
+import (
+    "bytes"
+    "reflect"
+    "strconv"
+    "sync"
+    "testing"
+    "encoding/binary"
+)
+
+// TestAEADImmutable checks that AEAD only has plain value
+// fields. State that changes after construction belongs in a
+// wrapper type, like Limited or ReuseGuard, so that an AEAD
+// stays safe to share between goroutines.
+func TestAEADImmutable(t *testing.T) {
+    typ := reflect.TypeOf(AEAD{})
+    for i := 0; i < typ.NumField(); i++ {
+        f := typ.Field(i)
+        switch f.Type.Kind() {
+        case reflect.Uint64, reflect.Int:
+        default:
+            t.Errorf("AEAD.%s has type %v: keep mutable state out of AEAD", f.Name, f.Type)
+        }
+    }
+}
+
+// TestConcurrent seals and opens with one AEAD from many
+// goroutines at once. Run it with -race.
+func TestConcurrent(t *testing.T) {
+    const (
+        goroutines = 64
+        iterations = 50
+    )
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            c, err := tc.fn(seq(0, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            a := c.(*AEAD)
+
+            // Inputs and a dst prefix shared by every goroutine,
+            // which only read them.
+            pt := seq(0, 100)
+            ad := seq(100, 120)
+            prefix := []byte("prefix")[:6:6]
+            nonce := make([]byte, NonceSize)
+            shared := a.Seal(nil, nonce, pt, ad)
+
+            var wg sync.WaitGroup
+            for g := 0; g < goroutines; g++ {
+                wg.Add(1)
+                go func(g int) {
+                    defer wg.Done()
+                    buf := make([]byte, 0, len(pt)+TagSize)
+                    out := make([]byte, len(pt)+TagSize)
+                    ownNonce := make([]byte, NonceSize)
+                    for i := 0; i < iterations; i++ {
+                        // The shared message, with the shared
+                        // prefix as dst.
+                        if got := a.Seal(prefix, nonce, pt, ad); !bytes.Equal(got[len(prefix):], shared) {
+                            t.Errorf("goroutine %d: Seal: expected %#x, got %#x", g, shared, got[len(prefix):])
+                            return
+                        }
+                        if got, err := a.Open(prefix, nonce, shared, ad); err != nil || !bytes.Equal(got[len(prefix):], pt) {
+                            t.Errorf("goroutine %d: Open: %v", g, err)
+                            return
+                        }
+
+                        // A message of its own in reused buffers.
+                        binary.BigEndian.PutUint64(ownNonce, uint64(g))
+                        binary.BigEndian.PutUint64(ownNonce[8:], uint64(i))
+                        msg := pt[:(g+i)%len(pt)]
+                        buf = a.Seal(buf[:0], ownNonce, msg, ad)
+                        n, err := a.OpenTo(out, ownNonce, buf, ad)
+                        if err != nil || !bytes.Equal(out[:n], msg) {
+                            t.Errorf("goroutine %d: OpenTo: %v", g, err)
+                            return
+                        }
+                    }
+                }(g)
+            }
+            wg.Wait()
+        })
+    }
+}
+
+// BenchmarkSealParallel seals from every goroutine with one
+// AEAD. Run it with -cpu 1,2,4,8: the throughput should scale
+// with the number of CPUs, since the calls share nothing that
+// they write.
+func BenchmarkSealParallel(b *testing.B) {
+    for _, size := range []int{64, 1024} {
+        b.Run(strconv.Itoa(size), func(b *testing.B) {
+            a := New128aKey(&[KeySize]byte{})
+            b.SetBytes(int64(size))
+            b.RunParallel(func(pb *testing.PB) {
+                nonce := make([]byte, NonceSize)
+                pt := make([]byte, size)
+                buf := make([]byte, 0, size+TagSize)
+                for pb.Next() {
+                    buf = a.Seal(buf[:0], nonce, pt, nil)
+                }
+            })
+        })
+    }
+}
@@ -0,0 +1,142 @@
+// Package container implements a self-describing file format
+// for data encrypted with an ASCON AEAD.
+//
+// A container records everything needed to decrypt it besides
+// the key: the variant, the tag size, the chunk size and the
+// nonce prefix. Its payload is a stream of package stream, whose
+// every chunk authenticates the whole header as additional
+// data, so changing any header field makes the container fail
+// to open. SPEC.md describes the format, with test vectors.
+package container
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "strconv"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/stream"
+)
+
+const (
+    // Magic starts every container.
+    Magic = "ASCON"
+    // Version1 is the only container format version.
+    Version1 = 1
+    // HeaderSize is the size in bytes of the container header,
+    // which ends with the stream header.
+    HeaderSize = len(Magic) + 3 + stream.HeaderSize
+    // prefixSize is the size of the fields before the stream
+    // header.
+    prefixSize = HeaderSize - stream.HeaderSize
+)
+
+var (
+    // ErrNotContainer is returned by NewReader for data that
+    // does not start with Magic.
+    ErrNotContainer = errors.New("container: not an ascon container")
+    // ErrUnsupportedVersion is returned by NewReader for a
+    // container of an unknown format version.
+    ErrUnsupportedVersion = errors.New("container: unsupported version")
+)
+
+// Header describes a container.
+type Header struct {
+    Variant   ascon.Variant
+    TagSize   int
+    ChunkSize int
+    Prefix    [stream.PrefixSize]byte
+}
+
+// NewWriter writes the header of a container of the variant v
+// to w, and returns a stream.Writer that encrypts everything
+// written to it into the container with key, in chunks of
+// chunkSize bytes. The nonce prefix is random. Close must be
+// called to write the final chunk.
+func NewWriter(w io.Writer, v ascon.Variant, key []byte, chunkSize int, opts ...stream.Option) (*stream.Writer, error) {
+    var prefix [stream.PrefixSize]byte
+    if _, err := rand.Read(prefix[:]); err != nil {
+        return nil, err
+    }
+    return newWriter(w, v, key, chunkSize, prefix[:], opts)
+}
+
+func newWriter(w io.Writer, v ascon.Variant, key []byte, chunkSize int, prefix []byte, opts []stream.Option) (*stream.Writer, error) {
+    aead, err := ascon.New(key, ascon.WithVariant(v))
+    if err != nil {
+        return nil, err
+    }
+    fields := appendFields(nil, v, aead)
+    if _, err := w.Write(fields); err != nil {
+        return nil, err
+    }
+    return stream.NewWriter(aead, prefix, chunkSize, w, append(opts, stream.WithAdditionalData(fields))...)
+}
+
+// appendFields appends the header fields before the stream
+// header.
+func appendFields(b []byte, v ascon.Variant, aead cipher.AEAD) []byte {
+    b = append(b, Magic...)
+    return append(b, Version1, byte(v), byte(aead.Overhead()))
+}
+
+// Reader decrypts a container. Only authenticated plaintext is
+// returned by Read.
+type Reader struct {
+    *stream.Reader
+    header Header
+}
+
+// NewReader reads the header of a container from r, and returns
+// a Reader that decrypts it with key. A modified header, as
+// well as a wrong key, makes the first Read fail with
+// stream.ErrInvalidChunk.
+func NewReader(r io.Reader, key []byte, opts ...stream.Option) (*Reader, error) {
+    fields := make([]byte, prefixSize)
+    if _, err := io.ReadFull(r, fields); err != nil {
+        if err == io.EOF || err == io.ErrUnexpectedEOF {
+            return nil, ErrNotContainer
+        }
+        return nil, err
+    }
+    if string(fields[:len(Magic)]) != Magic {
+        return nil, ErrNotContainer
+    }
+    if fields[len(Magic)] != Version1 {
+        return nil, ErrUnsupportedVersion
+    }
+    v := ascon.Variant(fields[len(Magic)+1])
+    aead, err := ascon.New(key, ascon.WithVariant(v))
+    if err != nil {
+        return nil, err
+    }
+    tagSize := int(fields[len(Magic)+2])
+    if tagSize != aead.Overhead() {
+        return nil, errors.New("container: unsupported tag size " + strconv.Itoa(tagSize))
+    }
+
+    // The stream header is read again by stream.NewReader.
+    var sh [stream.HeaderSize]byte
+    if _, err := io.ReadFull(r, sh[:]); err != nil {
+        if err == io.EOF {
+            err = io.ErrUnexpectedEOF
+        }
+        return nil, err
+    }
+    sr, err := stream.NewReader(aead, io.MultiReader(bytes.NewReader(sh[:]), r), append(opts, stream.WithAdditionalData(fields))...)
+    if err != nil {
+        return nil, err
+    }
+    h := Header{Variant: v, TagSize: tagSize, ChunkSize: int(binary.BigEndian.Uint32(sh[1:]))}
+    copy(h.Prefix[:], sh[5:])
+    return &Reader{Reader: sr, header: h}, nil
+}
+
+// Header returns the header of the container.
+func (r *Reader) Header() Header {
+    return r.header
+}
@@ -0,0 +1,204 @@
+package container
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/hex"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/stream"
+)
+
+type vector struct {
+    variant   ascon.Variant
+    chunkSize int
+    key       []byte
+    prefix    []byte
+    pt        []byte
+    container []byte
+}
+
+// specVectors returns the test vectors of SPEC.md.
+func specVectors(t *testing.T) []vector {
+    f, err := os.Open("SPEC.md")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+
+    var vecs []vector
+    s := bufio.NewScanner(f)
+    s.Buffer(nil, 1<<20)
+    for s.Scan() {
+        field, value, ok := strings.Cut(s.Text(), "=")
+        if !ok {
+            continue
+        }
+        field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+        if field == "Variant" {
+            v, err := strconv.Atoi(value)
+            if err != nil {
+                t.Fatal(err)
+            }
+            vecs = append(vecs, vector{variant: ascon.Variant(v)})
+            continue
+        }
+        if len(vecs) == 0 {
+            continue
+        }
+        v := &vecs[len(vecs)-1]
+        if field == "ChunkSize" {
+            if v.chunkSize, err = strconv.Atoi(value); err != nil {
+                t.Fatal(err)
+            }
+            continue
+        }
+        b, err := hex.DecodeString(value)
+        if err != nil {
+            t.Fatalf("%s: %v", field, err)
+        }
+        switch field {
+        case "Key":
+            v.key = b
+        case "Prefix":
+            v.prefix = b
+        case "PT":
+            v.pt = b
+        case "Container":
+            v.container = b
+        default:
+            t.Fatalf("unknown field %q", field)
+        }
+    }
+    if err := s.Err(); err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) == 0 {
+        t.Fatal("no test vectors in SPEC.md")
+    }
+    return vecs
+}
+
+func open(key, container []byte) ([]byte, error) {
+    r, err := NewReader(bytes.NewReader(container), key)
+    if err != nil {
+        return nil, err
+    }
+    return io.ReadAll(r)
+}
+
+func TestSpecVectors(t *testing.T) {
+    for i, v := range specVectors(t) {
+        var buf bytes.Buffer
+        w, err := newWriter(&buf, v.variant, v.key, v.chunkSize, v.prefix, nil)
+        if err != nil {
+            t.Fatal(err)
+        }
+        w.Write(v.pt)
+        if err := w.Close(); err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(buf.Bytes(), v.container) {
+            t.Errorf("vector %d: expected %x, got %x", i, v.container, buf.Bytes())
+        }
+
+        r, err := NewReader(bytes.NewReader(v.container), v.key)
+        if err != nil {
+            t.Fatalf("vector %d: %v", i, err)
+        }
+        want := Header{Variant: v.variant, TagSize: 16, ChunkSize: v.chunkSize}
+        copy(want.Prefix[:], v.prefix)
+        if h := r.Header(); h != want {
+            t.Errorf("vector %d: expected header %+v, got %+v", i, want, h)
+        }
+        if pt, err := io.ReadAll(r); err != nil || !bytes.Equal(pt, v.pt) {
+            t.Errorf("vector %d: got %x, %v", i, pt, err)
+        }
+    }
+}
+
+func TestRoundTrip(t *testing.T) {
+    key := bytes.Repeat([]byte{7}, ascon.KeySize80pq)
+    pt := bytes.Repeat([]byte("container"), 5000)
+    for _, v := range []ascon.Variant{ascon.Ascon128, ascon.Ascon128a, ascon.Ascon80pq, ascon.AsconAEAD128} {
+        key := key[:v.KeySize()]
+        var a, b bytes.Buffer
+        for _, buf := range []*bytes.Buffer{&a, &b} {
+            w, err := NewWriter(buf, v, key, 1000, stream.WithWorkers(2))
+            if err != nil {
+                t.Fatal(err)
+            }
+            if _, err := io.Copy(w, bytes.NewReader(pt)); err != nil {
+                t.Fatal(err)
+            }
+            if err := w.Close(); err != nil {
+                t.Fatal(err)
+            }
+        }
+        if bytes.Equal(a.Bytes()[:HeaderSize], b.Bytes()[:HeaderSize]) {
+            t.Errorf("%v: two containers have the same prefix", v)
+        }
+        if got, err := open(key, a.Bytes()); err != nil || !bytes.Equal(got, pt) {
+            t.Errorf("%v: got %d bytes, %v", v, len(got), err)
+        }
+    }
+}
+
+// TestHeaderAuthenticated checks that every bit of the header is
+// either rejected or authenticated.
+func TestHeaderAuthenticated(t *testing.T) {
+    v := specVectors(t)[1]
+    for i := 0; i < HeaderSize; i++ {
+        for bit := 0; bit < 8; bit++ {
+            c := append([]byte(nil), v.container...)
+            c[i] ^= 1 << bit
+            if pt, err := open(v.key, c); err == nil || len(pt) != 0 {
+                t.Errorf("byte %d, bit %d: got %x, %v", i, bit, pt, err)
+            }
+        }
+    }
+}
+
+func TestReaderErrors(t *testing.T) {
+    v := specVectors(t)[1]
+    modified := func(i int, b byte) []byte {
+        c := append([]byte(nil), v.container...)
+        c[i] = b
+        return c
+    }
+    for _, tc := range []struct {
+        name      string
+        key       []byte
+        container []byte
+        err       error
+    }{
+        {"empty", v.key, nil, ErrNotContainer},
+        {"short", v.key, v.container[:4], ErrNotContainer},
+        {"magic", v.key, modified(0, 'a'), ErrNotContainer},
+        {"version 0", v.key, modified(5, 0), ErrUnsupportedVersion},
+        {"version 2", v.key, modified(5, 2), ErrUnsupportedVersion},
+        {"stream version", v.key, modified(8, 2), stream.ErrUnsupportedVersion},
+        {"wrong key", make([]byte, 16), v.container, stream.ErrInvalidChunk},
+        {"other variant", v.key, modified(6, byte(ascon.Ascon128)), stream.ErrInvalidChunk},
+        {"truncated", v.key, v.container[:len(v.container)-1], stream.ErrInvalidChunk},
+        {"header only", v.key, v.container[:HeaderSize], stream.ErrInvalidChunk},
+    } {
+        if _, err := open(tc.key, tc.container); err != tc.err {
+            t.Errorf("%s: expected %v, got %v", tc.name, tc.err, err)
+        }
+    }
+    for name, c := range map[string][]byte{
+        "unknown variant": modified(6, 9),
+        "tag size":        modified(7, 8),
+        "80pq key size":   modified(6, byte(ascon.Ascon80pq)),
+    } {
+        if _, err := NewReader(bytes.NewReader(c), v.key); err == nil {
+            t.Errorf("%s: NewReader accepted the header", name)
+        }
+    }
+}
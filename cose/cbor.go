@@ -0,0 +1,170 @@
+package cose
+
+import (
+    "encoding/binary"
+)
+
+// The subset of CBOR (RFC 8949) that COSE_Encrypt0 needs: the
+// encoder writes the preferred, shortest encodings, and the
+// decoder accepts any well-formed item of definite length.
+
+const (
+    majorUint   = 0
+    majorNint   = 1
+    majorBytes  = 2
+    majorText   = 3
+    majorArray  = 4
+    majorMap    = 5
+    majorTag    = 6
+    majorSimple = 7
+
+    // simpleNull is the argument of the CBOR null, f6.
+    simpleNull = 22
+
+    // maxDepth bounds the nesting of skipped items.
+    maxDepth = 16
+)
+
+// appendHead appends the head of an item of type major with the
+// argument arg.
+func appendHead(b []byte, major byte, arg uint64) []byte {
+    major <<= 5
+    switch {
+    case arg < 24:
+        return append(b, major|byte(arg))
+    case arg <= 0xff:
+        return append(b, major|24, byte(arg))
+    case arg <= 0xffff:
+        return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+    case arg <= 0xffffffff:
+        return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+    default:
+        return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+    }
+}
+
+// appendInt appends the integer n.
+func appendInt(b []byte, n int64) []byte {
+    if n < 0 {
+        return appendHead(b, majorNint, uint64(-1-n))
+    }
+    return appendHead(b, majorUint, uint64(n))
+}
+
+// appendBytes appends the byte string s.
+func appendBytes(b, s []byte) []byte {
+    return append(appendHead(b, majorBytes, uint64(len(s))), s...)
+}
+
+// decoder reads CBOR items from b.
+type decoder struct {
+    b []byte
+}
+
+// head reads the head of the next item. For byte and text
+// strings, it checks that the whole string follows.
+func (d *decoder) head() (major byte, arg uint64, err error) {
+    if len(d.b) == 0 {
+        return 0, 0, ErrMalformed
+    }
+    major, info := d.b[0]>>5, d.b[0]&0x1f
+    d.b = d.b[1:]
+    switch {
+    case info < 24:
+        arg = uint64(info)
+    case info <= 27:
+        n := 1 << (info - 24)
+        if len(d.b) < n {
+            return 0, 0, ErrMalformed
+        }
+        for _, c := range d.b[:n] {
+            arg = arg<<8 | uint64(c)
+        }
+        d.b = d.b[n:]
+    default:
+        // Reserved, or of indefinite length.
+        return 0, 0, ErrMalformed
+    }
+    if (major == majorBytes || major == majorText) && arg > uint64(len(d.b)) {
+        return 0, 0, ErrMalformed
+    }
+    return major, arg, nil
+}
+
+// bytes reads a byte string.
+func (d *decoder) bytes() ([]byte, error) {
+    major, n, err := d.head()
+    if err != nil {
+        return nil, err
+    }
+    if major != majorBytes {
+        return nil, ErrMalformed
+    }
+    s := d.b[:n:n]
+    d.b = d.b[n:]
+    return s, nil
+}
+
+// text reads a text string. It does not check that it is
+// valid UTF-8.
+func (d *decoder) text() (string, error) {
+    major, n, err := d.head()
+    if err != nil {
+        return "", err
+    }
+    if major != majorText {
+        return "", ErrMalformed
+    }
+    s := string(d.b[:n])
+    d.b = d.b[n:]
+    return s, nil
+}
+
+// int reads an integer that fits an int64.
+func (d *decoder) int() (int64, error) {
+    major, n, err := d.head()
+    if err != nil {
+        return 0, err
+    }
+    if major > majorNint || n > 1<<63-1 {
+        return 0, ErrMalformed
+    }
+    if major == majorNint {
+        return -1 - int64(n), nil
+    }
+    return int64(n), nil
+}
+
+// skip reads and discards an item, nested no deeper than depth.
+func (d *decoder) skip(depth int) error {
+    if depth == 0 {
+        return ErrMalformed
+    }
+    major, n, err := d.head()
+    if err != nil {
+        return err
+    }
+    switch major {
+    case majorBytes, majorText:
+        d.b = d.b[n:]
+    case majorArray, majorMap:
+        if major == majorMap {
+            if n > uint64(len(d.b)) {
+                return ErrMalformed
+            }
+            n *= 2
+        }
+        // Every item takes at least a byte.
+        if n > uint64(len(d.b)) {
+            return ErrMalformed
+        }
+        for ; n > 0; n-- {
+            if err := d.skip(depth - 1); err != nil {
+                return err
+            }
+        }
+    case majorTag:
+        return d.skip(depth - 1)
+    }
+    return nil
+}
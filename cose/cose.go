@@ -0,0 +1,342 @@
+// Package cose implements COSE_Encrypt0 messages (RFC 9052)
+// encrypted with an ASCON AEAD.
+//
+// A COSE_Encrypt0 message is the CBOR array
+//
+//    [protected: bstr, unprotected: map, ciphertext: bstr / nil]
+//
+// in which protected holds the encoded map of the protected
+// header parameters, and ciphertext the ASCON ciphertext and
+// its 16-byte tag. The additional data of the AEAD is the
+// Enc_structure of RFC 9052, section 5.3:
+//
+//    ["Encrypt0", protected, external_aad]
+//
+// with protected exactly as it appears in the message, so that
+// changing a single byte of it makes the message fail to open.
+// The message has the algorithm identifier in its protected
+// header, and the 16-byte nonce in its unprotected header as the
+// IV parameter, with an optional key identifier.
+//
+// IANA has not assigned ASCON algorithm identifiers, so the
+// identifier is a parameter of NewCipher. PrivateAlgorithm, from
+// the private use range, will do between parties that agree on
+// it.
+//
+// References:
+//
+//    [rfc9052]: https://www.rfc-editor.org/rfc/rfc9052
+//    [rfc8949]: https://www.rfc-editor.org/rfc/rfc8949
+//
+package cose
+
+import (
+    "errors"
+    "crypto/cipher"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// PrivateAlgorithm is the first algorithm identifier of the
+// COSE private use range, for use until IANA assigns identifiers
+// to ASCON.
+const PrivateAlgorithm = -65537
+
+// tagEncrypt0 is the CBOR tag of COSE_Encrypt0_Tagged.
+const tagEncrypt0 = 16
+
+// The labels of the header parameters of RFC 9052, section 3.1.
+const (
+    labelAlg       = 1
+    labelCrit      = 2
+    labelKeyID     = 4
+    labelIV        = 5
+    labelPartialIV = 6
+)
+
+var (
+    // ErrMalformed is returned for a message, or a header, that
+    // is not well-formed CBOR or not a valid COSE_Encrypt0.
+    ErrMalformed = errors.New("cose: malformed message")
+
+    // ErrAlgorithm is returned by Open for a message with
+    // another algorithm identifier than the Cipher.
+    ErrAlgorithm = errors.New("cose: unexpected algorithm")
+
+    // ErrUnsupportedHeader is returned for a message with a
+    // crit or Partial IV header parameter, which this package
+    // does not implement.
+    ErrUnsupportedHeader = errors.New("cose: unsupported header parameter")
+
+    // errDetached is returned by OpenMessage for a message
+    // whose ciphertext is missing.
+    errDetached = errors.New("cose: detached ciphertext")
+)
+
+// Header holds the header parameters of a bucket that this
+// package understands. Zero values are absent: no COSE
+// algorithm has identifier 0.
+type Header struct {
+    Algorithm int64
+    KeyID     []byte
+    IV        []byte
+}
+
+// appendHeader appends the CBOR map of h, in the canonical
+// order of its labels.
+func appendHeader(b []byte, h Header) []byte {
+    n := 0
+    if h.Algorithm != 0 {
+        n++
+    }
+    if h.KeyID != nil {
+        n++
+    }
+    if h.IV != nil {
+        n++
+    }
+    b = appendHead(b, majorMap, uint64(n))
+    if h.Algorithm != 0 {
+        b = appendInt(appendInt(b, labelAlg), h.Algorithm)
+    }
+    if h.KeyID != nil {
+        b = appendBytes(appendInt(b, labelKeyID), h.KeyID)
+    }
+    if h.IV != nil {
+        b = appendBytes(appendInt(b, labelIV), h.IV)
+    }
+    return b
+}
+
+// header reads a header map. It skips the parameters it
+// does not know, but rejects duplicate labels.
+func (d *decoder) header() (Header, error) {
+    var h Header
+    major, n, err := d.head()
+    if err != nil {
+        return h, err
+    }
+    if major != majorMap || n > uint64(len(d.b)) {
+        return h, ErrMalformed
+    }
+    // Labels are integers or text strings.
+    type label struct {
+        isText bool
+        n      int64
+        text   string
+    }
+    seen := map[label]bool{}
+    for ; n > 0; n-- {
+        isText := len(d.b) > 0 && d.b[0]>>5 == majorText
+        l := label{isText: isText}
+        if isText {
+            l.text, err = d.text()
+        } else {
+            l.n, err = d.int()
+        }
+        if err != nil {
+            return h, err
+        }
+        if seen[l] {
+            return h, ErrMalformed
+        }
+        seen[l] = true
+
+        switch {
+        case isText:
+            err = d.skip(maxDepth)
+        case l.n == labelAlg:
+            h.Algorithm, err = d.int()
+            if err == nil && h.Algorithm == 0 {
+                err = ErrMalformed
+            }
+        case l.n == labelKeyID:
+            h.KeyID, err = d.bytes()
+        case l.n == labelIV:
+            h.IV, err = d.bytes()
+        case l.n == labelCrit, l.n == labelPartialIV:
+            err = ErrUnsupportedHeader
+        default:
+            err = d.skip(maxDepth)
+        }
+        if err != nil {
+            return h, err
+        }
+    }
+    return h, nil
+}
+
+// ParseHeader decodes the protected header bucket protected, as
+// found in Message.Protected.
+func ParseHeader(protected []byte) (Header, error) {
+    // An empty map may be sent as an empty string.
+    if len(protected) == 0 {
+        return Header{}, nil
+    }
+    d := &decoder{protected}
+    h, err := d.header()
+    if err != nil {
+        return Header{}, err
+    }
+    if len(d.b) > 0 {
+        return Header{}, ErrMalformed
+    }
+    return h, nil
+}
+
+// EncStructure returns the Enc_structure of a COSE_Encrypt0
+// message with the protected bucket protected, which is the
+// additional data of its AEAD.
+func EncStructure(protected, externalAAD []byte) []byte {
+    const context = "Encrypt0"
+    b := make([]byte, 0, 1+1+len(context)+9+len(protected)+9+len(externalAAD))
+    b = appendHead(b, majorArray, 3)
+    b = appendHead(b, majorText, uint64(len(context)))
+    b = append(b, context...)
+    b = appendBytes(b, protected)
+    return appendBytes(b, externalAAD)
+}
+
+// Message is a COSE_Encrypt0 message.
+type Message struct {
+    // Protected is the encoded protected bucket, kept as it was
+    // received since it is authenticated byte for byte.
+    Protected []byte
+
+    // Unprotected holds the unprotected header parameters.
+    Unprotected Header
+
+    // Ciphertext is the ciphertext and tag, or nil if it is
+    // detached, in which case it must be set before the
+    // message is opened.
+    Ciphertext []byte
+}
+
+// MarshalBinary encodes m as a COSE_Encrypt0_Tagged item. The
+// unprotected parameters that Header does not hold are lost.
+func (m *Message) MarshalBinary() ([]byte, error) {
+    b := appendHead(nil, majorTag, tagEncrypt0)
+    b = appendHead(b, majorArray, 3)
+    b = appendBytes(b, m.Protected)
+    b = appendHeader(b, m.Unprotected)
+    if m.Ciphertext == nil {
+        return appendHead(b, majorSimple, simpleNull), nil
+    }
+    return appendBytes(b, m.Ciphertext), nil
+}
+
+// UnmarshalBinary decodes a COSE_Encrypt0 message into m, with
+// or without its tag. It checks that the protected bucket is a
+// valid header.
+func (m *Message) UnmarshalBinary(data []byte) error {
+    d := &decoder{data}
+    if len(d.b) > 0 && d.b[0] == majorTag<<5|tagEncrypt0 {
+        d.b = d.b[1:]
+    }
+    major, n, err := d.head()
+    if err != nil {
+        return err
+    }
+    if major != majorArray || n != 3 {
+        return ErrMalformed
+    }
+    var msg Message
+    if msg.Protected, err = d.bytes(); err != nil {
+        return err
+    }
+    if _, err := ParseHeader(msg.Protected); err != nil {
+        return err
+    }
+    if msg.Unprotected, err = d.header(); err != nil {
+        return err
+    }
+    if len(d.b) > 0 && d.b[0] == majorSimple<<5|simpleNull {
+        d.b = d.b[1:]
+    } else if msg.Ciphertext, err = d.bytes(); err != nil {
+        return err
+    }
+    if len(d.b) > 0 {
+        return ErrMalformed
+    }
+    *m = msg
+    return nil
+}
+
+// Cipher seals and opens COSE_Encrypt0 messages with an ASCON
+// AEAD and its algorithm identifier.
+type Cipher struct {
+    alg  int64
+    aead cipher.AEAD
+}
+
+// NewCipher returns a Cipher for the variant v with key, which
+// identifies it by the COSE algorithm identifier alg.
+func NewCipher(alg int64, v ascon.Variant, key []byte) (*Cipher, error) {
+    if alg == 0 {
+        return nil, errors.New("cose: algorithm identifier 0 is reserved")
+    }
+    aead, err := ascon.New(key, ascon.WithVariant(v))
+    if err != nil {
+        return nil, err
+    }
+    return &Cipher{alg: alg, aead: aead}, nil
+}
+
+// Seal encrypts and authenticates plaintext and externalAAD, and
+// returns the tagged COSE_Encrypt0 message. The nonce must be
+// unique for the key. A non-nil keyID is sent as the kid
+// parameter, in the unprotected header, so it is not
+// authenticated.
+func (c *Cipher) Seal(nonce, keyID, plaintext, externalAAD []byte) ([]byte, error) {
+    if len(nonce) != ascon.NonceSize {
+        return nil, errors.New("cose: invalid nonce size")
+    }
+    m := &Message{
+        Protected:   appendHeader(nil, Header{Algorithm: c.alg}),
+        Unprotected: Header{KeyID: keyID, IV: nonce},
+    }
+    m.Ciphertext = c.aead.Seal(make([]byte, 0, len(plaintext)+c.aead.Overhead()),
+        nonce, plaintext, EncStructure(m.Protected, externalAAD))
+    return m.MarshalBinary()
+}
+
+// Open decodes the COSE_Encrypt0 message data and returns its
+// plaintext, as OpenMessage.
+func (c *Cipher) Open(data, externalAAD []byte) ([]byte, error) {
+    var m Message
+    if err := m.UnmarshalBinary(data); err != nil {
+        return nil, err
+    }
+    return c.OpenMessage(&m, externalAAD)
+}
+
+// OpenMessage authenticates and decrypts m and returns its
+// plaintext. It returns ErrAlgorithm if m does not have the
+// algorithm identifier of c in its protected header, and
+// ascon.ErrAuthentication if m or externalAAD were modified.
+func (c *Cipher) OpenMessage(m *Message, externalAAD []byte) ([]byte, error) {
+    p, err := ParseHeader(m.Protected)
+    if err != nil {
+        return nil, err
+    }
+    u := m.Unprotected
+    // A parameter must not be in both buckets.
+    if (p.Algorithm != 0 && u.Algorithm != 0) || (p.KeyID != nil && u.KeyID != nil) ||
+        (p.IV != nil && u.IV != nil) {
+        return nil, ErrMalformed
+    }
+    if p.Algorithm != c.alg {
+        return nil, ErrAlgorithm
+    }
+    iv := p.IV
+    if iv == nil {
+        iv = u.IV
+    }
+    if len(iv) != ascon.NonceSize {
+        return nil, ErrMalformed
+    }
+    if m.Ciphertext == nil {
+        return nil, errDetached
+    }
+    return c.aead.Open(nil, iv, m.Ciphertext, EncStructure(m.Protected, externalAAD))
+}
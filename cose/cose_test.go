@@ -0,0 +1,261 @@
+package cose
+
+import (
+    "bytes"
+    "encoding/hex"
+    "errors"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+var (
+    testKey   = fromHex("000102030405060708090a0b0c0d0e0f")
+    testNonce = fromHex("101112131415161718191a1b1c1d1e1f")
+    testKeyID = []byte("11")
+    testPT    = []byte("This is the content.")
+)
+
+// fromHex decodes s, ignoring spaces.
+func fromHex(s string) []byte {
+    b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+    if err != nil {
+        panic(err)
+    }
+    return b
+}
+
+// The fixture, encoded by hand from RFC 8949 and RFC 9052.
+const (
+    // {1: -65537}
+    fixtureProtected = "a1 01 3a00010000"
+
+    // ["Encrypt0", h'a1013a00010000', h'']
+    fixtureEncStructure = "83 68 456e637279707430 47 a1013a00010000 40"
+
+    // 16([h'a1013a00010000', {4: h'3131', 5: h'1011...1f'},
+    //     h'<36 bytes>'])
+    fixturePrefix = "d0 83 47 a1013a00010000 a2 04 42 3131 05 50 101112131415161718191a1b1c1d1e1f 58 24"
+)
+
+func testCipher(t *testing.T) *Cipher {
+    c, err := NewCipher(PrivateAlgorithm, ascon.AsconAEAD128, testKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return c
+}
+
+// fixture returns the fixture message and its ciphertext,
+// computed with the AEAD directly.
+func fixture(t *testing.T) (msg, ct []byte) {
+    aead, err := ascon.New(testKey, ascon.WithVariant(ascon.AsconAEAD128))
+    if err != nil {
+        t.Fatal(err)
+    }
+    ct = aead.Seal(nil, testNonce, testPT, fromHex(fixtureEncStructure))
+    return append(fromHex(fixturePrefix), ct...), ct
+}
+
+func TestFixture(t *testing.T) {
+    want, ct := fixture(t)
+    if got := EncStructure(fromHex(fixtureProtected), nil); !bytes.Equal(got, fromHex(fixtureEncStructure)) {
+        t.Fatalf("Enc_structure: expected %x, got %x", fromHex(fixtureEncStructure), got)
+    }
+
+    c := testCipher(t)
+    msg, err := c.Seal(testNonce, testKeyID, testPT, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(msg, want) {
+        t.Fatalf("expected %x, got %x", want, msg)
+    }
+    pt, err := c.Open(want, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(pt, testPT) {
+        t.Fatalf("expected %q, got %q", testPT, pt)
+    }
+
+    // Untagged messages also parse.
+    var m Message
+    if err := m.UnmarshalBinary(want[1:]); err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(m.Protected, fromHex(fixtureProtected)) || !bytes.Equal(m.Unprotected.KeyID, testKeyID) ||
+        !bytes.Equal(m.Unprotected.IV, testNonce) || !bytes.Equal(m.Ciphertext, ct) {
+        t.Fatalf("unexpected message %+v", m)
+    }
+    if h, err := ParseHeader(m.Protected); err != nil || h.Algorithm != PrivateAlgorithm {
+        t.Fatalf("expected algorithm %d, got %d, %v", PrivateAlgorithm, h.Algorithm, err)
+    }
+    if b, _ := m.MarshalBinary(); !bytes.Equal(b, want) {
+        t.Fatalf("expected %x, got %x", want, b)
+    }
+}
+
+func TestExternalAAD(t *testing.T) {
+    c := testCipher(t)
+    aad := []byte("device 42")
+    msg, err := c.Seal(testNonce, nil, testPT, aad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if pt, err := c.Open(msg, aad); err != nil || !bytes.Equal(pt, testPT) {
+        t.Fatalf("expected %q, got %q, %v", testPT, pt, err)
+    }
+    if _, err := c.Open(msg, []byte("device 43")); err != ascon.ErrAuthentication {
+        t.Fatalf("expected %v, got %v", ascon.ErrAuthentication, err)
+    }
+    if _, err := c.Open(msg, nil); err != ascon.ErrAuthentication {
+        t.Fatalf("expected %v, got %v", ascon.ErrAuthentication, err)
+    }
+}
+
+func TestModifiedProtected(t *testing.T) {
+    _, ct := fixture(t)
+    unprotected := Header{KeyID: testKeyID, IV: testNonce}
+    for _, tc := range []struct {
+        name      string
+        protected string
+        header    Header
+        err       error
+    }{
+        // The same algorithm, encoded in 8 bytes.
+        {"longer encoding", "a1 01 3b0000000000010000", unprotected, ascon.ErrAuthentication},
+        {"added parameter", "a2 01 3a00010000 20 00", unprotected, ascon.ErrAuthentication},
+        {"text label", "a2 01 3a00010000 61 78 00", unprotected, ascon.ErrAuthentication},
+        {"other algorithm", "a1 01 3a00010001", unprotected, ErrAlgorithm},
+        {"no algorithm", "a0", unprotected, ErrAlgorithm},
+        {"empty", "", unprotected, ErrAlgorithm},
+        {"unprotected algorithm", "", Header{Algorithm: PrivateAlgorithm, KeyID: testKeyID, IV: testNonce}, ErrAlgorithm},
+        {"algorithm in both", fixtureProtected, Header{Algorithm: PrivateAlgorithm, IV: testNonce}, ErrMalformed},
+        {"duplicate algorithm", "a2 01 3a00010000 01 3a00010000", unprotected, ErrMalformed},
+        {"crit", "a2 01 3a00010000 02 81 04", unprotected, ErrUnsupportedHeader},
+        {"truncated", "a1 01 3a000100", unprotected, ErrMalformed},
+        {"trailing data", "a1 01 3a00010000 00", unprotected, ErrMalformed},
+        {"indefinite map", "bf 01 3a00010000 ff", unprotected, ErrMalformed},
+        {"text algorithm", "a1 01 63 666f6f", unprotected, ErrMalformed},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            m := &Message{Protected: fromHex(tc.protected), Unprotected: tc.header, Ciphertext: ct}
+            if _, err := testCipher(t).OpenMessage(m, nil); !errors.Is(err, tc.err) {
+                t.Fatalf("expected %v, got %v", tc.err, err)
+            }
+            b, err := m.MarshalBinary()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if _, err := testCipher(t).Open(b, nil); err == nil {
+                t.Fatal("modified message opened")
+            }
+        })
+    }
+}
+
+func TestTampering(t *testing.T) {
+    msg, _ := fixture(t)
+    c := testCipher(t)
+    // The key identifier, at bytes 13 and 14, is not
+    // authenticated.
+    for i := range msg {
+        if i == 13 || i == 14 {
+            continue
+        }
+        bad := append([]byte(nil), msg...)
+        bad[i] ^= 1
+        if _, err := c.Open(bad, nil); err == nil {
+            t.Fatalf("byte %d: modified message opened", i)
+        }
+    }
+    for i := range msg {
+        if _, err := c.Open(msg[:i], nil); err == nil {
+            t.Fatalf("message truncated to %d bytes opened", i)
+        }
+    }
+    if _, err := c.Open(append(msg, 0), nil); err != ErrMalformed {
+        t.Fatalf("expected %v, got %v", ErrMalformed, err)
+    }
+
+    other, err := NewCipher(PrivateAlgorithm, ascon.Ascon128a, testKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := other.Open(msg, nil); err != ascon.ErrAuthentication {
+        t.Fatalf("expected %v, got %v", ascon.ErrAuthentication, err)
+    }
+}
+
+func TestDetached(t *testing.T) {
+    msg, ct := fixture(t)
+    var m Message
+    if err := m.UnmarshalBinary(msg); err != nil {
+        t.Fatal(err)
+    }
+    m.Ciphertext = nil
+    b, err := m.MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.HasSuffix(b, []byte{0xf6}) {
+        t.Fatalf("expected a null ciphertext, got %x", b)
+    }
+    var d Message
+    if err := d.UnmarshalBinary(b); err != nil {
+        t.Fatal(err)
+    }
+    c := testCipher(t)
+    if _, err := c.OpenMessage(&d, nil); err != errDetached {
+        t.Fatalf("expected %v, got %v", errDetached, err)
+    }
+    d.Ciphertext = ct
+    if pt, err := c.OpenMessage(&d, nil); err != nil || !bytes.Equal(pt, testPT) {
+        t.Fatalf("expected %q, got %q, %v", testPT, pt, err)
+    }
+}
+
+func TestCBOR(t *testing.T) {
+    for _, tc := range []struct {
+        n    int64
+        want string
+    }{
+        {0, "00"},
+        {23, "17"},
+        {24, "1818"},
+        {255, "18ff"},
+        {256, "190100"},
+        {65535, "19ffff"},
+        {65536, "1a00010000"},
+        {1 << 32, "1b0000000100000000"},
+        {-1, "20"},
+        {-24, "37"},
+        {-25, "3818"},
+        {-65537, "3a00010000"},
+        {-1 << 63, "3b7fffffffffffffff"},
+    } {
+        b := appendInt(nil, tc.n)
+        if hex.EncodeToString(b) != tc.want {
+            t.Fatalf("%d: expected %s, got %x", tc.n, tc.want, b)
+        }
+        n, err := (&decoder{b}).int()
+        if err != nil || n != tc.n {
+            t.Fatalf("%s: expected %d, got %d, %v", tc.want, tc.n, n, err)
+        }
+    }
+
+    // Integers beyond int64, and skipped items nested too
+    // deeply.
+    if _, err := (&decoder{fromHex("1bffffffffffffffff")}).int(); err != ErrMalformed {
+        t.Fatalf("expected %v, got %v", ErrMalformed, err)
+    }
+    deep := append(bytes.Repeat([]byte{0x81}, maxDepth), 0)
+    if err := (&decoder{deep}).skip(maxDepth); err != ErrMalformed {
+        t.Fatalf("expected %v, got %v", ErrMalformed, err)
+    }
+    if err := (&decoder{deep[1:]}).skip(maxDepth); err != nil {
+        t.Fatal(err)
+    }
+}
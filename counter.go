@@ -0,0 +1,102 @@
+package ascon
+
+import (
+    "errors"
+    "math"
+    "sync/atomic"
+    "encoding/binary"
+)
+
+// NoncePrefixSize is the size in bytes of a NonceCounter prefix.
+const NoncePrefixSize = NonceSize - 8
+
+// ErrNonceExhausted is returned by NonceCounter when every
+// counter value has been used.
+var ErrNonceExhausted = errors.New("ascon: nonce counter exhausted")
+
+// NonceCounter generates unique counter nonces. It is safe for
+// concurrent use.
+//
+// Each nonce is the 8-byte prefix followed by a big-endian
+// 64-bit counter. The counter starts at zero and never wraps:
+// once 2^64-1 nonces have been returned, Next fails with
+// ErrNonceExhausted.
+//
+// To survive restarts, persist the counter with MarshalBinary
+// before handing out the nonces it covers, or persist it
+// periodically and, after restoring it with UnmarshalBinary,
+// call Skip with at least the number of nonces that may have
+// been used since the last save.
+type NonceCounter struct {
+    prefix [NoncePrefixSize]byte
+    next   atomic.Uint64
+}
+
+// NewNonceCounter returns a NonceCounter with the given
+// NoncePrefixSize byte prefix. Counters that share a key must
+// use distinct prefixes.
+func NewNonceCounter(prefix []byte) (*NonceCounter, error) {
+    if len(prefix) != NoncePrefixSize {
+        return nil, errors.New("ascon: bad nonce prefix length")
+    }
+    c := new(NonceCounter)
+    copy(c.prefix[:], prefix)
+    return c, nil
+}
+
+// Next returns the next nonce.
+func (c *NonceCounter) Next() ([NonceSize]byte, error) {
+    var nonce [NonceSize]byte
+    for {
+        n := c.next.Load()
+        if n == math.MaxUint64 {
+            return nonce, ErrNonceExhausted
+        }
+        if c.next.CompareAndSwap(n, n+1) {
+            copy(nonce[:], c.prefix[:])
+            binary.BigEndian.PutUint64(nonce[NoncePrefixSize:], n)
+            return nonce, nil
+        }
+    }
+}
+
+// Skip advances the counter by n without returning the skipped
+// nonces. It returns ErrNonceExhausted, and leaves the counter
+// exhausted, if fewer than n values remain.
+func (c *NonceCounter) Skip(n uint64) error {
+    for {
+        cur := c.next.Load()
+        next := cur + n
+        var err error
+        if next < cur || next == math.MaxUint64 {
+            next = math.MaxUint64
+            err = ErrNonceExhausted
+        }
+        if c.next.CompareAndSwap(cur, next) {
+            return err
+        }
+    }
+}
+
+// MarshalBinary encodes the prefix and the next counter value.
+func (c *NonceCounter) MarshalBinary() ([]byte, error) {
+    var payload [NonceSize]byte
+    copy(payload[:], c.prefix[:])
+    binary.BigEndian.PutUint64(payload[NoncePrefixSize:], c.next.Load())
+    return appendEnvelope(make([]byte, 0, envelopeSize+NonceSize), idNonceCounter, payload[:]), nil
+}
+
+// UnmarshalBinary restores a NonceCounter saved by
+// MarshalBinary.
+func (c *NonceCounter) UnmarshalBinary(data []byte) error {
+    payload, err := openEnvelope(data, idNonceCounter)
+    if err != nil {
+        return err
+    }
+    if len(payload) != NonceSize {
+        return ErrInvalidState
+    }
+    copy(c.prefix[:], payload)
+    c.next.Store(binary.BigEndian.Uint64(payload[NoncePrefixSize:]))
+    return nil
+}
@@ -0,0 +1,116 @@
+package ascon
+
+import (
+    "encoding/binary"
+    "math"
+    "sync"
+    "testing"
+)
+
+func TestNonceCounter(t *testing.T) {
+    prefix := []byte("prefix!!")
+    c, err := NewNonceCounter(prefix)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := uint64(0); i < 3; i++ {
+        n, err := c.Next()
+        if err != nil {
+            t.Fatal(err)
+        }
+        if string(n[:NoncePrefixSize]) != string(prefix) || binary.BigEndian.Uint64(n[NoncePrefixSize:]) != i {
+            t.Fatalf("#%d: got %x", i, n)
+        }
+    }
+
+    data, err := c.MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+    var r NonceCounter
+    if err := r.UnmarshalBinary(data); err != nil {
+        t.Fatal(err)
+    }
+    if err := r.Skip(100); err != nil {
+        t.Fatal(err)
+    }
+    n, _ := r.Next()
+    if string(n[:NoncePrefixSize]) != string(prefix) || binary.BigEndian.Uint64(n[NoncePrefixSize:]) != 103 {
+        t.Fatalf("got %x", n)
+    }
+
+    if _, err := NewNonceCounter(prefix[:7]); err == nil {
+        t.Fatal("expected an error")
+    }
+    if err := r.UnmarshalBinary(data[:15]); err == nil {
+        t.Fatal("expected an error")
+    }
+    // The bare prefix and counter, without an envelope.
+    if err := r.UnmarshalBinary(make([]byte, NonceSize)); err == nil {
+        t.Fatal("expected an error for a state without an envelope")
+    }
+}
+
+func TestNonceCounterExhausted(t *testing.T) {
+    c, _ := NewNonceCounter(make([]byte, NoncePrefixSize))
+    if err := c.Skip(math.MaxUint64 - 2); err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 2; i++ {
+        if _, err := c.Next(); err != nil {
+            t.Fatalf("#%d: %v", i, err)
+        }
+    }
+    for i := 0; i < 2; i++ {
+        if _, err := c.Next(); err != ErrNonceExhausted {
+            t.Fatalf("expected %v, got %v", ErrNonceExhausted, err)
+        }
+    }
+
+    c, _ = NewNonceCounter(make([]byte, NoncePrefixSize))
+    c.Skip(10)
+    if err := c.Skip(math.MaxUint64 - 5); err != ErrNonceExhausted {
+        t.Fatalf("expected %v, got %v", ErrNonceExhausted, err)
+    }
+    if _, err := c.Next(); err != ErrNonceExhausted {
+        t.Fatalf("expected %v, got %v", ErrNonceExhausted, err)
+    }
+}
+
+func TestNonceCounterConcurrent(t *testing.T) {
+    c, _ := NewNonceCounter(make([]byte, NoncePrefixSize))
+    const goroutines, per = 16, 1000
+
+    var (
+        wg   sync.WaitGroup
+        mu   sync.Mutex
+        seen = make(map[[NonceSize]byte]bool)
+    )
+    for g := 0; g < goroutines; g++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            local := make([][NonceSize]byte, 0, per)
+            for i := 0; i < per; i++ {
+                n, err := c.Next()
+                if err != nil {
+                    t.Error(err)
+                    return
+                }
+                local = append(local, n)
+            }
+            mu.Lock()
+            defer mu.Unlock()
+            for _, n := range local {
+                if seen[n] {
+                    t.Errorf("duplicate nonce %x", n)
+                }
+                seen[n] = true
+            }
+        }()
+    }
+    wg.Wait()
+    if len(seen) != goroutines*per {
+        t.Fatalf("expected %d nonces, got %d", goroutines*per, len(seen))
+    }
+}
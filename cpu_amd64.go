@@ -0,0 +1,30 @@
+//go:build amd64 && gc && !purego && !ascon_interleaved
+
+package ascon
+
+// CPU features are detected once, when the package is
+// initialized, and only select between whole functions such as
+// p12x4, never inside a round.
+
+// useAVX2 reports whether the CPU and the operating system
+// support AVX2, for the four-way permutation.
+var useAVX2 = hasAVX2()
+
+func hasAVX2() bool {
+    _, _, ecx, _ := cpuid(1, 0)
+    // The operating system must have enabled the AVX state
+    // with OSXSAVE and save the SSE and AVX registers.
+    const osxsave, avx = 1 << 27, 1 << 28
+    if ecx&(osxsave|avx) != osxsave|avx {
+        return false
+    }
+    if eax, _ := xgetbv(); eax&6 != 6 {
+        return false
+    }
+    _, ebx, _, _ := cpuid(7, 0)
+    return ebx&(1<<5) != 0
+}
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
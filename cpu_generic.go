@@ -0,0 +1,9 @@
+//go:build !amd64 || !gc || purego || ascon_interleaved
+
+package ascon
+
+// The assembly for arm64 only uses ARMv8.0 instructions, so
+// there is nothing to detect there or on the other targets.
+// The riscv64 assembly uses the Zbb rotations if GORISCV64
+// allows them at build time.
+const useAVX2 = false
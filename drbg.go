@@ -0,0 +1,185 @@
+package ascon
+
+import (
+    "io"
+    "sync"
+    "errors"
+)
+
+// drbgLabel is the Ascon-CXOF128 customization string of the
+// DRBG.
+const drbgLabel = "go-ascon DRBG v1"
+
+const (
+    // MinDRBGSeedSize is the minimum size in bytes of the seed
+    // of NewDRBG.
+    MinDRBGSeedSize = KeySize
+    // DefaultReseedInterval is the number of output bytes
+    // after which a DRBG created by NewDRBGFromEntropy reseeds
+    // when the interval passed to it is zero.
+    DefaultReseedInterval = 1 << 20
+
+    drbgKeySize = 32
+    // drbgMaxRequest is the most output generated under one
+    // key; longer reads ratchet in between.
+    drbgMaxRequest = 1 << 16
+)
+
+// Domain separation bytes of the DRBG operations.
+const (
+    drbgInstantiate byte = iota
+    drbgReseed
+    drbgGenerate
+)
+
+// DRBG is a deterministic random bit generator built on
+// Ascon-CXOF128. It implements io.Reader.
+//
+// The DRBG keeps a 256-bit key k. It is set from the seed as
+//
+//    k = CXOF(0x00 || seed)
+//
+// reseeding with entropy e sets
+//
+//    k = CXOF(0x01 || k || e)
+//
+// and producing output reads k' || out from
+//
+//    CXOF(0x02 || k)
+//
+// and replaces k with k', where CXOF is Ascon-CXOF128
+// customized with "go-ascon DRBG v1" and k' is its first 32
+// bytes of output. The key is replaced after every Read, and
+// every 64 KiB within long reads, so a later compromise of the
+// state does not reveal earlier output.
+//
+// A DRBG is safe for concurrent use: calls to Read and Reseed
+// are serialized with a mutex. Concurrent readers receive
+// distinct, non-overlapping output, but which reader gets
+// which part of the deterministic stream depends on
+// scheduling.
+type DRBG struct {
+    mu  sync.Mutex
+    x   *XOF
+    k   [drbgKeySize]byte
+    buf [1 + drbgKeySize]byte
+
+    // entropy, if non-nil, is read to reseed after interval
+    // output bytes; count is the output since the last reseed.
+    entropy  io.Reader
+    interval int
+    count    int
+}
+
+var _ io.Reader = (*DRBG)(nil)
+
+// NewDRBG returns a DRBG seeded with seed, which must be at
+// least MinDRBGSeedSize bytes of secret, uniformly random data.
+//
+// The output is a deterministic function of the seed and of
+// the data passed to Reseed, which is useful for testing and
+// for deriving a reproducible stream from a key. It never
+// reseeds by itself; use NewDRBGFromEntropy for that.
+func NewDRBG(seed []byte) (*DRBG, error) {
+    if len(seed) < MinDRBGSeedSize {
+        return nil, errors.New("ascon: DRBG seed too short")
+    }
+    x, err := NewCXOF128([]byte(drbgLabel))
+    if err != nil {
+        return nil, err
+    }
+    d := &DRBG{x: x}
+    x.Write([]byte{drbgInstantiate})
+    x.Write(seed)
+    x.Read(d.k[:])
+    x.Reset()
+    return d, nil
+}
+
+// NewDRBGFromEntropy returns a DRBG seeded with 32 bytes read
+// from entropy, such as crypto/rand.Reader. It reads 32 more
+// bytes from entropy to reseed after every interval bytes of
+// output, or every DefaultReseedInterval bytes if interval is
+// zero, splitting reads that cross the interval. An error
+// from entropy is returned by the Read that needed to reseed.
+func NewDRBGFromEntropy(entropy io.Reader, interval int) (*DRBG, error) {
+    if interval < 0 {
+        return nil, errors.New("ascon: negative reseed interval")
+    }
+    if interval == 0 {
+        interval = DefaultReseedInterval
+    }
+    var seed [drbgKeySize]byte
+    if _, err := io.ReadFull(entropy, seed[:]); err != nil {
+        return nil, err
+    }
+    d, err := NewDRBG(seed[:])
+    clear(seed[:])
+    if err != nil {
+        return nil, err
+    }
+    d.entropy = entropy
+    d.interval = interval
+    return d, nil
+}
+
+// Reseed mixes entropy into the state. The entropy does not
+// have to be uniformly random, and any length is accepted.
+func (d *DRBG) Reseed(entropy []byte) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.reseed(entropy)
+}
+
+func (d *DRBG) reseed(entropy []byte) {
+    d.x.Write([]byte{drbgReseed})
+    d.x.Write(d.k[:])
+    d.x.Write(entropy)
+    d.x.Read(d.k[:])
+    d.x.Reset()
+    d.count = 0
+}
+
+// Read fills p with pseudorandom bytes. It only returns an
+// error if an automatic reseed fails, in which case n reports
+// the bytes written before the reseed was due; a later Read
+// tries to reseed again.
+func (d *DRBG) Read(p []byte) (n int, err error) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    for len(p) > 0 {
+        if d.entropy != nil && d.count >= d.interval {
+            var e [drbgKeySize]byte
+            if _, err := io.ReadFull(d.entropy, e[:]); err != nil {
+                return n, err
+            }
+            d.reseed(e[:])
+            clear(e[:])
+        }
+
+        m := len(p)
+        if m > drbgMaxRequest {
+            m = drbgMaxRequest
+        }
+        if d.entropy != nil && m > d.interval-d.count {
+            m = d.interval - d.count
+        }
+        d.generate(p[:m])
+        d.count += m
+        n += m
+        p = p[m:]
+    }
+    return n, nil
+}
+
+// generate ratchets the key and fills out.
+func (d *DRBG) generate(out []byte) {
+    d.buf[0] = drbgGenerate
+    copy(d.buf[1:], d.k[:])
+    d.x.Write(d.buf[:])
+    d.x.Read(d.k[:])
+    d.x.Read(out)
+    d.x.Reset()
+    clear(d.buf[:])
+}
@@ -0,0 +1,180 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "sync"
+    "testing"
+    "testing/iotest"
+    "encoding/hex"
+)
+
+// seq returns the bytes from, from+1, ..., to-1.
+func seq(from, to int) []byte {
+    b := make([]byte, 0, to-from)
+    for i := from; i < to; i++ {
+        b = append(b, byte(i))
+    }
+    return b
+}
+
+func TestDRBGVectors(t *testing.T) {
+    d, err := NewDRBG(seq(0, 16))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, want := range []string{
+        "ef70f8e3b7f8c5c2c43623d5a390d8bb5ec44cbfb1da4e7c612bbdedbe12fae3",
+        "c8f52ec446",
+        "db27d1dacaa973c5611686a6c8d362ef31d6cef002ecffe324e9dcdf9945fc382362b263679c35a6",
+        "", // Reseed
+        "848d666d756f4f1e7765742ec4f044e424870a473fa8bb0bbb73677aaae7a24f",
+    } {
+        if want == "" {
+            d.Reseed([]byte("entropy"))
+            continue
+        }
+        w, _ := hex.DecodeString(want)
+        got := make([]byte, len(w))
+        if n, err := d.Read(got); n != len(got) || err != nil {
+            t.Fatalf("#%d: Read returned %d, %v", i, n, err)
+        }
+        if !bytes.Equal(got, w) {
+            t.Fatalf("#%d: expected %x, got %x", i, w, got)
+        }
+    }
+
+    // Reads longer than 64 KiB ratchet in between.
+    d, err = NewDRBG(seq(0, 32))
+    if err != nil {
+        t.Fatal(err)
+    }
+    long := make([]byte, 1<<16+7)
+    d.Read(long)
+    next := make([]byte, 16)
+    d.Read(next)
+    for _, tc := range []struct {
+        got  []byte
+        want string
+    }{
+        {long[len(long)-16:], "ced41ba128130b694c2923e667ab3f26"},
+        {next, "f85721409c395a2846f417ecc5beda40"},
+    } {
+        if hex.EncodeToString(tc.got) != tc.want {
+            t.Fatalf("expected %s, got %x", tc.want, tc.got)
+        }
+    }
+
+    if _, err := NewDRBG(make([]byte, MinDRBGSeedSize-1)); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+// countingReader returns entropy bytes 0, 1, 2, ... and
+// counts the calls.
+type countingReader struct {
+    b     byte
+    calls int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+    r.calls++
+    for i := range p {
+        p[i] = r.b
+        r.b++
+    }
+    return len(p), nil
+}
+
+func TestDRBGFromEntropy(t *testing.T) {
+    r := &countingReader{}
+    d, err := NewDRBGFromEntropy(r, 64)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want, err := NewDRBG(seq(0, 32))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    got, exp := make([]byte, 64), make([]byte, 64)
+    for i := 0; i < 4; i++ {
+        if i > 0 {
+            want.Reseed(seq(32*i, 32*i+32))
+        }
+        d.Read(got)
+        want.Read(exp)
+        if !bytes.Equal(got, exp) {
+            t.Fatalf("#%d: expected %x, got %x", i, exp, got)
+        }
+    }
+    if r.calls != 4 {
+        t.Fatalf("expected 4 entropy reads, got %d", r.calls)
+    }
+
+    d, err = NewDRBGFromEntropy(iotest.TimeoutReader(&countingReader{}), 64)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if n, err := d.Read(make([]byte, 100)); n != 64 || !errors.Is(err, iotest.ErrTimeout) {
+        t.Fatalf("expected 64, %v; got %d, %v", iotest.ErrTimeout, n, err)
+    }
+    if _, err := NewDRBGFromEntropy(iotest.ErrReader(iotest.ErrTimeout), 0); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := NewDRBGFromEntropy(&countingReader{}, -1); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+// TestDRBGConcurrent checks that concurrent readers receive the
+// same outputs, in some order, as sequential reads.
+func TestDRBGConcurrent(t *testing.T) {
+    const n = 64
+    d, err := NewDRBG(seq(0, 16))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var wg sync.WaitGroup
+    out := make([][]byte, n)
+    for i := range out {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            out[i] = make([]byte, 24)
+            d.Read(out[i])
+        }(i)
+    }
+    wg.Wait()
+
+    want := make(map[string]bool)
+    s, err := NewDRBG(seq(0, 16))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < n; i++ {
+        b := make([]byte, 24)
+        s.Read(b)
+        want[string(b)] = true
+    }
+    for i, b := range out {
+        if !want[string(b)] {
+            t.Fatalf("#%d: unexpected output %x", i, b)
+        }
+        delete(want, string(b))
+    }
+}
+
+func BenchmarkDRBG(b *testing.B) {
+    d, err := NewDRBG(make([]byte, 32))
+    if err != nil {
+        b.Fatal(err)
+    }
+    buf := make([]byte, 1024)
+    b.SetBytes(int64(len(buf)))
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        d.Read(buf)
+    }
+}
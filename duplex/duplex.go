@@ -0,0 +1,192 @@
+// Package duplex implements a generic duplex sponge over the
+// ASCON permutation.
+//
+// A Duplex absorbs input into, and squeezes output from, the
+// first Rate bytes of the state, applying the b-round
+// permutation between blocks. Initialization and finalization
+// steps act on the whole state through State and use the
+// a-round permutation through Permute. This is enough to
+// rebuild the ASCON AEADs, hashes and MACs, and to build new
+// constructions, without managing block boundaries by hand.
+//
+// The permutation between two blocks is applied lazily, when
+// the next block is first touched. A block that has been
+// completed, by filling it or by Pad, is only permuted by the
+// next Absorb, Squeeze, Encrypt, Decrypt or Separate call, so
+// a final block can still be followed by Permute or by direct
+// changes to the state, as when ASCON adds the key before
+// finalization.
+//
+// Nothing in this package is an authenticated cipher by
+// itself. It is a building block; new constructions built on
+// it need their own analysis.
+package duplex
+
+import (
+    "errors"
+
+    "github.com/pedroalbanese/go-ascon/permutation"
+)
+
+// MaxRate is the maximum rate in bytes, which leaves at
+// least one 64-bit word of capacity.
+const MaxRate = permutation.Size - 8
+
+// Duplex is a duplex sponge over the ASCON permutation. The
+// zero value is not usable; use New or NewLE.
+type Duplex struct {
+    s    permutation.State
+    rate int
+    a, b int
+    le   bool
+    // pos is the offset into the current block. pos == rate
+    // means the block is complete and the b-round permutation
+    // is pending.
+    pos int
+}
+
+// New returns a duplex with a rate of rate bytes, aRounds
+// rounds for Permute and bRounds rounds between blocks, and
+// an all-zero state. Bytes are mapped to the state words in
+// big-endian order and Pad appends 0x80, as in ASCON 1.2.
+//
+// rate must be in the range [1, MaxRate] and both round
+// counts in the range [1, 12].
+func New(rate, aRounds, bRounds int) (*Duplex, error) {
+    if rate < 1 || rate > MaxRate {
+        return nil, errors.New("duplex: invalid rate")
+    }
+    if aRounds < 1 || aRounds > 12 || bRounds < 1 || bRounds > 12 {
+        return nil, errors.New("duplex: invalid number of rounds")
+    }
+    return &Duplex{rate: rate, a: aRounds, b: bRounds}, nil
+}
+
+// NewLE is like New, but maps bytes to the state words in
+// little-endian order and Pad appends 0x01, as in NIST SP
+// 800-232.
+func NewLE(rate, aRounds, bRounds int) (*Duplex, error) {
+    d, err := New(rate, aRounds, bRounds)
+    if err != nil {
+        return nil, err
+    }
+    d.le = true
+    return d, nil
+}
+
+// Rate returns the rate in bytes.
+func (d *Duplex) Rate() int {
+    return d.rate
+}
+
+// State returns the state for direct initialization and
+// finalization steps, such as loading an IV, key and nonce or
+// adding the key to the capacity.
+func (d *Duplex) State() *permutation.State {
+    return &d.s
+}
+
+// Permute applies the a-round permutation to the whole state
+// and starts a new block. It replaces a pending b-round
+// permutation rather than adding to it.
+func (d *Duplex) Permute() {
+    d.s.Permute(d.a)
+    d.pos = 0
+}
+
+// Absorb XORs p into the rate, block by block.
+func (d *Duplex) Absorb(p []byte) {
+    for _, c := range p {
+        d.next()
+        d.xor(d.pos, c)
+        d.pos++
+    }
+}
+
+// Squeeze returns the next n bytes of the rate, block by
+// block.
+func (d *Duplex) Squeeze(n int) []byte {
+    out := make([]byte, n)
+    for i := range out {
+        d.next()
+        out[i] = d.byte(d.pos)
+        d.pos++
+    }
+    return out
+}
+
+// Encrypt XORs src into the rate and writes the result, which
+// is the new content of the rate, to dst. dst and src may
+// overlap exactly or not at all. It panics if dst is shorter
+// than src.
+func (d *Duplex) Encrypt(dst, src []byte) {
+    _ = dst[:len(src)]
+    for i, c := range src {
+        d.next()
+        d.xor(d.pos, c)
+        dst[i] = d.byte(d.pos)
+        d.pos++
+    }
+}
+
+// Decrypt inverts Encrypt: it writes src XORed with the rate to
+// dst and overwrites the rate with src. dst and src may
+// overlap exactly or not at all. It panics if dst is shorter
+// than src.
+func (d *Duplex) Decrypt(dst, src []byte) {
+    _ = dst[:len(src)]
+    for i, c := range src {
+        d.next()
+        k := d.byte(d.pos)
+        d.xor(d.pos, k^c)
+        dst[i] = k ^ c
+        d.pos++
+    }
+}
+
+// Pad appends the padding byte at the current position and
+// completes the block. If the current block is already
+// complete, the padding goes into a new block.
+func (d *Duplex) Pad() {
+    d.next()
+    if d.le {
+        d.xor(d.pos, 0x01)
+    } else {
+        d.xor(d.pos, 0x80)
+    }
+    d.pos = d.rate
+}
+
+// Separate applies a pending b-round permutation and then
+// XORs ds into the last state word x4, which is always part of
+// the capacity. ASCON uses it to separate the associated data
+// from the message.
+func (d *Duplex) Separate(ds uint64) {
+    d.next()
+    d.s[4] ^= ds
+}
+
+// next applies a pending b-round permutation.
+func (d *Duplex) next() {
+    if d.pos == d.rate {
+        d.s.Permute(d.b)
+        d.pos = 0
+    }
+}
+
+// shift returns the bit offset of byte i of the state within
+// its word.
+func (d *Duplex) shift(i int) uint {
+    if d.le {
+        return uint(8 * (i % 8))
+    }
+    return uint(56 - 8*(i%8))
+}
+
+func (d *Duplex) byte(i int) byte {
+    return byte(d.s[i/8] >> d.shift(i))
+}
+
+func (d *Duplex) xor(i int, c byte) {
+    d.s[i/8] ^= uint64(c) << d.shift(i)
+}
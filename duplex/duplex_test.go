@@ -0,0 +1,219 @@
+package duplex
+
+import (
+    "bytes"
+    "testing"
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/permutation"
+)
+
+// aead rebuilds the ASCON AEADs on top of a Duplex.
+type aead struct {
+    iv   uint64
+    rate int
+    a, b int
+    le   bool
+}
+
+var (
+    ascon128     = aead{iv: 0x80400c0600000000, rate: 8, a: 12, b: 6}
+    ascon128a    = aead{iv: 0x80800c0800000000, rate: 16, a: 12, b: 8}
+    asconAEAD128 = aead{iv: 0x00001000808c0001, rate: 16, a: 12, b: 8, le: true}
+)
+
+func (a aead) word(b []byte) uint64 {
+    if a.le {
+        return binary.LittleEndian.Uint64(b)
+    }
+    return binary.BigEndian.Uint64(b)
+}
+
+func (a aead) put(b []byte, x uint64) {
+    if a.le {
+        binary.LittleEndian.PutUint64(b, x)
+    } else {
+        binary.BigEndian.PutUint64(b, x)
+    }
+}
+
+// init loads the IV, key and nonce, applies the a-round
+// permutation, adds the key and absorbs the associated data.
+func (a aead) init(t *testing.T, key, nonce, ad []byte) (*Duplex, uint64, uint64) {
+    var d *Duplex
+    var err error
+    if a.le {
+        d, err = NewLE(a.rate, a.a, a.b)
+    } else {
+        d, err = New(a.rate, a.a, a.b)
+    }
+    if err != nil {
+        t.Fatal(err)
+    }
+    k0, k1 := a.word(key), a.word(key[8:])
+    s := d.State()
+    *s = permutation.State{a.iv, k0, k1, a.word(nonce), a.word(nonce[8:])}
+    d.Permute()
+    s[3] ^= k0
+    s[4] ^= k1
+
+    if len(ad) > 0 {
+        d.Absorb(ad)
+        d.Pad()
+    }
+    if a.le {
+        d.Separate(1 << 63)
+    } else {
+        d.Separate(1)
+    }
+    return d, k0, k1
+}
+
+// tag pads the message, adds the key after the rate, applies
+// the a-round permutation and returns the tag.
+func (a aead) tag(d *Duplex, k0, k1 uint64) []byte {
+    d.Pad()
+    s := d.State()
+    s[a.rate/8] ^= k0
+    s[a.rate/8+1] ^= k1
+    d.Permute()
+    tag := make([]byte, 16)
+    a.put(tag, s[3]^k0)
+    a.put(tag[8:], s[4]^k1)
+    return tag
+}
+
+func (a aead) seal(t *testing.T, key, nonce, pt, ad []byte) []byte {
+    d, k0, k1 := a.init(t, key, nonce, ad)
+    ct := make([]byte, len(pt))
+    d.Encrypt(ct, pt)
+    return append(ct, a.tag(d, k0, k1)...)
+}
+
+func (a aead) open(t *testing.T, key, nonce, ct, ad []byte) ([]byte, bool) {
+    d, k0, k1 := a.init(t, key, nonce, ad)
+    n := len(ct) - 16
+    pt := make([]byte, n)
+    d.Decrypt(pt, ct[:n])
+    return pt, bytes.Equal(a.tag(d, k0, k1), ct[n:])
+}
+
+func seq(n int) []byte {
+    b := make([]byte, n)
+    for i := range b {
+        b[i] = byte(i)
+    }
+    return b
+}
+
+func TestAEAD(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        a    aead
+        fn   func([]byte) (cipher.AEAD, error)
+    }{
+        {"Ascon-128", ascon128, ascon.New128},
+        {"Ascon-128a", ascon128a, ascon.New128a},
+        {"Ascon-AEAD128", asconAEAD128, ascon.NewAEAD128},
+    } {
+        key, nonce := seq(16), seq(16)
+        ref, err := tc.fn(key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        for _, n := range []int{0, 1, 7, 8, 9, 15, 16, 17, 33, 64} {
+            for _, m := range []int{0, 1, 8, 16, 21} {
+                pt, ad := seq(n), seq(m)
+                want := ref.Seal(nil, nonce, pt, ad)
+                got := tc.a.seal(t, key, nonce, pt, ad)
+                if !bytes.Equal(got, want) {
+                    t.Fatalf("%s (%d, %d): expected %#x, got %#x", tc.name, n, m, want, got)
+                }
+                out, ok := tc.a.open(t, key, nonce, got, ad)
+                if !ok || !bytes.Equal(out, pt) {
+                    t.Fatalf("%s (%d, %d): Open failed", tc.name, n, m)
+                }
+                got[0] ^= 1
+                if _, ok := tc.a.open(t, key, nonce, got, ad); ok {
+                    t.Fatalf("%s (%d, %d): forgery accepted", tc.name, n, m)
+                }
+            }
+        }
+    }
+}
+
+// TestHash rebuilds Ascon-Hash: absorb, pad, permute, squeeze.
+func TestHash(t *testing.T) {
+    for _, n := range []int{0, 1, 7, 8, 9, 64, 100} {
+        msg := seq(n)
+        d, err := New(8, 12, 12)
+        if err != nil {
+            t.Fatal(err)
+        }
+        d.State()[0] = 0x00400c0000000100
+        d.Permute()
+        d.Absorb(msg)
+        d.Pad()
+        d.Permute()
+        got := d.Squeeze(ascon.HashSize)
+        want := ascon.Sum256(msg)
+        if !bytes.Equal(got, want[:]) {
+            t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+        }
+    }
+}
+
+func TestChunks(t *testing.T) {
+    msg := seq(50)
+    var want []byte
+    for _, step := range []int{50, 1, 3, 8, 13} {
+        d, err := New(16, 12, 8)
+        if err != nil {
+            t.Fatal(err)
+        }
+        for p := msg; len(p) > 0; {
+            n := step
+            if n > len(p) {
+                n = len(p)
+            }
+            d.Absorb(p[:n])
+            p = p[n:]
+        }
+        d.Pad()
+        var got []byte
+        for len(got) < 50 {
+            n := step
+            if n > 50-len(got) {
+                n = 50 - len(got)
+            }
+            got = append(got, d.Squeeze(n)...)
+        }
+        if want == nil {
+            want = got
+        } else if !bytes.Equal(got, want) {
+            t.Fatalf("step %d: expected %#x, got %#x", step, want, got)
+        }
+    }
+}
+
+func TestNew(t *testing.T) {
+    for _, tc := range []struct{ rate, a, b int }{
+        {0, 12, 6}, {MaxRate + 1, 12, 6}, {8, 0, 6}, {8, 13, 6}, {8, 12, 0}, {8, 12, 13},
+    } {
+        if _, err := New(tc.rate, tc.a, tc.b); err == nil {
+            t.Fatalf("%v: expected an error", tc)
+        }
+        if _, err := NewLE(tc.rate, tc.a, tc.b); err == nil {
+            t.Fatalf("%v: expected an error", tc)
+        }
+    }
+    d, err := New(MaxRate, 1, 1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if d.Rate() != MaxRate {
+        t.Fatalf("expected rate %d, got %d", MaxRate, d.Rate())
+    }
+}
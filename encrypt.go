@@ -0,0 +1,94 @@
+package ascon
+
+import (
+    "io"
+    "crypto/cipher"
+    "crypto/rand"
+)
+
+// Format versions of Encrypt. Every version fixes the AEAD,
+// so a new algorithm or layout needs a new version byte while
+// Decrypt keeps accepting the old ones.
+const (
+    // encryptV1 is Ascon-AEAD128 with a random 16-byte nonce.
+    encryptV1 byte = 0x01
+)
+
+// encryptHeaderSize is the size of the version byte and nonce
+// that precede the ciphertext.
+const encryptHeaderSize = 1 + NonceSize
+
+// randReader is the source of nonces for Encrypt, replaced in
+// tests.
+var randReader io.Reader = rand.Reader
+
+// Encrypt encrypts and authenticates plaintext and additional
+// data with a 16-byte key and a random nonce, and returns
+//
+//    version || nonce || ciphertext || authenticator
+//
+// The version byte identifies the format, currently
+// Ascon-AEAD128 with a 16-byte nonce read from crypto/rand.
+// The version byte and nonce are authenticated along with the
+// additional data. Encrypt returns an error if the key is the
+// wrong size or no randomness is available.
+//
+// Random nonces make it safe to encrypt up to about 2^48
+// messages with the same key.
+func Encrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+    aead, err := NewAEAD128(key)
+    if err != nil {
+        return nil, err
+    }
+
+    out := make([]byte, encryptHeaderSize, encryptHeaderSize+len(plaintext)+aead.Overhead())
+    out[0] = encryptV1
+    if _, err := io.ReadFull(randReader, out[1:]); err != nil {
+        return nil, err
+    }
+    return newEncryptSession(aead, out, additionalData).Encrypt(out, plaintext), nil
+}
+
+// Decrypt opens a message produced by Encrypt with the same key
+// and additional data.
+//
+// Any modification of the message, including of its version
+// byte or length, is reported with the same error as an
+// authentication failure.
+func Decrypt(key, blob, additionalData []byte) ([]byte, error) {
+    if len(key) != KeySize {
+        // Return the constructor's error for a bad key.
+        _, err := NewAEAD128(key)
+        return nil, err
+    }
+    if len(blob) < encryptHeaderSize {
+        return nil, errOpenShort
+    }
+
+    var aead cipher.AEAD
+    switch blob[0] {
+    case encryptV1:
+        aead, _ = NewAEAD128(key)
+    default:
+        return nil, ErrAuthentication
+    }
+    if len(blob) < encryptHeaderSize+aead.Overhead() {
+        return nil, errOpenShort
+    }
+
+    s := newEncryptSession(aead, blob[:encryptHeaderSize], additionalData)
+    return s.Decrypt(nil, blob[encryptHeaderSize:])
+}
+
+// newEncryptSession starts a Session that authenticates the
+// fixed-size header, which holds the version byte and nonce,
+// followed by the caller's additional data.
+func newEncryptSession(aead cipher.AEAD, header, additionalData []byte) *Session {
+    s, err := NewSession(aead, header[1:])
+    if err != nil {
+        panic(err)
+    }
+    s.WriteAdditionalData(header)
+    s.WriteAdditionalData(additionalData)
+    return s
+}
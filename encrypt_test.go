@@ -0,0 +1,81 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "errors"
+    "io"
+    "testing"
+    "testing/iotest"
+)
+
+func TestEncrypt(t *testing.T) {
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    for i := range key {
+        key[i] = byte(i)
+        nonce[i] = byte(16 + i)
+    }
+
+    defer func(r io.Reader) { randReader = r }(randReader)
+    randReader = bytes.NewReader(nonce)
+
+    blob, err := Encrypt(key, []byte("hello"), []byte("ad"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    want, _ := hex.DecodeString("01101112131415161718191a1b1c1d1e1f" +
+        "dd8a557201be678f5504a84222e3828c3a4f845255")
+    if !bytes.Equal(blob, want) {
+        t.Fatalf("expected %x, got %x", want, blob)
+    }
+
+    pt, err := Decrypt(key, blob, []byte("ad"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(pt) != "hello" {
+        t.Fatalf("expected %q, got %q", "hello", pt)
+    }
+
+    for i := range blob {
+        bad := append([]byte(nil), blob...)
+        bad[i] ^= 1
+        if _, err := Decrypt(key, bad, []byte("ad")); !errors.Is(err, ErrAuthentication) {
+            t.Fatalf("byte %d: expected %v, got %v", i, ErrAuthentication, err)
+        }
+    }
+    for n := 0; n < len(blob); n++ {
+        if _, err := Decrypt(key, blob[:n], []byte("ad")); !errors.Is(err, ErrAuthentication) {
+            t.Fatalf("%d bytes: expected %v, got %v", n, ErrAuthentication, err)
+        }
+    }
+    if _, err := Decrypt(key, blob, []byte("AD")); !errors.Is(err, ErrAuthentication) {
+        t.Fatalf("expected %v, got %v", ErrAuthentication, err)
+    }
+    if _, err := Decrypt(key[:8], blob, nil); err == nil || errors.Is(err, ErrAuthentication) {
+        t.Fatalf("expected a key error, got %v", err)
+    }
+}
+
+func TestEncryptRandomness(t *testing.T) {
+    key := make([]byte, KeySize)
+    a, err := Encrypt(key, nil, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, err := Encrypt(key, nil, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if bytes.Equal(a, b) {
+        t.Fatal("Encrypt reused a nonce")
+    }
+
+    defer func(r io.Reader) { randReader = r }(randReader)
+    errRand := errors.New("no entropy")
+    randReader = iotest.ErrReader(errRand)
+    if _, err := Encrypt(key, []byte("x"), nil); err != errRand {
+        t.Fatalf("expected %v, got %v", errRand, err)
+    }
+}
@@ -0,0 +1,91 @@
+package ascon
+
+import (
+    "errors"
+    "encoding/binary"
+)
+
+// Every MarshalBinary method of the module wraps its state in
+// the same envelope:
+//
+//    magic    "ascon", 5 bytes
+//    version  1 byte, envelopeVersion
+//    type     1 byte, the id of what was marshaled
+//    length   4 bytes, big-endian, the length of the payload
+//    payload  the state itself
+//    checksum 8 bytes, the start of the Ascon-Hash digest of
+//             everything before it
+//
+// so that a state fed to the wrong UnmarshalBinary, or
+// corrupted in storage, is rejected instead of silently
+// producing wrong output. Version 1, the format of earlier
+// releases, had no length or checksum and was only used by the
+// hash functions; their UnmarshalBinary still accepts it.
+
+const (
+    envelopeMagic   = "ascon"
+    envelopeVersion = 2
+    // envelopeHeaderSize is the size of the magic, version,
+    // type and length.
+    envelopeHeaderSize   = len(envelopeMagic) + 1 + 1 + 4
+    envelopeChecksumSize = 8
+    // envelopeSize is the size of an envelope besides its
+    // payload.
+    envelopeSize = envelopeHeaderSize + envelopeChecksumSize
+)
+
+var (
+    // ErrInvalidState is returned by the UnmarshalBinary
+    // methods for a state that is truncated, has trailing data,
+    // was corrupted, or was not marshaled by this module.
+    ErrInvalidState = errors.New("ascon: invalid marshaled state")
+
+    // ErrStateVersion is returned by the UnmarshalBinary
+    // methods for a state in a format version they do not know,
+    // such as one marshaled by a later release.
+    ErrStateVersion = errors.New("ascon: unsupported marshaled state version")
+
+    // ErrStateType is returned by the UnmarshalBinary methods
+    // for a valid state of another type, such as an Ascon-Hash
+    // state passed to an XOF.
+    ErrStateType = errors.New("ascon: marshaled state of another type")
+)
+
+// appendEnvelope appends to b the envelope of payload, a state
+// of type typ.
+func appendEnvelope(b []byte, typ byte, payload []byte) []byte {
+    start := len(b)
+    b = append(b, envelopeMagic...)
+    b = append(b, envelopeVersion, typ)
+    b = binary.BigEndian.AppendUint32(b, uint32(len(payload)))
+    b = append(b, payload...)
+    sum := Sum256(b[start:])
+    return append(b, sum[:envelopeChecksumSize]...)
+}
+
+// openEnvelope checks the envelope b of a state of type typ and
+// returns its payload.
+func openEnvelope(b []byte, typ byte) ([]byte, error) {
+    if len(b) < len(envelopeMagic)+1 || string(b[:len(envelopeMagic)]) != envelopeMagic {
+        return nil, ErrInvalidState
+    }
+    if b[len(envelopeMagic)] != envelopeVersion {
+        return nil, ErrStateVersion
+    }
+    if len(b) < envelopeSize {
+        return nil, ErrInvalidState
+    }
+    body := b[:len(b)-envelopeChecksumSize]
+    sum := Sum256(body)
+    if string(sum[:envelopeChecksumSize]) != string(b[len(body):]) {
+        return nil, ErrInvalidState
+    }
+    if b[len(envelopeMagic)+1] != typ {
+        return nil, ErrStateType
+    }
+    payload := body[envelopeHeaderSize:]
+    if binary.BigEndian.Uint32(body[envelopeHeaderSize-4:]) != uint32(len(payload)) {
+        return nil, ErrInvalidState
+    }
+    return payload, nil
+}
@@ -0,0 +1,234 @@
+package ascon
+
+import (
+    "bytes"
+    "crypto/cipher"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon/ascontest"
+)
+
+// fuzzAEADs are the AEADs under fuzzing, selected by the first
+// argument of each target, with the vectors that seed it.
+var fuzzAEADs = []struct {
+    fn      func([]byte) (cipher.AEAD, error)
+    keySize int
+    variant ascontest.Variant
+}{
+    {New128, KeySize, ascontest.Ascon128},
+    {New128a, KeySize, ascontest.Ascon128a},
+    {New80pq, KeySize80pq, ascontest.Ascon80pq},
+    {NewAEAD128, KeySize, ascontest.AsconAEAD128},
+}
+
+// addVectorSeeds seeds f with every known answer test, passing
+// each vector to add along with the index of its AEAD.
+func addVectorSeeds(f *testing.F, add func(variant uint8, v vector)) {
+    for i, tc := range fuzzAEADs {
+        vecs, err := ascontest.ParseKAT(ascontest.KAT(tc.variant))
+        if err != nil {
+            f.Fatal(err)
+        }
+        for _, v := range vecs {
+            add(uint8(i), vector{key: v.Key, nonce: v.Nonce, pt: v.PT, ad: v.AD, ct: v.CT})
+        }
+    }
+}
+
+// fuzzAEAD returns the AEAD selected by variant, keyed with key
+// padded or truncated to its key size, and the nonce likewise
+// fitted to NonceSize.
+func fuzzAEAD(t *testing.T, variant uint8, key, nonce []byte) (*AEAD, []byte) {
+    tc := fuzzAEADs[int(variant)%len(fuzzAEADs)]
+    k := make([]byte, tc.keySize)
+    copy(k, key)
+    c, err := tc.fn(k)
+    if err != nil {
+        t.Fatal(err)
+    }
+    n := make([]byte, NonceSize)
+    copy(n, nonce)
+    return c.(*AEAD), n
+}
+
+// FuzzSealOpen checks that every way of sealing a message
+// agrees with Seal, and that Open recovers the plaintext.
+func FuzzSealOpen(f *testing.F) {
+    addVectorSeeds(f, func(variant uint8, v vector) {
+        f.Add(variant, v.key, v.nonce, v.ad, v.pt)
+    })
+    f.Fuzz(func(t *testing.T, variant uint8, key, nonce, ad, pt []byte) {
+        a, nonce := fuzzAEAD(t, variant, key, nonce)
+        ct := a.Seal(nil, nonce, pt, ad)
+        if len(ct) != len(pt)+a.Overhead() {
+            t.Fatalf("expected %d bytes, got %d", len(pt)+a.Overhead(), len(ct))
+        }
+
+        got, err := a.Open(nil, nonce, ct, ad)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, pt) {
+            t.Fatalf("expected %#x, got %#x", pt, got)
+        }
+
+        // In place.
+        buf := append([]byte(nil), pt...)
+        if got := a.Seal(buf[:0], nonce, buf, ad); !bytes.Equal(got, ct) {
+            t.Fatalf("in place: expected %#x, got %#x", ct, got)
+        }
+        buf = append([]byte(nil), ct...)
+        if got, err := a.Open(buf[:0], nonce, buf, ad); err != nil || !bytes.Equal(got, pt) {
+            t.Fatalf("in place: expected %#x, got %#x, %v", pt, got, err)
+        }
+
+        c, tag := a.SealDetached(nil, nil, nonce, pt, ad)
+        if !bytes.Equal(append(c, tag...), ct) {
+            t.Fatalf("SealDetached: expected %#x, got %#x %#x", ct, c, tag)
+        }
+        out := make([]byte, len(ct))
+        if n, err := a.SealTo(out, nonce, pt, ad); err != nil || !bytes.Equal(out[:n], ct) {
+            t.Fatalf("SealTo: expected %#x, got %#x, %v", ct, out[:n], err)
+        }
+        if n, err := a.OpenTo(out, nonce, ct, ad); err != nil || !bytes.Equal(out[:n], pt) {
+            t.Fatalf("OpenTo: expected %#x, got %#x, %v", pt, out[:n], err)
+        }
+
+        s, err := NewSession(a, nonce)
+        if err != nil {
+            t.Fatal(err)
+        }
+        s.WriteAdditionalData(ad)
+        if got := s.Encrypt(nil, pt); !bytes.Equal(got, ct) {
+            t.Fatalf("Session: expected %#x, got %#x", ct, got)
+        }
+    })
+}
+
+// FuzzOpenMutated checks that Open rejects any change to the
+// ciphertext, the nonce or the additional data, without
+// panicking and without releasing any plaintext.
+func FuzzOpenMutated(f *testing.F) {
+    addVectorSeeds(f, func(variant uint8, v vector) {
+        f.Add(variant, v.key, v.nonce, v.ad, v.pt, uint8(0), uint16(len(v.pt)), uint8(1))
+    })
+    f.Fuzz(func(t *testing.T, variant uint8, key, nonce, ad, pt []byte, target uint8, pos uint16, mask uint8) {
+        a, nonce := fuzzAEAD(t, variant, key, nonce)
+        ct := a.Seal(nil, nonce, pt, ad)
+        if mask == 0 {
+            mask = 1
+        }
+
+        flip := func(b []byte) []byte {
+            b = append([]byte(nil), b...)
+            b[int(pos)%len(b)] ^= mask
+            return b
+        }
+        badNonce, badCT, badAD := nonce, ct, ad
+        switch target % 6 {
+        case 0:
+            badCT = flip(ct)
+        case 1:
+            badNonce = flip(nonce)
+        case 2:
+            if len(ad) == 0 {
+                badAD = []byte{mask}
+            } else {
+                badAD = flip(ad)
+            }
+        case 3:
+            badCT = ct[:int(pos)%len(ct)]
+        case 4:
+            badCT = append(ct[:len(ct):len(ct)], mask)
+        case 5:
+            badNonce = nonce[:int(pos)%NonceSize]
+        }
+
+        dst := append([]byte(nil), badCT...)
+        got, err := a.Open(dst[:0], badNonce, dst, badAD)
+        if err == nil || got != nil {
+            t.Fatalf("%d: accepted a modified message", target%6)
+        }
+        // In-place decryption leaves zeros or the ciphertext,
+        // never plaintext.
+        if n := len(badCT) - a.Overhead(); n > 0 {
+            if !bytes.Equal(dst[:n], make([]byte, n)) && !bytes.Equal(dst[:n], badCT[:n]) {
+                t.Fatalf("%d: dst holds %#x", target%6, dst[:n])
+            }
+        }
+    })
+}
+
+// FuzzOverlap places the input and output of Seal and Open at
+// arbitrary offsets of one buffer. An exact overlap must work,
+// like disjoint buffers, and any other overlap must panic
+// before writing anything. Nothing outside the output may
+// change.
+func FuzzOverlap(f *testing.F) {
+    f.Add(uint8(1), seq(0, 37), uint8(0), uint8(0))
+    f.Add(uint8(0), seq(0, 8), uint8(3), uint8(5))
+    f.Add(uint8(3), seq(0, 16), uint8(40), uint8(0))
+    // Open used to accept an output that overlaps only the
+    // authenticator, and then failed on the tag it overwrote.
+    f.Add(uint8(0), []byte("0"), uint8(3), uint8(5))
+    f.Fuzz(func(t *testing.T, variant uint8, pt []byte, in, out uint8) {
+        a, nonce := fuzzAEAD(t, variant, nil, nil)
+        ad := []byte("ad")
+        want := a.Seal(nil, nonce, pt, ad)
+
+        check := func(name string, input, expected []byte, fn func(dst, input []byte) []byte) {
+            size := len(input) + int(in) + len(expected) + int(out) + 16
+            buf := make([]byte, size)
+            for i := range buf {
+                buf[i] = byte(i*7 + 1)
+            }
+            copy(buf[in:], input)
+            input = buf[in : int(in)+len(input)]
+            dst := buf[out:out]
+            orig := append([]byte(nil), buf...)
+
+            lo, hi := int(in), int(in)+len(input)
+            olo, ohi := int(out), int(out)+len(expected)
+            inexact := len(input) > 0 && len(expected) > 0 && lo != olo && lo < ohi && olo < hi
+
+            var got []byte
+            panicked := func() (p bool) {
+                defer func() {
+                    if recover() != nil {
+                        p = true
+                    }
+                }()
+                got = fn(dst, input)
+                return false
+            }()
+            if panicked != inexact {
+                t.Fatalf("%s: in %d, out %d: panicked %v, expected %v", name, in, out, panicked, inexact)
+            }
+            if panicked {
+                if !bytes.Equal(buf, orig) {
+                    t.Fatalf("%s: in %d, out %d: buffer changed before the panic", name, in, out)
+                }
+                return
+            }
+            if !bytes.Equal(got, expected) {
+                t.Fatalf("%s: in %d, out %d: expected %#x, got %#x", name, in, out, expected, got)
+            }
+            for i := range buf {
+                if (i < olo || i >= ohi) && buf[i] != orig[i] {
+                    t.Fatalf("%s: in %d, out %d: byte %d outside the output changed", name, in, out, i)
+                }
+            }
+        }
+
+        check("Seal", pt, want, func(dst, input []byte) []byte {
+            return a.Seal(dst, nonce, input, ad)
+        })
+        check("Open", want, pt, func(dst, input []byte) []byte {
+            got, err := a.Open(dst, nonce, input, ad)
+            if err != nil {
+                t.Fatal(err)
+            }
+            return got
+        })
+    })
+}
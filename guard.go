@@ -0,0 +1,149 @@
+package ascon
+
+import (
+    "sync"
+    "crypto/cipher"
+    "hash/maphash"
+)
+
+// Parameters of the ReuseGuard Bloom filter, per unit of
+// capacity.
+const (
+    guardFilterBits   = 128 // filter size in bits
+    guardFilterLoad   = 4   // nonces absorbed before it is cleared
+    guardFilterHashes = 22  // bit positions set per nonce
+)
+
+// ReuseGuard is a cipher.AEAD that detects nonce reuse in Seal.
+// It is meant for tests and canary deployments, not as a
+// substitute for generating unique nonces.
+//
+// The most recent nonces, up to the capacity given to
+// NewReuseGuard, are kept in an exact set. When the set is full
+// its nonces are moved to a Bloom filter of 128 bits per unit
+// of capacity, using 22 bit positions per nonce, and the set
+// starts over. The filter is cleared once it holds 4*capacity
+// nonces, so a reuse is only detected among roughly the last
+// 5*capacity nonces. A match in the filter may be a false
+// positive, with a probability below 10^-6 for each Seal.
+//
+// Memory use is bounded by capacity: about 16 bytes per unit
+// for the filter, plus the exact set.
+//
+// A ReuseGuard is safe for concurrent use if the underlying
+// AEAD is. Open is passed through unchanged.
+type ReuseGuard struct {
+    cipher.AEAD
+
+    // OnReuse, if set, is called when Seal sees a nonce that
+    // was already used, with certain reporting whether the
+    // nonce was found in the exact set rather than the filter.
+    // Seal then proceeds. If OnReuse is nil, Seal panics.
+    //
+    // OnReuse must be set before the ReuseGuard is used.
+    OnReuse func(nonce []byte, certain bool)
+
+    mu       sync.Mutex
+    capacity int
+    exact    map[string]struct{}
+    filter   []uint64
+    filtered int
+    seed     maphash.Seed
+}
+
+// NewReuseGuard wraps aead in a ReuseGuard that remembers about
+// the last 5*capacity nonces. capacity must be positive.
+func NewReuseGuard(aead cipher.AEAD, capacity int) *ReuseGuard {
+    if capacity <= 0 {
+        panic("ascon: ReuseGuard capacity must be positive")
+    }
+    return &ReuseGuard{
+        AEAD:     aead,
+        capacity: capacity,
+        exact:    make(map[string]struct{}, capacity),
+        filter:   make([]uint64, capacity*guardFilterBits/64),
+        seed:     maphash.MakeSeed(),
+    }
+}
+
+// Seal records nonce, reports it if it was seen before, and
+// seals the message with the underlying AEAD.
+func (g *ReuseGuard) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if reused, certain := g.record(nonce); reused {
+        if g.OnReuse == nil {
+            panic("ascon: nonce reused")
+        }
+        g.OnReuse(nonce, certain)
+    }
+    return g.AEAD.Seal(dst, nonce, plaintext, additionalData)
+}
+
+// record adds nonce to the exact set and reports whether it was
+// already present in the set or the filter.
+func (g *ReuseGuard) record(nonce []byte) (reused, certain bool) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+
+    if _, ok := g.exact[string(nonce)]; ok {
+        return true, true
+    }
+    reused = g.filterContains(nonce)
+
+    g.exact[string(nonce)] = struct{}{}
+    if len(g.exact) >= g.capacity {
+        if g.filtered+len(g.exact) > guardFilterLoad*g.capacity {
+            clear(g.filter)
+            g.filtered = 0
+        }
+        for n := range g.exact {
+            g.filterAdd(n)
+        }
+        g.filtered += len(g.exact)
+        clear(g.exact)
+    }
+    return reused, false
+}
+
+// filterPositions calls fn with each filter bit position of n.
+//
+// Positions follow triple hashing, a + i*b + i^2*c. With plain
+// double hashing two nonces whose a and b collide modulo the
+// filter size share every position, which dominates the false
+// positive rate of small filters.
+func (g *ReuseGuard) filterPositions(n string, fn func(word int, bit uint64) bool) {
+    var h maphash.Hash
+    h.SetSeed(g.seed)
+    h.WriteString(n)
+    a := h.Sum64()
+    h.WriteByte(1)
+    b := h.Sum64() | 1
+    h.WriteByte(2)
+    c := h.Sum64()
+
+    m := uint64(len(g.filter) * 64)
+    for i := uint64(0); i < guardFilterHashes; i++ {
+        p := (a + i*b + i*i*c) % m
+        if !fn(int(p/64), 1<<(p%64)) {
+            return
+        }
+    }
+}
+
+func (g *ReuseGuard) filterAdd(n string) {
+    g.filterPositions(n, func(word int, bit uint64) bool {
+        g.filter[word] |= bit
+        return true
+    })
+}
+
+func (g *ReuseGuard) filterContains(nonce []byte) bool {
+    if g.filtered == 0 {
+        return false
+    }
+    found := true
+    g.filterPositions(string(nonce), func(word int, bit uint64) bool {
+        found = g.filter[word]&bit != 0
+        return found
+    })
+    return found
+}
@@ -0,0 +1,110 @@
+package ascon
+
+import (
+    "crypto/cipher"
+    "encoding/binary"
+    "testing"
+)
+
+func guardNonce(i uint64) []byte {
+    n := make([]byte, NonceSize)
+    binary.BigEndian.PutUint64(n[8:], i)
+    return n
+}
+
+func TestReuseGuard(t *testing.T) {
+    aead, _ := New128a(make([]byte, KeySize))
+    g := NewReuseGuard(aead, 16)
+
+    type report struct {
+        nonce   uint64
+        certain bool
+    }
+    var reports []report
+    g.OnReuse = func(nonce []byte, certain bool) {
+        reports = append(reports, report{binary.BigEndian.Uint64(nonce[8:]), certain})
+    }
+
+    for i := uint64(0); i < 40; i++ {
+        g.Seal(nil, guardNonce(i), nil, nil)
+    }
+    if len(reports) != 0 {
+        t.Fatalf("unexpected reports %v", reports)
+    }
+
+    // 39 is still in the exact set, 3 has moved to the filter.
+    g.Seal(nil, guardNonce(39), nil, nil)
+    g.Seal(nil, guardNonce(3), nil, nil)
+    if len(reports) != 2 || reports[0] != (report{39, true}) || reports[1] != (report{3, false}) {
+        t.Fatalf("unexpected reports %v", reports)
+    }
+
+    // The output is unchanged.
+    want := aead.Seal(nil, guardNonce(1000), []byte("pt"), nil)
+    got := g.Seal(nil, guardNonce(1000), []byte("pt"), nil)
+    if string(got) != string(want) {
+        t.Fatalf("expected %x, got %x", want, got)
+    }
+    if pt, err := g.Open(nil, guardNonce(1000), got, nil); err != nil || string(pt) != "pt" {
+        t.Fatalf("Open: %q, %v", pt, err)
+    }
+}
+
+func TestReuseGuardPanics(t *testing.T) {
+    aead, _ := New128a(make([]byte, KeySize))
+    g := NewReuseGuard(aead, 4)
+    g.Seal(nil, guardNonce(1), nil, nil)
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expected a panic")
+        }
+    }()
+    g.Seal(nil, guardNonce(1), nil, nil)
+}
+
+func TestReuseGuardBounded(t *testing.T) {
+    aead, _ := New128a(make([]byte, KeySize))
+    g := NewReuseGuard(aead, 64)
+    var falsePositives int
+    g.OnReuse = func([]byte, bool) { falsePositives++ }
+
+    for i := uint64(0); i < 100000; i++ {
+        g.Seal(nil, guardNonce(i), nil, nil)
+        if len(g.exact) >= g.capacity || g.filtered > guardFilterLoad*g.capacity {
+            t.Fatalf("#%d: %d exact, %d filtered", i, len(g.exact), g.filtered)
+        }
+    }
+    if falsePositives != 0 {
+        t.Fatalf("%d false positives", falsePositives)
+    }
+    if len(g.filter) != 64*guardFilterBits/64 {
+        t.Fatalf("filter grew to %d words", len(g.filter))
+    }
+}
+
+func BenchmarkReuseGuard(b *testing.B) {
+    for _, guarded := range []bool{false, true} {
+        name := "plain"
+        if guarded {
+            name = "guarded"
+        }
+        b.Run(name, func(b *testing.B) {
+            var aead cipher.AEAD
+            aead, _ = New128a(make([]byte, KeySize))
+            if guarded {
+                aead = NewReuseGuard(aead, 1<<12)
+            }
+            nonce := make([]byte, NonceSize)
+            pt := make([]byte, 64)
+            b.SetBytes(int64(len(pt)))
+            var out []byte
+
+            b.ResetTimer()
+            for i := 0; i < b.N; i++ {
+                binary.BigEndian.PutUint64(nonce[8:], uint64(i))
+                out = aead.Seal(out[:0], nonce, pt, nil)
+            }
+        })
+    }
+}
@@ -0,0 +1,325 @@
+package ascon
+
+import (
+    "hash"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/byteorder"
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+const (
+    ivHash  uint64 = 0x00400c0000000100 // Ascon-Hash
+    ivHasha uint64 = 0x00400c0400000100 // Ascon-Hasha
+
+    ivHash256 uint64 = 0x0000080100cc0002 // Ascon-Hash256 (SP 800-232)
+)
+
+const (
+    // HashSize is the size in bytes of an Ascon-Hash digest.
+    HashSize = 32
+    // HashBlockSize is the rate in bytes of Ascon-Hash.
+    HashBlockSize = 8
+    // HMACBlockSize is the block size in bytes that the hashes
+    // report to crypto/hmac. It does not depend on the rate.
+    HMACBlockSize = 64
+)
+
+// hashInit is the state of Ascon-Hash after the initial
+// permutation, which only depends on the IV.
+var (
+    hashInit  = spongeInit(ivHash)
+    hashaInit = spongeInit(ivHasha)
+
+    hash256Init = spongeInit(ivHash256)
+)
+
+func spongeInit(iv uint64) state {
+    s := state{0: iv}
+    p12(&s)
+    return s
+}
+
+// sponge is the unkeyed sponge shared by the hash functions.
+//
+// Input is absorbed into x0 at a 64-bit rate with b rounds
+// between blocks, and p12 is applied before squeezing. The
+// NIST SP 800-232 functions load and store x0 in little-endian
+// byte order and pad with 0x01 instead of 0x80.
+type sponge struct {
+    // id identifies the function in marshaled states.
+    id   byte
+    s    state
+    init state
+    buf  [HashBlockSize]byte
+    n    int
+    b    int
+    le   bool
+}
+
+func (d *sponge) reset() {
+    d.s = d.init
+    d.n = 0
+}
+
+// permute applies the b-round permutation.
+func (d *sponge) permute() {
+    if d.b == 8 {
+        p8(&d.s)
+    } else {
+        p12(&d.s)
+    }
+}
+
+func (d *sponge) load(b []byte) uint64 {
+    if d.le {
+        return le64(b)
+    }
+    return be64(b)
+}
+
+func (d *sponge) store(b []byte) {
+    if d.le {
+        putle64(b, d.s[0])
+    } else {
+        putbe64(b, d.s[0])
+    }
+}
+
+func (d *sponge) write(p []byte) {
+    if d.n > 0 {
+        n := copy(d.buf[d.n:], p)
+        d.n += n
+        p = p[n:]
+        if d.n < HashBlockSize {
+            return
+        }
+        d.s[0] ^= d.load(d.buf[:])
+        d.permute()
+        d.n = 0
+    }
+    for len(p) >= HashBlockSize {
+        d.s[0] ^= d.load(p)
+        d.permute()
+        p = p[HashBlockSize:]
+    }
+    d.n = copy(d.buf[:], p)
+}
+
+// finish pads and absorbs the final block, leaving the state
+// ready for squeezing.
+func (d *sponge) finish() {
+    if d.le {
+        d.s[0] ^= byteorder.LE64n(d.buf[:d.n]) ^ byteorder.PadLE(d.n)
+    } else {
+        d.s[0] ^= byteorder.BE64n(d.buf[:d.n]) ^ byteorder.Pad(d.n)
+    }
+    p12(&d.s)
+}
+
+// squeeze writes len(dst) bytes of output, which must be a
+// multiple of HashBlockSize.
+func (d *sponge) squeeze(dst []byte) {
+    for {
+        d.store(dst)
+        dst = dst[HashBlockSize:]
+        if len(dst) == 0 {
+            return
+        }
+        d.permute()
+    }
+}
+
+type digest struct {
+    sponge
+    size int
+}
+
+var _ hash.Hash = (*digest)(nil)
+
+// NewHash returns a new hash.Hash computing the Ascon-Hash
+// checksum.
+//
+// Ascon-Hash is the hash function of the ASCON 1.2
+// submission. It does not interoperate with Ascon-Hash256
+// from NIST SP 800-232.
+//
+// The hashes returned by NewHash, NewHasha and NewHash256 also
+// implement encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler to save and restore their state,
+// and a Clone() hash.Hash method that returns an independent
+// copy, for example to hash several messages that share a
+// prefix.
+//
+// BlockSize reports HMACBlockSize, the block size of SHA-256,
+// rather than the 8-byte rate. crypto/hmac pads HMAC keys to the
+// block size and hashes longer ones, so with the rate it would
+// cut every key to 64 bits. With HMACBlockSize, keys of up to
+// 64 bytes are used in full, and HMAC and HKDF over these hashes
+// have the strength of their keys. For keyed uses, NewMAC,
+// NewPRF and package kdf are still faster.
+func NewHash() hash.Hash {
+    d := &digest{
+        sponge: sponge{id: idHash, init: hashInit, b: 12},
+        size:   HashSize,
+    }
+    d.Reset()
+    return d
+}
+
+// NewHasha returns a new hash.Hash computing the Ascon-Hasha
+// checksum.
+//
+// Ascon-Hasha is like Ascon-Hash, but uses the 8-round
+// permutation between blocks instead of p12. It is faster,
+// especially for short messages, and produces different
+// digests.
+func NewHasha() hash.Hash {
+    d := &digest{
+        sponge: sponge{id: idHasha, init: hashaInit, b: 8},
+        size:   HashSize,
+    }
+    d.Reset()
+    return d
+}
+
+// NewHash256 returns a new hash.Hash computing the
+// Ascon-Hash256 checksum as standardized by NIST SP 800-232.
+//
+// Ascon-Hash256 is derived from Ascon-Hash, but loads and
+// stores data in little-endian byte order and uses a different
+// IV, so its digests differ from those of NewHash.
+func NewHash256() hash.Hash {
+    d := &digest{
+        sponge: sponge{id: idHash256, init: hash256Init, b: 12, le: true},
+        size:   HashSize,
+    }
+    d.Reset()
+    return d
+}
+
+// Sum256 returns the Ascon-Hash digest of data.
+//
+// Like NewHash, it computes the ASCON 1.2 hash, not
+// Ascon-Hash256 from NIST SP 800-232. The state is kept on the
+// stack, so Sum256 does not allocate.
+func Sum256(data []byte) [HashSize]byte {
+    return sum(hashInit, 12, data)
+}
+
+// SumHasha returns the Ascon-Hasha digest of data. It does not
+// allocate.
+func SumHasha(data []byte) [HashSize]byte {
+    return sum(hashaInit, 8, data)
+}
+
+// Hash256Batch sets digests[i] to the Ascon-Hash256 digest of
+// msgs[i], as computed by NewHash256. It panics if digests and
+// msgs do not have the same length.
+//
+// On amd64 CPUs with AVX2, it hashes four messages at once for
+// as long as they all have input left, so it is fastest for
+// messages of equal lengths, such as the leaves of a hash
+// tree.
+func Hash256Batch(digests [][HashSize]byte, msgs [][]byte) {
+    if len(digests) != len(msgs) {
+        panic("ascon: batch length mismatch")
+    }
+    i := 0
+    if useAVX2 {
+        for ; i+4 <= len(msgs); i += 4 {
+            hash256x4((*[4][HashSize]byte)(digests[i:]), (*[4][]byte)(msgs[i:]))
+        }
+    }
+    for ; i < len(msgs); i++ {
+        d := sponge{init: hash256Init, b: 12, le: true}
+        d.reset()
+        d.write(msgs[i])
+        d.finish()
+        d.squeeze(digests[i][:])
+    }
+}
+
+// hash256x4 computes four Ascon-Hash256 digests at once. The
+// messages are absorbed together up to the end of the shortest
+// one, and then finished one by one unless they all end in the
+// same block.
+func hash256x4(digests *[4][HashSize]byte, msgs *[4][]byte) {
+    s := [4]state{hash256Init, hash256Init, hash256Init, hash256Init}
+    m := *msgs
+    for len(m[0]) >= HashBlockSize && len(m[1]) >= HashBlockSize &&
+        len(m[2]) >= HashBlockSize && len(m[3]) >= HashBlockSize {
+        for j := range s {
+            s[j][0] ^= binary.LittleEndian.Uint64(m[j])
+            m[j] = m[j][HashBlockSize:]
+        }
+        p12x4(&s)
+    }
+
+    if len(m[0]) < HashBlockSize && len(m[1]) < HashBlockSize &&
+        len(m[2]) < HashBlockSize && len(m[3]) < HashBlockSize {
+        for j := range s {
+            s[j][0] ^= byteorder.LE64n(m[j]) ^ byteorder.PadLE(len(m[j]))
+        }
+        p12x4(&s)
+        for off := 0; off < HashSize; off += HashBlockSize {
+            if off > 0 {
+                p12x4(&s)
+            }
+            for j := range s {
+                binary.LittleEndian.PutUint64(digests[j][off:], s[j][0])
+            }
+        }
+        return
+    }
+
+    for j := range s {
+        d := sponge{s: s[j], b: 12, le: true}
+        d.write(m[j])
+        d.finish()
+        d.squeeze(digests[j][:])
+    }
+}
+
+func sum(init state, b int, data []byte) [HashSize]byte {
+    d := sponge{init: init, b: b}
+    d.reset()
+    d.write(data)
+    d.finish()
+    var out [HashSize]byte
+    d.squeeze(out[:])
+    return out
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+    d.write(p)
+    return len(p), nil
+}
+
+func (d *digest) Sum(b []byte) []byte {
+    // Work on a copy so that the caller can keep writing.
+    c := *d
+    c.finish()
+    ret, out := subtle.SliceForAppend(b, c.size)
+    c.squeeze(out)
+    return ret
+}
+
+// Clone returns an independent copy of the hash in its
+// current state.
+func (d *digest) Clone() hash.Hash {
+    c := *d
+    return &c
+}
+
+func (d *digest) Reset() {
+    d.reset()
+}
+
+func (d *digest) Size() int {
+    return d.size
+}
+
+func (d *digest) BlockSize() int {
+    return HMACBlockSize
+}
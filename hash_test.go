@@ -0,0 +1,221 @@
+package ascon
+
+import (
+    "bytes"
+    "hash"
+    "path/filepath"
+    "testing"
+)
+
+func TestVectorsHash(t *testing.T) {
+    testHashVectors(t, NewHash, "vectors_hash.txt")
+}
+
+func TestVectorsHasha(t *testing.T) {
+    testHashVectors(t, NewHasha, "vectors_hasha.txt")
+}
+
+func TestVectorsHash256(t *testing.T) {
+    testHashVectors(t, NewHash256, "vectors_hash256.txt")
+}
+
+func testHashVectors(t *testing.T, fn func() hash.Hash, name string) {
+    vecs, err := readVecs(filepath.Join("testdata", name))
+    if err != nil {
+        t.Fatal(err)
+    }
+    h := fn()
+    for i, v := range vecs {
+        h.Reset()
+        h.Write(v.msg)
+        if got := h.Sum(nil); !bytes.Equal(got, v.md) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.md, got)
+        }
+    }
+}
+
+func TestHash(t *testing.T) {
+    testHash(t, NewHash)
+}
+
+func TestHasha(t *testing.T) {
+    testHash(t, NewHasha)
+}
+
+func TestHash256(t *testing.T) {
+    testHash(t, NewHash256)
+}
+
+func testHash(t *testing.T, fn func() hash.Hash) {
+    h := fn()
+    if h.Size() != HashSize {
+        t.Fatalf("expected size %d, got %d", HashSize, h.Size())
+    }
+    if h.BlockSize() != HMACBlockSize {
+        t.Fatalf("expected block size %d, got %d", HMACBlockSize, h.BlockSize())
+    }
+
+    msg := make([]byte, 100)
+    for i := range msg {
+        msg[i] = byte(i)
+    }
+    h.Write(msg)
+    want := h.Sum(nil)
+
+    // Sum must not change the running state.
+    h.Reset()
+    for i := range msg {
+        h.Write(msg[i : i+1])
+        h.Sum(nil)
+    }
+    if got := h.Sum([]byte("prefix")); !bytes.Equal(got, append([]byte("prefix"), want...)) {
+        t.Fatalf("expected %#x, got %#x", want, got)
+    }
+}
+
+func FuzzHash(f *testing.F) {
+    fuzzHash(f, NewHash)
+}
+
+func FuzzHasha(f *testing.F) {
+    fuzzHash(f, NewHasha)
+}
+
+func FuzzHash256(f *testing.F) {
+    fuzzHash(f, NewHash256)
+}
+
+func fuzzHash(f *testing.F, fn func() hash.Hash) {
+    f.Add([]byte{}, uint8(1))
+    f.Add([]byte("the quick brown fox jumps over the lazy dog"), uint8(3))
+    f.Fuzz(func(t *testing.T, msg []byte, step uint8) {
+        h := fn()
+        h.Write(msg)
+        want := h.Sum(nil)
+
+        h.Reset()
+        for p, i := msg, 0; len(p) > 0; i++ {
+            n := (int(step) + i) % (2*HashBlockSize + 1)
+            if n > len(p) {
+                n = len(p)
+            }
+            h.Write(p[:n])
+            p = p[n:]
+        }
+        if got := h.Sum(nil); !bytes.Equal(got, want) {
+            t.Fatalf("expected %#x, got %#x", want, got)
+        }
+    })
+}
+
+func BenchmarkHash64(b *testing.B) {
+    benchmarkHash(b, NewHash, 64)
+}
+
+func BenchmarkHash1K(b *testing.B) {
+    benchmarkHash(b, NewHash, 1024)
+}
+
+func BenchmarkHasha64(b *testing.B) {
+    benchmarkHash(b, NewHasha, 64)
+}
+
+func BenchmarkHasha1K(b *testing.B) {
+    benchmarkHash(b, NewHasha, 1024)
+}
+
+func BenchmarkHash256_64(b *testing.B) {
+    benchmarkHash(b, NewHash256, 64)
+}
+
+func BenchmarkHash256_1K(b *testing.B) {
+    benchmarkHash(b, NewHash256, 1024)
+}
+
+func benchmarkHash(b *testing.B, fn func() hash.Hash, size int) {
+    b.SetBytes(int64(size))
+    b.ReportAllocs()
+    buf := make([]byte, size)
+    h := fn()
+    var out []byte
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        h.Reset()
+        h.Write(buf)
+        out = h.Sum(out[:0])
+    }
+}
+
+func TestHashClone(t *testing.T) {
+    for _, fn := range []func() hash.Hash{NewHash, NewHasha, NewHash256} {
+        h := fn()
+        h.Write([]byte("shared prefix, longer than a block"))
+        for _, suffix := range []string{"", "a", "suffix two"} {
+            c := h.(interface{ Clone() hash.Hash }).Clone()
+            c.Write([]byte(suffix))
+
+            want := fn()
+            want.Write([]byte("shared prefix, longer than a block" + suffix))
+            if got := c.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+                t.Fatalf("%q: expected %#x, got %#x", suffix, want.Sum(nil), got)
+            }
+        }
+
+        // The original is unaffected by writes to the clones.
+        want := fn()
+        want.Write([]byte("shared prefix, longer than a block"))
+        if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+            t.Fatalf("expected %#x, got %#x", want.Sum(nil), got)
+        }
+    }
+}
+
+func TestSum(t *testing.T) {
+    data := []byte("a 40-byte record of log data, or so.....")
+    for _, tc := range []struct {
+        name string
+        fn   func() hash.Hash
+        sum  func([]byte) [HashSize]byte
+    }{
+        {"Sum256", NewHash, Sum256},
+        {"SumHasha", NewHasha, SumHasha},
+    } {
+        for n := 0; n <= len(data); n++ {
+            h := tc.fn()
+            h.Write(data[:n])
+            want := h.Sum(nil)
+            if got := tc.sum(data[:n]); !bytes.Equal(got[:], want) {
+                t.Fatalf("%s: %d bytes: expected %#x, got %#x", tc.name, n, want, got)
+            }
+        }
+        allocs := testing.AllocsPerRun(100, func() {
+            tc.sum(data)
+        })
+        if allocs != 0 {
+            t.Fatalf("%s: expected 0 allocations, got %v", tc.name, allocs)
+        }
+    }
+}
+
+func BenchmarkSum256_40(b *testing.B) {
+    data := make([]byte, 40)
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        Sum256(data)
+    }
+}
+
+func BenchmarkSumHasha40(b *testing.B) {
+    data := make([]byte, 40)
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        SumHasha(data)
+    }
+}
+
+func BenchmarkHash40(b *testing.B) {
+    benchmarkHash(b, NewHash, 40)
+}
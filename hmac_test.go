@@ -0,0 +1,117 @@
+package ascon
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "hash"
+    "testing"
+)
+
+// hkdfKey is HKDF of RFC 5869, as computed by
+// golang.org/x/crypto/hkdf and crypto/hkdf, written here so
+// that the test needs neither a dependency nor a newer Go.
+func hkdfKey(h func() hash.Hash, secret, salt, info []byte, length int) []byte {
+    if salt == nil {
+        salt = make([]byte, h().Size())
+    }
+    extract := hmac.New(h, salt)
+    extract.Write(secret)
+    prk := extract.Sum(nil)
+
+    var out, t []byte
+    for i := byte(1); len(out) < length; i++ {
+        expand := hmac.New(h, prk)
+        expand.Write(t)
+        expand.Write(info)
+        expand.Write([]byte{i})
+        t = expand.Sum(nil)
+        out = append(out, t...)
+    }
+    return out[:length]
+}
+
+func TestHKDFKey(t *testing.T) {
+    // RFC 5869, test case 1.
+    ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+    salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+    info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+    const want = "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+    if got := hex.EncodeToString(hkdfKey(sha256.New, ikm, salt, info, 42)); got != want {
+        t.Fatalf("expected %s, got %s", want, got)
+    }
+}
+
+// These vectors lock down the output of crypto/hmac and HKDF
+// over the ASCON hashes, with HMACBlockSize as the block size.
+// They match Python's hmac module over an implementation of the
+// hashes that does not use this package.
+func TestHMACInterop(t *testing.T) {
+    seq := func(from, to int) []byte {
+        b := make([]byte, 0, to-from)
+        for i := from; i < to; i++ {
+            b = append(b, byte(i))
+        }
+        return b
+    }
+    for _, tc := range []struct {
+        name                     string
+        fn                       func() hash.Hash
+        fox, key20, key100, hkdf string
+    }{
+        {
+            "Hash", NewHash,
+            "f3940d8983d62d8df4524837e1072fe8a2ec8d844d4ab783ac14f4d6f730b274",
+            "69c3a38da7128bde2e65e03bc9bb52dea58a2a23e73bc4450ff890facbafd6e6",
+            "533d86eccdd8d5bb5c6882618990a599ca8519fb9714304df01a6a4fde8e0e89",
+            "281cf2ba075b5e28363e7b5cf729c2d0468e8a7595b64514784866697ceb1ec393a6dafa49f50ad78dec",
+        },
+        {
+            "Hasha", NewHasha,
+            "078a2cfb1ca9493fcdb9229d3388ccdb46a9fd25e70071f41ae5b0a108ef3305",
+            "53e35a1ad0efd4d7a7eb4c9f0da0a047ac1311d2a8d25784d097809fff88103b",
+            "cf6138f1faafe9188b8917c8fd534c215dd3181ce510a946746d45c246444a04",
+            "9d602a60382efb34ebc1b1b91daf58295467a71c7f497fab5d63e00d3799f68dee814325a742b6b395b7",
+        },
+        {
+            "Hash256", NewHash256,
+            "6b416e06cc40640207e54ca769825c572edfecaf2894d2f196548fb673184079",
+            "74a82a8db4cfa999097987de94efd686a467be244baee314c18f9c77f3655f20",
+            "87f9e9758e624b6cb1f4de63b1c17f82ff3286addee80ea3529466664450d013",
+            "8dc4fbc74347ac01d069e3f7682d02d4117e8650a0956e18caa40680020c0ab5093e52469e32d1bf4e12",
+        },
+    } {
+        m := hmac.New(tc.fn, []byte("key"))
+        m.Write([]byte("The quick brown fox jumps over the lazy dog"))
+        if got := hex.EncodeToString(m.Sum(nil)); got != tc.fox {
+            t.Fatalf("%s: expected %s, got %s", tc.name, tc.fox, got)
+        }
+
+        // A 20-byte key is used in full; a 100-byte key, longer
+        // than HMACBlockSize, is hashed first.
+        m = hmac.New(tc.fn, seq(0, 20))
+        m.Write([]byte("msg"))
+        if got := hex.EncodeToString(m.Sum(nil)); got != tc.key20 {
+            t.Fatalf("%s: 20-byte key: expected %s, got %s", tc.name, tc.key20, got)
+        }
+        m = hmac.New(tc.fn, seq(0, 100))
+        m.Write([]byte("msg"))
+        if got := hex.EncodeToString(m.Sum(nil)); got != tc.key100 {
+            t.Fatalf("%s: 100-byte key: expected %s, got %s", tc.name, tc.key100, got)
+        }
+
+        // Keys that only differ after their eighth byte give
+        // different MACs.
+        a, b := seq(0, 16), seq(0, 16)
+        b[15] ^= 1
+        ma, mb := hmac.New(tc.fn, a), hmac.New(tc.fn, b)
+        if hmac.Equal(ma.Sum(nil), mb.Sum(nil)) {
+            t.Fatalf("%s: HMAC ignores the end of a 16-byte key", tc.name)
+        }
+
+        okm := hkdfKey(tc.fn, seq(0, 22), seq(0, 13), seq(0xf0, 0xfa), 42)
+        if got := hex.EncodeToString(okm); got != tc.hkdf {
+            t.Fatalf("%s: HKDF: expected %s, got %s", tc.name, tc.hkdf, got)
+        }
+    }
+}
@@ -0,0 +1,50 @@
+package ascon
+
+import (
+    "runtime"
+    "runtime/debug"
+    "strings"
+    "testing"
+)
+
+// TestImplementation checks that the build tags select the
+// expected code, so that a CI job run with -tags purego or
+// -tags ascon_interleaved really tests the generic or the
+// interleaved code.
+func TestImplementation(t *testing.T) {
+    info, ok := debug.ReadBuildInfo()
+    if !ok {
+        t.Skip("no build information")
+    }
+    tags := make(map[string]bool)
+    for _, s := range info.Settings {
+        if s.Key == "-tags" {
+            for _, tag := range strings.Split(s.Value, ",") {
+                tags[tag] = true
+            }
+        }
+    }
+
+    want := "generic"
+    switch runtime.GOARCH {
+    case "arm", "mips", "mipsle":
+        want = "interleaved"
+    case "amd64", "arm64", "riscv64":
+        if runtime.Compiler == "gc" && !tags["purego"] {
+            want = "asm"
+        }
+        if runtime.GOARCH != "amd64" && !tags["ascon_asm_"+runtime.GOARCH] {
+            want = "generic"
+        }
+    }
+    if tags["ascon_interleaved"] {
+        want = "interleaved"
+    }
+    if implementation != want {
+        t.Fatalf("expected the %s code, got %s", want, implementation)
+    }
+    if useAVX2 && (implementation != "asm" || runtime.GOARCH != "amd64") {
+        t.Fatalf("AVX2 used by the %s code on %s", implementation, runtime.GOARCH)
+    }
+    t.Logf("%s code, AVX2: %v", implementation, useAVX2)
+}
@@ -0,0 +1,179 @@
+package ascon
+
+import (
+    "bytes"
+    "testing"
+)
+
+// inPlaceSizes are the plaintext sizes of the in-place tests,
+// around the block sizes.
+var inPlaceSizes = []int{0, 1, 7, 8, 9, 15, 16, 17, 33, 100}
+
+func TestSealInPlace(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            aead, err := tc.fn(seq(0, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            nonce, ad := seq(0, NonceSize), seq(100, 110)
+            for _, n := range inPlaceSizes {
+                pt := seq(50, 50+n)
+                want := aead.Seal(nil, nonce, pt, ad)
+
+                // With spare capacity, the output stays in buf.
+                buf := make([]byte, n, n+aead.Overhead())
+                copy(buf, pt)
+                got := aead.Seal(buf[:0], nonce, buf, ad)
+                if !bytes.Equal(got, want) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+                }
+                if &got[:1][0] != &buf[:1][0] {
+                    t.Fatalf("%d: the output does not use the capacity of buf", n)
+                }
+
+                // The same after a prefix.
+                buf = make([]byte, 5+n, 5+n+aead.Overhead())
+                copy(buf[5:], pt)
+                got = aead.Seal(buf[:5], nonce, buf[5:], ad)
+                if !bytes.Equal(got[5:], want) || &got[0] != &buf[0] {
+                    t.Fatalf("%d: prefix: expected %#x, got %#x", n, want, got[5:])
+                }
+
+                // Without it, the output moves and buf is left
+                // as is.
+                buf = append([]byte(nil), pt...)[:n:n]
+                got = aead.Seal(buf[:0], nonce, buf, ad)
+                if !bytes.Equal(got, want) {
+                    t.Fatalf("%d: no capacity: expected %#x, got %#x", n, want, got)
+                }
+                if !bytes.Equal(buf, pt) {
+                    t.Fatalf("%d: no capacity: buf was modified", n)
+                }
+
+                a := aead.(*AEAD)
+                buf = make([]byte, n+a.Overhead())
+                copy(buf, pt)
+                if m, err := a.SealTo(buf, nonce, buf[:n], ad); err != nil || !bytes.Equal(buf[:m], want) {
+                    t.Fatalf("%d: SealTo: expected %#x, got %#x, %v", n, want, buf[:m], err)
+                }
+                buf = append([]byte(nil), pt...)
+                ct, tag := a.SealDetached(buf[:0], nil, nonce, buf, ad)
+                if !bytes.Equal(append(ct, tag...), want) || n > 0 && &ct[0] != &buf[0] {
+                    t.Fatalf("%d: SealDetached: expected %#x, got %#x%x", n, want, ct, tag)
+                }
+            }
+        })
+    }
+}
+
+func TestOpenInPlace(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            aead, err := tc.fn(seq(0, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            a := aead.(*AEAD)
+            nonce, ad := seq(0, NonceSize), seq(100, 110)
+            for _, n := range inPlaceSizes {
+                pt := seq(50, 50+n)
+                sealed := aead.Seal(nil, nonce, pt, ad)
+
+                buf := append([]byte(nil), sealed...)
+                got, err := aead.Open(buf[:0], nonce, buf, ad)
+                if err != nil || !bytes.Equal(got, pt) {
+                    t.Fatalf("%d: expected %#x, got %#x, %v", n, pt, got, err)
+                }
+                if n > 0 && &got[0] != &buf[0] {
+                    t.Fatalf("%d: the output is not in buf", n)
+                }
+                if !bytes.Equal(buf[n:], sealed[n:]) {
+                    t.Fatalf("%d: the authenticator was modified", n)
+                }
+
+                // A failed open in place zeroes the plaintext,
+                // which destroys the ciphertext.
+                buf = append([]byte(nil), sealed...)
+                buf[len(buf)-1] ^= 1
+                if _, err := aead.Open(buf[:0], nonce, buf, ad); err == nil {
+                    t.Fatalf("%d: accepted a bad tag", n)
+                }
+                if !bytes.Equal(buf[:n], make([]byte, n)) {
+                    t.Fatalf("%d: the output was not zeroed", n)
+                }
+
+                buf = append([]byte(nil), sealed...)
+                if m, err := a.OpenTo(buf, nonce, buf, ad); err != nil || !bytes.Equal(buf[:m], pt) {
+                    t.Fatalf("%d: OpenTo: expected %#x, got %#x, %v", n, pt, buf[:m], err)
+                }
+                buf = append([]byte(nil), sealed...)
+                got, err = a.OpenDetached(buf[:0], nonce, buf[:n], buf[n:], ad)
+                if err != nil || !bytes.Equal(got, pt) {
+                    t.Fatalf("%d: OpenDetached: expected %#x, got %#x, %v", n, pt, got, err)
+                }
+            }
+        })
+    }
+}
+
+// TestInPlaceOffsets checks that every overlap other than the
+// exact one panics, at each small offset in both directions.
+func TestInPlaceOffsets(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            aead, err := tc.fn(seq(0, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            a := aead.(*AEAD)
+            nonce := seq(0, NonceSize)
+            const n = 40
+            sealed := aead.Seal(nil, nonce, seq(0, n), nil)
+
+            for off := 1; off <= TagSize+1; off++ {
+                for name, fn := range map[string]func(buf []byte){
+                    // The output starts before the input.
+                    "Seal/before": func(buf []byte) { aead.Seal(buf[:0], nonce, buf[off:off+n], nil) },
+                    "Open/before": func(buf []byte) {
+                        copy(buf[off:], sealed)
+                        aead.Open(buf[:0], nonce, buf[off:off+len(sealed)], nil)
+                    },
+                    "SealTo/before": func(buf []byte) { a.SealTo(buf, nonce, buf[off:off+n], nil) },
+                    "OpenDetached/before": func(buf []byte) {
+                        copy(buf[off:], sealed)
+                        a.OpenDetached(buf[:0], nonce, buf[off:off+n], sealed[n:], nil)
+                    },
+
+                    // The output starts after the input.
+                    "Seal/after": func(buf []byte) { aead.Seal(buf[off:off], nonce, buf[:n], nil) },
+                    "Open/after": func(buf []byte) {
+                        copy(buf, sealed)
+                        aead.Open(buf[off:off], nonce, buf[:len(sealed)], nil)
+                    },
+                    "OpenTo/after": func(buf []byte) {
+                        copy(buf, sealed)
+                        a.OpenTo(buf[off:], nonce, buf[:len(sealed)], nil)
+                    },
+                    "SealDetached/after": func(buf []byte) { a.SealDetached(buf[off:off], nil, nonce, buf[:n], nil) },
+
+                    // The plaintext would overwrite the
+                    // authenticator.
+                    "Open/tag": func(buf []byte) {
+                        copy(buf, sealed)
+                        aead.Open(buf[n+1-off:n+1-off], nonce, buf[:len(sealed)], nil)
+                    },
+                } {
+                    func() {
+                        defer func() {
+                            if recover() == nil {
+                                t.Fatalf("%s at offset %d: expected a panic", name, off)
+                            }
+                        }()
+                        fn(make([]byte, 2*(n+TagSize)))
+                    }()
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,299 @@
+// Package insecure implements round-reduced ASCON for
+// cryptanalysis and teaching.
+//
+// Nothing in this package is secure. Reducing the number of
+// permutation rounds breaks the security claims of ASCON, and
+// published attacks recover keys or forge messages for small
+// round counts. Use package ascon for real data.
+package insecure
+
+import (
+    "errors"
+    "runtime"
+    "strconv"
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+    "github.com/pedroalbanese/go-ascon/permutation"
+)
+
+// Variant selects the rate and key size of the underlying
+// ASCON mode.
+type Variant int
+
+const (
+    // Ascon128 uses the 64-bit rate of ASCON-128.
+    Ascon128 Variant = iota
+    // Ascon128a uses the 128-bit rate of ASCON-128a.
+    Ascon128a
+)
+
+const (
+    // KeySize is the size in bytes of keys.
+    KeySize = 16
+    // NonceSize is the size in bytes of nonces.
+    NonceSize = 16
+    // TagSize is the size in bytes of authenticators.
+    TagSize = 16
+    // MaxRounds is the number of rounds of the full ASCON
+    // permutation.
+    MaxRounds = 12
+)
+
+var errOpen = errors.New("ascon: message authentication failed")
+
+// An Option configures NewUnsafe.
+type Option func(*options)
+
+type options struct {
+    acknowledged bool
+}
+
+// IKnowThisIsInsecure acknowledges that the AEAD returned by
+// NewUnsafe provides no security. NewUnsafe refuses to
+// construct an AEAD without it.
+func IKnowThisIsInsecure() Option {
+    return func(o *options) {
+        o.acknowledged = true
+    }
+}
+
+type aead struct {
+    k0, k1 uint64
+    iv     uint64
+    rate   int
+    a, b   int
+}
+
+var _ cipher.AEAD = (*aead)(nil)
+
+// NewUnsafe creates a round-reduced ASCON AEAD that uses
+// aRounds of the permutation for initialization and
+// finalization and bRounds for processing data.
+//
+// Both round counts must be in the range [1, MaxRounds] and
+// p^r always runs the last r rounds of p12, as in the
+// specification. The IV is always that of ASCON-128 or
+// ASCON-128a, with the round counts 12 and 6 or 8: the
+// round-reduced instances of the published cryptanalysis only
+// reduce the permutation calls, and keeping the IV makes their
+// results reproducible. NewUnsafe(key, Ascon128, 12, 6) and
+// NewUnsafe(key, Ascon128a, 12, 8) are identical to ASCON-128
+// and ASCON-128a.
+//
+// opts must include IKnowThisIsInsecure.
+func NewUnsafe(key []byte, variant Variant, aRounds, bRounds int, opts ...Option) (cipher.AEAD, error) {
+    var o options
+    for _, fn := range opts {
+        fn(&o)
+    }
+    if !o.acknowledged {
+        return nil, errors.New("ascon: round-reduced ASCON is insecure")
+    }
+
+    if len(key) != KeySize {
+        return nil, errors.New("ascon: bad key length")
+    }
+    if aRounds < 1 || aRounds > MaxRounds || bRounds < 1 || bRounds > MaxRounds {
+        return nil, errors.New("ascon: bad round count")
+    }
+
+    var rate, b int
+    switch variant {
+    case Ascon128:
+        rate, b = 8, 6
+    case Ascon128a:
+        rate, b = 16, 8
+    default:
+        return nil, errors.New("ascon: unknown variant")
+    }
+
+    return &aead{
+        k0:   binary.BigEndian.Uint64(key[0:]),
+        k1:   binary.BigEndian.Uint64(key[8:]),
+        iv:   uint64(KeySize*8)<<56 | uint64(rate*8)<<48 | uint64(MaxRounds)<<40 | uint64(b)<<32,
+        rate: rate,
+        a:    aRounds,
+        b:    bRounds,
+    }, nil
+}
+
+func (a *aead) NonceSize() int {
+    return NonceSize
+}
+
+func (a *aead) Overhead() int {
+    return TagSize
+}
+
+func (a *aead) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    var s state
+    a.init(&s, nonce)
+    a.additionalData(&s, additionalData)
+
+    ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    a.crypt(&s, out, plaintext, false)
+    a.finalize(&s)
+    binary.BigEndian.PutUint64(out[len(plaintext):], s[3])
+    binary.BigEndian.PutUint64(out[len(plaintext)+8:], s[4])
+
+    return ret
+}
+
+func (a *aead) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpen
+    }
+
+    if len(ciphertext) < TagSize {
+        return nil, errOpen
+    }
+
+    tag := ciphertext[len(ciphertext)-TagSize:]
+    ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+    var s state
+    a.init(&s, nonce)
+    a.additionalData(&s, additionalData)
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    a.crypt(&s, out, ciphertext, true)
+    a.finalize(&s)
+
+    var expectedTag [TagSize]byte
+    binary.BigEndian.PutUint64(expectedTag[0:], s[3])
+    binary.BigEndian.PutUint64(expectedTag[8:], s[4])
+
+    if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+        for i := range out {
+            out[i] = 0
+        }
+
+        runtime.KeepAlive(out)
+        return nil, errOpen
+    }
+
+    return ret, nil
+}
+
+func (a *aead) init(s *state, nonce []byte) {
+    *s = state{
+        a.iv,
+        a.k0,
+        a.k1,
+        binary.BigEndian.Uint64(nonce[0:]),
+        binary.BigEndian.Uint64(nonce[8:]),
+    }
+    s.permute(a.a)
+    s[3] ^= a.k0
+    s[4] ^= a.k1
+}
+
+func (a *aead) additionalData(s *state, ad []byte) {
+    if len(ad) > 0 {
+        for len(ad) >= a.rate {
+            s.xor(ad[:a.rate])
+            s.permute(a.b)
+            ad = ad[a.rate:]
+        }
+        var block [16]byte
+        n := copy(block[:], ad)
+        block[n] = 0x80
+        s.xor(block[:a.rate])
+        s.permute(a.b)
+    }
+    s[4] ^= 1
+}
+
+// crypt encrypts or decrypts src into dst.
+func (a *aead) crypt(s *state, dst, src []byte, decrypt bool) {
+    var block, ks [16]byte
+    for {
+        n := copy(block[:], src)
+        if n > a.rate {
+            n = a.rate
+        }
+        for i := n; i < a.rate; i++ {
+            block[i] = 0
+        }
+
+        s.store(ks[:a.rate])
+        for i := 0; i < n; i++ {
+            dst[i] = ks[i] ^ block[i]
+        }
+        if decrypt {
+            // The ciphertext becomes the rate.
+            copy(ks[:n], block[:n])
+            s.load(ks[:a.rate])
+        } else {
+            s.xor(block[:a.rate])
+        }
+
+        if n < a.rate {
+            pad := ks[:a.rate]
+            for i := range pad {
+                pad[i] = 0
+            }
+            pad[n] = 0x80
+            s.xor(pad)
+            return
+        }
+        s.permute(a.b)
+        src = src[n:]
+        dst = dst[n:]
+    }
+}
+
+func (a *aead) finalize(s *state) {
+    if a.rate == 8 {
+        s[1] ^= a.k0
+        s[2] ^= a.k1
+    } else {
+        s[2] ^= a.k0
+        s[3] ^= a.k1
+    }
+    s.permute(a.a)
+    s[3] ^= a.k0
+    s[4] ^= a.k1
+}
+
+type state [5]uint64
+
+// xor XORs the big-endian words in b into the rate.
+func (s *state) xor(b []byte) {
+    for i := 0; i < len(b)/8; i++ {
+        s[i] ^= binary.BigEndian.Uint64(b[8*i:])
+    }
+}
+
+// load overwrites the rate with the big-endian words in b.
+func (s *state) load(b []byte) {
+    for i := 0; i < len(b)/8; i++ {
+        s[i] = binary.BigEndian.Uint64(b[8*i:])
+    }
+}
+
+// store writes the rate to b.
+func (s *state) store(b []byte) {
+    for i := 0; i < len(b)/8; i++ {
+        binary.BigEndian.PutUint64(b[8*i:], s[i])
+    }
+}
+
+// permute runs the last r rounds of p12.
+func (s *state) permute(r int) {
+    permutation.Rounds((*permutation.State)(s), r)
+}
@@ -0,0 +1,195 @@
+package insecure
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/cipher"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func TestRefusesWithoutAcknowledgement(t *testing.T) {
+    if _, err := NewUnsafe(make([]byte, KeySize), Ascon128, 6, 4); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+func TestBadParameters(t *testing.T) {
+    key := make([]byte, KeySize)
+    for _, tc := range []struct {
+        key     []byte
+        variant Variant
+        a, b    int
+    }{
+        {key[:15], Ascon128, 12, 6},
+        {key, Ascon128, 0, 6},
+        {key, Ascon128, 12, 0},
+        {key, Ascon128a, 13, 8},
+        {key, Ascon128a, 12, 13},
+        {key, Variant(42), 12, 6},
+    } {
+        if _, err := NewUnsafe(tc.key, tc.variant, tc.a, tc.b, IKnowThisIsInsecure()); err == nil {
+            t.Fatalf("%+v: expected an error", tc)
+        }
+    }
+}
+
+// TestFullRounds checks that the full round counts reproduce
+// package ascon exactly.
+func TestFullRounds(t *testing.T) {
+    for _, tc := range []struct {
+        variant Variant
+        a, b    int
+        fn      func([]byte) (cipher.AEAD, error)
+    }{
+        {Ascon128, 12, 6, ascon.New128},
+        {Ascon128a, 12, 8, ascon.New128a},
+    } {
+        key := make([]byte, KeySize)
+        nonce := make([]byte, NonceSize)
+        for i := range key {
+            key[i] = byte(i)
+            nonce[i] = byte(i)
+        }
+        want, err := tc.fn(key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        got, err := NewUnsafe(key, tc.variant, tc.a, tc.b, IKnowThisIsInsecure())
+        if err != nil {
+            t.Fatal(err)
+        }
+        buf := make([]byte, 40)
+        for i := range buf {
+            buf[i] = byte(i)
+        }
+        for n := 0; n <= len(buf); n++ {
+            for m := 0; m <= len(buf); m += 7 {
+                w := want.Seal(nil, nonce, buf[:n], buf[:m])
+                g := got.Seal(nil, nonce, buf[:n], buf[:m])
+                if !bytes.Equal(w, g) {
+                    t.Fatalf("%d, %d: expected %#x, got %#x", n, m, w, g)
+                }
+                pt, err := got.Open(nil, nonce, g, buf[:m])
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(pt, buf[:n]) {
+                    t.Fatalf("expected %#x, got %#x", buf[:n], pt)
+                }
+            }
+        }
+    }
+}
+
+type vector struct {
+    rate, a, b         int
+    key, nonce, pt, ad []byte
+    ct                 []byte
+}
+
+func readVecs(path string) ([]vector, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var vecs []vector
+
+    s := bufio.NewScanner(f)
+    for n := 1; s.Scan(); n++ {
+        t := s.Text()
+        if t == "" {
+            continue
+        }
+        if strings.HasPrefix(t, "Count = ") {
+            vecs = append(vecs, vector{})
+            continue
+        }
+        i := strings.IndexByte(t, '=')
+        if i < 0 || len(vecs) == 0 {
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+        v := &vecs[len(vecs)-1]
+        name, val := strings.TrimSpace(t[:i]), strings.TrimSpace(t[i+1:])
+        switch name {
+        case "Rate", "A", "B":
+            x, err := strconv.Atoi(val)
+            if err != nil {
+                return nil, fmt.Errorf("malformed line %d: %v", n, err)
+            }
+            switch name {
+            case "Rate":
+                v.rate = x
+            case "A":
+                v.a = x
+            case "B":
+                v.b = x
+            }
+            continue
+        }
+        buf, err := hex.DecodeString(val)
+        if err != nil {
+            return nil, fmt.Errorf("malformed line %d: %v", n, err)
+        }
+        switch name {
+        case "Key":
+            v.key = buf
+        case "Nonce":
+            v.nonce = buf
+        case "PT":
+            v.pt = buf
+        case "AD":
+            v.ad = buf
+        case "CT":
+            v.ct = buf
+        default:
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+    }
+    return vecs, s.Err()
+}
+
+// TestReducedRoundVectors reads testdata/vectors.txt, which is
+// written by testdata/gen.py insecure at the root of the module,
+// from an implementation that does not use this package.
+func TestReducedRoundVectors(t *testing.T) {
+    vecs, err := readVecs("testdata/vectors.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) == 0 {
+        t.Fatal("no vectors")
+    }
+    for i, v := range vecs {
+        variant := Ascon128
+        if v.rate == 128 {
+            variant = Ascon128a
+        }
+        aead, err := NewUnsafe(v.key, variant, v.a, v.b, IKnowThisIsInsecure())
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        got := aead.Seal(nil, v.nonce, v.pt, v.ad)
+        if !bytes.Equal(got, v.ct) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, got)
+        }
+        pt, err := aead.Open(nil, v.nonce, got, v.ad)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(pt, v.pt) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, pt)
+        }
+        if _, err := aead.Open(nil, v.nonce[:15], got, v.ad); err == nil {
+            t.Fatalf("#%d: accepted a short nonce", i+1)
+        }
+    }
+}
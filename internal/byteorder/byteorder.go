@@ -0,0 +1,72 @@
+// Package byteorder implements the partial-word loads, stores
+// and padding of the ASCON modes, shared by package ascon and
+// its subpackages.
+//
+// A partial word holds the n < 8 bytes at the end of a message.
+// In the big-endian modes of ASCON v1.2 they are the leading
+// bytes of the word, and the padding byte 0x80 follows them. In
+// the little-endian modes of SP 800-232 they are the trailing
+// bytes of the word, and the padding byte is 0x01.
+package byteorder
+
+// Pad returns the big-endian padding word of n bytes.
+func Pad(n int) uint64 {
+    return 0x80 << (56 - 8*n)
+}
+
+// BE64n returns the big-endian word of the bytes of b, at most
+// eight, followed by zeros.
+func BE64n(b []byte) uint64 {
+    var x uint64
+    for i := len(b) - 1; i >= 0; i-- {
+        x |= uint64(b[i]) << (56 - i*8)
+    }
+    return x
+}
+
+// PutBE64n writes the leading len(b) bytes of the big-endian
+// word x to b.
+func PutBE64n(b []byte, x uint64) {
+    for i := len(b) - 1; i >= 0; i-- {
+        b[i] = byte(x >> (56 - 8*i))
+    }
+}
+
+// Mask clears the n leading bytes of the big-endian word x.
+func Mask(x uint64, n int) uint64 {
+    for i := 0; i < n; i++ {
+        x &^= 255 << (56 - 8*i)
+    }
+    return x
+}
+
+// PadLE returns the little-endian padding word of n bytes.
+func PadLE(n int) uint64 {
+    return 0x01 << (8 * n)
+}
+
+// LE64n returns the little-endian word of the bytes of b, at
+// most eight, followed by zeros.
+func LE64n(b []byte) uint64 {
+    var x uint64
+    for i := len(b) - 1; i >= 0; i-- {
+        x |= uint64(b[i]) << (8 * i)
+    }
+    return x
+}
+
+// PutLE64n writes the leading len(b) bytes of the little-endian
+// word x to b.
+func PutLE64n(b []byte, x uint64) {
+    for i := len(b) - 1; i >= 0; i-- {
+        b[i] = byte(x >> (8 * i))
+    }
+}
+
+// MaskLE clears the n leading bytes of the little-endian word x.
+func MaskLE(x uint64, n int) uint64 {
+    for i := 0; i < n; i++ {
+        x &^= 255 << (8 * i)
+    }
+    return x
+}
@@ -0,0 +1,73 @@
+package byteorder
+
+import (
+    "bytes"
+    "testing"
+    "encoding/binary"
+)
+
+// TestByteOrder checks the partial word helpers against
+// encoding/binary on zero-padded words. With the KATs, which
+// run the same generic code with the purego tag on any host,
+// it covers the loads and stores of big-endian targets.
+func TestByteOrder(t *testing.T) {
+    b := seq(1, 9)
+    const x = 0x0102030405060708
+    for n := 0; n <= 8; n++ {
+        var w [8]byte
+        copy(w[:], b[:n])
+
+        if got, want := BE64n(b[:n]), binary.BigEndian.Uint64(w[:]); got != want {
+            t.Errorf("BE64n(%d): expected %#x, got %#x", n, want, got)
+        }
+        if got, want := LE64n(b[:n]), binary.LittleEndian.Uint64(w[:]); got != want {
+            t.Errorf("LE64n(%d): expected %#x, got %#x", n, want, got)
+        }
+
+        got := make([]byte, n)
+        PutBE64n(got, x)
+        if want := seq(1, n+1); !bytes.Equal(got, want) {
+            t.Errorf("PutBE64n(%d): expected %x, got %x", n, want, got)
+        }
+        PutLE64n(got, x)
+        binary.LittleEndian.PutUint64(w[:], x)
+        if !bytes.Equal(got, w[:n]) {
+            t.Errorf("PutLE64n(%d): expected %x, got %x", n, w[:n], got)
+        }
+
+        if n < 8 {
+            // The padding byte follows the n message bytes.
+            var p [8]byte
+            p[n] = 0x80
+            if got, want := Pad(n), binary.BigEndian.Uint64(p[:]); got != want {
+                t.Errorf("Pad(%d): expected %#x, got %#x", n, want, got)
+            }
+            p[n] = 0x01
+            if got, want := PadLE(n), binary.LittleEndian.Uint64(p[:]); got != want {
+                t.Errorf("PadLE(%d): expected %#x, got %#x", n, want, got)
+            }
+        }
+
+        // Mask clears the n leading bytes.
+        var m [8]byte
+        binary.BigEndian.PutUint64(m[:], ^uint64(0))
+        for i := 0; i < n; i++ {
+            m[i] = 0
+        }
+        if got, want := Mask(^uint64(0), n), binary.BigEndian.Uint64(m[:]); got != want {
+            t.Errorf("Mask(%d): expected %#x, got %#x", n, want, got)
+        }
+        if got, want := MaskLE(^uint64(0), n), binary.LittleEndian.Uint64(m[:]); got != want {
+            t.Errorf("MaskLE(%d): expected %#x, got %#x", n, want, got)
+        }
+    }
+}
+
+// seq returns the bytes from, from+1, ..., to-1.
+func seq(from, to int) []byte {
+    b := make([]byte, 0, to-from)
+    for i := from; i < to; i++ {
+        b = append(b, byte(i))
+    }
+    return b
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+    "flag"
+    "fmt"
+    "io"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/armor"
+    "github.com/pedroalbanese/go-ascon/container"
+    "github.com/pedroalbanese/go-ascon/stream"
+)
+
+// encrypt seals everything read from src into a container with
+// the AEAD of v, written to dst.
+func encrypt(dst io.Writer, src io.Reader, v ascon.Variant, key []byte) error {
+    w, err := container.NewWriter(dst, v, key, stream.DefaultChunkSize)
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(w, src); err != nil {
+        return err
+    }
+    return w.Close()
+}
+
+// decrypt opens the container read from src and writes its
+// plaintext to dst. It only writes authenticated chunks, but
+// returns an error, after writing the chunks before it, if one
+// fails to open.
+func decrypt(dst io.Writer, src io.Reader, key []byte) error {
+    r, err := container.NewReader(src, key)
+    if err != nil {
+        return err
+    }
+    _, err = io.Copy(dst, r)
+    return err
+}
+
+const asconUsage = `usage: ascon seal [-k key | -p] [-variant name] [-a] [-in file] [-out file] [-f]
+       ascon open [-k key | -p] [-a] [-in file] [-out file] [-f]
+       ascon keygen [-variant name]
+
+seal encrypts a file into a container, which records the
+variant, and open decrypts it. The key is in hexadecimal or
+base64, or in the file named by -k file:PATH, or in $ASCON_KEY
+without -k. The input and output default to the standard input
+and output. With -a, seal writes the container in ASCII armor,
+and open reads it.
+
+With -p, the key is derived from a password, read from the
+terminal or from $ASCON_PASSWORD, with scrypt, and the file is
+encrypted with Ascon-128a instead of into a container. The whole
+file is then held in memory.
+
+open fails, and removes the output, if the file does not
+authenticate.
+
+keygen prints a random key of the variant in hexadecimal.
+`
+
+// keygen runs ascon keygen.
+func keygen(args []string, s Streams) int {
+    fs := newFlagSet("ascon keygen", s.Stderr)
+    fs.Usage = func() { fmt.Fprint(s.Stderr, asconUsage) }
+    variant := fs.String("variant", ascon.AsconAEAD128.String(), "AEAD variant")
+    if err := fs.Parse(args); err != nil {
+        return exitUsage
+    }
+    if fs.NArg() > 0 {
+        return usageError(fs, "unexpected arguments")
+    }
+    v, err := ascon.ParseVariant(*variant)
+    if err != nil {
+        return usageError(fs, err.Error())
+    }
+    key, err := ascon.GenerateKey(v)
+    if err != nil {
+        return fail(s.Stderr, fs.Name(), err)
+    }
+    if _, err := fmt.Fprintln(s.Stdout, ascon.FormatKey(key)); err != nil {
+        return fail(s.Stderr, fs.Name(), err)
+    }
+    return exitOK
+}
+
+// Ascon runs the ascon command with the arguments args, which
+// do not include the command name.
+func Ascon(args []string, s Streams) int {
+    if len(args) > 0 && args[0] == "keygen" {
+        return keygen(args[1:], s)
+    }
+    if len(args) == 0 || (args[0] != "seal" && args[0] != "open") {
+        fmt.Fprint(s.Stderr, asconUsage)
+        return exitUsage
+    }
+    seal := args[0] == "seal"
+
+    fs := newFlagSet("ascon "+args[0], s.Stderr)
+    fs.Usage = func() { fmt.Fprint(s.Stderr, asconUsage) }
+    keySpec := fs.String("k", "", keyHelp)
+    variant := fs.String("variant", ascon.AsconAEAD128.String(), "AEAD variant, for seal")
+    in := fs.String("in", "", "input `file`")
+    out := fs.String("out", "", "output `file`")
+    force := fs.Bool("f", false, "overwrite the output file")
+    armored := fs.Bool("a", false, "write or read ASCII armor")
+    usePassword := fs.Bool("p", false, "use a password instead of a key")
+    if err := fs.Parse(args[1:]); err != nil {
+        return exitUsage
+    }
+    if fs.NArg() > 0 {
+        return usageError(fs, "unexpected arguments")
+    }
+    v, err := ascon.ParseVariant(*variant)
+    if err != nil {
+        return usageError(fs, err.Error())
+    }
+    var key, password []byte
+    if *usePassword {
+        set := map[string]bool{}
+        fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+        if set["k"] || set["variant"] {
+            return usageError(fs, "-p does not take a key or variant")
+        }
+        if password, err = loadPassword(seal); err != nil {
+            return fail(s.Stderr, fs.Name(), err)
+        }
+    } else {
+        // open learns the variant from the container, so any
+        // key size will do.
+        variants := []ascon.Variant{ascon.AsconAEAD128, ascon.Ascon80pq}
+        if seal {
+            variants = []ascon.Variant{v}
+        }
+        if key, err = loadKey(*keySpec, variants...); err != nil {
+            return usageError(fs, err.Error())
+        }
+    }
+
+    r, err := openInput(*in, s.Stdin)
+    if err != nil {
+        return fail(s.Stderr, fs.Name(), err)
+    }
+    defer r.Close()
+    w, err := createOutput(*out, *force, s.Stdout)
+    if err != nil {
+        return fail(s.Stderr, fs.Name(), err)
+    }
+    dst, src := io.Writer(w), io.Reader(r)
+    var aw io.WriteCloser
+    if *armored && seal {
+        aw = armor.NewEncoder(w)
+        dst = aw
+    } else if *armored {
+        src = armor.NewDecoder(r)
+    }
+    switch {
+    case seal && *usePassword:
+        err = encryptPassword(dst, src, password)
+    case seal:
+        err = encrypt(dst, src, v, key)
+    case *usePassword:
+        err = decryptPassword(dst, src, password)
+    default:
+        err = decrypt(dst, src, key)
+    }
+    if err == nil && aw != nil {
+        err = aw.Close()
+    }
+    if err := w.close(err); err != nil {
+        return fail(s.Stderr, fs.Name(), err)
+    }
+    return exitOK
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+    "bytes"
+    "encoding/hex"
+    "math/rand"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon/container"
+)
+
+var testKey = hex.EncodeToString(bytes.Repeat([]byte{0x42}, 16))
+
+// run runs cmd with stdin and returns its exit status, stdout
+// and stderr.
+func run(t *testing.T, cmd func([]string, Streams) int, stdin []byte, args ...string) (int, []byte, string) {
+    t.Helper()
+    var stdout, stderr bytes.Buffer
+    code := cmd(args, Streams{Stdin: bytes.NewReader(stdin), Stdout: &stdout, Stderr: &stderr})
+    return code, stdout.Bytes(), stderr.String()
+}
+
+func TestAsconRoundTrip(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    for _, variant := range []string{"Ascon-128", "Ascon-128a", "Ascon-80pq", "Ascon-AEAD128"} {
+        key := testKey
+        if variant == "Ascon-80pq" {
+            key += "42424242"
+        }
+        for _, n := range []int{0, 1, 1 << 16, 3<<16 + 7} {
+            msg := make([]byte, n)
+            rng.Read(msg)
+            code, sealed, stderr := run(t, Ascon, msg, "seal", "-k", key, "-variant", variant)
+            if code != exitOK {
+                t.Fatalf("%s: seal: exit %d: %s", variant, code, stderr)
+            }
+            code, opened, stderr := run(t, Ascon, sealed, "open", "-k", key)
+            if code != exitOK {
+                t.Fatalf("%s: open: exit %d: %s", variant, code, stderr)
+            }
+            if !bytes.Equal(opened, msg) {
+                t.Fatalf("%s: %d bytes: open returned another message", variant, n)
+            }
+        }
+    }
+}
+
+func TestAsconFiles(t *testing.T) {
+    dir := t.TempDir()
+    in, sealed, out := filepath.Join(dir, "msg"), filepath.Join(dir, "msg.asc"), filepath.Join(dir, "msg.out")
+    msg := bytes.Repeat([]byte("ascon"), 50000)
+    if err := os.WriteFile(in, msg, 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if code, _, stderr := run(t, Ascon, nil, "seal", "-k", testKey, "-in", in, "-out", sealed); code != exitOK {
+        t.Fatalf("seal: exit %d: %s", code, stderr)
+    }
+    if code, _, stderr := run(t, Ascon, nil, "open", "-k", testKey, "-in", sealed, "-out", out); code != exitOK {
+        t.Fatalf("open: exit %d: %s", code, stderr)
+    }
+    if b, err := os.ReadFile(out); err != nil || !bytes.Equal(b, msg) {
+        t.Fatalf("open wrote another message: %v", err)
+    }
+
+    // An existing output is left alone without -f.
+    if err := os.WriteFile(out, []byte("keep"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    code, _, stderr := run(t, Ascon, nil, "open", "-k", testKey, "-in", sealed, "-out", out)
+    if code != exitFailure || !strings.Contains(stderr, "-f") {
+        t.Errorf("open over an existing file: exit %d: %s", code, stderr)
+    }
+    if b, _ := os.ReadFile(out); string(b) != "keep" {
+        t.Errorf("open without -f changed the existing file")
+    }
+    if code, _, stderr := run(t, Ascon, nil, "open", "-k", testKey, "-in", sealed, "-out", out, "-f"); code != exitOK {
+        t.Fatalf("open -f: exit %d: %s", code, stderr)
+    }
+    if b, _ := os.ReadFile(out); !bytes.Equal(b, msg) {
+        t.Errorf("open -f did not overwrite the file")
+    }
+}
+
+func TestAsconAuthFailure(t *testing.T) {
+    dir := t.TempDir()
+    msg := bytes.Repeat([]byte{1}, 1<<17)
+    _, sealed, _ := run(t, Ascon, msg, "seal", "-k", testKey)
+
+    wrongKey := strings.Repeat("00", 16)
+    tampered := append([]byte(nil), sealed...)
+    tampered[len(tampered)/2] ^= 1
+    variant := append([]byte(nil), sealed...)
+    variant[len(container.Magic)+1] = 2
+    for name, tc := range map[string]struct {
+        key  string
+        file []byte
+    }{
+        "wrong key":     {wrongKey, sealed},
+        "tampered":      {testKey, tampered},
+        "truncated":     {testKey, sealed[:len(sealed)-1]},
+        "variant":       {testKey, variant},
+        "not encrypted": {testKey, msg},
+        "empty":         {testKey, nil},
+    } {
+        out := filepath.Join(dir, strings.ReplaceAll(name, " ", "-"))
+        code, _, stderr := run(t, Ascon, tc.file, "open", "-k", tc.key, "-out", out)
+        if code != exitFailure || stderr == "" {
+            t.Errorf("%s: exit %d: %s", name, code, stderr)
+        }
+        if _, err := os.Stat(out); !os.IsNotExist(err) {
+            t.Errorf("%s: the output was not removed: %v", name, err)
+        }
+    }
+}
+
+func TestAsconUsage(t *testing.T) {
+    t.Setenv(keyEnv, "")
+    for _, args := range [][]string{
+        nil,
+        {"encrypt"},
+        {"seal"},
+        {"seal", "-k", "xyz"},
+        {"seal", "-k", testKey, "-variant", "AES"},
+        {"seal", "-k", testKey, "extra"},
+        {"open", "-bogus"},
+    } {
+        if code, _, stderr := run(t, Ascon, nil, args...); code != exitUsage || stderr == "" {
+            t.Errorf("%q: exit %d: %s", args, code, stderr)
+        }
+    }
+    // The key must not be echoed back.
+    if _, _, stderr := run(t, Ascon, nil, "seal", "-k", "0123456789abcdeg"); strings.Contains(stderr, "0123456789abcdeg") {
+        t.Errorf("the usage error contains the key: %s", stderr)
+    }
+}
+
+func TestAsconArmor(t *testing.T) {
+    msg := bytes.Repeat([]byte("armored"), 20000)
+    code, sealed, stderr := run(t, Ascon, msg, "seal", "-k", testKey, "-a")
+    if code != exitOK {
+        t.Fatalf("seal: exit %d: %s", code, stderr)
+    }
+    if !bytes.HasPrefix(sealed, []byte("-----BEGIN ASCON MESSAGE-----\n")) {
+        t.Fatalf("seal -a did not armor the output: %q", sealed[:40])
+    }
+    if code, opened, stderr := run(t, Ascon, sealed, "open", "-k", testKey, "-a"); code != exitOK || !bytes.Equal(opened, msg) {
+        t.Fatalf("open: exit %d: %s", code, stderr)
+    }
+
+    // A truncated paste fails.
+    lines := bytes.SplitAfter(sealed, []byte("\n"))
+    cut := bytes.Join(append(lines[:len(lines)/2:len(lines)/2], lines[len(lines)-3:]...), nil)
+    if code, _, _ := run(t, Ascon, cut, "open", "-k", testKey, "-a"); code != exitFailure {
+        t.Errorf("truncated armor: exit %d", code)
+    }
+    if code, _, _ := run(t, Ascon, sealed, "open", "-k", testKey); code != exitFailure {
+        t.Errorf("armor without -a: exit %d", code)
+    }
+}
@@ -0,0 +1,104 @@
+// Package cli implements the commands in the cmd directory, so
+// that they can be tested without building and running them.
+//
+// Each command is a function taking its arguments and standard
+// streams, which returns the exit status: 0 on success, 1 on
+// failure, and 2 for a usage error.
+package cli
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+)
+
+// Streams are the standard streams of a command.
+type Streams struct {
+    Stdin          io.Reader
+    Stdout, Stderr io.Writer
+}
+
+// Exit statuses.
+const (
+    exitOK      = 0
+    exitFailure = 1
+    exitUsage   = 2
+)
+
+// newFlagSet returns a flag set that reports errors to stderr
+// and does not exit.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+    fs := flag.NewFlagSet(name, flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    return fs
+}
+
+// fail prints err prefixed by the command name and returns
+// exitFailure.
+func fail(stderr io.Writer, name string, err error) int {
+    fmt.Fprintf(stderr, "%s: %v\n", name, err)
+    return exitFailure
+}
+
+// usageError prints msg and the usage of fs and returns
+// exitUsage.
+func usageError(fs *flag.FlagSet, msg string) int {
+    fmt.Fprintf(fs.Output(), "%s: %s\n", fs.Name(), msg)
+    fs.Usage()
+    return exitUsage
+}
+
+// openInput opens the file name for reading, or returns stdin
+// if name is empty or "-".
+func openInput(name string, stdin io.Reader) (io.ReadCloser, error) {
+    if name == "" || name == "-" {
+        return io.NopCloser(stdin), nil
+    }
+    return os.Open(name)
+}
+
+// output is a file being written, which is removed unless the
+// command succeeds.
+type output struct {
+    io.Writer
+    f *os.File
+}
+
+// createOutput creates the file name for writing, or returns
+// stdout if name is empty or "-". An existing file is only
+// replaced if force is set.
+func createOutput(name string, force bool, stdout io.Writer) (*output, error) {
+    if name == "" || name == "-" {
+        return &output{Writer: stdout}, nil
+    }
+    flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+    if force {
+        flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+    }
+    f, err := os.OpenFile(name, flags, 0o600)
+    if errors.Is(err, os.ErrExist) {
+        return nil, fmt.Errorf("%s already exists, use -f to overwrite it", filepath.Clean(name))
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &output{Writer: f, f: f}, nil
+}
+
+// close closes the file, and removes it if err is not nil. It
+// returns err, or the error of closing the file.
+func (o *output) close(err error) error {
+    if o.f == nil {
+        return err
+    }
+    if cerr := o.f.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        os.Remove(o.f.Name())
+    }
+    return err
+}
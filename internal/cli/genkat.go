@@ -0,0 +1,132 @@
+package cli
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// The largest plaintext and additional data of the known answer
+// tests, MAX_MESSAGE_LENGTH and MAX_ASSOCIATED_DATA_LENGTH in
+// genkat_aead.c of the reference implementation.
+const (
+    katMaxMessage = 32
+    katMaxAD      = 32
+)
+
+// katVariants names the directory of each variant's file and is
+// the order in which they are written.
+var katVariants = []struct {
+    v   ascon.Variant
+    dir string
+}{
+    {ascon.Ascon128, "ascon128"},
+    {ascon.Ascon128a, "ascon128a"},
+    {ascon.Ascon80pq, "ascon80pq"},
+    {ascon.AsconAEAD128, "asconaead128"},
+}
+
+// katFileName returns the name the reference implementation
+// gives to the file of v, such as LWC_AEAD_KAT_128_128.txt for a
+// 128-bit key and nonce.
+func katFileName(v ascon.Variant) string {
+    return fmt.Sprintf("LWC_AEAD_KAT_%d_%d.txt", 8*v.KeySize(), 8*ascon.NonceSize)
+}
+
+// writeKAT writes the known answer tests of v in the format of
+// genkat_aead.c: the key and nonce are 00 01 02 ..., and so are
+// the plaintext and additional data, for every combination of
+// their lengths from 0 to 32, additional data first.
+func writeKAT(w io.Writer, v ascon.Variant) error {
+    key, nonce := katSeq(v.KeySize()), katSeq(ascon.NonceSize)
+    aead, err := ascon.New(key, ascon.WithVariant(v))
+    if err != nil {
+        return err
+    }
+    bw := bufio.NewWriter(w)
+    count := 1
+    for mlen := 0; mlen <= katMaxMessage; mlen++ {
+        for adlen := 0; adlen <= katMaxAD; adlen++ {
+            pt, ad := katSeq(mlen), katSeq(adlen)
+            ct := aead.Seal(nil, nonce, pt, ad)
+            fmt.Fprintf(bw, "Count = %d\nKey = %X\nNonce = %X\nPT = %X\nAD = %X\nCT = %X\n\n",
+                count, key, nonce, pt, ad, ct)
+            count++
+        }
+    }
+    return bw.Flush()
+}
+
+func katSeq(n int) []byte {
+    b := make([]byte, n)
+    for i := range b {
+        b[i] = byte(i)
+    }
+    return b
+}
+
+const genKATUsage = `usage: ascon-genkat [-dir dir]
+       ascon-genkat -variant name
+
+ascon-genkat writes the known answer tests of every AEAD variant
+in the LWC_AEAD_KAT format of the reference implementation, to a
+directory per variant under dir: %s.
+With -variant, it writes those of one variant to the standard
+output.
+`
+
+// GenKAT runs the ascon-genkat command with the arguments args,
+// which do not include the command name.
+func GenKAT(args []string, s Streams) int {
+    var dirs []string
+    for _, kv := range katVariants {
+        dirs = append(dirs, filepath.Join(kv.dir, katFileName(kv.v)))
+    }
+    fs := newFlagSet("ascon-genkat", s.Stderr)
+    fs.Usage = func() { fmt.Fprintf(s.Stderr, genKATUsage, strings.Join(dirs, ", ")) }
+    dir := fs.String("dir", ".", "output `directory`")
+    variant := fs.String("variant", "", "write the tests of one variant")
+    if err := fs.Parse(args); err != nil {
+        return exitUsage
+    }
+    if fs.NArg() > 0 {
+        return usageError(fs, "unexpected arguments")
+    }
+
+    if *variant != "" {
+        v, err := ascon.ParseVariant(*variant)
+        if err != nil {
+            return usageError(fs, err.Error())
+        }
+        if err := writeKAT(s.Stdout, v); err != nil {
+            return fail(s.Stderr, fs.Name(), err)
+        }
+        return exitOK
+    }
+    for _, kv := range katVariants {
+        if err := writeKATFile(filepath.Join(*dir, kv.dir), kv.v); err != nil {
+            return fail(s.Stderr, fs.Name(), err)
+        }
+    }
+    return exitOK
+}
+
+func writeKATFile(dir string, v ascon.Variant) error {
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return err
+    }
+    f, err := os.Create(filepath.Join(dir, katFileName(v)))
+    if err != nil {
+        return err
+    }
+    if err := writeKAT(f, v); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
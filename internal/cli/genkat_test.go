@@ -0,0 +1,45 @@
+package cli
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon/ascontest"
+)
+
+// TestGenKAT checks that the generated files are byte for byte
+// the known answer tests embedded in package ascontest.
+func TestGenKAT(t *testing.T) {
+    dir := t.TempDir()
+    if code, _, stderr := run(t, GenKAT, nil, "-dir", dir); code != exitOK {
+        t.Fatalf("exit %d: %s", code, stderr)
+    }
+    for _, kv := range katVariants {
+        want := ascontest.KAT(ascontest.Variant(kv.v))
+        got, err := os.ReadFile(filepath.Join(dir, kv.dir, katFileName(kv.v)))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Errorf("%v: the generated file differs from the embedded one", kv.v)
+        }
+
+        _, out, _ := run(t, GenKAT, nil, "-variant", kv.v.String())
+        if !bytes.Equal(out, want) {
+            t.Errorf("%v: -variant differs from the embedded file", kv.v)
+        }
+    }
+    if _, err := os.Stat(filepath.Join(dir, "ascon80pq", "LWC_AEAD_KAT_160_128.txt")); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestGenKATUsage(t *testing.T) {
+    for _, args := range [][]string{{"-variant", "AES"}, {"extra"}, {"-bogus"}} {
+        if code, _, stderr := run(t, GenKAT, nil, args...); code != exitUsage || stderr == "" {
+            t.Errorf("%q: exit %d: %s", args, code, stderr)
+        }
+    }
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+    "fmt"
+    "hash"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "encoding/hex"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// algorithm is a hash or XOF of the asconhash command. Exactly
+// one of its fields is set.
+type algorithm struct {
+    hash func() hash.Hash
+    xof  func() *ascon.XOF
+}
+
+var algorithms = map[string]algorithm{
+    "hash":    {hash: ascon.NewHash},
+    "hasha":   {hash: ascon.NewHasha},
+    "hash256": {hash: ascon.NewHash256},
+    "xof":     {xof: ascon.NewXOF},
+    "xofa":    {xof: ascon.NewXOFa},
+    "xof128":  {xof: ascon.NewXOF128},
+}
+
+// defaultXOFSize is the output size of the XOFs without -n.
+const defaultXOFSize = 32
+
+// sum returns the n-byte digest of the data read from r. n is
+// ignored by the hashes. The data goes through the ReadFrom
+// methods of the package, which absorb it without copying it
+// through the hash buffer.
+func (a algorithm) sum(r io.Reader, n int) ([]byte, error) {
+    if a.hash != nil {
+        h := a.hash()
+        if _, err := h.(io.ReaderFrom).ReadFrom(r); err != nil {
+            return nil, err
+        }
+        return h.Sum(nil), nil
+    }
+    x := a.xof()
+    if _, err := x.ReadFrom(r); err != nil {
+        return nil, err
+    }
+    out := make([]byte, n)
+    x.Read(out)
+    return out, nil
+}
+
+func algorithmNames() string {
+    names := make([]string, 0, len(algorithms))
+    for name := range algorithms {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return strings.Join(names, ", ")
+}
+
+const asconHashUsage = `usage: asconhash [-a algorithm] [-n size] [-0] [file ...]
+
+asconhash prints a line with the digest and name of each file,
+in the layout of sha256sum, or of the standard input if there
+are no files or a file is "-". The algorithm is one of
+%s, and defaults to hash256.
+-n sets the output size in bytes of the XOFs, 32 by default.
+With -0, lines end with a NUL byte instead of a newline.
+
+asconhash exits with status 1 if a file cannot be read, after
+printing the digests of the others.
+`
+
+// AsconHash runs the asconhash command with the arguments args,
+// which do not include the command name.
+func AsconHash(args []string, s Streams) int {
+    fs := newFlagSet("asconhash", s.Stderr)
+    fs.Usage = func() { fmt.Fprintf(s.Stderr, asconHashUsage, algorithmNames()) }
+    name := fs.String("a", "hash256", "`algorithm`")
+    size := fs.Int("n", 0, "XOF output `size` in bytes")
+    nul := fs.Bool("0", false, "end lines with a NUL byte")
+    if err := fs.Parse(args); err != nil {
+        return exitUsage
+    }
+    alg, ok := algorithms[*name]
+    if !ok {
+        return usageError(fs, "unknown algorithm "+*name)
+    }
+    switch {
+    case *size < 0:
+        return usageError(fs, "negative output size")
+    case *size != 0 && alg.xof == nil:
+        return usageError(fs, "-n only applies to the XOFs")
+    case *size == 0:
+        *size = defaultXOFSize
+    }
+    end := "\n"
+    if *nul {
+        end = "\x00"
+    }
+
+    files := fs.Args()
+    if len(files) == 0 {
+        files = []string{"-"}
+    }
+    code := exitOK
+    for _, file := range files {
+        digest, err := hashFile(alg, *size, file, s.Stdin)
+        if err != nil {
+            fail(s.Stderr, fs.Name(), err)
+            code = exitFailure
+            continue
+        }
+        if _, err := fmt.Fprintf(s.Stdout, "%s  %s%s", hex.EncodeToString(digest), file, end); err != nil {
+            return fail(s.Stderr, fs.Name(), err)
+        }
+    }
+    return code
+}
+
+func hashFile(alg algorithm, size int, name string, stdin io.Reader) ([]byte, error) {
+    r, err := openInput(name, stdin)
+    if err != nil {
+        return nil, err
+    }
+    defer r.Close()
+    digest, err := alg.sum(r, size)
+    if err != nil {
+        // The errors of an os.File name it, those of the
+        // standard input do not.
+        if _, ok := err.(*os.PathError); !ok {
+            err = fmt.Errorf("%s: %v", name, err)
+        }
+        return nil, err
+    }
+    return digest, nil
+}
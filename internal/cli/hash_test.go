@@ -0,0 +1,114 @@
+package cli
+
+import (
+    "bytes"
+    "encoding/hex"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func TestAsconHash(t *testing.T) {
+    dir := t.TempDir()
+    a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+    msgA, msgB := []byte("abc"), bytes.Repeat([]byte{7}, 100000)
+    if err := os.WriteFile(a, msgA, 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(b, msgB, 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    hash256 := func(msg []byte) string {
+        h := ascon.NewHash256()
+        h.Write(msg)
+        return hex.EncodeToString(h.Sum(nil))
+    }
+    xof128 := func(msg []byte, n int) string {
+        x := ascon.NewXOF128()
+        x.Write(msg)
+        out := make([]byte, n)
+        x.Read(out)
+        return hex.EncodeToString(out)
+    }
+
+    code, out, stderr := run(t, AsconHash, nil, a, b)
+    if code != exitOK {
+        t.Fatalf("exit %d: %s", code, stderr)
+    }
+    if want := hash256(msgA) + "  " + a + "\n" + hash256(msgB) + "  " + b + "\n"; string(out) != want {
+        t.Errorf("expected %q, got %q", want, out)
+    }
+
+    code, out, _ = run(t, AsconHash, msgB, "-a", "xof128", "-n", "64", "-0")
+    if want := xof128(msgB, 64) + "  -\x00"; code != exitOK || string(out) != want {
+        t.Errorf("exit %d: expected %q, got %q", code, want, out)
+    }
+    code, out, _ = run(t, AsconHash, msgA, "-a", "xof128", "-")
+    if want := xof128(msgA, defaultXOFSize) + "  -\n"; code != exitOK || string(out) != want {
+        t.Errorf("exit %d: expected %q, got %q", code, want, out)
+    }
+}
+
+func TestAsconHashAlgorithms(t *testing.T) {
+    msg := []byte("the quick brown fox")
+    hashes := map[string]func() []byte{
+        "hash":    func() []byte { d := ascon.Sum256(msg); return d[:] },
+        "hasha":   func() []byte { d := ascon.SumHasha(msg); return d[:] },
+        "hash256": func() []byte { h := ascon.NewHash256(); h.Write(msg); return h.Sum(nil) },
+        "xof":     func() []byte { return squeeze(ascon.NewXOF(), msg) },
+        "xofa":    func() []byte { return squeeze(ascon.NewXOFa(), msg) },
+        "xof128":  func() []byte { return squeeze(ascon.NewXOF128(), msg) },
+    }
+    if len(hashes) != len(algorithms) {
+        t.Fatalf("%d algorithms, %d tested", len(algorithms), len(hashes))
+    }
+    for name, fn := range hashes {
+        _, out, stderr := run(t, AsconHash, msg, "-a", name)
+        if want := hex.EncodeToString(fn()) + "  -\n"; string(out) != want {
+            t.Errorf("%s: expected %q, got %q%s", name, want, out, stderr)
+        }
+    }
+}
+
+func squeeze(x *ascon.XOF, msg []byte) []byte {
+    x.Write(msg)
+    out := make([]byte, defaultXOFSize)
+    x.Read(out)
+    return out
+}
+
+func TestAsconHashUnreadable(t *testing.T) {
+    dir := t.TempDir()
+    a, missing := filepath.Join(dir, "a"), filepath.Join(dir, "missing")
+    if err := os.WriteFile(a, []byte("abc"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    code, out, stderr := run(t, AsconHash, nil, missing, dir, a)
+    if code != exitFailure {
+        t.Errorf("exit %d", code)
+    }
+    if !strings.Contains(stderr, missing) || !strings.Contains(stderr, dir) {
+        t.Errorf("the errors do not name the files: %s", stderr)
+    }
+    if !strings.HasSuffix(string(out), "  "+a+"\n") || strings.Count(string(out), "\n") != 1 {
+        t.Errorf("the readable file was not hashed: %q", out)
+    }
+}
+
+func TestAsconHashUsage(t *testing.T) {
+    for _, args := range [][]string{
+        {"-a", "sha256"},
+        {"-n", "16"},
+        {"-a", "xof", "-n", "-1"},
+        {"-bogus"},
+    } {
+        if code, _, stderr := run(t, AsconHash, nil, args...); code != exitUsage || stderr == "" {
+            t.Errorf("%q: exit %d: %s", args, code, stderr)
+        }
+    }
+}
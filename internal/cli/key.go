@@ -0,0 +1,41 @@
+package cli
+
+import (
+    "errors"
+    "os"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// keyEnv is the environment variable holding the key when
+// there is no -k flag, so that it need not appear on the
+// command line, where other users can see it.
+const keyEnv = "ASCON_KEY"
+
+const keyHelp = "key in hex or base64, or file:PATH of a file holding it (default $" + keyEnv + ")"
+
+var errNoKey = errors.New("missing key, use -k or $" + keyEnv)
+
+// loadKey parses the key given by spec, the value of a -k flag,
+// or by the keyEnv variable if spec is empty, with
+// ascon.ParseKey. The key must have the size of one of
+// variants, which are tried in turn.
+func loadKey(spec string, variants ...ascon.Variant) ([]byte, error) {
+    if spec == "" {
+        spec = os.Getenv(keyEnv)
+    }
+    if spec == "" {
+        return nil, errNoKey
+    }
+    var first error
+    for _, v := range variants {
+        key, err := ascon.ParseKey(spec, v)
+        if err == nil {
+            return key, nil
+        }
+        if first == nil {
+            first = err
+        }
+    }
+    return nil, first
+}
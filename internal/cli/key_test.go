@@ -0,0 +1,80 @@
+package cli
+
+import (
+    "bytes"
+    "encoding/base64"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func TestLoadKey(t *testing.T) {
+    want := bytes.Repeat([]byte{0x42}, ascon.KeySize)
+    file := filepath.Join(t.TempDir(), "key")
+    if err := os.WriteFile(file, []byte("  "+strings.ToUpper(testKey)+"\n"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv(keyEnv, testKey)
+    for _, spec := range []string{testKey, base64.StdEncoding.EncodeToString(want), "file:" + file, ""} {
+        if key, err := loadKey(spec, ascon.AsconAEAD128); err != nil || !bytes.Equal(key, want) {
+            t.Errorf("%q: got %x, %v", spec, key, err)
+        }
+    }
+
+    long := testKey + "42424242"
+    if key, err := loadKey(long, ascon.AsconAEAD128, ascon.Ascon80pq); err != nil || len(key) != ascon.KeySize80pq {
+        t.Errorf("a 20-byte key: got %x, %v", key, err)
+    }
+    if _, err := loadKey(long, ascon.AsconAEAD128); err != ascon.KeySizeError(ascon.KeySize80pq) {
+        t.Errorf("a 20-byte key for Ascon-AEAD128: got %v", err)
+    }
+
+    t.Setenv(keyEnv, "")
+    if _, err := loadKey("", ascon.AsconAEAD128); err != errNoKey {
+        t.Errorf("no key: got %v", err)
+    }
+    if _, err := loadKey("file:"+file+".missing", ascon.AsconAEAD128); err == nil {
+        t.Errorf("a missing file was accepted")
+    }
+    secret := "00112233445566778899aabbccddeefg"
+    if err := os.WriteFile(file, []byte(secret), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    for _, spec := range []string{secret, "file:" + file} {
+        _, err := loadKey(spec, ascon.AsconAEAD128)
+        if err == nil || strings.Contains(err.Error(), secret[:8]) {
+            t.Errorf("%q: got %v", spec, err)
+        }
+    }
+}
+
+func TestAsconKeyEnv(t *testing.T) {
+    t.Setenv(keyEnv, testKey)
+    _, sealed, _ := run(t, Ascon, []byte("hello"), "seal")
+    if code, out, stderr := run(t, Ascon, sealed, "open"); code != exitOK || string(out) != "hello" {
+        t.Errorf("exit %d: %q%s", code, out, stderr)
+    }
+}
+
+func TestAsconKeygen(t *testing.T) {
+    for _, v := range []ascon.Variant{ascon.AsconAEAD128, ascon.Ascon80pq} {
+        code, out, stderr := run(t, Ascon, nil, "keygen", "-variant", v.String())
+        if code != exitOK {
+            t.Fatalf("exit %d: %s", code, stderr)
+        }
+        key := strings.TrimSuffix(string(out), "\n")
+        if k, err := ascon.ParseKey(key, v); err != nil || len(k) != v.KeySize() {
+            t.Fatalf("%v: got %q, %v", v, out, err)
+        }
+        _, sealed, _ := run(t, Ascon, []byte("msg"), "seal", "-k", key, "-variant", v.String())
+        if code, out, _ := run(t, Ascon, sealed, "open", "-k", key); code != exitOK || string(out) != "msg" {
+            t.Errorf("%v: the generated key does not work: exit %d", v, code)
+        }
+    }
+    if code, _, _ := run(t, Ascon, nil, "keygen", "-variant", "AES"); code != exitUsage {
+        t.Errorf("unknown variant: exit %d", code)
+    }
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "crypto/subtle"
+    "encoding/hex"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+var errTagMismatch = errors.New("the tag does not match")
+
+// macSum returns the size-byte Ascon-Mac tag, truncated, or
+// Ascon-Prf output if prf is set, of the data read from r.
+func macSum(key []byte, prf bool, size int, r io.Reader) ([]byte, error) {
+    if prf {
+        p, err := ascon.NewPRF(key)
+        if err != nil {
+            return nil, err
+        }
+        if _, err := io.Copy(p, r); err != nil {
+            return nil, err
+        }
+        out := make([]byte, size)
+        p.Read(out)
+        return out, nil
+    }
+    h, err := ascon.NewMAC(key)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := io.Copy(h, r); err != nil {
+        return nil, err
+    }
+    return h.Sum(nil)[:size], nil
+}
+
+const asconMACUsage = `usage: asconmac [-k key] [-t size] [-prf] [file ...]
+       asconmac [-k key] [-prf] -verify tag [file]
+
+asconmac prints a line with the Ascon-Mac tag and name of each
+file, in the layout of sha256sum, or of the standard input if
+there are no files or a file is "-". The key is in hexadecimal
+or base64, or in the file named by -k file:PATH, or in
+$ASCON_KEY without -k. -t truncates the tags to size bytes, from %d to %d; with
+-prf, they are Ascon-Prf outputs of any size from %d.
+
+With -verify, asconmac checks that tag, in hexadecimal, is the
+tag of the file, and exits with status 0 if it is and 1 if not.
+The tag sets the size, and the comparison is constant-time.
+`
+
+// AsconMAC runs the asconmac command with the arguments args,
+// which do not include the command name.
+func AsconMAC(args []string, s Streams) int {
+    fs := newFlagSet("asconmac", s.Stderr)
+    fs.Usage = func() {
+        fmt.Fprintf(s.Stderr, asconMACUsage, ascon.MinMACSize, ascon.MACSize, ascon.MinMACSize)
+    }
+    keySpec := fs.String("k", "", keyHelp)
+    size := fs.Int("t", 0, "tag `size` in bytes (default 16)")
+    prf := fs.Bool("prf", false, "compute Ascon-Prf outputs")
+    verify := fs.String("verify", "", "check the `tag` of the input")
+    if err := fs.Parse(args); err != nil {
+        return exitUsage
+    }
+    // Ascon-Mac has the 16-byte keys of Ascon-AEAD128.
+    key, err := loadKey(*keySpec, ascon.AsconAEAD128)
+    if err != nil {
+        return usageError(fs, err.Error())
+    }
+    var tag []byte
+    if *verify != "" {
+        if tag, err = hex.DecodeString(*verify); err != nil {
+            return usageError(fs, "the tag is not hexadecimal")
+        }
+        if *size != 0 && *size != len(tag) {
+            return usageError(fs, "-t does not match the size of the tag")
+        }
+        if fs.NArg() > 1 {
+            return usageError(fs, "-verify takes a single file")
+        }
+        *size = len(tag)
+    }
+    if *size == 0 {
+        *size = ascon.MACSize
+    }
+    if *size < ascon.MinMACSize || (!*prf && *size > ascon.MACSize) {
+        return usageError(fs, fmt.Sprintf("invalid tag size %d", *size))
+    }
+
+    files := fs.Args()
+    if len(files) == 0 {
+        files = []string{"-"}
+    }
+    code := exitOK
+    for _, file := range files {
+        sum, err := macFile(key, *prf, *size, file, s.Stdin)
+        if err != nil {
+            fail(s.Stderr, fs.Name(), err)
+            code = exitFailure
+            continue
+        }
+        if tag != nil {
+            if subtle.ConstantTimeCompare(sum, tag) != 1 {
+                return fail(s.Stderr, fs.Name(), errTagMismatch)
+            }
+            continue
+        }
+        if _, err := fmt.Fprintf(s.Stdout, "%s  %s\n", hex.EncodeToString(sum), file); err != nil {
+            return fail(s.Stderr, fs.Name(), err)
+        }
+    }
+    return code
+}
+
+func macFile(key []byte, prf bool, size int, name string, stdin io.Reader) ([]byte, error) {
+    r, err := openInput(name, stdin)
+    if err != nil {
+        return nil, err
+    }
+    defer r.Close()
+    return macSum(key, prf, size, r)
+}
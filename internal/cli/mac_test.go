@@ -0,0 +1,84 @@
+package cli
+
+import (
+    "bytes"
+    "encoding/hex"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func TestAsconMAC(t *testing.T) {
+    key := bytes.Repeat([]byte{0x42}, ascon.KeySize)
+    msg := bytes.Repeat([]byte("firmware"), 10000)
+    tag := ascon.MAC(key, msg)
+
+    code, out, stderr := run(t, AsconMAC, msg, "-k", testKey)
+    if want := hex.EncodeToString(tag[:]) + "  -\n"; code != exitOK || string(out) != want {
+        t.Fatalf("exit %d: expected %q, got %q%s", code, want, out, stderr)
+    }
+    _, out, _ = run(t, AsconMAC, msg, "-k", testKey, "-t", "8")
+    if want := hex.EncodeToString(tag[:8]) + "  -\n"; string(out) != want {
+        t.Errorf("-t 8: expected %q, got %q", want, out)
+    }
+
+    prf, _ := ascon.NewPRF(key)
+    prf.Write(msg)
+    want := make([]byte, 40)
+    prf.Read(want)
+    _, out, _ = run(t, AsconMAC, msg, "-k", testKey, "-prf", "-t", "40")
+    if string(out) != hex.EncodeToString(want)+"  -\n" {
+        t.Errorf("-prf: expected %x, got %q", want, out)
+    }
+}
+
+func TestAsconMACVerify(t *testing.T) {
+    key := bytes.Repeat([]byte{0x42}, ascon.KeySize)
+    msg := []byte("firmware image")
+    tag := ascon.MAC(key, msg)
+    file := filepath.Join(t.TempDir(), "image")
+    if err := os.WriteFile(file, msg, 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    for _, n := range []int{ascon.MACSize, ascon.MinMACSize} {
+        good := hex.EncodeToString(tag[:n])
+        if code, out, stderr := run(t, AsconMAC, nil, "-k", testKey, "-verify", good, file); code != exitOK || len(out) != 0 {
+            t.Errorf("%d bytes: exit %d: %q%s", n, code, out, stderr)
+        }
+        bad := append([]byte(nil), tag[:n]...)
+        bad[n-1] ^= 1
+        if code, _, stderr := run(t, AsconMAC, nil, "-k", testKey, "-verify", hex.EncodeToString(bad), file); code != exitFailure || stderr == "" {
+            t.Errorf("%d bytes: a wrong tag: exit %d", n, code)
+        }
+    }
+    if code, _, _ := run(t, AsconMAC, msg[1:], "-k", testKey, "-verify", hex.EncodeToString(tag[:])); code != exitFailure {
+        t.Errorf("another message: exit %d", code)
+    }
+    if code, _, _ := run(t, AsconMAC, nil, "-k", testKey, "-verify", hex.EncodeToString(tag[:]), file+".missing"); code != exitFailure {
+        t.Errorf("a missing file: exit %d", code)
+    }
+}
+
+func TestAsconMACUsage(t *testing.T) {
+    t.Setenv(keyEnv, "")
+    tag := strings.Repeat("00", ascon.MACSize)
+    for _, args := range [][]string{
+        nil,
+        {"-k", testKey + "00"},
+        {"-k", testKey, "-t", "7"},
+        {"-k", testKey, "-t", "17"},
+        {"-k", testKey, "-prf", "-t", "4"},
+        {"-k", testKey, "-verify", "xyz"},
+        {"-k", testKey, "-verify", tag[:14]},
+        {"-k", testKey, "-verify", tag, "-t", "8"},
+        {"-k", testKey, "-verify", tag, "a", "b"},
+    } {
+        if code, _, stderr := run(t, AsconMAC, nil, args...); code != exitUsage || stderr == "" {
+            t.Errorf("%q: exit %d: %s", args, code, stderr)
+        }
+    }
+}
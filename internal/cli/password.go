@@ -0,0 +1,99 @@
+package cli
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "os/exec"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// passwordEnv is the environment variable holding the password
+// of -p, for scripts. Without it, the password is read from the
+// terminal.
+const passwordEnv = "ASCON_PASSWORD"
+
+var errPasswordMismatch = errors.New("the passwords do not match")
+
+// readPassword prints prompt to the terminal and reads a line
+// from it without echoing it. It is replaced in tests.
+var readPassword = func(prompt string) ([]byte, error) {
+    tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+    if err != nil {
+        return nil, fmt.Errorf("no terminal to read the password from, set $%s", passwordEnv)
+    }
+    defer tty.Close()
+    // Turn off echo with stty, which works on every Unix
+    // without terminal ioctls. If it fails, the password is
+    // echoed rather than not read at all.
+    stty := func(arg string) {
+        cmd := exec.Command("stty", arg)
+        cmd.Stdin = tty
+        cmd.Run()
+    }
+    stty("-echo")
+    defer stty("echo")
+
+    fmt.Fprint(tty, prompt)
+    line, err := bufio.NewReader(tty).ReadBytes('\n')
+    fmt.Fprintln(tty)
+    if err != nil && (err != io.EOF || len(line) == 0) {
+        return nil, err
+    }
+    return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// loadPassword returns the password of passwordEnv, or reads it
+// from the terminal, twice if confirm is set.
+func loadPassword(confirm bool) ([]byte, error) {
+    if pw := os.Getenv(passwordEnv); pw != "" {
+        return []byte(pw), nil
+    }
+    pw, err := readPassword("Password: ")
+    if err != nil {
+        return nil, err
+    }
+    if confirm {
+        again, err := readPassword("Repeat the password: ")
+        if err != nil {
+            return nil, err
+        }
+        if !bytes.Equal(pw, again) {
+            return nil, errPasswordMismatch
+        }
+    }
+    return pw, nil
+}
+
+// encryptPassword encrypts everything read from src with
+// ascon.EncryptWithPassword, which holds it in memory.
+func encryptPassword(dst io.Writer, src io.Reader, password []byte) error {
+    pt, err := io.ReadAll(src)
+    if err != nil {
+        return err
+    }
+    blob, err := ascon.EncryptWithPassword(password, pt, nil)
+    if err != nil {
+        return err
+    }
+    _, err = dst.Write(blob)
+    return err
+}
+
+// decryptPassword opens a message of encryptPassword.
+func decryptPassword(dst io.Writer, src io.Reader, password []byte) error {
+    blob, err := io.ReadAll(src)
+    if err != nil {
+        return err
+    }
+    pt, err := ascon.DecryptWithPassword(password, blob)
+    if err != nil {
+        return err
+    }
+    _, err = dst.Write(pt)
+    return err
+}
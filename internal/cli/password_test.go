@@ -0,0 +1,72 @@
+package cli
+
+import (
+    "bytes"
+    "errors"
+    "testing"
+)
+
+func TestAsconPassword(t *testing.T) {
+    t.Setenv(passwordEnv, "correct horse")
+    msg := []byte("a secret")
+    for _, armor := range [][]string{nil, {"-a"}} {
+        code, sealed, stderr := run(t, Ascon, msg, append([]string{"seal", "-p"}, armor...)...)
+        if code != exitOK {
+            t.Fatalf("seal: exit %d: %s", code, stderr)
+        }
+        code, opened, stderr := run(t, Ascon, sealed, append([]string{"open", "-p"}, armor...)...)
+        if code != exitOK || !bytes.Equal(opened, msg) {
+            t.Fatalf("open: exit %d: %q%s", code, opened, stderr)
+        }
+        if len(armor) > 0 {
+            continue
+        }
+
+        t.Setenv(passwordEnv, "wrong horse")
+        if code, _, stderr := run(t, Ascon, sealed, "open", "-p"); code != exitFailure {
+            t.Errorf("wrong password: exit %d: %s", code, stderr)
+        }
+        t.Setenv(passwordEnv, "correct horse")
+    }
+
+    for _, args := range [][]string{
+        {"seal", "-p", "-k", testKey},
+        {"seal", "-p", "-variant", "Ascon-128"},
+    } {
+        if code, _, _ := run(t, Ascon, msg, args...); code != exitUsage {
+            t.Errorf("%q: exit %d", args, code)
+        }
+    }
+}
+
+func TestAsconPasswordPrompt(t *testing.T) {
+    t.Setenv(passwordEnv, "")
+    var answers []string
+    var prompts int
+    defer func(fn func(string) ([]byte, error)) { readPassword = fn }(readPassword)
+    readPassword = func(string) ([]byte, error) {
+        if prompts == len(answers) {
+            return nil, errors.New("no terminal")
+        }
+        prompts++
+        return []byte(answers[prompts-1]), nil
+    }
+
+    answers, prompts = []string{"pw", "pw"}, 0
+    code, sealed, stderr := run(t, Ascon, []byte("msg"), "seal", "-p")
+    if code != exitOK || prompts != 2 {
+        t.Fatalf("seal: exit %d after %d prompts: %s", code, prompts, stderr)
+    }
+    answers, prompts = []string{"pw"}, 0
+    if code, out, stderr := run(t, Ascon, sealed, "open", "-p"); code != exitOK || string(out) != "msg" || prompts != 1 {
+        t.Errorf("open: exit %d after %d prompts: %q%s", code, prompts, out, stderr)
+    }
+    answers, prompts = []string{"pw", "wp"}, 0
+    if code, _, stderr := run(t, Ascon, []byte("msg"), "seal", "-p"); code != exitFailure {
+        t.Errorf("mismatched passwords: exit %d: %s", code, stderr)
+    }
+    answers, prompts = nil, 0
+    if code, _, stderr := run(t, Ascon, []byte("msg"), "seal", "-p"); code != exitFailure {
+        t.Errorf("no terminal: exit %d: %s", code, stderr)
+    }
+}
@@ -0,0 +1,140 @@
+// Package merkle implements the Ascon-Hash256 Merkle tree of the
+// treehash and manifest packages.
+//
+// The tree is defined as follows, where H is Ascon-Hash256 and
+// BE64 is the 8-byte big-endian encoding of an integer:
+//
+//  1. Leaf i, counting from zero, with the data d hashes to
+//     H(0x00 || BE64(i) || d).
+//  2. Each level of the tree is built from the one below it by
+//     replacing every pair of adjacent nodes, from left to
+//     right, with H(0x01 || left || right). An odd node at the
+//     end of a level is carried up unchanged. This repeats
+//     until a single node remains, the root. Without leaves,
+//     the root is 32 zero bytes.
+//  3. The digest is H(0x02 || BE64(p1) || ... || BE64(pk) || root),
+//     where the parameters p1, ..., pk frame the tree and are
+//     chosen by its user: treehash uses the leaf size and the
+//     length of the input, manifest the number of entries.
+//
+// The leaf index and the framing ensure that leaves cannot be
+// reordered, truncated or confused with interior nodes.
+package merkle
+
+import (
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// Size is the size in bytes of a node.
+const Size = ascon.HashSize
+
+// Domain separation prefixes.
+const (
+    prefixLeaf   = 0x00
+    prefixParent = 0x01
+    prefixDigest = 0x02
+)
+
+// Node is the hash of a leaf or of an interior node.
+type Node [Size]byte
+
+// Leaf returns the hash of leaf i with the data data.
+func Leaf(i uint64, data []byte) Node {
+    var hdr [9]byte
+    hdr[0] = prefixLeaf
+    binary.BigEndian.PutUint64(hdr[1:], i)
+    h := ascon.NewHash256()
+    h.Write(hdr[:])
+    h.Write(data)
+    var n Node
+    h.Sum(n[:0])
+    return n
+}
+
+// Parent returns the hash of the interior node of left and
+// right.
+func Parent(left, right *Node) Node {
+    h := ascon.NewHash256()
+    h.Write([]byte{prefixParent})
+    h.Write(left[:])
+    h.Write(right[:])
+    var n Node
+    h.Sum(n[:0])
+    return n
+}
+
+// Digest returns the digest of the tree of root framed by params.
+func Digest(root *Node, params ...uint64) [Size]byte {
+    h := ascon.NewHash256()
+    h.Write([]byte{prefixDigest})
+    var buf [8]byte
+    for _, p := range params {
+        binary.BigEndian.PutUint64(buf[:], p)
+        h.Write(buf[:])
+    }
+    h.Write(root[:])
+    var d [Size]byte
+    h.Sum(d[:0])
+    return d
+}
+
+// subtree is a complete subtree of 2^height leaves.
+type subtree struct {
+    h      Node
+    height int
+}
+
+// Tree computes the root of a tree from its leaf hashes, in
+// order, in memory logarithmic in their number. Its zero value
+// is a tree without leaves.
+type Tree struct {
+    // n is the number of leaves pushed.
+    n uint64
+    // stack holds the roots of the complete subtrees of the
+    // leaves pushed, of strictly decreasing height.
+    stack []subtree
+}
+
+// Push appends the leaf hash leaf, which is the hash of leaf
+// Len(), to t.
+func (t *Tree) Push(leaf Node) {
+    s := subtree{h: leaf}
+    for len(t.stack) > 0 && t.stack[len(t.stack)-1].height == s.height {
+        top := t.stack[len(t.stack)-1]
+        t.stack = t.stack[:len(t.stack)-1]
+        s = subtree{h: Parent(&top.h, &s.h), height: s.height + 1}
+    }
+    t.stack = append(t.stack, s)
+    t.n++
+}
+
+// Len returns the number of leaves of t.
+func (t *Tree) Len() uint64 {
+    return t.n
+}
+
+// Root returns the root of t. t can still be pushed to.
+func (t *Tree) Root() Node {
+    if len(t.stack) == 0 {
+        return Node{}
+    }
+    root := t.stack[len(t.stack)-1].h
+    for i := len(t.stack) - 2; i >= 0; i-- {
+        root = Parent(&t.stack[i].h, &root)
+    }
+    return root
+}
+
+// Clone returns a copy of t, which can be pushed to without
+// changing t.
+func (t *Tree) Clone() *Tree {
+    return &Tree{n: t.n, stack: append([]subtree(nil), t.stack...)}
+}
+
+// Reset removes the leaves of t.
+func (t *Tree) Reset() {
+    t.n = 0
+    t.stack = t.stack[:0]
+}
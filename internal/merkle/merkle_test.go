@@ -0,0 +1,105 @@
+package merkle
+
+import (
+    "encoding/binary"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func h(parts ...[]byte) [Size]byte {
+    d := ascon.NewHash256()
+    for _, p := range parts {
+        d.Write(p)
+    }
+    var out [Size]byte
+    d.Sum(out[:0])
+    return out
+}
+
+// reference computes the digest of the leaves as specified in the
+// package documentation, level by level.
+func reference(leaves [][]byte, params ...uint64) [Size]byte {
+    var level [][Size]byte
+    for i, d := range leaves {
+        level = append(level, h([]byte{0x00}, binary.BigEndian.AppendUint64(nil, uint64(i)), d))
+    }
+    for len(level) > 1 {
+        var next [][Size]byte
+        for i := 0; i < len(level); i += 2 {
+            if i+1 == len(level) {
+                next = append(next, level[i])
+            } else {
+                next = append(next, h([]byte{0x01}, level[i][:], level[i+1][:]))
+            }
+        }
+        level = next
+    }
+    var root [Size]byte
+    if len(level) > 0 {
+        root = level[0]
+    }
+    framing := []byte{0x02}
+    for _, p := range params {
+        framing = binary.BigEndian.AppendUint64(framing, p)
+    }
+    return h(framing, root[:])
+}
+
+func TestTree(t *testing.T) {
+    var tree Tree
+    var leaves [][]byte
+    digests := map[[Size]byte]bool{}
+    for n := 0; n <= 33; n++ {
+        if n > 0 {
+            d := []byte{byte(n)}
+            leaves = append(leaves, d)
+            tree.Push(Leaf(uint64(n-1), d))
+        }
+        if tree.Len() != uint64(n) {
+            t.Fatalf("%d leaves: Len is %d", n, tree.Len())
+        }
+        root := tree.Root()
+        d := Digest(&root, uint64(n))
+        if d != reference(leaves, uint64(n)) {
+            t.Fatalf("%d leaves: digest differs from the reference", n)
+        }
+        if digests[d] {
+            t.Fatalf("%d leaves: repeated digest", n)
+        }
+        digests[d] = true
+    }
+
+    // A clone does not share the leaves pushed after it.
+    c := tree.Clone()
+    before := tree.Root()
+    c.Push(Leaf(c.Len(), nil))
+    if tree.Root() != before || c.Root() == before || tree.Len() == c.Len() {
+        t.Fatal("Clone shares its leaves with the tree")
+    }
+
+    tree.Reset()
+    if tree.Len() != 0 || tree.Root() != (Node{}) {
+        t.Fatal("Reset left leaves in the tree")
+    }
+}
+
+func TestDigest(t *testing.T) {
+    var root Node
+    root[0] = 1
+    seen := map[[Size]byte]bool{}
+    for _, params := range [][]uint64{nil, {0}, {1}, {1, 0}, {0, 1}} {
+        d := Digest(&root, params...)
+        framing := []byte{0x02}
+        for _, p := range params {
+            framing = binary.BigEndian.AppendUint64(framing, p)
+        }
+        if d != h(framing, root[:]) {
+            t.Fatalf("%v: digest differs from the reference", params)
+        }
+        if seen[d] {
+            t.Fatalf("%v: repeated digest", params)
+        }
+        seen[d] = true
+    }
+}
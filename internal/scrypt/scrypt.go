@@ -0,0 +1,146 @@
+// Package scrypt implements the scrypt password-based key
+// derivation function of RFC 7914, so that the module does not
+// depend on golang.org/x/crypto.
+//
+// References:
+//
+//    [rfc7914]: https://www.rfc-editor.org/rfc/rfc7914
+//
+package scrypt
+
+import (
+    "errors"
+    "math/bits"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/binary"
+)
+
+// Key derives a key of keyLen bytes from the password and salt
+// with the CPU/memory cost N, a power of two greater than 1,
+// the block size r and the parallelization p. It uses 128*r*N
+// bytes of memory.
+func Key(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+    if N <= 1 || N&(N-1) != 0 {
+        return nil, errors.New("scrypt: N must be a power of two greater than 1")
+    }
+    if r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 || r > maxInt/128/p || r > maxInt/256 || N > maxInt/128/r {
+        return nil, errors.New("scrypt: parameters are too large")
+    }
+    if keyLen <= 0 {
+        return nil, errors.New("scrypt: bad key length")
+    }
+
+    b := pbkdf2(password, salt, p*128*r)
+    xy := make([]uint32, 64*r)
+    v := make([]uint32, 32*N*r)
+    for i := 0; i < p; i++ {
+        romix(b[i*128*r:], r, N, v, xy)
+    }
+    return pbkdf2(password, b, keyLen), nil
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// pbkdf2 returns keyLen bytes of PBKDF2-HMAC-SHA256 with a
+// single iteration, the only count scrypt uses.
+func pbkdf2(password, salt []byte, keyLen int) []byte {
+    prf := hmac.New(sha256.New, password)
+    out := make([]byte, 0, keyLen+sha256.Size)
+    var ctr [4]byte
+    for block := uint32(1); len(out) < keyLen; block++ {
+        prf.Reset()
+        prf.Write(salt)
+        binary.BigEndian.PutUint32(ctr[:], block)
+        prf.Write(ctr[:])
+        out = prf.Sum(out)
+    }
+    return out[:keyLen]
+}
+
+// romix is scryptROMix, applied in place to the 128*r bytes of
+// b, with v and xy as scratch space.
+func romix(b []byte, r, N int, v, xy []uint32) {
+    x, y := xy[:32*r], xy[32*r:]
+    for i := range x {
+        x[i] = binary.LittleEndian.Uint32(b[4*i:])
+    }
+    for i := 0; i < N; i++ {
+        copy(v[i*32*r:], x)
+        blockMix(x, y, r)
+        x, y = y, x
+    }
+    for i := 0; i < N; i++ {
+        // integerify: the first word of the last 64-byte block.
+        j := int(x[(2*r-1)*16] & uint32(N-1))
+        vj := v[j*32*r : (j+1)*32*r]
+        for k := range x {
+            x[k] ^= vj[k]
+        }
+        blockMix(x, y, r)
+        x, y = y, x
+    }
+    for i, w := range x {
+        binary.LittleEndian.PutUint32(b[4*i:], w)
+    }
+}
+
+// blockMix is scryptBlockMix: it writes to out the mix of the
+// 2*r 64-byte blocks of in.
+func blockMix(in, out []uint32, r int) {
+    var t [16]uint32
+    copy(t[:], in[(2*r-1)*16:])
+    for i := 0; i < 2*r; i++ {
+        for k := range t {
+            t[k] ^= in[i*16+k]
+        }
+        salsa208(&t)
+        // Even blocks go to the first half of out, odd ones to
+        // the second.
+        copy(out[(i/2+(i&1)*r)*16:], t[:])
+    }
+}
+
+// salsa208 applies the Salsa20/8 core to b.
+func salsa208(b *[16]uint32) {
+    x := *b
+    for i := 0; i < 8; i += 2 {
+        // Columns.
+        x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+        x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+        x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+        x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+        x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+        x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+        x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+        x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+        x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+        x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+        x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+        x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+        x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+        x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+        x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+        x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+        // Rows.
+        x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+        x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+        x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+        x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+        x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+        x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+        x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+        x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+        x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+        x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+        x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+        x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+        x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+        x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+        x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+        x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+    }
+    for i := range b {
+        b[i] += x[i]
+    }
+}
@@ -0,0 +1,74 @@
+package scrypt
+
+import (
+    "bytes"
+    "encoding/hex"
+    "strings"
+    "testing"
+)
+
+// The test vectors of RFC 7914, section 12, except the last,
+// which needs 1 GiB of memory.
+var vectors = []struct {
+    password, salt string
+    N, r, p        int
+    key            string
+}{
+    {"", "", 16, 1, 1, `
+        77 d6 57 62 38 65 7b 20 3b 19 ca 42 c1 8a 04 97
+        f1 6b 48 44 e3 07 4a e8 df df fa 3f ed e2 14 42
+        fc d0 06 9d ed 09 48 f8 32 6a 75 3a 0f c8 1f 17
+        e8 d3 e0 fb 2e 0d 36 28 cf 35 e2 0c 38 d1 89 06`},
+    {"password", "NaCl", 1024, 8, 16, `
+        fd ba be 1c 9d 34 72 00 78 56 e7 19 0d 01 e9 fe
+        7c 6a d7 cb c8 23 78 30 e7 73 76 63 4b 37 31 62
+        2e af 30 d9 2e 22 a3 88 6f f1 09 27 9d 98 30 da
+        c7 27 af b9 4a 83 ee 6d 83 60 cb df a2 cc 06 40`},
+    {"pleaseletmein", "SodiumChloride", 16384, 8, 1, `
+        70 23 bd cb 3a fd 73 48 46 1c 06 cd 81 fd 38 eb
+        fd a8 fb ba 90 4f 8e 3e a9 b5 43 f6 54 5d a1 f2
+        d5 43 29 55 61 3f 0f cf 62 d4 97 05 24 2a 9a f9
+        e6 1e 85 dc 0d 65 1e 40 df cf 01 7b 45 57 58 87`},
+}
+
+func TestVectors(t *testing.T) {
+    for _, v := range vectors {
+        want, err := hex.DecodeString(strings.Join(strings.Fields(v.key), ""))
+        if err != nil {
+            t.Fatal(err)
+        }
+        got, err := Key([]byte(v.password), []byte(v.salt), v.N, v.r, v.p, len(want))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Errorf("N=%d, r=%d, p=%d: expected %x, got %x", v.N, v.r, v.p, want, got)
+        }
+    }
+}
+
+// TestPBKDF2 checks the PBKDF2-HMAC-SHA256 vector of RFC 7914,
+// section 11, which has one iteration.
+func TestPBKDF2(t *testing.T) {
+    want, _ := hex.DecodeString("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+        "49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+    if got := pbkdf2([]byte("passwd"), []byte("salt"), len(want)); !bytes.Equal(got, want) {
+        t.Errorf("expected %x, got %x", want, got)
+    }
+}
+
+func TestBadParameters(t *testing.T) {
+    for _, p := range [][4]int{
+        {0, 1, 1, 32},
+        {1, 1, 1, 32},
+        {3, 1, 1, 32},
+        {16, 0, 1, 32},
+        {16, 1, 0, 32},
+        {16, 1 << 15, 1 << 15, 32},
+        {16, 1, 1, 0},
+    } {
+        if _, err := Key(nil, nil, p[0], p[1], p[2], p[3]); err == nil {
+            t.Errorf("N=%d, r=%d, p=%d, keyLen=%d: accepted", p[0], p[1], p[2], p[3])
+        }
+    }
+}
@@ -0,0 +1,252 @@
+package ascon
+
+import (
+    "errors"
+    "io"
+    "crypto/cipher"
+)
+
+// streamBufSize is the size of the internal buffers used by
+// EncryptWriter and DecryptReader.
+const streamBufSize = 4096
+
+// EncryptWriter encrypts a single message as it is written.
+//
+// The ciphertext written to the underlying io.Writer, followed
+// by the authenticator written by Close, is identical to the
+// output of Seal for the same nonce, additional data and
+// plaintext.
+type EncryptWriter struct {
+    a   *AEAD
+    s   state
+    w   io.Writer
+    buf [BlockSize128a]byte
+    n   int
+    out []byte
+    err error
+    // total is the number of plaintext bytes written.
+    total uint64
+    // max is the limit on total, MaxPlaintextSize outside of
+    // tests.
+    max uint64
+}
+
+// NewEncryptWriter returns an EncryptWriter that seals a single
+// message with aead, which must have been created by New128,
+// New128a, New80pq, NewAEAD128 or one of their truncated tag
+// variants.
+//
+// The nonce and additional data are used exactly as in Seal.
+// Like Seal, the writer refuses additional data longer than
+// MaxAADSize and, in Write, a plaintext longer than
+// MaxPlaintextSize, with ErrMessageTooLarge.
+func NewEncryptWriter(aead cipher.AEAD, nonce, additionalData []byte, w io.Writer) (*EncryptWriter, error) {
+    a, err := asASCON(aead, nonce)
+    if err != nil {
+        return nil, err
+    }
+    if tooLarge(0, uint64(len(additionalData))) {
+        return nil, ErrMessageTooLarge
+    }
+
+    e := &EncryptWriter{a: a, w: w, max: MaxPlaintextSize}
+    a.init(&e.s, nonce)
+    a.additionalData(&e.s, additionalData)
+    return e, nil
+}
+
+// Write encrypts p and writes the ciphertext to the underlying
+// io.Writer. Up to one block of plaintext is buffered until
+// the next Write or Close.
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+    if e.err != nil {
+        return 0, e.err
+    }
+
+    if e.total+uint64(len(p)) > e.max {
+        return 0, ErrMessageTooLarge
+    }
+    e.total += uint64(len(p))
+
+    total := len(p)
+    bs := e.a.blockSize()
+    if e.n > 0 {
+        n := copy(e.buf[e.n:bs], p)
+        e.n += n
+        p = p[n:]
+        if e.n < bs {
+            return total, nil
+        }
+        e.a.encryptBlocks(&e.s, e.buf[:bs], e.buf[:bs])
+        e.n = 0
+        if err := e.write(e.buf[:bs]); err != nil {
+            return total - len(p), err
+        }
+    }
+
+    if e.out == nil && len(p) >= bs {
+        e.out = make([]byte, streamBufSize)
+    }
+    for len(p) >= bs {
+        n := len(p) &^ (bs - 1)
+        if n > len(e.out) {
+            n = len(e.out)
+        }
+        e.a.encryptBlocks(&e.s, e.out[:n], p[:n])
+        p = p[n:]
+        if err := e.write(e.out[:n]); err != nil {
+            return total - len(p), err
+        }
+    }
+    e.n = copy(e.buf[:], p)
+
+    return total, nil
+}
+
+// Close encrypts any buffered plaintext and writes the final
+// ciphertext block and the authenticator. It does not close
+// the underlying io.Writer.
+func (e *EncryptWriter) Close() error {
+    if e.err != nil {
+        if e.err == errWriterClosed {
+            return nil
+        }
+        return e.err
+    }
+    if e.a.iv == 0 {
+        e.err = ErrWiped
+        return e.err
+    }
+
+    var out [BlockSize128a + TagSize]byte
+    n := e.n
+    m, err := sealedLen(n, e.a.tagSize, e.max)
+    if err != nil {
+        e.err = err
+        return err
+    }
+    e.a.sealMessage(&e.s, out[:n], out[n:m], e.buf[:n])
+    if err := e.write(out[:m]); err != nil {
+        return err
+    }
+    e.err = errWriterClosed
+    return nil
+}
+
+var errWriterClosed = errors.New("ascon: write to closed EncryptWriter")
+
+func (e *EncryptWriter) write(p []byte) error {
+    if _, err := e.w.Write(p); err != nil {
+        e.err = err
+        return err
+    }
+    return nil
+}
+
+// DecryptReader decrypts a single message as it is read.
+//
+// DecryptReader releases plaintext before the authenticator at
+// the end of the message has been checked. Until Read returns
+// io.EOF, the plaintext it has returned is unauthenticated and
+// must not be acted upon; if Read returns an error instead,
+// everything read so far must be discarded. Use Open, or a
+// chunked format that authenticates every chunk, when this is
+// not acceptable.
+type DecryptReader struct {
+    a   *AEAD
+    s   state
+    r   io.Reader
+    buf []byte
+    n   int // ciphertext bytes buffered in buf
+    k   int // bytes at the front of buf that were returned in out
+    out []byte
+    err error
+}
+
+// NewDecryptReader returns a DecryptReader that opens a single
+// message produced by Seal or EncryptWriter with aead, which
+// must have been created by New128, New128a, New80pq,
+// NewAEAD128 or one of their truncated tag variants.
+func NewDecryptReader(aead cipher.AEAD, nonce, additionalData []byte, r io.Reader) (*DecryptReader, error) {
+    a, err := asASCON(aead, nonce)
+    if err != nil {
+        return nil, err
+    }
+
+    d := &DecryptReader{
+        a:   a,
+        r:   r,
+        buf: make([]byte, streamBufSize),
+    }
+    a.init(&d.s, nonce)
+    a.additionalData(&d.s, additionalData)
+    return d, nil
+}
+
+// Read decrypts ciphertext from the underlying io.Reader into p.
+//
+// Once the end of the message has been reached, Read returns
+// io.EOF if the authenticator is valid and an error otherwise.
+// The final partial block is only released after the
+// authenticator has been checked.
+func (d *DecryptReader) Read(p []byte) (int, error) {
+    for len(d.out) == 0 {
+        if d.err != nil {
+            return 0, d.err
+        }
+        d.fill()
+    }
+    n := copy(p, d.out)
+    d.out = d.out[n:]
+    return n, nil
+}
+
+// fill reads more ciphertext and decrypts every whole block
+// that cannot be part of the trailing authenticator.
+func (d *DecryptReader) fill() {
+    if d.k > 0 {
+        d.n = copy(d.buf, d.buf[d.k:d.n])
+        d.k = 0
+    }
+
+    n, err := d.r.Read(d.buf[d.n:])
+    d.n += n
+
+    if err == io.EOF {
+        d.finish()
+        return
+    }
+    if err != nil {
+        d.err = err
+        return
+    }
+
+    bs := d.a.blockSize()
+    avail := d.n - d.a.tagSize
+    if avail < bs {
+        return
+    }
+    k := avail &^ (bs - 1)
+    d.a.decryptBlocks(&d.s, d.buf[:k], d.buf[:k])
+    d.out = d.buf[:k]
+    d.k = k
+}
+
+func (d *DecryptReader) finish() {
+    if d.n < d.a.tagSize {
+        d.err = ErrAuthentication
+        return
+    }
+
+    var tag [TagSize]byte
+    copy(tag[:], d.buf[d.n-d.a.tagSize:d.n])
+    ct := d.buf[:d.n-d.a.tagSize]
+
+    if err := d.a.openMessage(&d.s, ct, ct, tag[:d.a.tagSize]); err != nil {
+        d.err = err
+        return
+    }
+    d.out = ct
+    d.k = len(ct)
+    d.err = io.EOF
+}
@@ -0,0 +1,110 @@
+package ascon
+
+import (
+    "bytes"
+    "io"
+    "math/rand"
+    "testing"
+    "testing/iotest"
+)
+
+func TestEncryptWriter(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            aead, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            nonce := make([]byte, NonceSize)
+            ad := []byte("header")
+
+            for _, size := range []int{0, 1, 7, 8, 9, 15, 16, 17, 31, 32, 33, 100, 5000, 10000} {
+                pt := make([]byte, size)
+                rng.Read(pt)
+                want := aead.Seal(nil, nonce, pt, ad)
+
+                var buf bytes.Buffer
+                w, err := NewEncryptWriter(aead, nonce, ad, &buf)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                for p := pt; len(p) > 0; {
+                    n := rng.Intn(40) + 1
+                    if n > len(p) {
+                        n = len(p)
+                    }
+                    if _, err := w.Write(p[:n]); err != nil {
+                        t.Fatal(err)
+                    }
+                    p = p[n:]
+                }
+                if err := w.Close(); err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(buf.Bytes(), want) {
+                    t.Fatalf("%d: expected %#x, got %#x", size, want, buf.Bytes())
+                }
+                if _, err := w.Write([]byte{0}); err == nil {
+                    t.Fatalf("%d: expected an error", size)
+                }
+            }
+        })
+    }
+}
+
+func TestDecryptReader(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            aead, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            nonce := make([]byte, NonceSize)
+            ad := []byte("header")
+
+            for _, size := range []int{0, 1, 7, 8, 9, 15, 16, 17, 31, 32, 33, 100, 5000, 10000} {
+                pt := make([]byte, size)
+                rng.Read(pt)
+                ct := aead.Seal(nil, nonce, pt, ad)
+
+                for _, r := range []io.Reader{
+                    bytes.NewReader(ct),
+                    iotest.OneByteReader(bytes.NewReader(ct)),
+                    iotest.HalfReader(bytes.NewReader(ct)),
+                } {
+                    d, err := NewDecryptReader(aead, nonce, ad, r)
+                    if err != nil {
+                        t.Fatal(err)
+                    }
+                    got, err := io.ReadAll(d)
+                    if err != nil {
+                        t.Fatalf("%d: %v", size, err)
+                    }
+                    if !bytes.Equal(got, pt) {
+                        t.Fatalf("%d: expected %#x, got %#x", size, pt, got)
+                    }
+                }
+
+                bad := append([]byte(nil), ct...)
+                bad[rng.Intn(len(bad))] ^= 1
+                d, err := NewDecryptReader(aead, nonce, ad, bytes.NewReader(bad))
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if _, err := io.ReadAll(d); err != ErrAuthentication {
+                    t.Fatalf("%d: expected %v, got %v", size, ErrAuthentication, err)
+                }
+
+                d, err = NewDecryptReader(aead, nonce, ad, bytes.NewReader(ct[:len(ct)-1]))
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if _, err := io.ReadAll(d); err != ErrAuthentication {
+                    t.Fatalf("%d: expected %v, got %v", size, ErrAuthentication, err)
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,208 @@
+// Package isap implements the ISAP-A-128a leakage-resilient
+// AEAD cipher.
+//
+// ISAP uses the ASCON permutation in a mode designed to resist
+// differential power analysis: every message is processed under
+// session keys derived from the long-term key and the nonce by
+// a re-keying function that absorbs the nonce one bit at a
+// time, so an attacker only ever observes a few traces of the
+// long-term key being used with any chosen input.
+//
+// References:
+//
+//    [isap]: https://isap.iaik.tugraz.at
+//
+package isap
+
+import (
+    "errors"
+    "strconv"
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/permutation"
+    "github.com/pedroalbanese/go-ascon/internal/byteorder"
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+const (
+    // KeySize is the size in bytes of ISAP-A-128a keys.
+    KeySize = 16
+    // NonceSize is the size in bytes of ISAP-A-128a nonces.
+    NonceSize = 16
+    // TagSize is the size in bytes of ISAP-A-128a
+    // authenticators.
+    TagSize = 16
+)
+
+// Rounds of the permutation used by ISAP-A-128a.
+const (
+    sH = 12 // hashing
+    sB = 1  // re-keying, per absorbed bit
+    sE = 6  // encryption
+    sK = 12 // re-keying, initialization and finalization
+)
+
+// rateH is the rate in bytes of the hash and encryption.
+const rateH = 8
+
+const (
+    ivA  uint64 = 0x018040010c01060c // ISAP_IV_A
+    ivKA uint64 = 0x028040010c01060c // ISAP_IV_KA
+    ivKE uint64 = 0x038040010c01060c // ISAP_IV_KE
+)
+
+var errOpen = errors.New("isap: message authentication failed")
+
+type isap struct {
+    k0, k1 uint64
+}
+
+var _ cipher.AEAD = (*isap)(nil)
+
+// NewA128a creates an ISAP-A-128a AEAD.
+//
+// The key must be exactly 16 bytes long. Each nonce must be
+// unique for a key.
+func NewA128a(key []byte) (cipher.AEAD, error) {
+    if len(key) != KeySize {
+        return nil, errors.New("isap: bad key length")
+    }
+    return &isap{
+        k0: binary.BigEndian.Uint64(key[0:8]),
+        k1: binary.BigEndian.Uint64(key[8:16]),
+    }, nil
+}
+
+func (a *isap) NonceSize() int {
+    return NonceSize
+}
+
+func (a *isap) Overhead() int {
+    return TagSize
+}
+
+func (a *isap) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSize {
+        panic("isap: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    n0 := binary.BigEndian.Uint64(nonce[0:8])
+    n1 := binary.BigEndian.Uint64(nonce[8:16])
+
+    ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("isap: invalid buffer overlap")
+    }
+
+    ct := out[:len(plaintext)]
+    a.encrypt(n0, n1, ct, plaintext)
+    t0, t1 := a.mac(n0, n1, additionalData, ct)
+    binary.BigEndian.PutUint64(out[len(plaintext):], t0)
+    binary.BigEndian.PutUint64(out[len(plaintext)+8:], t1)
+
+    return ret
+}
+
+func (a *isap) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpen
+    }
+
+    if len(ciphertext) < TagSize {
+        return nil, errOpen
+    }
+
+    tag := ciphertext[len(ciphertext)-TagSize:]
+    ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+    n0 := binary.BigEndian.Uint64(nonce[0:8])
+    n1 := binary.BigEndian.Uint64(nonce[8:16])
+
+    // ISAP authenticates the ciphertext before decrypting it,
+    // so nothing is written to dst on failure.
+    var expectedTag [TagSize]byte
+    t0, t1 := a.mac(n0, n1, additionalData, ciphertext)
+    binary.BigEndian.PutUint64(expectedTag[0:], t0)
+    binary.BigEndian.PutUint64(expectedTag[8:], t1)
+    if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+        return nil, errOpen
+    }
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) {
+        panic("isap: invalid buffer overlap")
+    }
+    a.encrypt(n0, n1, out, ciphertext)
+
+    return ret, nil
+}
+
+// rk is the re-keying function IsapRk. It absorbs the 128-bit
+// y one bit at a time under the long-term key and returns the
+// resulting state, of which the caller uses the leading
+// 128 or 192 bits as the session key.
+func (a *isap) rk(iv, y0, y1 uint64) permutation.State {
+    s := permutation.State{0: a.k0, 1: a.k1, 2: iv}
+    permutation.Rounds(&s, sK)
+
+    y := [2]uint64{y0, y1}
+    for i := 0; i < 128; i++ {
+        s[0] ^= (y[i/64] >> (63 - i%64) & 1) << 63
+        if i < 127 {
+            permutation.Rounds(&s, sB)
+        }
+    }
+    permutation.Rounds(&s, sK)
+    return s
+}
+
+// mac is IsapMac: it hashes the additional data and the
+// ciphertext, then derives the session key K_A* from the hash
+// state and squeezes the authenticator under it.
+func (a *isap) mac(n0, n1 uint64, ad, ct []byte) (uint64, uint64) {
+    s := permutation.State{0: n0, 1: n1, 2: ivA}
+    permutation.Rounds(&s, sH)
+    absorb(&s, ad)
+    s[4] ^= 1
+    absorb(&s, ct)
+
+    k := a.rk(ivKA, s[0], s[1])
+    s[0] = k[0]
+    s[1] = k[1]
+    permutation.Rounds(&s, sH)
+    return s[0], s[1]
+}
+
+// encrypt is IsapEnc: it xors src with the key stream derived
+// from the session key K_E* and the nonce.
+func (a *isap) encrypt(n0, n1 uint64, dst, src []byte) {
+    if len(src) == 0 {
+        return
+    }
+
+    s := a.rk(ivKE, n0, n1)
+    s[3] = n0
+    s[4] = n1
+    for len(src) >= rateH {
+        permutation.Rounds(&s, sE)
+        binary.BigEndian.PutUint64(dst, binary.BigEndian.Uint64(src)^s[0])
+        src = src[rateH:]
+        dst = dst[rateH:]
+    }
+    if len(src) > 0 {
+        permutation.Rounds(&s, sE)
+        byteorder.PutBE64n(dst, byteorder.BE64n(src)^s[0])
+    }
+}
+
+// absorb absorbs p at the hash rate, padding the final block.
+func absorb(s *permutation.State, p []byte) {
+    for len(p) >= rateH {
+        s[0] ^= binary.BigEndian.Uint64(p)
+        permutation.Rounds(s, sH)
+        p = p[rateH:]
+    }
+    s[0] ^= byteorder.BE64n(p) ^ byteorder.Pad(len(p))
+    permutation.Rounds(s, sH)
+}
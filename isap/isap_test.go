@@ -0,0 +1,173 @@
+package isap
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "strings"
+    "testing"
+)
+
+type vector struct {
+    key, nonce, pt, ad, ct []byte
+}
+
+func TestVectorsA128a(t *testing.T) {
+    testVectors(t, "testdata/vectors_a128a.txt")
+}
+
+// TestOfficialKAT checks the KAT file of the ISAP-A-128a
+// submission to the NIST lightweight cryptography process,
+// LWC_AEAD_KAT_128_128.txt of isapa128av20, copied unchanged to
+// testdata. A missing file is a failure.
+func TestOfficialKAT(t *testing.T) {
+    const path = "testdata/LWC_AEAD_KAT_128_128.txt"
+    if _, err := os.Stat(path); os.IsNotExist(err) {
+        t.Fatal("the official KAT file " + path + " is missing; copy it from isapa128av20 of the ISAP submission")
+    }
+    testVectors(t, path)
+}
+
+func testVectors(t *testing.T, path string) {
+    vecs, err := readVecs(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) == 0 {
+        t.Fatal("no vectors")
+    }
+    for i, v := range vecs {
+        c, err := NewA128a(v.key)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        ciphertext := c.Seal(nil, v.nonce, v.pt, v.ad)
+        if !bytes.Equal(ciphertext, v.ct) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ciphertext)
+        }
+        plaintext, err := c.Open(nil, v.nonce, v.ct, v.ad)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(plaintext, v.pt) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, plaintext)
+        }
+    }
+}
+
+// TestParameters checks the initialization vectors against the
+// parameters of ISAP-A-128a in the specification, section 2:
+// each is a domain byte followed by k, rH, rB, sH, sB, sE and sK.
+func TestParameters(t *testing.T) {
+    params := []byte{8 * KeySize, 8 * rateH, 1, sH, sB, sE, sK}
+    for _, tc := range []struct {
+        domain byte
+        iv     uint64
+    }{
+        {1, ivA},
+        {2, ivKA},
+        {3, ivKE},
+    } {
+        want := binary.BigEndian.Uint64(append([]byte{tc.domain}, params...))
+        if tc.iv != want {
+            t.Errorf("IV %d: expected %#016x, got %#016x", tc.domain, want, tc.iv)
+        }
+    }
+}
+
+func TestTampering(t *testing.T) {
+    c, err := NewA128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    nonce := make([]byte, NonceSize)
+    pt := []byte("a smartcard APDU payload")
+    ad := []byte("header")
+    ct := c.Seal(nil, nonce, pt, ad)
+
+    for i := range ct {
+        bad := append([]byte(nil), ct...)
+        bad[i] ^= 1
+        if _, err := c.Open(nil, nonce, bad, ad); err != errOpen {
+            t.Fatalf("byte %d: expected %v, got %v", i, errOpen, err)
+        }
+    }
+    if _, err := c.Open(nil, nonce, ct, []byte("Header")); err != errOpen {
+        t.Fatalf("expected %v, got %v", errOpen, err)
+    }
+    bad := append([]byte(nil), nonce...)
+    bad[NonceSize-1] ^= 1
+    if _, err := c.Open(nil, bad, ct, ad); err != errOpen {
+        t.Fatalf("expected %v, got %v", errOpen, err)
+    }
+
+    // Open in place.
+    got, err := c.Open(ct[:0], nonce, ct, ad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(got, pt) {
+        t.Fatalf("expected %q, got %q", pt, got)
+    }
+}
+
+func readVecs(path string) ([]vector, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var vecs []vector
+
+    s := bufio.NewScanner(f)
+    for n := 1; s.Scan(); n++ {
+        t := s.Text()
+        if t == "" {
+            continue
+        }
+        if strings.HasPrefix(t, "Count = ") {
+            vecs = append(vecs, vector{})
+            continue
+        }
+        i := strings.IndexByte(t, '=')
+        if i < 0 || len(vecs) == 0 {
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+        buf, err := hex.DecodeString(strings.TrimSpace(t[i+1:]))
+        if err != nil {
+            return nil, fmt.Errorf("malformed line %d: %v", n, err)
+        }
+        v := &vecs[len(vecs)-1]
+        switch strings.TrimSpace(t[:i]) {
+        case "Key":
+            v.key = buf
+        case "Nonce":
+            v.nonce = buf
+        case "PT":
+            v.pt = buf
+        case "AD":
+            v.ad = buf
+        case "CT":
+            v.ct = buf
+        default:
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+    }
+    return vecs, s.Err()
+}
+
+func TestNonceLength(t *testing.T) {
+    aead, err := NewA128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, n := range []int{0, 15, 17} {
+        if _, err := aead.Open(nil, make([]byte, n), make([]byte, 64), nil); err != errOpen {
+            t.Fatalf("%d: expected %v, got %v", n, errOpen, err)
+        }
+    }
+}
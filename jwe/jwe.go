@@ -0,0 +1,250 @@
+// Package jwe implements JSON Web Encryption (RFC 7516) in the
+// compact serialization, with an ASCON content encryption
+// algorithm.
+//
+// A compact JWE is the five base64url strings
+//
+//    header . encrypted key . iv . ciphertext . tag
+//
+// separated by dots, without padding. The header is the JSON
+// object of the protected header parameters, and the additional
+// data of the AEAD is, as in RFC 7516, section 5.1, the ASCII
+// bytes of its base64url encoding exactly as it appears in the
+// message, so that changing a single character of it makes the
+// message fail to decrypt.
+//
+// The content encryption algorithm, the "enc" header parameter,
+// is Encryption: Ascon-128a with a 16-byte key, IV and tag. IANA
+// has not registered ASCON algorithms, so the name is one for
+// private use. The key management algorithm, "alg", is "dir":
+// the key is shared beforehand and used as the content
+// encryption key, so the encrypted key is empty.
+//
+// ContentCipher has the key and IV sizes and the encrypt and
+// decrypt operations that JOSE libraries ask of a content
+// encryption algorithm, for those that take new ones. Libraries
+// that do not, such as go-jose, can still carry the messages as
+// compact strings, as long as Decrypt is given the string as it
+// was received: a library that parses the header and serializes
+// it again may change its bytes, and with them the additional
+// data.
+//
+// References:
+//
+//    [rfc7516]: https://www.rfc-editor.org/rfc/rfc7516
+//    [rfc7518]: https://www.rfc-editor.org/rfc/rfc7518
+//
+package jwe
+
+import (
+    "errors"
+    "io"
+    "strings"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/json"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+const (
+    // Encryption is the name of the content encryption
+    // algorithm of this package, for the "enc" header
+    // parameter.
+    Encryption = "ASCON-128A"
+    // Direct is the name of the key management algorithm of
+    // this package, for the "alg" header parameter.
+    Direct = "dir"
+
+    // KeySize is the size in bytes of the key.
+    KeySize = ascon.KeySize
+    // IVSize is the size in bytes of the IV.
+    IVSize = ascon.NonceSize
+    // TagSize is the size in bytes of the authentication tag.
+    TagSize = ascon.TagSize
+)
+
+var (
+    // ErrMalformed is returned for a message that is not a
+    // valid compact JWE, or whose header is not a JSON object.
+    ErrMalformed = errors.New("jwe: malformed message")
+
+    // ErrAlgorithm is returned by Decrypt for a message with
+    // another "alg" or "enc" than this package implements.
+    ErrAlgorithm = errors.New("jwe: unexpected algorithm")
+
+    // ErrUnsupportedHeader is returned for a message with a
+    // "crit" or "zip" header parameter, which this package does
+    // not implement.
+    ErrUnsupportedHeader = errors.New("jwe: unsupported header parameter")
+)
+
+// randReader is the source of IVs, replaced in tests.
+var randReader io.Reader = rand.Reader
+
+// b64 is the base64url encoding of RFC 7515, section 2, which
+// has no padding. It is strict, so that every part of a message
+// has a single encoding.
+var b64 = base64.RawURLEncoding.Strict()
+
+// ContentCipher is the content encryption algorithm Encryption.
+// Its zero value is ready to use.
+type ContentCipher struct{}
+
+// KeySize returns the size in bytes of the content encryption
+// key, KeySize.
+func (ContentCipher) KeySize() int { return KeySize }
+
+// IVSize returns the size in bytes of the IV, IVSize.
+func (ContentCipher) IVSize() int { return IVSize }
+
+// Seal encrypts and authenticates plaintext and authenticates
+// aad with the content encryption key cek and iv, and returns
+// the ciphertext and the tag. The iv must be unique for all
+// time for a key.
+func (ContentCipher) Seal(cek, iv, plaintext, aad []byte) (ciphertext, tag []byte, err error) {
+    aead, err := ascon.New(cek, ascon.WithVariant(ascon.Ascon128a))
+    if err != nil {
+        return nil, nil, err
+    }
+    if len(iv) != IVSize {
+        return nil, nil, errors.New("jwe: invalid IV size")
+    }
+    out := aead.Seal(nil, iv, plaintext, aad)
+    n := len(out) - TagSize
+    return out[:n:n], out[n:], nil
+}
+
+// Open authenticates ciphertext, tag and aad with the content
+// encryption key cek and iv, and returns the plaintext. It
+// returns ascon.ErrAuthentication if any of them were modified.
+func (ContentCipher) Open(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+    aead, err := ascon.New(cek, ascon.WithVariant(ascon.Ascon128a))
+    if err != nil {
+        return nil, err
+    }
+    if len(iv) != IVSize || len(tag) != TagSize {
+        return nil, ascon.ErrAuthentication
+    }
+    in := make([]byte, 0, len(ciphertext)+TagSize)
+    in = append(in, ciphertext...)
+    in = append(in, tag...)
+    return aead.Open(in[:0], iv, in, aad)
+}
+
+// Header holds the protected header parameters of a message
+// that this package understands. Empty values are absent.
+type Header struct {
+    Algorithm   string `json:"alg"`
+    Encryption  string `json:"enc"`
+    KeyID       string `json:"kid,omitempty"`
+    ContentType string `json:"cty,omitempty"`
+}
+
+// AAD returns the additional data of the AEAD for the encoded
+// protected header protected, the first part of a compact JWE.
+func AAD(protected string) []byte {
+    return []byte(protected)
+}
+
+// Encrypt encrypts plaintext with key into a compact JWE with
+// the protected header h, in which Algorithm and Encryption may
+// be empty, and are then set to Direct and Encryption. The IV is
+// random.
+func Encrypt(key, plaintext []byte, h Header) (string, error) {
+    if h.Algorithm == "" {
+        h.Algorithm = Direct
+    }
+    if h.Encryption == "" {
+        h.Encryption = Encryption
+    }
+    if h.Algorithm != Direct || h.Encryption != Encryption {
+        return "", ErrAlgorithm
+    }
+    var iv [IVSize]byte
+    if _, err := io.ReadFull(randReader, iv[:]); err != nil {
+        return "", err
+    }
+    return encrypt(key, iv[:], plaintext, h)
+}
+
+// encrypt is Encrypt with the IV iv and a complete header h.
+func encrypt(key, iv, plaintext []byte, h Header) (string, error) {
+    hdr, err := json.Marshal(h)
+    if err != nil {
+        return "", err
+    }
+    protected := b64.EncodeToString(hdr)
+    ct, tag, err := ContentCipher{}.Seal(key, iv, plaintext, AAD(protected))
+    if err != nil {
+        return "", err
+    }
+    return protected + ".." + b64.EncodeToString(iv) + "." +
+        b64.EncodeToString(ct) + "." + b64.EncodeToString(tag), nil
+}
+
+// ParseHeader decodes the protected header of the compact JWE s,
+// without decrypting it, so that the key can be chosen by its
+// KeyID. The header is unauthenticated until Decrypt succeeds.
+func ParseHeader(s string) (Header, error) {
+    protected, _, ok := strings.Cut(s, ".")
+    if !ok {
+        return Header{}, ErrMalformed
+    }
+    return parseHeader(protected)
+}
+
+func parseHeader(protected string) (Header, error) {
+    buf, err := b64.DecodeString(protected)
+    if err != nil {
+        return Header{}, ErrMalformed
+    }
+    var params map[string]json.RawMessage
+    if err := json.Unmarshal(buf, &params); err != nil || params == nil {
+        return Header{}, ErrMalformed
+    }
+    if _, ok := params["crit"]; ok {
+        return Header{}, ErrUnsupportedHeader
+    }
+    if _, ok := params["zip"]; ok {
+        return Header{}, ErrUnsupportedHeader
+    }
+    var h Header
+    if err := json.Unmarshal(buf, &h); err != nil {
+        return Header{}, ErrMalformed
+    }
+    return h, nil
+}
+
+// Decrypt decrypts the compact JWE s with key, and returns its
+// plaintext and protected header. It returns ErrAlgorithm if the
+// header does not name Direct and Encryption, and
+// ascon.ErrAuthentication if any part of s was modified or s was
+// encrypted with another key.
+func Decrypt(key []byte, s string) ([]byte, Header, error) {
+    parts := strings.Split(s, ".")
+    if len(parts) != 5 {
+        return nil, Header{}, ErrMalformed
+    }
+    h, err := parseHeader(parts[0])
+    if err != nil {
+        return nil, Header{}, err
+    }
+    if h.Algorithm != Direct || h.Encryption != Encryption {
+        return nil, Header{}, ErrAlgorithm
+    }
+    if parts[1] != "" {
+        return nil, Header{}, ErrMalformed
+    }
+    var raw [3][]byte
+    for i, p := range parts[2:] {
+        if raw[i], err = b64.DecodeString(p); err != nil {
+            return nil, Header{}, ErrMalformed
+        }
+    }
+    pt, err := ContentCipher{}.Open(key, raw[0], raw[1], raw[2], AAD(parts[0]))
+    if err != nil {
+        return nil, Header{}, err
+    }
+    return pt, h, nil
+}
@@ -0,0 +1,195 @@
+package jwe
+
+import (
+    "bytes"
+    "encoding/base64"
+    "errors"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+var (
+    testKey = bytes.Repeat([]byte{0x42}, KeySize)
+    testIV  = bytes.Repeat([]byte{0x24}, IVSize)
+    testPT  = []byte("The true sign of intelligence is not knowledge but imagination.")
+)
+
+// fixture builds a compact JWE with the header hdr by hand, with
+// an AEAD of the root package, so that it does not depend on the
+// code under test.
+func fixture(t *testing.T, hdr string) string {
+    t.Helper()
+    enc := base64.RawURLEncoding
+    protected := enc.EncodeToString([]byte(hdr))
+    aead, err := ascon.New128a(testKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+    out := aead.Seal(nil, testIV, testPT, []byte(protected))
+    n := len(out) - TagSize
+    return protected + ".." + enc.EncodeToString(testIV) + "." +
+        enc.EncodeToString(out[:n]) + "." + enc.EncodeToString(out[n:])
+}
+
+func TestFixture(t *testing.T) {
+    want := fixture(t, `{"alg":"dir","enc":"ASCON-128A","kid":"k1"}`)
+    randReader = bytes.NewReader(testIV)
+    defer func() { randReader = defaultRand }()
+    got, err := Encrypt(testKey, testPT, Header{KeyID: "k1"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got != want {
+        t.Fatalf("Encrypt:\n got %s\nwant %s", got, want)
+    }
+
+    pt, h, err := Decrypt(testKey, want)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(pt, testPT) {
+        t.Fatalf("expected %q, got %q", testPT, pt)
+    }
+    if h != (Header{Algorithm: Direct, Encryption: Encryption, KeyID: "k1"}) {
+        t.Fatalf("unexpected header %+v", h)
+    }
+    if h, err := ParseHeader(want); err != nil || h.KeyID != "k1" {
+        t.Fatalf("ParseHeader: got %+v, %v", h, err)
+    }
+}
+
+// defaultRand is the default randReader.
+var defaultRand = randReader
+
+func TestRoundTrip(t *testing.T) {
+    for _, pt := range [][]byte{nil, {0}, testPT, make([]byte, 1000)} {
+        s, err := Encrypt(testKey, pt, Header{ContentType: "text/plain"})
+        if err != nil {
+            t.Fatal(err)
+        }
+        got, h, err := Decrypt(testKey, s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, pt) || h.ContentType != "text/plain" {
+            t.Fatalf("got %q, %+v", got, h)
+        }
+        other := bytes.Repeat([]byte{0x43}, KeySize)
+        if _, _, err := Decrypt(other, s); !errors.Is(err, ascon.ErrAuthentication) {
+            t.Fatalf("wrong key: expected ErrAuthentication, got %v", err)
+        }
+    }
+    a, _ := Encrypt(testKey, testPT, Header{})
+    b, _ := Encrypt(testKey, testPT, Header{})
+    if a == b {
+        t.Fatal("two messages have the same IV")
+    }
+    if _, err := Encrypt(testKey, testPT, Header{Algorithm: "A128KW"}); err != ErrAlgorithm {
+        t.Fatalf("expected ErrAlgorithm, got %v", err)
+    }
+    if _, err := Encrypt(testKey[1:], testPT, Header{}); err == nil {
+        t.Fatal("expected an error for a short key")
+    }
+}
+
+func TestModifiedHeader(t *testing.T) {
+    for _, tc := range []struct {
+        hdr string
+        err error
+    }{
+        // The same parameters, encoded differently, are another
+        // additional data.
+        {`{"enc":"ASCON-128A","alg":"dir","kid":"k1"}`, ascon.ErrAuthentication},
+        {`{"alg":"dir","enc":"ASCON-128A","kid":"k1" }`, ascon.ErrAuthentication},
+        {`{"alg":"dir","enc":"ASCON-128A","kid":"k2"}`, ascon.ErrAuthentication},
+        {`{"alg":"dir","enc":"ASCON-128A"}`, ascon.ErrAuthentication},
+        {`{"alg":"dir","enc":"A128GCM","kid":"k1"}`, ErrAlgorithm},
+        {`{"alg":"A128KW","enc":"ASCON-128A","kid":"k1"}`, ErrAlgorithm},
+        {`{"enc":"ASCON-128A","kid":"k1"}`, ErrAlgorithm},
+        {`{"alg":"dir","enc":"ASCON-128A","crit":["exp"],"exp":1}`, ErrUnsupportedHeader},
+        {`{"alg":"dir","enc":"ASCON-128A","zip":"DEF"}`, ErrUnsupportedHeader},
+        {`{"alg":"dir","enc":"ASCON-128A","kid":1}`, ErrMalformed},
+        {`["alg","dir"]`, ErrMalformed},
+        {`null`, ErrMalformed},
+        {`{"alg":"dir"`, ErrMalformed},
+    } {
+        parts := strings.Split(fixture(t, `{"alg":"dir","enc":"ASCON-128A","kid":"k1"}`), ".")
+        parts[0] = base64.RawURLEncoding.EncodeToString([]byte(tc.hdr))
+        if _, _, err := Decrypt(testKey, strings.Join(parts, ".")); !errors.Is(err, tc.err) {
+            t.Errorf("%s: expected %v, got %v", tc.hdr, tc.err, err)
+        }
+    }
+}
+
+func TestTampering(t *testing.T) {
+    s := fixture(t, `{"alg":"dir","enc":"ASCON-128A"}`)
+    parts := strings.Split(s, ".")
+    for _, i := range []int{2, 3, 4} {
+        raw, err := base64.RawURLEncoding.DecodeString(parts[i])
+        if err != nil {
+            t.Fatal(err)
+        }
+        for j := range raw {
+            raw[j] ^= 1
+            p := append([]string(nil), parts...)
+            p[i] = base64.RawURLEncoding.EncodeToString(raw)
+            if _, _, err := Decrypt(testKey, strings.Join(p, ".")); !errors.Is(err, ascon.ErrAuthentication) {
+                t.Fatalf("part %d, byte %d: expected ErrAuthentication, got %v", i, j, err)
+            }
+            raw[j] ^= 1
+        }
+    }
+
+    for _, m := range []string{
+        "",
+        parts[0],
+        strings.Join(parts[:4], "."),
+        s + ".",
+        // An encrypted key, which "dir" does not have.
+        parts[0] + ".AA." + strings.Join(parts[2:], "."),
+        // Padding, and non-canonical trailing bits.
+        s + "=",
+        strings.Join(parts[:4], ".") + "." + parts[4][:len(parts[4])-1] + "B",
+        strings.Join(parts[:2], ".") + ".!" + strings.Join(parts[2:], "."),
+    } {
+        if _, _, err := Decrypt(testKey, m); err != ErrMalformed {
+            t.Errorf("%q: expected ErrMalformed, got %v", m, err)
+        }
+    }
+
+    // A truncated IV or tag is the wrong size.
+    for _, i := range []int{2, 4} {
+        p := append([]string(nil), parts...)
+        p[i] = p[i][:16]
+        if _, _, err := Decrypt(testKey, strings.Join(p, ".")); !errors.Is(err, ascon.ErrAuthentication) {
+            t.Errorf("part %d: expected ErrAuthentication, got %v", i, err)
+        }
+    }
+}
+
+func TestContentCipher(t *testing.T) {
+    var c ContentCipher
+    if c.KeySize() != 16 || c.IVSize() != 16 {
+        t.Fatalf("got sizes %d and %d", c.KeySize(), c.IVSize())
+    }
+    aad := AAD("eyJhbGciOiJkaXIifQ")
+    ct, tag, err := c.Seal(testKey, testIV, testPT, aad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ct) != len(testPT) || len(tag) != TagSize {
+        t.Fatalf("got %d and %d bytes", len(ct), len(tag))
+    }
+    pt, err := c.Open(testKey, testIV, ct, tag, aad)
+    if err != nil || !bytes.Equal(pt, testPT) {
+        t.Fatalf("got %q, %v", pt, err)
+    }
+    if _, err := c.Open(testKey, testIV, ct, tag, aad[1:]); !errors.Is(err, ascon.ErrAuthentication) {
+        t.Fatalf("expected ErrAuthentication, got %v", err)
+    }
+    if _, _, err := c.Seal(testKey, testIV[1:], testPT, aad); err == nil {
+        t.Fatal("expected an error for a short IV")
+    }
+}
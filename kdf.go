@@ -0,0 +1,45 @@
+package ascon
+
+import (
+    "encoding/binary"
+)
+
+// deriveKeyLabel domain separates DeriveKey from the other
+// uses of Ascon-Prf in this package. It is longer than a
+// nonce, so no DeriveKey input equals the Ascon-Prf input of
+// NewCommitting128 or NewX128a.
+const deriveKeyLabel = "go-ascon DeriveKey v1"
+
+// DeriveKey derives an independent KeySize byte key from the
+// master key and the context fields.
+//
+// The derived key is the first 16 bytes of
+//
+//    Ascon-Prf(master, label || BE64(len(c1)) || c1 || ... || BE64(len(cn)) || cn)
+//
+// where label is the ASCII string "go-ascon DeriveKey v1". Every
+// field is prefixed by its length, so distinct lists of fields
+// always derive distinct keys: ("ab", "c"), ("a", "bc") and
+// ("abc") are all different.
+//
+// The master key must be exactly 16 bytes long.
+func DeriveKey(master []byte, context ...[]byte) ([]byte, error) {
+    if len(master) != KeySize {
+        return nil, KeySizeError(len(master))
+    }
+
+    var m macState
+    m.init(ivPRF, binary.BigEndian.Uint64(master[0:]), binary.BigEndian.Uint64(master[8:]))
+    m.write([]byte(deriveKeyLabel))
+    var n [8]byte
+    for _, c := range context {
+        binary.BigEndian.PutUint64(n[:], uint64(len(c)))
+        m.write(n[:])
+        m.write(c)
+    }
+    m.finish()
+
+    key := make([]byte, KeySize)
+    m.squeeze(key)
+    return key, nil
+}
@@ -0,0 +1,94 @@
+// Package kdf implements an HKDF-style extract-then-expand key
+// derivation function built on ASCON.
+//
+// Extract condenses input keying material of any length and
+// quality into a 16-byte pseudorandom key with Ascon-CXOF128,
+// and Expand stretches that key into as many output bytes as
+// needed with Ascon-Prf. Like HKDF, Extract is only needed when
+// the input keying material is not already a uniformly random
+// key, and Expand outputs for the same key and info but
+// different lengths are prefixes of one another.
+//
+// References:
+//
+//    [hkdf]: https://www.rfc-editor.org/rfc/rfc5869
+//
+package kdf
+
+import (
+    "errors"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+const (
+    // PRKSize is the size in bytes of the pseudorandom keys
+    // returned by Extract.
+    PRKSize = ascon.KeySize
+    // MaxLength is the maximum output size in bytes of Expand:
+    // 255 Ascon-Prf output blocks, as in HKDF.
+    MaxLength = 255 * 16
+)
+
+// extractLabel domain separates Extract from other uses of
+// Ascon-CXOF128.
+const extractLabel = "go-ascon kdf Extract v1"
+
+// Extract derives a PRKSize byte pseudorandom key from the
+// input keying material ikm and an optional salt.
+//
+// The key is the first PRKSize bytes of
+//
+//    Ascon-CXOF128(label, BE64(len(salt)) || salt || ikm)
+//
+// where label is the ASCII string "go-ascon kdf Extract v1".
+// A nil salt is the same as an empty one.
+func Extract(salt, ikm []byte) []byte {
+    x, err := ascon.NewCXOF128([]byte(extractLabel))
+    if err != nil {
+        panic(err)
+    }
+    var n [8]byte
+    binary.BigEndian.PutUint64(n[:], uint64(len(salt)))
+    x.Write(n[:])
+    x.Write(salt)
+    x.Write(ikm)
+
+    prk := make([]byte, PRKSize)
+    x.Read(prk)
+    return prk
+}
+
+// Expand derives length bytes of output keying material from
+// the pseudorandom key prk and the context info.
+//
+// The output is Ascon-Prf(prk, info) truncated to length
+// bytes. prk must be PRKSize bytes long, for example a key
+// returned by Extract, and length must be in the range
+// [1, MaxLength].
+func Expand(prk, info []byte, length int) ([]byte, error) {
+    if len(prk) != PRKSize {
+        return nil, errors.New("kdf: bad pseudorandom key length")
+    }
+    if length < 1 || length > MaxLength {
+        return nil, errors.New("kdf: bad output length")
+    }
+
+    p, err := ascon.NewPRF(prk)
+    if err != nil {
+        return nil, err
+    }
+    p.Write(info)
+    out := make([]byte, length)
+    p.Read(out)
+    return out, nil
+}
+
+// Key derives length bytes of output keying material from ikm,
+// salt and info. It is equivalent to
+//
+//    Expand(Extract(salt, ikm), info, length)
+func Key(ikm, salt, info []byte, length int) ([]byte, error) {
+    return Expand(Extract(salt, ikm), info, length)
+}
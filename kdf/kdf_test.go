@@ -0,0 +1,92 @@
+package kdf
+
+import (
+    "bytes"
+    "encoding/hex"
+    "testing"
+)
+
+func seq(from, to int) []byte {
+    b := make([]byte, 0, to-from)
+    for i := from; i < to; i++ {
+        b = append(b, byte(i))
+    }
+    return b
+}
+
+func TestVectors(t *testing.T) {
+    for i, tc := range []struct {
+        ikm, salt, info []byte
+        prk, okm        string
+    }{
+        {
+            nil, nil, nil,
+            "43c79d8300600191e10a822df6fe9ec0",
+            "60e4586e822a572c18a1fd2761eb2bb4",
+        },
+        {
+            seq(0, 22), seq(0, 13), seq(0xf0, 0xfa),
+            "b9505843253e73f960ba2abe46f94be3",
+            "a77598e7b5e6971c5cad4b449d3f5cb1d5ab01fa9958bd64ca13df3940b4e49e" +
+                "12e0f90b85f48aa4ebcd",
+        },
+        {
+            []byte("secret"), nil, []byte("app v1"),
+            "56778226207cb0e00aa3cbbd4083b787",
+            "83976cf18641cd443991bae9559ee4802e829ffdcf2413dc1a22a60fbd8d2fbc",
+        },
+        {
+            seq(0, 80), seq(0x60, 0xb0), seq(0xb0, 0x100),
+            "5500510b1f6fc13c9f53cff2506afda3",
+            "9c5dd678eb2c81c114bdfbce353d79cb10b90e92565acf57458ebc388bfef734" +
+                "b4cc135771d70931ef98253166edd2e11b3b56566aa041a1710b7ddd70208086" +
+                "3525c42ca98dd21271d074539e3a8d38a469",
+        },
+    } {
+        wantPRK, _ := hex.DecodeString(tc.prk)
+        wantOKM, _ := hex.DecodeString(tc.okm)
+
+        prk := Extract(tc.salt, tc.ikm)
+        if !bytes.Equal(prk, wantPRK) {
+            t.Fatalf("#%d: expected PRK %x, got %x", i, wantPRK, prk)
+        }
+        okm, err := Expand(prk, tc.info, len(wantOKM))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(okm, wantOKM) {
+            t.Fatalf("#%d: expected OKM %x, got %x", i, wantOKM, okm)
+        }
+        okm, err = Key(tc.ikm, tc.salt, tc.info, len(wantOKM))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(okm, wantOKM) {
+            t.Fatalf("#%d: Key: expected %x, got %x", i, wantOKM, okm)
+        }
+
+        // Shorter outputs are prefixes.
+        short, err := Expand(prk, tc.info, 5)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(short, wantOKM[:5]) {
+            t.Fatalf("#%d: expected %x, got %x", i, wantOKM[:5], short)
+        }
+    }
+}
+
+func TestExpandErrors(t *testing.T) {
+    prk := make([]byte, PRKSize)
+    if _, err := Expand(prk, nil, MaxLength); err != nil {
+        t.Fatal(err)
+    }
+    for _, n := range []int{-1, 0, MaxLength + 1} {
+        if _, err := Expand(prk, nil, n); err == nil {
+            t.Fatalf("%d: expected an error", n)
+        }
+    }
+    if _, err := Expand(prk[1:], nil, 16); err == nil {
+        t.Fatal("expected an error")
+    }
+}
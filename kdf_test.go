@@ -0,0 +1,43 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+    master := make([]byte, KeySize)
+    for i := range master {
+        master[i] = byte(i)
+    }
+    long := make([]byte, 100)
+    for i := range long {
+        long[i] = byte(i)
+    }
+    for _, tc := range []struct {
+        context [][]byte
+        key     string
+    }{
+        {nil, "8318bdb2e1109f69364cf2852e788970"},
+        {[][]byte{{}}, "fa53015b1bc94a36e7bd0255f31b2e24"},
+        {[][]byte{[]byte("ab"), []byte("c")}, "61b3a863a0084d9d1f5aba1e7d2e4257"},
+        {[][]byte{[]byte("a"), []byte("bc")}, "b734ffb634ef10fad35494ff3f57ec6f"},
+        {[][]byte{[]byte("abc")}, "290e571f0c69dcd3da8d4b358ae39b39"},
+        {[][]byte{[]byte("tenant-42"), []byte("device-7")}, "4e9de54be454946aac0bbdd6466c8aa1"},
+        {[][]byte{long}, "6c631072fe4e410eec1ee9faa8bcc1a2"},
+    } {
+        want, _ := hex.DecodeString(tc.key)
+        got, err := DeriveKey(master, tc.context...)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("%q: expected %x, got %x", tc.context, want, got)
+        }
+    }
+
+    if _, err := DeriveKey(make([]byte, KeySize+1)); err == nil {
+        t.Fatal("expected an error")
+    }
+}
@@ -0,0 +1,92 @@
+package ascon
+
+import (
+    "errors"
+    "io"
+    "os"
+    "strings"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/hex"
+)
+
+// errKeyEncoding is the error of ParseKey for a string that is
+// neither hexadecimal nor base64. Like every error of ParseKey,
+// it does not quote the string, which may be key material.
+var errKeyEncoding = errors.New("ascon: key is neither hexadecimal nor base64")
+
+// GenerateKey returns a random key of the size of v, read from
+// crypto/rand.
+func GenerateKey(v Variant) ([]byte, error) {
+    n := v.KeySize()
+    if n == 0 {
+        return nil, errors.New("ascon: unknown variant " + v.String())
+    }
+    key := make([]byte, n)
+    if _, err := io.ReadFull(rand.Reader, key); err != nil {
+        return nil, err
+    }
+    return key, nil
+}
+
+// ParseKey parses a key of the variant v, in hexadecimal, in
+// standard or URL-safe base64 with or without padding, or, if
+// s is "file:PATH", from the file PATH. Surrounding whitespace
+// is ignored. A file holds the key in hexadecimal or base64,
+// or as raw bytes if it is exactly the size of the key.
+//
+// It returns a KeySizeError for a key of the wrong size. Its
+// errors never include the key.
+func ParseKey(s string, v Variant) ([]byte, error) {
+    n := v.KeySize()
+    if n == 0 {
+        return nil, errors.New("ascon: unknown variant " + v.String())
+    }
+    if path, ok := strings.CutPrefix(strings.TrimSpace(s), "file:"); ok {
+        b, err := os.ReadFile(path)
+        if err != nil {
+            return nil, err
+        }
+        if len(b) == n {
+            return b, nil
+        }
+        s = string(b)
+    }
+    s = strings.TrimSpace(s)
+
+    // The encodings of keys of a given size have different
+    // lengths, so at most one of them gives n bytes.
+    var size int
+    if key, err := hex.DecodeString(s); err == nil {
+        if len(key) == n {
+            return key, nil
+        }
+        size = len(key)
+    } else {
+        size = -1
+    }
+    for _, enc := range []*base64.Encoding{
+        base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+    } {
+        key, err := enc.DecodeString(s)
+        if err != nil {
+            continue
+        }
+        if len(key) == n {
+            return key, nil
+        }
+        if size < 0 {
+            size = len(key)
+        }
+    }
+    if size < 0 {
+        return nil, errKeyEncoding
+    }
+    return nil, KeySizeError(size)
+}
+
+// FormatKey returns key in lowercase hexadecimal, which
+// ParseKey accepts.
+func FormatKey(key []byte) string {
+    return hex.EncodeToString(key)
+}
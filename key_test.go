@@ -0,0 +1,94 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/base64"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+    for _, v := range []Variant{Ascon128, Ascon128a, Ascon80pq, AsconAEAD128} {
+        a, err := GenerateKey(v)
+        if err != nil {
+            t.Fatal(err)
+        }
+        b, _ := GenerateKey(v)
+        if len(a) != v.KeySize() || bytes.Equal(a, b) {
+            t.Errorf("%v: got %x and %x", v, a, b)
+        }
+        if _, err := New(a, WithVariant(v)); err != nil {
+            t.Errorf("%v: %v", v, err)
+        }
+    }
+    if _, err := GenerateKey(0); err == nil {
+        t.Error("GenerateKey(0) succeeded")
+    }
+}
+
+func TestParseKey(t *testing.T) {
+    dir := t.TempDir()
+    for _, v := range []Variant{Ascon128, Ascon80pq} {
+        key := seq(0xf0-v.KeySize(), 0xf0)
+        raw := filepath.Join(dir, v.String()+".raw")
+        text := filepath.Join(dir, v.String()+".txt")
+        if err := os.WriteFile(raw, key, 0o600); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(text, []byte(FormatKey(key)+"\n"), 0o600); err != nil {
+            t.Fatal(err)
+        }
+        for _, s := range []string{
+            FormatKey(key),
+            strings.ToUpper(FormatKey(key)),
+            " " + FormatKey(key) + "\n",
+            base64.StdEncoding.EncodeToString(key),
+            base64.RawStdEncoding.EncodeToString(key),
+            base64.URLEncoding.EncodeToString(key),
+            base64.RawURLEncoding.EncodeToString(key),
+            "file:" + raw,
+            "file:" + text,
+        } {
+            if got, err := ParseKey(s, v); err != nil || !bytes.Equal(got, key) {
+                t.Errorf("%v: %q: got %x, %v", v, s, got, err)
+            }
+        }
+    }
+}
+
+func TestParseKeyErrors(t *testing.T) {
+    key := seq(0, KeySize)
+    secret := FormatKey(key)
+    for _, tc := range []struct {
+        s    string
+        v    Variant
+        size int
+    }{
+        {secret, Ascon80pq, KeySize},
+        {secret[:30], Ascon128, KeySize - 1},
+        {base64.StdEncoding.EncodeToString(key), Ascon80pq, KeySize},
+        {"", Ascon128, 0},
+    } {
+        _, err := ParseKey(tc.s, tc.v)
+        if !errors.Is(err, ErrInvalidKeySize) || err != KeySizeError(tc.size) {
+            t.Errorf("%q: expected a KeySizeError(%d), got %v", tc.s, tc.size, err)
+        }
+    }
+
+    for _, s := range []string{secret[:31] + "g", secret + "!", "file:" + filepath.Join(t.TempDir(), "missing")} {
+        _, err := ParseKey(s, Ascon128)
+        if err == nil {
+            t.Errorf("%q: accepted", s)
+            continue
+        }
+        if strings.Contains(err.Error(), secret[:8]) {
+            t.Errorf("%q: the error quotes the key: %v", s, err)
+        }
+    }
+    if _, err := ParseKey(secret, 0); err == nil {
+        t.Error("ParseKey with variant 0 succeeded")
+    }
+}
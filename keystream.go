@@ -0,0 +1,112 @@
+package ascon
+
+import (
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+type keyStream struct {
+    s   state
+    buf [BlockSize128a]byte
+    off int // next unused byte of buf
+}
+
+var _ cipher.Stream = (*keyStream)(nil)
+
+// NewStream returns a cipher.Stream that XORs data with the
+// ASCON-128a keystream for key and nonce.
+//
+// The keystream is the ciphertext of an all-zero plaintext
+// under ASCON-128a with empty additional data, so it equals
+// the output of New128a's Seal for a zero plaintext without
+// the authenticator. It provides confidentiality only: the
+// ciphertext is malleable and nothing detects modification.
+// Use an AEAD whenever the message format can afford a tag.
+//
+// The key and nonce must be 16 bytes long, and a nonce must
+// never be reused with the same key.
+func NewStream(key, nonce []byte) (cipher.Stream, error) {
+    k := new(keyStream)
+    if err := k.init(key, nonce); err != nil {
+        return nil, err
+    }
+    return k, nil
+}
+
+// XORKeyStream XORs src with the keystream of NewStream for key
+// and nonce and writes the result to dst, as a single call to
+// the XORKeyStream method of a new Stream would.
+//
+// dst may be src for in-place operation; otherwise it must not
+// overlap src. XORKeyStream panics if dst is shorter than src.
+func XORKeyStream(dst, src, key, nonce []byte) error {
+    var k keyStream
+    if err := k.init(key, nonce); err != nil {
+        return err
+    }
+    k.XORKeyStream(dst, src)
+    return nil
+}
+
+func (k *keyStream) init(key, nonce []byte) error {
+    if len(key) != KeySize {
+        return KeySizeError(len(key))
+    }
+    if len(nonce) != NonceSize {
+        return ErrInvalidNonceSize
+    }
+
+    k.s.init(iv128a,
+        binary.BigEndian.Uint64(key[0:]), binary.BigEndian.Uint64(key[8:]),
+        binary.BigEndian.Uint64(nonce[0:]), binary.BigEndian.Uint64(nonce[8:]))
+    k.s.additionalData128a(nil)
+    k.off = BlockSize128a
+    return nil
+}
+
+func (k *keyStream) XORKeyStream(dst, src []byte) {
+    if len(dst) < len(src) {
+        panic("ascon: output smaller than input")
+    }
+    dst = dst[:len(src)]
+    if subtle.InexactOverlap(dst, src) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    if k.off < BlockSize128a {
+        n := xorBytes(dst, src, k.buf[k.off:])
+        k.off += n
+        src = src[n:]
+        dst = dst[n:]
+    }
+
+    for len(src) >= BlockSize128a {
+        binary.BigEndian.PutUint64(dst[0:8], binary.BigEndian.Uint64(src[0:8])^k.s[0])
+        binary.BigEndian.PutUint64(dst[8:16], binary.BigEndian.Uint64(src[8:16])^k.s[1])
+        p8(&k.s)
+        src = src[BlockSize128a:]
+        dst = dst[BlockSize128a:]
+    }
+
+    if len(src) > 0 {
+        binary.BigEndian.PutUint64(k.buf[0:8], k.s[0])
+        binary.BigEndian.PutUint64(k.buf[8:16], k.s[1])
+        p8(&k.s)
+        k.off = xorBytes(dst, src, k.buf[:])
+    }
+}
+
+// xorBytes sets dst[i] = src[i] ^ ks[i] for as many bytes as
+// src and ks both have, and returns that count.
+func xorBytes(dst, src, ks []byte) int {
+    n := len(src)
+    if len(ks) < n {
+        n = len(ks)
+    }
+    for i := 0; i < n; i++ {
+        dst[i] = src[i] ^ ks[i]
+    }
+    return n
+}
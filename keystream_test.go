@@ -0,0 +1,157 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "math/rand"
+    "testing"
+)
+
+func newTestStream(t *testing.T) (key, nonce []byte) {
+    key = make([]byte, KeySize)
+    nonce = make([]byte, NonceSize)
+    for i := range key {
+        key[i] = byte(i)
+        nonce[i] = byte(16 + i)
+    }
+    return key, nonce
+}
+
+func TestStreamVectors(t *testing.T) {
+    key, nonce := newTestStream(t)
+    want, _ := hex.DecodeString("89d098be2b2b225c1cb8a65e22eb3671" +
+        "2cba4e0c5e609b419039899840209061ee4989598ba5c661")
+
+    s, err := NewStream(key, nonce)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got := make([]byte, len(want))
+    s.XORKeyStream(got, got)
+    if !bytes.Equal(got, want) {
+        t.Fatalf("expected %x, got %x", want, got)
+    }
+
+    // The keystream is the ASCON-128a encryption of zeros.
+    aead, err := New128a(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, n := range []int{0, 1, 15, 16, 17, 100, 1000} {
+        s, _ := NewStream(key, nonce)
+        ks := make([]byte, n)
+        s.XORKeyStream(ks, ks)
+        ct := aead.Seal(nil, nonce, make([]byte, n), nil)
+        if !bytes.Equal(ks, ct[:n]) {
+            t.Fatalf("%d: expected %x, got %x", n, ct[:n], ks)
+        }
+    }
+}
+
+func TestStreamSplit(t *testing.T) {
+    key, nonce := newTestStream(t)
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    src := make([]byte, 1000)
+    rng.Read(src)
+
+    s, _ := NewStream(key, nonce)
+    want := make([]byte, len(src))
+    s.XORKeyStream(want, src)
+
+    for i := 0; i < 50; i++ {
+        s, _ := NewStream(key, nonce)
+        got := make([]byte, len(src))
+        for off := 0; off < len(src); {
+            n := rng.Intn(40)
+            if off+n > len(src) {
+                n = len(src) - off
+            }
+            s.XORKeyStream(got[off:off+n], src[off:off+n])
+            off += n
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("#%d: split calls differ from a single call", i)
+        }
+    }
+
+    // Decryption is the same operation.
+    s, _ = NewStream(key, nonce)
+    s.XORKeyStream(want, want)
+    if !bytes.Equal(want, src) {
+        t.Fatal("round trip failed")
+    }
+}
+
+func TestStreamPanics(t *testing.T) {
+    key, nonce := newTestStream(t)
+    for _, tc := range []struct {
+        name     string
+        dst, src func([]byte) []byte
+    }{
+        {"short", func(b []byte) []byte { return b[:10] }, func(b []byte) []byte { return b[20:] }},
+        {"overlap", func(b []byte) []byte { return b[1:] }, func(b []byte) []byte { return b[:20] }},
+    } {
+        func() {
+            defer func() {
+                if recover() == nil {
+                    t.Fatalf("%s: expected a panic", tc.name)
+                }
+            }()
+            s, _ := NewStream(key, nonce)
+            buf := make([]byte, 40)
+            s.XORKeyStream(tc.dst(buf), tc.src(buf))
+        }()
+    }
+
+    if _, err := NewStream(key[:15], nonce); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := NewStream(key, nonce[:15]); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+func TestXORKeyStream(t *testing.T) {
+    key, nonce := newTestStream(t)
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for i := 0; i < 200; i++ {
+        src := make([]byte, rng.Intn(300))
+        rng.Read(src)
+
+        // Equivalent to an incremental Stream fed the same
+        // bytes in arbitrary pieces.
+        s, _ := NewStream(key, nonce)
+        want := make([]byte, len(src))
+        for off := 0; off < len(src); {
+            n := rng.Intn(33)
+            if off+n > len(src) {
+                n = len(src) - off
+            }
+            s.XORKeyStream(want[off:off+n], src[off:off+n])
+            off += n
+        }
+
+        got := make([]byte, len(src))
+        if err := XORKeyStream(got, src, key, nonce); err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("#%d: expected %x, got %x", i, want, got)
+        }
+
+        buf := append([]byte(nil), src...)
+        if err := XORKeyStream(buf, buf, key, nonce); err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(buf, want) {
+            t.Fatalf("#%d: in place: expected %x, got %x", i, want, buf)
+        }
+    }
+
+    if err := XORKeyStream(nil, nil, key[:8], nonce); err == nil {
+        t.Fatal("expected an error")
+    }
+    if err := XORKeyStream(nil, nil, key, nonce[:8]); err == nil {
+        t.Fatal("expected an error")
+    }
+}
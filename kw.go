@@ -0,0 +1,98 @@
+package ascon
+
+import (
+    "errors"
+    "runtime"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// ErrUnwrap is returned by Unwrap when the wrapped key fails
+// its integrity check.
+var ErrUnwrap = errors.New("ascon: key unwrap failed")
+
+// Wrap deterministically encrypts and authenticates key under
+// the 16-byte key-encryption key kek, returning
+// len(key)+TagSize bytes.
+//
+// Wrap is an SIV construction that needs no nonce:
+//
+//    V = Ascon-Mac(kek, key)
+//    C = ASCON-128a(kek, nonce=V, ad="", key) without its tag
+//
+// and the result is C || V. Any non-empty key length is
+// supported without padding.
+//
+// Wrapping the same key twice yields the same output, so Wrap
+// must only be used for high-entropy secrets such as keys.
+func Wrap(kek, key []byte) ([]byte, error) {
+    if len(kek) != KeySize {
+        return nil, KeySizeError(len(kek))
+    }
+    if len(key) == 0 {
+        return nil, KeySizeError(len(key))
+    }
+
+    k0 := binary.BigEndian.Uint64(kek[0:])
+    k1 := binary.BigEndian.Uint64(kek[8:])
+
+    out := make([]byte, len(key)+TagSize)
+    v := out[len(key):]
+    wrapIV(v, k0, k1, key)
+
+    var s state
+    s.init(iv128a, k0, k1,
+        binary.BigEndian.Uint64(v[0:]), binary.BigEndian.Uint64(v[8:]))
+    s.additionalData128a(nil)
+    s.encrypt128a(out[:len(key)], key)
+
+    return out, nil
+}
+
+// Unwrap reverses Wrap, returning ErrUnwrap if wrapped was not
+// produced by Wrap under kek.
+func Unwrap(kek, wrapped []byte) ([]byte, error) {
+    if len(kek) != KeySize {
+        return nil, KeySizeError(len(kek))
+    }
+    if len(wrapped) <= TagSize {
+        return nil, ErrUnwrap
+    }
+
+    k0 := binary.BigEndian.Uint64(kek[0:])
+    k1 := binary.BigEndian.Uint64(kek[8:])
+
+    v := wrapped[len(wrapped)-TagSize:]
+    wrapped = wrapped[:len(wrapped)-TagSize]
+
+    out := make([]byte, len(wrapped))
+
+    var s state
+    s.init(iv128a, k0, k1,
+        binary.BigEndian.Uint64(v[0:]), binary.BigEndian.Uint64(v[8:]))
+    s.additionalData128a(nil)
+    s.decrypt128a(out, wrapped)
+
+    var expectedV [TagSize]byte
+    wrapIV(expectedV[:], k0, k1, out)
+
+    if subtle.ConstantTimeCompare(expectedV[:], v) != 1 {
+        for i := range out {
+            out[i] = 0
+        }
+
+        runtime.KeepAlive(out)
+        return nil, ErrUnwrap
+    }
+
+    return out, nil
+}
+
+func wrapIV(dst []byte, k0, k1 uint64, key []byte) {
+    var m macState
+    m.init(ivMAC, k0, k1)
+    m.write(key)
+    m.finish()
+    m.squeeze(dst)
+}
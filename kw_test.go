@@ -0,0 +1,70 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "errors"
+    "testing"
+)
+
+func TestWrap(t *testing.T) {
+    kek := make([]byte, KeySize)
+    for i := range kek {
+        kek[i] = byte(i)
+    }
+    for _, tc := range []struct {
+        n       int
+        wrapped string
+    }{
+        {16, "e4a43968f222c58d295172a0c1cc5af77d09ff4b4de1ae108b53b06e0cd96304"},
+        {32, "36adb7791f4e6438487b5332a2033ccdfd06917eb9a550b6469736cd3b2129e8" +
+            "9797a5cc54158710ddf4a5853d6559d0"},
+        {20, "e41573f5523d9f1207a9a0423121095685f34384fb952a9cf6ecdda508f7e2b60b8a7a2c"},
+        {5, "292a2fe498303bd9aae61313fa432f0146eb089770"},
+    } {
+        key := make([]byte, tc.n)
+        for i := range key {
+            key[i] = byte(0x40 + i)
+        }
+        want, _ := hex.DecodeString(tc.wrapped)
+        got, err := Wrap(kek, key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("%d: expected %#x, got %#x", tc.n, want, got)
+        }
+        k, err := Unwrap(kek, got)
+        if err != nil {
+            t.Fatalf("%d: %v", tc.n, err)
+        }
+        if !bytes.Equal(k, key) {
+            t.Fatalf("%d: expected %#x, got %#x", tc.n, key, k)
+        }
+
+        for i := range got {
+            w := append([]byte(nil), got...)
+            w[i] ^= 1
+            k, err := Unwrap(kek, w)
+            if !errors.Is(err, ErrUnwrap) {
+                t.Fatalf("%d: #%d: expected ErrUnwrap, got %v", tc.n, i, err)
+            }
+            if k != nil {
+                t.Fatalf("%d: #%d: expected nil key", tc.n, i)
+            }
+        }
+    }
+}
+
+func TestWrapErrors(t *testing.T) {
+    kek := make([]byte, KeySize)
+    if _, err := Wrap(kek[:15], make([]byte, 16)); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := Wrap(kek, nil); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := Unwrap(kek, make([]byte, TagSize)); !errors.Is(err, ErrUnwrap) {
+        t.Fatalf("expected ErrUnwrap, got %v", err)
+    }
+}
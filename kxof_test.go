@@ -0,0 +1,60 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "testing"
+)
+
+func TestKXOF(t *testing.T) {
+    key := make([]byte, 32)
+    for i := range key {
+        key[i] = byte(i)
+    }
+    for _, tc := range []struct {
+        key, label, context []byte
+        out                 string
+    }{
+        {key[:16], nil, nil, "42234e89e100eedbcf13d1407e7165969c7e399b8e3373309d29ce7880974beb"},
+        {key[:16], []byte("ab"), []byte("c"), "ffac5279f5bcde0f824ab5c185724b5461605b37c17fe0adcdc2e94763c8b452"},
+        {key[:16], []byte("a"), []byte("bc"), "0e961d681106d178b0f3028e52d57a926c2edad2dbc927c6ae942d1d15169001"},
+        {
+            key, []byte("myproto v2 client write key"), []byte("transcript"),
+            "0572ad7a8b447d1b501ecfce58b2da91eaf19473f0f2b5a30ebae1b7c6846a60" +
+                "857326d3cadc2fa5fc79d71f33c1116f7d90",
+        },
+    } {
+        want, _ := hex.DecodeString(tc.out)
+        got, err := KXOF(tc.key, tc.label, tc.context, len(want))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("%q/%q: expected %x, got %x", tc.label, tc.context, want, got)
+        }
+
+        // Streaming the context, then again after Reset.
+        x, err := NewKXOF(tc.key, tc.label)
+        if err != nil {
+            t.Fatal(err)
+        }
+        for i := 0; i < 2; i++ {
+            for _, c := range tc.context {
+                x.Write([]byte{c})
+            }
+            got := make([]byte, len(want))
+            x.Read(got)
+            if !bytes.Equal(got, want) {
+                t.Fatalf("%q/%q: NewKXOF: expected %x, got %x", tc.label, tc.context, want, got)
+            }
+            x.Reset()
+        }
+    }
+
+    if _, err := KXOF(key[:KeySize-1], nil, nil, 16); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := KXOF(key, nil, nil, 0); err == nil {
+        t.Fatal("expected an error")
+    }
+}
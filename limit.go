@@ -0,0 +1,92 @@
+package ascon
+
+import (
+    "errors"
+    "sync/atomic"
+    "crypto/cipher"
+)
+
+// ErrKeyExhausted is returned by Limited.TrySeal, and is the
+// panic value of Limited.Seal, once a message no longer fits in
+// the budget of the key.
+var ErrKeyExhausted = errors.New("ascon: key usage limit reached")
+
+// Limited is a cipher.AEAD that enforces a usage budget for its
+// key: at most maxBytes of plaintext and additional data, in
+// at most maxMessages messages, may be sealed. It is meant to
+// force a key rotation well before the limits of the
+// algorithm, which are far larger than what most protocols
+// allow for a key.
+//
+// A message is charged in full before it is sealed. One that
+// does not fit is refused without being charged, so a smaller
+// message may still go through. Neither budget is ever
+// exceeded, but near the limits a call that races with a
+// refused one may be refused as well.
+//
+// A Limited is safe for concurrent use if the underlying AEAD
+// is. Open is passed through unchanged, and is not charged.
+type Limited struct {
+    cipher.AEAD
+
+    maxBytes, maxMessages uint64
+    bytes, messages       atomic.Uint64
+}
+
+// NewLimited wraps aead in a Limited with a budget of maxBytes
+// bytes and maxMessages messages.
+func NewLimited(aead cipher.AEAD, maxBytes, maxMessages uint64) *Limited {
+    return &Limited{AEAD: aead, maxBytes: maxBytes, maxMessages: maxMessages}
+}
+
+// Seal is like TrySeal, but panics with ErrKeyExhausted instead
+// of returning it, as cipher.AEAD has no way to report it.
+func (l *Limited) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    out, err := l.TrySeal(dst, nonce, plaintext, additionalData)
+    if err != nil {
+        panic(err)
+    }
+    return out
+}
+
+// TrySeal charges the message to the budget and seals it with
+// the underlying AEAD, or returns ErrKeyExhausted if it does
+// not fit.
+func (l *Limited) TrySeal(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+    if !l.charge(uint64(len(plaintext)) + uint64(len(additionalData))) {
+        return nil, ErrKeyExhausted
+    }
+    return l.AEAD.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// Remaining returns the bytes and messages left in the budget.
+func (l *Limited) Remaining() (bytes, messages uint64) {
+    return l.maxBytes - l.bytes.Load(), l.maxMessages - l.messages.Load()
+}
+
+// charge takes a message of n bytes from the budget, and
+// reports whether it fit.
+func (l *Limited) charge(n uint64) bool {
+    if !take(&l.messages, 1, l.maxMessages) {
+        return false
+    }
+    if !take(&l.bytes, n, l.maxBytes) {
+        l.messages.Add(^uint64(0))
+        return false
+    }
+    return true
+}
+
+// take adds n to used if the sum does not exceed limit, and
+// reports whether it did.
+func take(used *atomic.Uint64, n, limit uint64) bool {
+    for {
+        cur := used.Load()
+        if n > limit-cur {
+            return false
+        }
+        if used.CompareAndSwap(cur, cur+n) {
+            return true
+        }
+    }
+}
@@ -0,0 +1,121 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "sync"
+    "testing"
+)
+
+func TestLimited(t *testing.T) {
+    aead, err := New128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    nonce := make([]byte, NonceSize)
+    pt, ad := make([]byte, 60), make([]byte, 40)
+
+    l := NewLimited(aead, 100, 4)
+    got, err := l.TrySeal(nil, nonce, pt, ad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := aead.Seal(nil, nonce, pt, ad); !bytes.Equal(got, want) {
+        t.Fatalf("expected %#x, got %#x", want, got)
+    }
+    if pt, err := l.Open(nil, nonce, got, ad); err != nil || len(pt) != 60 {
+        t.Fatalf("Open: %v", err)
+    }
+
+    // The byte budget is spent, but an empty message still
+    // fits.
+    if _, err := l.TrySeal(nil, nonce, pt[:1], nil); err != ErrKeyExhausted {
+        t.Fatalf("expected %v, got %v", ErrKeyExhausted, err)
+    }
+    if _, err := l.TrySeal(nil, nonce, nil, ad[:1]); err != ErrKeyExhausted {
+        t.Fatalf("expected %v, got %v", ErrKeyExhausted, err)
+    }
+    if b, m := l.Remaining(); b != 0 || m != 3 {
+        t.Fatalf("expected 0, 3 remaining, got %d, %d", b, m)
+    }
+    l.Seal(nil, nonce, nil, nil)
+    l.Seal(nil, nonce, nil, nil)
+    l.Seal(nil, nonce, nil, nil)
+    if b, m := l.Remaining(); b != 0 || m != 0 {
+        t.Fatalf("expected 0, 0 remaining, got %d, %d", b, m)
+    }
+    func() {
+        defer func() {
+            if err, _ := recover().(error); !errors.Is(err, ErrKeyExhausted) {
+                t.Fatalf("expected a panic with %v, got %v", ErrKeyExhausted, err)
+            }
+        }()
+        l.Seal(nil, nonce, nil, nil)
+    }()
+
+    // A refused message is not charged.
+    l = NewLimited(aead, 10, 2)
+    if _, err := l.TrySeal(nil, nonce, pt[:11], nil); err != ErrKeyExhausted {
+        t.Fatalf("expected %v, got %v", ErrKeyExhausted, err)
+    }
+    if _, err := l.TrySeal(nil, nonce, pt[:4], ad[:6]); err != nil {
+        t.Fatal(err)
+    }
+    if b, m := l.Remaining(); b != 0 || m != 1 {
+        t.Fatalf("expected 0, 1 remaining, got %d, %d", b, m)
+    }
+}
+
+func TestLimitedConcurrent(t *testing.T) {
+    aead, err := New128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, tc := range []struct {
+        maxBytes, maxMessages uint64
+        accepted              int
+    }{
+        {1 << 20, 250, 250},
+        {7 * 300, 1 << 20, 300},
+    } {
+        l := NewLimited(aead, tc.maxBytes, tc.maxMessages)
+        var mu sync.Mutex
+        var wg sync.WaitGroup
+        accepted := 0
+        for i := 0; i < 8; i++ {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                nonce := make([]byte, NonceSize)
+                for j := 0; j < 100; j++ {
+                    if _, err := l.TrySeal(nil, nonce, make([]byte, 7), nil); err == nil {
+                        mu.Lock()
+                        accepted++
+                        mu.Unlock()
+                    }
+                }
+            }()
+        }
+        wg.Wait()
+        if accepted != tc.accepted {
+            t.Fatalf("%d/%d: expected %d messages, got %d", tc.maxBytes, tc.maxMessages, tc.accepted, accepted)
+        }
+    }
+}
+
+func TestMessageLimits(t *testing.T) {
+    for _, tc := range []struct {
+        n, adLen uint64
+        want     bool
+    }{
+        {0, 0, false},
+        {MaxPlaintextSize, MaxAADSize, false},
+        {MaxPlaintextSize + 1, 0, true},
+        {0, MaxAADSize + 1, true},
+        {1<<64 - 1, 1<<64 - 1, true},
+    } {
+        if got := tooLarge(tc.n, tc.adLen); got != tc.want {
+            t.Fatalf("tooLarge(%d, %d) = %v", tc.n, tc.adLen, got)
+        }
+    }
+}
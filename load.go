@@ -0,0 +1,28 @@
+//go:build !ascon_unsafe
+
+package ascon
+
+import (
+    "encoding/binary"
+)
+
+// be64, putbe64, le64 and putle64 load and store the full
+// words of the block loops. With the ascon_unsafe tag, they use
+// native loads and stores for aligned slices instead, see
+// load_unsafe_le.go.
+
+func be64(b []byte) uint64 {
+    return binary.BigEndian.Uint64(b)
+}
+
+func putbe64(b []byte, x uint64) {
+    binary.BigEndian.PutUint64(b, x)
+}
+
+func le64(b []byte) uint64 {
+    return binary.LittleEndian.Uint64(b)
+}
+
+func putle64(b []byte, x uint64) {
+    binary.LittleEndian.PutUint64(b, x)
+}
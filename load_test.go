@@ -0,0 +1,98 @@
+package ascon
+
+import (
+    "bytes"
+    "math/rand"
+    "testing"
+    "encoding/binary"
+)
+
+func TestLoad(t *testing.T) {
+    buf := make([]byte, 32)
+    for i := range buf {
+        buf[i] = byte(i + 1)
+    }
+    for off := 0; off < 16; off++ {
+        b := buf[off : off+8]
+        if got, want := be64(b), binary.BigEndian.Uint64(b); got != want {
+            t.Fatalf("be64 at %d: expected %#x, got %#x", off, want, got)
+        }
+        if got, want := le64(b), binary.LittleEndian.Uint64(b); got != want {
+            t.Fatalf("le64 at %d: expected %#x, got %#x", off, want, got)
+        }
+
+        var want [8]byte
+        got := make([]byte, 24)
+        binary.BigEndian.PutUint64(want[:], 0x0102030405060708)
+        putbe64(got[off%8:], 0x0102030405060708)
+        if !bytes.Equal(got[off%8:off%8+8], want[:]) {
+            t.Fatalf("putbe64 at %d: expected %x, got %x", off%8, want, got[off%8:off%8+8])
+        }
+        binary.LittleEndian.PutUint64(want[:], 0x0102030405060708)
+        putle64(got[off%8:], 0x0102030405060708)
+        if !bytes.Equal(got[off%8:off%8+8], want[:]) {
+            t.Fatalf("putle64 at %d: expected %x, got %x", off%8, want, got[off%8:off%8+8])
+        }
+    }
+}
+
+// FuzzBlocks runs the block loops on inputs and outputs at odd
+// offsets, which take the encoding/binary path of the
+// ascon_unsafe tag, and on aligned copies, which take its
+// native path, and checks that the results match.
+func FuzzBlocks(f *testing.F) {
+    f.Add(seq(0, 48), uint8(1), uint8(3), int64(1))
+    f.Add(seq(0, 16), uint8(0), uint8(7), int64(2))
+    f.Fuzz(func(t *testing.T, msg []byte, srcOff, dstOff uint8, seed int64) {
+        s := randState(rand.New(rand.NewSource(seed)))
+        want := blockResults(s, msg, 0, 0)
+        got := blockResults(s, msg, int(srcOff%8), int(dstOff%8))
+        for i := range want {
+            if !bytes.Equal(got[i], want[i]) {
+                t.Fatalf("%d/%d: #%d: expected %x, got %x", srcOff%8, dstOff%8, i, want[i], got[i])
+            }
+        }
+    })
+}
+
+// blockResults returns the state and outputs of every block
+// loop on msg, with the input and output at the given offsets
+// from an aligned start.
+func blockResults(s0 state, msg []byte, srcOff, dstOff int) [][]byte {
+    src := make([]byte, srcOff+len(msg))[srcOff:]
+    copy(src, msg)
+    dst := make([]byte, dstOff+len(msg))[dstOff:]
+    var out [][]byte
+    record := func(s *state, dst []byte) {
+        out = append(out, appendState(nil, s), append([]byte(nil), dst...))
+    }
+
+    n128a := len(msg) &^ (BlockSize128a - 1)
+    n128 := len(msg) &^ (BlockSize128 - 1)
+    for _, fn := range []func(s *state){
+        func(s *state) { additionalData128aGeneric(s, src[:n128a]) },
+        func(s *state) { encryptBlocks128aGeneric(s, dst, src[:n128a]) },
+        func(s *state) { decryptBlocks128aGeneric(s, dst, src[:n128a]) },
+        func(s *state) { additionalData128a(s, src[:n128a]) },
+        func(s *state) { encryptBlocks128a(s, dst, src[:n128a]) },
+        func(s *state) { decryptBlocks128a(s, dst, src[:n128a]) },
+        func(s *state) { s.additionalDataBlocks128(src[:n128]) },
+        func(s *state) { s.encryptBlocks128(dst, src[:n128]) },
+        func(s *state) { s.decryptBlocks128(dst, src[:n128]) },
+        func(s *state) { s.additionalDataBlocksAEAD128(src[:n128a]) },
+        func(s *state) { s.encryptBlocksAEAD128(dst, src[:n128a]) },
+        func(s *state) { s.decryptBlocksAEAD128(dst, src[:n128a]) },
+    } {
+        s := s0
+        for i := range dst {
+            dst[i] = 0
+        }
+        fn(&s)
+        record(&s, dst)
+    }
+
+    h := NewHash256()
+    h.Write(src)
+    out = append(out, h.Sum(dst[:0]))
+    return out
+}
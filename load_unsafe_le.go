@@ -0,0 +1,58 @@
+//go:build ascon_unsafe && !(mips || mips64 || ppc64 || s390x)
+
+package ascon
+
+import (
+    "unsafe"
+    "math/bits"
+    "encoding/binary"
+)
+
+// On CPUs that cannot load unaligned words, the compiler turns
+// each binary.BigEndian.Uint64 into eight byte loads. The
+// ascon_unsafe tag loads and stores 8-byte aligned words
+// directly instead, with one byte swap where the byte order
+// differs, and falls back to encoding/binary for the others.
+// Slices of the start of an allocation are aligned, so that is
+// the common case for whole messages. Where the compiler
+// already combines the loads, as on amd64 and arm64, the tag
+// only adds the alignment checks.
+
+// aligned reports whether b starts at a multiple of 8 bytes.
+func aligned(b []byte) bool {
+    return uintptr(unsafe.Pointer(&b[0]))&7 == 0
+}
+
+func be64(b []byte) uint64 {
+    _ = b[7]
+    if aligned(b) {
+        return bits.ReverseBytes64(*(*uint64)(unsafe.Pointer(&b[0])))
+    }
+    return binary.BigEndian.Uint64(b)
+}
+
+func putbe64(b []byte, x uint64) {
+    _ = b[7]
+    if aligned(b) {
+        *(*uint64)(unsafe.Pointer(&b[0])) = bits.ReverseBytes64(x)
+        return
+    }
+    binary.BigEndian.PutUint64(b, x)
+}
+
+func le64(b []byte) uint64 {
+    _ = b[7]
+    if aligned(b) {
+        return *(*uint64)(unsafe.Pointer(&b[0]))
+    }
+    return binary.LittleEndian.Uint64(b)
+}
+
+func putle64(b []byte, x uint64) {
+    _ = b[7]
+    if aligned(b) {
+        *(*uint64)(unsafe.Pointer(&b[0])) = x
+        return
+    }
+    binary.LittleEndian.PutUint64(b, x)
+}
@@ -0,0 +1,335 @@
+package ascon
+
+import (
+    "errors"
+    "hash"
+    "io"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+const (
+    ivMAC uint64 = 0x80808c0000000080 // Ascon-Mac
+    ivPRF uint64 = 0x80808c0000000000 // Ascon-Prf
+)
+
+const (
+    // macRate is the absorbing rate in bytes of Ascon-Mac and
+    // Ascon-Prf.
+    macRate = 32
+    // macOutRate is the squeezing rate in bytes of Ascon-Mac
+    // and Ascon-Prf.
+    macOutRate = 16
+)
+
+// macState is the keyed sponge shared by Ascon-Mac and
+// Ascon-Prf.
+//
+// Input is absorbed into x0..x3 at a 256-bit rate and output
+// is squeezed from x0..x1 at a 128-bit rate, with p12 between
+// every block.
+type macState struct {
+    s   state
+    buf [macRate]byte
+    n   int
+}
+
+func (m *macState) init(iv, k0, k1 uint64) {
+    m.s = state{0: iv, 1: k0, 2: k1}
+    p12(&m.s)
+    m.n = 0
+}
+
+func (m *macState) write(p []byte) {
+    if m.n > 0 {
+        n := copy(m.buf[m.n:], p)
+        m.n += n
+        p = p[n:]
+        if m.n < macRate {
+            return
+        }
+        m.absorb(m.buf[:])
+        m.n = 0
+    }
+    for len(p) >= macRate {
+        m.absorb(p[:macRate])
+        p = p[macRate:]
+    }
+    m.n = copy(m.buf[:], p)
+}
+
+// absorb XORs a full block into the rate and permutes.
+func (m *macState) absorb(b []byte) {
+    m.s[0] ^= binary.BigEndian.Uint64(b[0:8])
+    m.s[1] ^= binary.BigEndian.Uint64(b[8:16])
+    m.s[2] ^= binary.BigEndian.Uint64(b[16:24])
+    m.s[3] ^= binary.BigEndian.Uint64(b[24:32])
+    p12(&m.s)
+}
+
+// finish pads and absorbs the final (possibly full) block and
+// applies the domain separation bit, leaving the state ready
+// for squeezing.
+func (m *macState) finish() {
+    for i := m.n; i < macRate; i++ {
+        m.buf[i] = 0
+    }
+    m.buf[m.n] = 0x80
+    m.s[0] ^= binary.BigEndian.Uint64(m.buf[0:8])
+    m.s[1] ^= binary.BigEndian.Uint64(m.buf[8:16])
+    m.s[2] ^= binary.BigEndian.Uint64(m.buf[16:24])
+    m.s[3] ^= binary.BigEndian.Uint64(m.buf[24:32])
+    m.s[4] ^= 1
+    p12(&m.s)
+    m.n = 0
+}
+
+// squeeze writes one 16-byte output block to dst and permutes.
+func (m *macState) squeeze(dst []byte) {
+    binary.BigEndian.PutUint64(dst[0:8], m.s[0])
+    binary.BigEndian.PutUint64(dst[8:16], m.s[1])
+    p12(&m.s)
+}
+
+// MACSize is the size in bytes of an Ascon-Mac authenticator.
+const MACSize = 16
+
+type mac struct {
+    m      macState
+    k0, k1 uint64
+}
+
+var _ hash.Hash = (*mac)(nil)
+
+// NewMAC returns a new hash.Hash computing the Ascon-Mac
+// authenticator of the data written to it under key.
+//
+// Ascon-Mac absorbs 32 bytes per permutation and produces
+// a MACSize byte tag. Like crypto/hmac, the result can be used
+// wherever a hash.Hash is expected, and Reset restores the
+// keyed initial state. Use VerifyMAC, Verify or crypto/subtle
+// to compare tags; never use bytes.Equal.
+//
+// The key must be exactly 16 bytes long.
+func NewMAC(key []byte) (hash.Hash, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+    h := &mac{
+        k0: binary.BigEndian.Uint64(key[0:]),
+        k1: binary.BigEndian.Uint64(key[8:]),
+    }
+    h.Reset()
+    return h, nil
+}
+
+func (h *mac) Write(p []byte) (int, error) {
+    h.m.write(p)
+    return len(p), nil
+}
+
+func (h *mac) Sum(b []byte) []byte {
+    // Work on a copy so that the caller can keep writing.
+    m := h.m
+    m.finish()
+    ret, out := subtle.SliceForAppend(b, MACSize)
+    m.squeeze(out)
+    return ret
+}
+
+func (h *mac) Reset() {
+    h.m.init(ivMAC, h.k0, h.k1)
+}
+
+func (h *mac) Size() int {
+    return MACSize
+}
+
+func (h *mac) BlockSize() int {
+    return macRate
+}
+
+// MAC returns the Ascon-Mac authenticator of msg under key.
+//
+// It panics if the key is not exactly 16 bytes long.
+func MAC(key, msg []byte) [MACSize]byte {
+    if len(key) != KeySize {
+        panic("ascon: bad key length")
+    }
+
+    var m macState
+    m.init(ivMAC, binary.BigEndian.Uint64(key[0:]), binary.BigEndian.Uint64(key[8:]))
+    m.write(msg)
+    m.finish()
+    var tag [MACSize]byte
+    m.squeeze(tag[:])
+    return tag
+}
+
+// MAC128 returns the 128-bit Ascon-Mac authenticator of data
+// under key. It is the same as MAC and, like it, does not
+// allocate.
+//
+// It panics if the key is not exactly 16 bytes long.
+func MAC128(key, data []byte) [MACSize]byte {
+    return MAC(key, data)
+}
+
+// VerifyMAC reports whether tag is the Ascon-Mac authenticator
+// of msg under key. The comparison is constant-time.
+//
+// It panics if the key is not exactly 16 bytes long.
+func VerifyMAC(key, msg, tag []byte) bool {
+    want := MAC(key, msg)
+    return subtle.ConstantTimeCompare(want[:], tag) == 1
+}
+
+// MinMACSize is the shortest truncated Ascon-Mac authenticator
+// accepted by Verify.
+const MinMACSize = 8
+
+// Verify reports whether tag is the Ascon-Mac authenticator of
+// message under key, or a truncation of it to at least
+// MinMACSize bytes. The comparison is constant-time for tags of
+// the same length.
+//
+// Truncated tags make forgeries proportionally easier; use
+// VerifyMAC to require the full tag.
+//
+// It panics if the key is not exactly 16 bytes long.
+func Verify(key, message, tag []byte) bool {
+    want := MAC(key, message)
+    if len(tag) < MinMACSize || len(tag) > MACSize {
+        return false
+    }
+    return subtle.ConstantTimeCompare(want[:len(tag)], tag) == 1
+}
+
+// PRF is the Ascon-Prf keyed pseudorandom function: input is
+// written with Write and an output of any length is read with
+// Read.
+//
+// Unlike the unkeyed XOF, the output of a PRF can only be
+// computed with the key, which makes it suitable for key
+// derivation and token generation. Reading n bytes and then m
+// bytes produces the same output as reading n+m bytes at once.
+// Calling Write after the first Read panics; use Reset to
+// start over.
+type PRF struct {
+    m      macState
+    k0, k1 uint64
+    // squeezing is set by the first Read, after which out
+    // holds the current output block and off the number of
+    // bytes of it already read.
+    squeezing bool
+    out       [macOutRate]byte
+    off       int
+}
+
+var _ io.ReadWriter = (*PRF)(nil)
+
+// NewPRF returns a new Ascon-Prf keyed with key.
+//
+// The key must be exactly 16 bytes long.
+func NewPRF(key []byte) (*PRF, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+    p := &PRF{
+        k0: binary.BigEndian.Uint64(key[0:]),
+        k1: binary.BigEndian.Uint64(key[8:]),
+    }
+    p.Reset()
+    return p, nil
+}
+
+// Write absorbs more data. It never returns an error, but
+// panics if called after Read.
+func (p *PRF) Write(b []byte) (int, error) {
+    if p.squeezing {
+        panic("ascon: PRF written after read")
+    }
+    p.m.write(b)
+    return len(b), nil
+}
+
+// Read squeezes len(b) bytes of output. It never returns an
+// error.
+func (p *PRF) Read(b []byte) (int, error) {
+    if !p.squeezing {
+        p.m.finish()
+        p.m.squeeze(p.out[:])
+        p.squeezing = true
+        p.off = 0
+    }
+
+    n := len(b)
+    for len(b) > 0 {
+        if p.off == macOutRate {
+            p.m.squeeze(p.out[:])
+            p.off = 0
+        }
+        c := copy(b, p.out[p.off:])
+        p.off += c
+        b = b[c:]
+    }
+    return n, nil
+}
+
+// Reset resets the PRF to its initial keyed state.
+func (p *PRF) Reset() {
+    p.m.init(ivPRF, p.k0, p.k1)
+    p.squeezing = false
+}
+
+const (
+    // MaxPRFShortInputSize is the maximum input size in bytes
+    // of Ascon-PrfShort.
+    MaxPRFShortInputSize = 16
+    // MaxPRFShortOutputSize is the maximum output size in bytes
+    // of Ascon-PrfShort.
+    MaxPRFShortOutputSize = 16
+)
+
+// PRFShort returns outLen bytes of Ascon-PrfShort output for
+// the input in under key.
+//
+// Ascon-PrfShort computes its output with a single call to
+// p12, so it is much cheaper than PRF for short inputs such as
+// per-packet counters. The input must be at most
+// MaxPRFShortInputSize bytes and outLen must be in the range
+// [1, MaxPRFShortOutputSize]. The input and output lengths are
+// both part of the computation, so truncating the output of
+// a longer call does not give the same result.
+//
+// The key must be exactly 16 bytes long.
+func PRFShort(key, in []byte, outLen int) ([]byte, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+    if len(in) > MaxPRFShortInputSize {
+        return nil, errors.New("ascon: PRFShort input too long")
+    }
+    if outLen < 1 || outLen > MaxPRFShortOutputSize {
+        return nil, errors.New("ascon: bad PRFShort output length")
+    }
+
+    k0 := binary.BigEndian.Uint64(key[0:])
+    k1 := binary.BigEndian.Uint64(key[8:])
+    var m [MaxPRFShortInputSize]byte
+    copy(m[:], in)
+    s := state{
+        0: 0x80<<56 | uint64(8*len(in))<<48 | 0x4c<<40 | uint64(8*outLen)<<32,
+        1: k0,
+        2: k1,
+        3: binary.BigEndian.Uint64(m[0:]),
+        4: binary.BigEndian.Uint64(m[8:]),
+    }
+    p12(&s)
+
+    var out [MaxPRFShortOutputSize]byte
+    binary.BigEndian.PutUint64(out[0:], s[3]^k0)
+    binary.BigEndian.PutUint64(out[8:], s[4]^k1)
+    return out[:outLen:outLen], nil
+}
@@ -0,0 +1,286 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "path/filepath"
+    "testing"
+)
+
+func TestVectorsMAC(t *testing.T) {
+    vecs, err := readVecs(filepath.Join("testdata", "vectors_mac.txt"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, v := range vecs {
+        h, err := NewMAC(v.key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        h.Write(v.msg)
+        if got := h.Sum(nil); !bytes.Equal(got, v.tag) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.tag, got)
+        }
+        if got := MAC(v.key, v.msg); !bytes.Equal(got[:], v.tag) {
+            t.Fatalf("#%d: MAC: expected %#x, got %#x", i+1, v.tag, got)
+        }
+        if !VerifyMAC(v.key, v.msg, v.tag) {
+            t.Fatalf("#%d: VerifyMAC failed", i+1)
+        }
+        bad := append([]byte(nil), v.tag...)
+        bad[i%len(bad)] ^= 1
+        if VerifyMAC(v.key, v.msg, bad) {
+            t.Fatalf("#%d: VerifyMAC accepted a bad tag", i+1)
+        }
+    }
+}
+
+func TestMACIncremental(t *testing.T) {
+    key := make([]byte, KeySize)
+    msg := make([]byte, 200)
+    for i := range msg {
+        msg[i] = byte(i)
+    }
+    want := MAC(key, msg)
+
+    h, err := NewMAC(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, step := range []int{1, 5, 31, 32, 33, 64} {
+        h.Reset()
+        for p := msg; len(p) > 0; {
+            n := step
+            if n > len(p) {
+                n = len(p)
+            }
+            h.Write(p[:n])
+            h.Sum(nil)
+            p = p[n:]
+        }
+        if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+            t.Fatalf("step %d: expected %#x, got %#x", step, want, got)
+        }
+    }
+
+    if _, err := NewMAC(key[1:]); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+func TestVectorsPRF(t *testing.T) {
+    vecs, err := readVecs(filepath.Join("testdata", "vectors_prf.txt"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, v := range vecs {
+        p, err := NewPRF(v.key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        p.Write(v.msg)
+        got := make([]byte, len(v.tag))
+        p.Read(got)
+        if !bytes.Equal(got, v.tag) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.tag, got)
+        }
+    }
+}
+
+func TestPRF(t *testing.T) {
+    key := make([]byte, KeySize)
+    for i := range key {
+        key[i] = byte(i)
+    }
+    want, _ := hex.DecodeString("83915dd4b15414d1ce2f165dd0345b2f45186eefb076e2b9994ab0806dd96a92" +
+        "27666eb3cc0fda5616aa377e3e9ace42eb19700dbb7a64323fa91dbe68cb6ef8" +
+        "73f0084141762d4b46364f05b1124da167543aef09d2f93cde9f614c1138e942" +
+        "4dcee1c3")
+
+    p, err := NewPRF(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, step := range []int{1, 7, 16, 17, len(want)} {
+        p.Reset()
+        p.Write([]byte("abc"))
+        var got []byte
+        for len(got) < len(want) {
+            n := step
+            if n > len(want)-len(got) {
+                n = len(want) - len(got)
+            }
+            b := make([]byte, n)
+            p.Read(b)
+            got = append(got, b...)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("step %d: expected %#x, got %#x", step, want, got)
+        }
+    }
+
+    if _, err := NewPRF(key[1:]); err == nil {
+        t.Fatal("expected an error")
+    }
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expected a panic")
+        }
+    }()
+    p.Write([]byte("more"))
+}
+
+func TestVectorsPRFShort(t *testing.T) {
+    vecs, err := readVecs(filepath.Join("testdata", "vectors_prfshort.txt"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, v := range vecs {
+        got, err := PRFShort(v.key, v.msg, len(v.tag))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, v.tag) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.tag, got)
+        }
+    }
+}
+
+func TestPRFShortLimits(t *testing.T) {
+    key := make([]byte, KeySize)
+    for _, tc := range []struct {
+        key    []byte
+        inLen  int
+        outLen int
+    }{
+        {key[1:], 0, 16},
+        {key, MaxPRFShortInputSize + 1, 16},
+        {key, 0, 0},
+        {key, 0, MaxPRFShortOutputSize + 1},
+    } {
+        if _, err := PRFShort(tc.key, make([]byte, tc.inLen), tc.outLen); err == nil {
+            t.Fatalf("%d/%d/%d: expected an error", len(tc.key), tc.inLen, tc.outLen)
+        }
+    }
+}
+
+func BenchmarkPRFShort8(b *testing.B) {
+    key := make([]byte, KeySize)
+    in := make([]byte, 8)
+    b.SetBytes(int64(len(in)))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        if _, err := PRFShort(key, in, 16); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
+
+func BenchmarkPRF8(b *testing.B) {
+    key := make([]byte, KeySize)
+    in := make([]byte, 8)
+    out := make([]byte, 16)
+    p, err := NewPRF(key)
+    if err != nil {
+        b.Fatal(err)
+    }
+    b.SetBytes(int64(len(in)))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        p.Reset()
+        p.Write(in)
+        p.Read(out)
+    }
+}
+
+func TestVerify(t *testing.T) {
+    key := make([]byte, KeySize)
+    msg := []byte("message")
+    tag := MAC(key, msg)
+    for n := 0; n <= MACSize+1; n++ {
+        in := append(tag[:], 0)[:n]
+        want := n >= MinMACSize && n <= MACSize
+        if got := Verify(key, msg, in); got != want {
+            t.Fatalf("%d bytes: expected %v, got %v", n, want, got)
+        }
+        if n > 0 {
+            bad := append([]byte(nil), in...)
+            bad[n-1] ^= 1
+            if Verify(key, msg, bad) {
+                t.Fatalf("%d bytes: accepted a bad tag", n)
+            }
+        }
+    }
+    if VerifyMAC(key, msg, tag[:MinMACSize]) {
+        t.Fatal("VerifyMAC accepted a truncated tag")
+    }
+}
+
+func TestMACReset(t *testing.T) {
+    key := []byte("0123456789abcdef")
+    h, err := NewMAC(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    h.Write([]byte("first message"))
+    h.Sum(nil)
+
+    h.Reset()
+    h.Write([]byte("second message"))
+    want := MAC(key, []byte("second message"))
+    if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+        t.Fatalf("expected %#x, got %#x", want, got)
+    }
+
+    // The reset state must be keyed.
+    h.Reset()
+    other, _ := NewMAC(make([]byte, KeySize))
+    if bytes.Equal(h.Sum(nil), other.Sum(nil)) {
+        t.Fatal("Reset lost the key")
+    }
+    if h.Size() != MACSize || h.BlockSize() != 32 {
+        t.Fatalf("unexpected sizes %d, %d", h.Size(), h.BlockSize())
+    }
+}
+
+func TestMAC128Allocs(t *testing.T) {
+    key := make([]byte, KeySize)
+    data := make([]byte, 40)
+    h, _ := NewMAC(key)
+    h.Write(data)
+    want := h.Sum(nil)
+    if got := MAC128(key, data); !bytes.Equal(got[:], want) {
+        t.Fatalf("expected %#x, got %#x", want, got)
+    }
+    allocs := testing.AllocsPerRun(100, func() {
+        MAC128(key, data)
+    })
+    if allocs != 0 {
+        t.Fatalf("expected 0 allocations, got %v", allocs)
+    }
+}
+
+func BenchmarkMAC128_40(b *testing.B) {
+    key := make([]byte, KeySize)
+    data := make([]byte, 40)
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        MAC128(key, data)
+    }
+}
+
+func BenchmarkMACHash40(b *testing.B) {
+    key := make([]byte, KeySize)
+    data := make([]byte, 40)
+    h, _ := NewMAC(key)
+    var out []byte
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        h.Reset()
+        h.Write(data)
+        out = h.Sum(out[:0])
+    }
+}
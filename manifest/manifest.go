@@ -0,0 +1,348 @@
+// Package manifest records the files of a directory tree with
+// their Ascon-Hash256 digests, in the manner of hashdeep, and
+// checks trees against such manifests.
+//
+// A manifest has an entry for every regular file and symbolic
+// link of a tree, holding its slash-separated path relative to
+// the root of the tree, its size and its digest:
+//
+//  - a regular file, empty or not, has its size in bytes and
+//    the Ascon-Hash256 digest of its contents;
+//  - a symbolic link is not followed, and has the length and
+//    the digest of its target, the path it points to.
+//
+// Directories are not recorded, so an empty directory does not
+// appear in a manifest. Other files, such as devices and named
+// pipes, make Build fail.
+//
+// The text form of a manifest is a header line followed by one
+// line per entry, sorted by path in byte order:
+//
+//    ascon-manifest 1
+//    f 0 0b3be5850f2f6b98caf29f8fdea89b64a1fa70aa249b8f839bd53baa304d92b2 empty
+//    f 5 <digest> docs/a.txt
+//    l 5 <digest> latest
+//
+// The fields of an entry are its kind, f for a file or l for a
+// link, its size in decimal, its digest in lowercase
+// hexadecimal and its path. A path that has control characters
+// or a backslash, or starts with a double quote, is written as a
+// Go string literal. The same tree always gives the same text,
+// so that changes to it read well in a diff.
+//
+// Root is a digest of the whole manifest, to be signed
+// elsewhere. It is the digest of the Merkle tree defined in
+// internal/merkle, in which leaf i is the text of entry i
+// without its newline, framed by the number of entries.
+package manifest
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "sort"
+    "strconv"
+    "strings"
+    "encoding/hex"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/internal/merkle"
+)
+
+// Size is the size in bytes of the digests of a manifest.
+const Size = ascon.HashSize
+
+// header is the first line of the text form of a manifest.
+const header = "ascon-manifest 1"
+
+// Kind is the kind of file of an entry.
+type Kind byte
+
+const (
+    // File is a regular file.
+    File Kind = 'f'
+    // Symlink is a symbolic link.
+    Symlink Kind = 'l'
+)
+
+// Entry is the record of a file in a manifest.
+type Entry struct {
+    // Path is the path of the file, as accepted by fs.ValidPath.
+    Path string
+    Kind Kind
+    // Size is the size of the file, or of the target of the
+    // link.
+    Size int64
+    // Digest is the Ascon-Hash256 digest of the contents of the
+    // file, or of the target of the link.
+    Digest [Size]byte
+}
+
+// Manifest is a list of entries, sorted by path.
+type Manifest struct {
+    Entries []Entry
+}
+
+// ErrSymlink is returned by Build for a symbolic link in a file
+// system that cannot read links.
+var ErrSymlink = errors.New("manifest: file system cannot read symbolic links")
+
+// readLinkFS is implemented by the file systems, such as those
+// of os.DirFS, that can read symbolic links.
+type readLinkFS interface {
+    ReadLink(name string) (string, error)
+}
+
+// Build walks fsys from its root and returns the manifest of its
+// files. It returns ErrSymlink for a symbolic link if fsys
+// cannot read links.
+func Build(fsys fs.FS) (*Manifest, error) {
+    m := &Manifest{}
+    err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+        e := Entry{Path: path}
+        switch t := d.Type(); {
+        case t.IsDir():
+            return nil
+        case t.IsRegular():
+            e.Kind = File
+            if e.Size, e.Digest, err = hashFile(fsys, path); err != nil {
+                return err
+            }
+        case t&fs.ModeSymlink != 0:
+            rl, ok := fsys.(readLinkFS)
+            if !ok {
+                return ErrSymlink
+            }
+            target, err := rl.ReadLink(path)
+            if err != nil {
+                return err
+            }
+            e.Kind = Symlink
+            e.Size, e.Digest = int64(len(target)), sum([]byte(target))
+        default:
+            return fmt.Errorf("manifest: %s: unsupported file type %v", path, t)
+        }
+        m.Entries = append(m.Entries, e)
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    // WalkDir visits directories in lexical order of their
+    // entries, which is not the order of the full paths: "a/b"
+    // comes before "a-b".
+    sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+    return m, nil
+}
+
+// sum returns the Ascon-Hash256 digest of the concatenation of
+// parts.
+func sum(parts ...[]byte) [Size]byte {
+    h := ascon.NewHash256()
+    for _, p := range parts {
+        h.Write(p)
+    }
+    var d [Size]byte
+    h.Sum(d[:0])
+    return d
+}
+
+// hashFile returns the size and digest of the file name.
+func hashFile(fsys fs.FS, name string) (int64, [Size]byte, error) {
+    var d [Size]byte
+    f, err := fsys.Open(name)
+    if err != nil {
+        return 0, d, err
+    }
+    defer f.Close()
+    h := ascon.NewHash256()
+    n, err := io.Copy(h, f)
+    if err != nil {
+        return 0, d, err
+    }
+    h.Sum(d[:0])
+    return n, d, nil
+}
+
+// quotePath reports whether path must be written quoted.
+func quotePath(path string) bool {
+    if strings.HasPrefix(path, `"`) {
+        return true
+    }
+    for _, r := range path {
+        if r < 0x20 || r == 0x7f || r == '\\' {
+            return true
+        }
+    }
+    return false
+}
+
+// appendLine appends the text of e, without a newline.
+func appendLine(b []byte, e *Entry) []byte {
+    b = append(b, byte(e.Kind), ' ')
+    b = strconv.AppendInt(b, e.Size, 10)
+    b = append(b, ' ')
+    var d [2 * Size]byte
+    hex.Encode(d[:], e.Digest[:])
+    b = append(b, d[:]...)
+    b = append(b, ' ')
+    if quotePath(e.Path) {
+        return strconv.AppendQuote(b, e.Path)
+    }
+    return append(b, e.Path...)
+}
+
+// MarshalText returns the text form of m. It fails if the
+// entries are not valid or not sorted.
+func (m *Manifest) MarshalText() ([]byte, error) {
+    if err := m.check(); err != nil {
+        return nil, err
+    }
+    b := append([]byte(header), '\n')
+    for i := range m.Entries {
+        b = append(appendLine(b, &m.Entries[i]), '\n')
+    }
+    return b, nil
+}
+
+// check returns an error if an entry of m is invalid or out of
+// order.
+func (m *Manifest) check() error {
+    for i, e := range m.Entries {
+        if !fs.ValidPath(e.Path) || e.Path == "." {
+            return fmt.Errorf("manifest: invalid path %q", e.Path)
+        }
+        if e.Kind != File && e.Kind != Symlink {
+            return fmt.Errorf("manifest: %s: invalid kind %q", e.Path, byte(e.Kind))
+        }
+        if e.Size < 0 {
+            return fmt.Errorf("manifest: %s: negative size", e.Path)
+        }
+        if i > 0 && m.Entries[i-1].Path >= e.Path {
+            return fmt.Errorf("manifest: %s: entries not sorted or repeated", e.Path)
+        }
+    }
+    return nil
+}
+
+// UnmarshalText parses the text form of a manifest into m. It
+// only accepts the text that MarshalText returns for the same
+// entries.
+func (m *Manifest) UnmarshalText(text []byte) error {
+    lines := strings.Split(string(text), "\n")
+    if len(lines) < 2 || lines[0] != header || lines[len(lines)-1] != "" {
+        return errors.New("manifest: not a manifest")
+    }
+    var parsed Manifest
+    for n, line := range lines[1 : len(lines)-1] {
+        e, err := parseLine(line)
+        if err != nil {
+            return fmt.Errorf("manifest: line %d: %v", n+2, err)
+        }
+        parsed.Entries = append(parsed.Entries, e)
+    }
+    if err := parsed.check(); err != nil {
+        return err
+    }
+    *m = parsed
+    return nil
+}
+
+// parseLine parses the text of an entry.
+func parseLine(line string) (Entry, error) {
+    var e Entry
+    f := strings.SplitN(line, " ", 4)
+    if len(f) != 4 || len(f[0]) != 1 {
+        return e, errors.New("malformed entry")
+    }
+    e.Kind = Kind(f[0][0])
+    size, err := strconv.ParseInt(f[1], 10, 64)
+    if err != nil || strconv.FormatInt(size, 10) != f[1] {
+        return e, errors.New("malformed size")
+    }
+    e.Size = size
+    if len(f[2]) != 2*Size || strings.ToLower(f[2]) != f[2] {
+        return e, errors.New("malformed digest")
+    }
+    if _, err := hex.Decode(e.Digest[:], []byte(f[2])); err != nil {
+        return e, errors.New("malformed digest")
+    }
+    e.Path = f[3]
+    if strings.HasPrefix(e.Path, `"`) {
+        if e.Path, err = strconv.Unquote(e.Path); err != nil {
+            return e, errors.New("malformed path")
+        }
+    }
+    // Reject the paths that would be written otherwise.
+    if !bytes.Equal(appendLine(nil, &e), []byte(line)) {
+        return e, errors.New("non-canonical entry")
+    }
+    return e, nil
+}
+
+// Root returns the digest of m, the root of the Merkle tree of
+// its entries.
+func (m *Manifest) Root() [Size]byte {
+    var t merkle.Tree
+    var line []byte
+    for i := range m.Entries {
+        line = appendLine(line[:0], &m.Entries[i])
+        t.Push(merkle.Leaf(uint64(i), line))
+    }
+    root := t.Root()
+    return merkle.Digest(&root, t.Len())
+}
+
+// Diff lists the differences between two manifests, by path.
+type Diff struct {
+    // Added are the paths only in the new manifest.
+    Added []string
+    // Removed are the paths only in the old manifest.
+    Removed []string
+    // Modified are the paths whose kind, size or digest changed.
+    Modified []string
+}
+
+// Empty reports whether the manifests had no differences.
+func (d *Diff) Empty() bool {
+    return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Compare returns the differences from the manifest from, the
+// old one, to the manifest to, the new one. Both must be sorted
+// by path.
+func Compare(from, to *Manifest) *Diff {
+    d := &Diff{}
+    a, b := from.Entries, to.Entries
+    for len(a) > 0 || len(b) > 0 {
+        switch {
+        case len(b) == 0 || (len(a) > 0 && a[0].Path < b[0].Path):
+            d.Removed = append(d.Removed, a[0].Path)
+            a = a[1:]
+        case len(a) == 0 || b[0].Path < a[0].Path:
+            d.Added = append(d.Added, b[0].Path)
+            b = b[1:]
+        default:
+            if a[0] != b[0] {
+                d.Modified = append(d.Modified, a[0].Path)
+            }
+            a, b = a[1:], b[1:]
+        }
+    }
+    return d
+}
+
+// Verify builds the manifest of fsys, as Build, and returns its
+// differences from m.
+func (m *Manifest) Verify(fsys fs.FS) (*Diff, error) {
+    cur, err := Build(fsys)
+    if err != nil {
+        return nil, err
+    }
+    return Compare(m, cur), nil
+}
@@ -0,0 +1,254 @@
+package manifest
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/hex"
+    "io/fs"
+    "reflect"
+    "strings"
+    "testing"
+    "testing/fstest"
+)
+
+// linkFS is a MapFS whose symbolic links point to the contents
+// of their files.
+type linkFS struct {
+    fstest.MapFS
+}
+
+func (l linkFS) ReadLink(name string) (string, error) {
+    f, ok := l.MapFS[name]
+    if !ok || f.Mode&fs.ModeSymlink == 0 {
+        return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+    }
+    return string(f.Data), nil
+}
+
+func testFS() linkFS {
+    return linkFS{fstest.MapFS{
+        "a/b":         {Data: []byte("nested")},
+        "a-b":         {Data: []byte("hyphen")},
+        "empty":       {Data: nil},
+        "emptydir":    {Mode: fs.ModeDir},
+        "latest":      {Data: []byte("a/b"), Mode: fs.ModeSymlink},
+        "with space":  {Data: []byte("x")},
+        "line\nbreak": {Data: []byte("y")},
+    }}
+}
+
+func digest(s string) string {
+    d := sum([]byte(s))
+    return hex.EncodeToString(d[:])
+}
+
+func TestBuild(t *testing.T) {
+    m, err := Build(testFS())
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := "ascon-manifest 1\n" +
+        "f 6 " + digest("hyphen") + " a-b\n" +
+        "f 6 " + digest("nested") + " a/b\n" +
+        "f 0 " + digest("") + " empty\n" +
+        "l 3 " + digest("a/b") + " latest\n" +
+        "f 1 " + digest("y") + " \"line\\nbreak\"\n" +
+        "f 1 " + digest("x") + " with space\n"
+    text, err := m.MarshalText()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(text) != want {
+        t.Fatalf("expected\n%s\ngot\n%s", want, text)
+    }
+    if !strings.Contains(want, "0b3be5850f2f6b98caf29f8fdea89b64a1fa70aa249b8f839bd53baa304d92b2 empty") {
+        t.Fatal("wrong digest of the empty file")
+    }
+
+    var parsed Manifest
+    if err := parsed.UnmarshalText(text); err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(&parsed, m) {
+        t.Fatalf("expected %+v, got %+v", m, &parsed)
+    }
+    if parsed.Root() != m.Root() {
+        t.Fatal("the parsed manifest has another root")
+    }
+}
+
+// reference computes Root as specified in the documentation of
+// the package and of internal/merkle.
+func reference(m *Manifest) [Size]byte {
+    var level [][Size]byte
+    for i := range m.Entries {
+        level = append(level, sum([]byte{0x00}, binary.BigEndian.AppendUint64(nil, uint64(i)),
+            appendLine(nil, &m.Entries[i])))
+    }
+    for len(level) > 1 {
+        var next [][Size]byte
+        for i := 0; i < len(level); i += 2 {
+            if i+1 == len(level) {
+                next = append(next, level[i])
+            } else {
+                next = append(next, sum([]byte{0x01}, level[i][:], level[i+1][:]))
+            }
+        }
+        level = next
+    }
+    var node [Size]byte
+    if len(level) > 0 {
+        node = level[0]
+    }
+    return sum([]byte{0x02}, binary.BigEndian.AppendUint64(nil, uint64(len(m.Entries))), node[:])
+}
+
+func TestRoot(t *testing.T) {
+    m := &Manifest{}
+    roots := map[[Size]byte]bool{}
+    for n := 0; n <= 17; n++ {
+        if n > 0 {
+            path := string(rune('a' + n))
+            m.Entries = append(m.Entries, Entry{Path: path, Kind: File, Size: 1, Digest: sum([]byte(path))})
+        }
+        r := m.Root()
+        if r != reference(m) {
+            t.Fatalf("%d entries: root differs from the reference", n)
+        }
+        if roots[r] {
+            t.Fatalf("%d entries: repeated root", n)
+        }
+        roots[r] = true
+    }
+
+    // Every field of every entry is bound.
+    base := m.Root()
+    for i := range m.Entries {
+        for _, change := range []func(e *Entry){
+            func(e *Entry) { e.Kind = Symlink },
+            func(e *Entry) { e.Size++ },
+            func(e *Entry) { e.Digest[Size-1] ^= 1 },
+            func(e *Entry) { e.Path += "z" },
+        } {
+            c := &Manifest{Entries: append([]Entry(nil), m.Entries...)}
+            change(&c.Entries[i])
+            if c.Root() == base {
+                t.Fatalf("entry %d: changed manifest has the same root", i)
+            }
+        }
+    }
+}
+
+func TestVerify(t *testing.T) {
+    fsys := testFS()
+    m, err := Build(fsys)
+    if err != nil {
+        t.Fatal(err)
+    }
+    d, err := m.Verify(fsys)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !d.Empty() {
+        t.Fatalf("unexpected differences %+v", d)
+    }
+
+    delete(fsys.MapFS, "a-b")
+    fsys.MapFS["new/file"] = &fstest.MapFile{Data: []byte("new")}
+    fsys.MapFS["a/b"] = &fstest.MapFile{Data: []byte("Nested")}
+    fsys.MapFS["empty"] = &fstest.MapFile{Data: nil, Mode: fs.ModeSymlink}
+    fsys.MapFS["latest"] = &fstest.MapFile{Data: []byte("a-b"), Mode: fs.ModeSymlink}
+    fsys.MapFS["emptydir/x"] = &fstest.MapFile{Data: nil}
+    d, err = m.Verify(fsys)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := &Diff{
+        Added:    []string{"emptydir/x", "new/file"},
+        Removed:  []string{"a-b"},
+        Modified: []string{"a/b", "empty", "latest"},
+    }
+    if !reflect.DeepEqual(d, want) {
+        t.Fatalf("expected %+v, got %+v", want, d)
+    }
+}
+
+func TestBuildErrors(t *testing.T) {
+    // A file system that cannot read links.
+    type openOnly struct{ fs.FS }
+    if _, err := Build(openOnly{testFS()}); err != ErrSymlink {
+        t.Fatalf("expected %v, got %v", ErrSymlink, err)
+    }
+    fsys := fstest.MapFS{"fifo": {Mode: fs.ModeNamedPipe}}
+    if _, err := Build(fsys); err == nil {
+        t.Fatal("expected an error for a named pipe")
+    }
+    m, err := Build(fstest.MapFS{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if text, _ := m.MarshalText(); string(text) != header+"\n" {
+        t.Fatalf("unexpected empty manifest %q", text)
+    }
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+    d := digest("x")
+    for _, text := range []string{
+        "",
+        "ascon-manifest 1",
+        "ascon-manifest 2\n",
+        "ascon-manifest 1\n\n",
+        "ascon-manifest 1\nf 1 " + d + " a",
+        "ascon-manifest 1\nf 1 " + d + " a\r\n",
+        "ascon-manifest 1\nx 1 " + d + " a\n",
+        "ascon-manifest 1\nf 01 " + d + " a\n",
+        "ascon-manifest 1\nf +1 " + d + " a\n",
+        "ascon-manifest 1\nf -1 " + d + " a\n",
+        "ascon-manifest 1\nf 1 " + strings.ToUpper(d) + " a\n",
+        "ascon-manifest 1\nf 1 " + d[1:] + " a\n",
+        "ascon-manifest 1\nf 1  " + d + " a\n",
+        "ascon-manifest 1\nf 1 " + d + "\n",
+        "ascon-manifest 1\nf 1 " + d + " /a\n",
+        "ascon-manifest 1\nf 1 " + d + " a/../b\n",
+        "ascon-manifest 1\nf 1 " + d + " .\n",
+        "ascon-manifest 1\nf 1 " + d + " \"a\"\n",
+        "ascon-manifest 1\nf 1 " + d + " \"a\n",
+        "ascon-manifest 1\nf 1 " + d + " b\nf 1 " + d + " a\n",
+        "ascon-manifest 1\nf 1 " + d + " a\nl 1 " + d + " a\n",
+    } {
+        var m Manifest
+        if err := m.UnmarshalText([]byte(text)); err == nil {
+            t.Errorf("%q: expected an error", text)
+        }
+    }
+
+    bad := &Manifest{Entries: []Entry{{Path: "b", Kind: File}, {Path: "a", Kind: File}}}
+    if _, err := bad.MarshalText(); err == nil {
+        t.Fatal("expected an error for unsorted entries")
+    }
+}
+
+func TestQuotedPaths(t *testing.T) {
+    for _, path := range []string{`"quoted"`, `back\slash`, "tab\there", "del\x7f", "ünïcode", "trailing "} {
+        m := &Manifest{Entries: []Entry{{Path: path, Kind: File}}}
+        text, err := m.MarshalText()
+        if err != nil {
+            t.Fatal(err)
+        }
+        if bytes.Count(text, []byte("\n")) != 2 {
+            t.Fatalf("%q: malformed text %q", path, text)
+        }
+        var parsed Manifest
+        if err := parsed.UnmarshalText(text); err != nil {
+            t.Fatalf("%q: %v", path, err)
+        }
+        if !reflect.DeepEqual(&parsed, m) {
+            t.Fatalf("expected %+v, got %+v", m, &parsed)
+        }
+    }
+    var m Manifest
+    if err := m.UnmarshalText([]byte(header + "\nf 0 " + digest("") + " a\nf 0 " + digest("") + " \"b\\u0041\"\n")); err == nil {
+        t.Fatal("expected an error for a needlessly quoted path")
+    }
+}
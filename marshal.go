@@ -0,0 +1,171 @@
+package ascon
+
+import (
+    "encoding"
+    "encoding/binary"
+)
+
+// Identifiers of the sponge functions and other types in
+// marshaled states.
+const (
+    idHash = 1 + iota
+    idHasha
+    idHash256
+    idXOF
+    idXOFa
+    idXOF128
+    idCXOF128
+    idNonceCounter
+)
+
+const (
+    // spongePayloadSize is the size of a marshaled sponge: the
+    // state, the number of buffered bytes and the buffer.
+    spongePayloadSize = 5*8 + 1 + HashBlockSize
+    // xofPayloadSize adds the squeezing flag and the read
+    // offset.
+    xofPayloadSize = spongePayloadSize + 2
+    // cxofPayloadSize adds the state after absorbing the
+    // customization string, which Reset returns to.
+    cxofPayloadSize = xofPayloadSize + 5*8
+
+    // legacyMagic starts the states of version 1, which are the
+    // identifier and the payload.
+    legacyMagic = "ascon\x01"
+)
+
+var (
+    _ encoding.BinaryMarshaler   = (*digest)(nil)
+    _ encoding.BinaryUnmarshaler = (*digest)(nil)
+    _ encoding.BinaryMarshaler   = (*XOF)(nil)
+    _ encoding.BinaryUnmarshaler = (*XOF)(nil)
+)
+
+func appendState(b []byte, s *state) []byte {
+    b = binary.BigEndian.AppendUint64(b, s[0])
+    b = binary.BigEndian.AppendUint64(b, s[1])
+    b = binary.BigEndian.AppendUint64(b, s[2])
+    b = binary.BigEndian.AppendUint64(b, s[3])
+    b = binary.BigEndian.AppendUint64(b, s[4])
+    return b
+}
+
+func consumeState(b []byte, s *state) []byte {
+    s[0] = binary.BigEndian.Uint64(b[0:])
+    s[1] = binary.BigEndian.Uint64(b[8:])
+    s[2] = binary.BigEndian.Uint64(b[16:])
+    s[3] = binary.BigEndian.Uint64(b[24:])
+    s[4] = binary.BigEndian.Uint64(b[32:])
+    return b[40:]
+}
+
+func (d *sponge) appendPayload(b []byte) []byte {
+    b = appendState(b, &d.s)
+    b = append(b, byte(d.n))
+    b = append(b, d.buf[:]...)
+    return b
+}
+
+// openPayload returns the payload of a marshaled sponge with
+// the identifier of d, from a current or version 1 envelope.
+func (d *sponge) openPayload(b []byte) ([]byte, error) {
+    if len(b) >= len(legacyMagic) && string(b[:len(legacyMagic)]) == legacyMagic {
+        b = b[len(legacyMagic):]
+        if len(b) == 0 {
+            return nil, ErrInvalidState
+        }
+        if b[0] != d.id {
+            return nil, ErrStateType
+        }
+        return b[1:], nil
+    }
+    return openEnvelope(b, d.id)
+}
+
+// consumePayload restores the sponge from a payload, and
+// returns the rest of it.
+func (d *sponge) consumePayload(b []byte) ([]byte, error) {
+    if len(b) < spongePayloadSize {
+        return nil, ErrInvalidState
+    }
+    var s state
+    b = consumeState(b, &s)
+    n := int(b[0])
+    if n >= HashBlockSize {
+        return nil, ErrInvalidState
+    }
+    d.s = s
+    d.n = n
+    copy(d.buf[:], b[1:])
+    return b[1+HashBlockSize:], nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (d *digest) MarshalBinary() ([]byte, error) {
+    payload := d.appendPayload(make([]byte, 0, spongePayloadSize))
+    return appendEnvelope(make([]byte, 0, envelopeSize+len(payload)), d.id, payload), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The
+// state must have been marshaled by the same hash function.
+func (d *digest) UnmarshalBinary(b []byte) error {
+    payload, err := d.openPayload(b)
+    if err != nil {
+        return err
+    }
+    c := d.sponge
+    rest, err := c.consumePayload(payload)
+    if err != nil {
+        return err
+    }
+    if len(rest) != 0 {
+        return ErrInvalidState
+    }
+    d.sponge = c
+    return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The state of an Ascon-CXOF128 includes the effect of its
+// customization string, so it is restored along with it.
+func (x *XOF) MarshalBinary() ([]byte, error) {
+    b := x.appendPayload(make([]byte, 0, cxofPayloadSize))
+    var squeezing byte
+    if x.squeezing {
+        squeezing = 1
+    }
+    b = append(b, squeezing, byte(x.off))
+    if x.id == idCXOF128 {
+        b = appendState(b, &x.init)
+    }
+    return appendEnvelope(make([]byte, 0, envelopeSize+len(b)), x.id, b), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The
+// state must have been marshaled by the same function.
+func (x *XOF) UnmarshalBinary(b []byte) error {
+    payload, err := x.openPayload(b)
+    if err != nil {
+        return err
+    }
+    c := *x
+    rest, err := c.consumePayload(payload)
+    if err != nil {
+        return err
+    }
+    size := xofPayloadSize - spongePayloadSize
+    if x.id == idCXOF128 {
+        size = cxofPayloadSize - spongePayloadSize
+    }
+    if len(rest) != size || rest[0] > 1 || rest[1] > HashBlockSize {
+        return ErrInvalidState
+    }
+    c.squeezing = rest[0] == 1
+    c.off = int(rest[1])
+    if x.id == idCXOF128 {
+        consumeState(rest[2:], &c.init)
+    }
+    *x = c
+    return nil
+}
@@ -0,0 +1,274 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding"
+    "errors"
+    "hash"
+    "testing"
+)
+
+func TestMarshalHash(t *testing.T) {
+    msg := make([]byte, 50)
+    for i := range msg {
+        msg[i] = byte(i)
+    }
+    for _, fn := range []func() hash.Hash{NewHash, NewHasha, NewHash256} {
+        h := fn()
+        h.Write(msg)
+        want := h.Sum(nil)
+
+        for i := 0; i <= len(msg); i++ {
+            h := fn()
+            h.Write(msg[:i])
+            state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+            if err != nil {
+                t.Fatal(err)
+            }
+            h2 := fn()
+            if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+                t.Fatalf("%d: %v", i, err)
+            }
+            h2.Write(msg[i:])
+            if got := h2.Sum(nil); !bytes.Equal(got, want) {
+                t.Fatalf("%d: expected %#x, got %#x", i, want, got)
+            }
+        }
+    }
+}
+
+func TestMarshalXOF(t *testing.T) {
+    msg := make([]byte, 30)
+    for i := range msg {
+        msg[i] = byte(i)
+    }
+    cxof := func() *XOF {
+        x, err := NewCXOF128([]byte("custom"))
+        if err != nil {
+            t.Fatal(err)
+        }
+        return x
+    }
+    for _, fn := range []func() *XOF{NewXOF, NewXOFa, NewXOF128, cxof} {
+        x := fn()
+        x.Write(msg)
+        want := make([]byte, 40)
+        x.Read(want)
+
+        for i := 0; i <= len(msg)+len(want); i++ {
+            x := fn()
+            out := make([]byte, 0, len(want))
+            if i <= len(msg) {
+                x.Write(msg[:i])
+            } else {
+                x.Write(msg)
+                out = out[:i-len(msg)]
+                x.Read(out)
+            }
+            state, err := x.MarshalBinary()
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            // Unmarshal into a default CXOF to check that the
+            // customization is restored too.
+            x2 := fn()
+            if x.id == idCXOF128 {
+                x2, _ = NewCXOF128(nil)
+            }
+            if err := x2.UnmarshalBinary(state); err != nil {
+                t.Fatalf("%d: %v", i, err)
+            }
+            if i <= len(msg) {
+                x2.Write(msg[i:])
+            }
+            rest := want[len(out):]
+            got := make([]byte, len(rest))
+            x2.Read(got)
+            if !bytes.Equal(got, rest) {
+                t.Fatalf("%d: expected %#x, got %#x", i, rest, got)
+            }
+
+            x2.Reset()
+            x2.Write(msg)
+            got = make([]byte, len(want))
+            x2.Read(got)
+            if !bytes.Equal(got, want) {
+                t.Fatalf("%d: Reset: expected %#x, got %#x", i, want, got)
+            }
+        }
+    }
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+    h := NewHash()
+    h.Write([]byte("abc"))
+    state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+    x := NewXOF()
+    x.Write([]byte("abc"))
+    xstate, err := x.MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // A well-formed envelope around a bad buffer count.
+    payload, err := openEnvelope(state, idHash)
+    if err != nil {
+        t.Fatal(err)
+    }
+    payload = append([]byte(nil), payload...)
+    payload[40] = HashBlockSize
+    bad := appendEnvelope(nil, idHash, payload)
+
+    for _, tc := range []struct {
+        name  string
+        u     encoding.BinaryUnmarshaler
+        state []byte
+    }{
+        {"empty", NewHash().(encoding.BinaryUnmarshaler), nil},
+        {"truncated", NewHash().(encoding.BinaryUnmarshaler), state[:len(state)-1]},
+        {"long", NewHash().(encoding.BinaryUnmarshaler), append(state, 0)},
+        {"Hasha", NewHasha().(encoding.BinaryUnmarshaler), state},
+        {"XOF", NewHash().(encoding.BinaryUnmarshaler), xstate},
+        {"Hash", NewXOF(), state},
+        {"XOFa", NewXOFa(), xstate},
+        {"XOF truncated", NewXOF(), xstate[:len(xstate)-1]},
+        {"buffered", NewHash().(encoding.BinaryUnmarshaler), bad},
+    } {
+        if err := tc.u.UnmarshalBinary(tc.state); err == nil {
+            t.Fatalf("%s: expected an error", tc.name)
+        }
+    }
+}
+
+// marshalers returns a marshaled state of each type in the
+// module, with a function to unmarshal a state into a value of
+// the same type.
+func marshalers(t *testing.T) map[string]struct {
+    state     []byte
+    unmarshal func([]byte) error
+} {
+    m := map[string]struct {
+        state     []byte
+        unmarshal func([]byte) error
+    }{}
+    add := func(name string, v encoding.BinaryMarshaler, fresh func() encoding.BinaryUnmarshaler) {
+        state, err := v.MarshalBinary()
+        if err != nil {
+            t.Fatal(err)
+        }
+        m[name] = struct {
+            state     []byte
+            unmarshal func([]byte) error
+        }{state, func(b []byte) error { return fresh().UnmarshalBinary(b) }}
+    }
+    for name, fn := range map[string]func() hash.Hash{"Hash": NewHash, "Hasha": NewHasha, "Hash256": NewHash256} {
+        fn := fn
+        h := fn()
+        h.Write([]byte("abc"))
+        add(name, h.(encoding.BinaryMarshaler), func() encoding.BinaryUnmarshaler {
+            return fn().(encoding.BinaryUnmarshaler)
+        })
+    }
+    cxof := func() *XOF {
+        x, err := NewCXOF128([]byte("custom"))
+        if err != nil {
+            t.Fatal(err)
+        }
+        return x
+    }
+    for name, fn := range map[string]func() *XOF{"XOF": NewXOF, "XOFa": NewXOFa, "XOF128": NewXOF128, "CXOF128": cxof} {
+        fn := fn
+        x := fn()
+        x.Write([]byte("abc"))
+        x.Read(make([]byte, 3))
+        add(name, x, func() encoding.BinaryUnmarshaler { return fn() })
+    }
+    c, err := NewNonceCounter(seq(0, NoncePrefixSize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    c.Skip(1000)
+    add("NonceCounter", c, func() encoding.BinaryUnmarshaler { return new(NonceCounter) })
+    return m
+}
+
+func TestUnmarshalEnvelope(t *testing.T) {
+    m := marshalers(t)
+    for name, tc := range m {
+        if err := tc.unmarshal(tc.state); err != nil {
+            t.Fatalf("%s: %v", name, err)
+        }
+
+        // Every state of another type is rejected as such.
+        for other, otc := range m {
+            if other == name {
+                continue
+            }
+            if err := tc.unmarshal(otc.state); !errors.Is(err, ErrStateType) {
+                t.Fatalf("%s state into %s: expected %v, got %v", other, name, ErrStateType, err)
+            }
+        }
+
+        future := append([]byte(nil), tc.state...)
+        future[len(envelopeMagic)] = envelopeVersion + 1
+        if err := tc.unmarshal(future); !errors.Is(err, ErrStateVersion) {
+            t.Fatalf("%s: version %d: expected %v, got %v", name, envelopeVersion+1, ErrStateVersion, err)
+        }
+        for _, b := range [][]byte{nil, tc.state[:envelopeSize-1], append(tc.state[:len(tc.state):len(tc.state)], 0)} {
+            if err := tc.unmarshal(b); !errors.Is(err, ErrInvalidState) {
+                t.Fatalf("%s: %d bytes: expected %v, got %v", name, len(b), ErrInvalidState, err)
+            }
+        }
+    }
+}
+
+// TestUnmarshalCorrupted flips every bit of each marshaled state
+// and expects it to be rejected.
+func TestUnmarshalCorrupted(t *testing.T) {
+    for name, tc := range marshalers(t) {
+        for i := range tc.state {
+            for bit := 0; bit < 8; bit++ {
+                b := append([]byte(nil), tc.state...)
+                b[i] ^= 1 << bit
+                if err := tc.unmarshal(b); err == nil {
+                    t.Fatalf("%s: accepted bit %d of byte %d flipped", name, bit, i)
+                }
+            }
+        }
+    }
+}
+
+// TestUnmarshalLegacy checks that the states of version 1, which
+// had no length or checksum, are still accepted.
+func TestUnmarshalLegacy(t *testing.T) {
+    msg := []byte("the message")
+    h := NewHasha()
+    h.Write(msg[:5])
+    state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+    payload, err := openEnvelope(state, idHasha)
+    if err != nil {
+        t.Fatal(err)
+    }
+    legacy := append(append([]byte(legacyMagic), idHasha), payload...)
+
+    h2 := NewHasha()
+    if err := h2.(encoding.BinaryUnmarshaler).UnmarshalBinary(legacy); err != nil {
+        t.Fatal(err)
+    }
+    h.Write(msg[5:])
+    h2.Write(msg[5:])
+    if want, got := h.Sum(nil), h2.Sum(nil); !bytes.Equal(got, want) {
+        t.Fatalf("expected %x, got %x", want, got)
+    }
+    if err := NewHash().(encoding.BinaryUnmarshaler).UnmarshalBinary(legacy); !errors.Is(err, ErrStateType) {
+        t.Fatalf("expected %v, got %v", ErrStateType, err)
+    }
+}
+
@@ -0,0 +1,321 @@
+//go:build ascon_masked
+
+package ascon
+
+import (
+    "io"
+    "math/bits"
+    "runtime"
+    "strconv"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/byteorder"
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// maskedRoundConstants are the constants of the twelve rounds
+// of p12.
+var maskedRoundConstants = [12]uint64{
+    0xf0, 0xe1, 0xd2, 0xc3, 0xb4, 0xa5,
+    0x96, 0x87, 0x78, 0x69, 0x5a, 0x4b,
+}
+
+type maskedAEAD struct {
+    // The key is stored as two shares, k = ka ^ kb.
+    ka, kb [2]uint64
+    iv     uint64
+    rate   int
+    b      int // rounds of the data permutation
+    fin    int // first state word the key is added to in finalization
+    rng    io.Reader
+}
+
+var _ cipher.AEAD = (*maskedAEAD)(nil)
+
+// NewMasked128 creates an ASCON-128 AEAD whose key and state
+// are held in two Boolean shares, as a first-order
+// countermeasure against power analysis.
+//
+// The nonlinear layer of every permutation round consumes
+// fresh randomness from rng, 40 bytes per round, and the key
+// shares are refreshed for every message. If rng is nil,
+// crypto/rand.Reader is used; a deterministic rng makes test
+// runs reproducible but provides no protection. rng must be
+// safe for concurrent use if the AEAD is, and Seal and Open
+// panic if it fails.
+//
+// The output is identical to New128. Masking in software only
+// raises the bar for an attacker: compiler transformations and
+// microarchitectural effects can still combine shares.
+//
+// NewMasked128 is only available with the ascon_masked build
+// tag.
+func NewMasked128(key []byte, rng io.Reader) (cipher.AEAD, error) {
+    return newMasked(key, rng, iv128, BlockSize128, 6, 1)
+}
+
+// NewMasked128a is like NewMasked128, but produces the same
+// output as New128a.
+func NewMasked128a(key []byte, rng io.Reader) (cipher.AEAD, error) {
+    return newMasked(key, rng, iv128a, BlockSize128a, 8, 2)
+}
+
+func newMasked(key []byte, rng io.Reader, iv uint64, rate, b, fin int) (cipher.AEAD, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+    if rng == nil {
+        rng = rand.Reader
+    }
+
+    a := &maskedAEAD{iv: iv, rate: rate, b: b, fin: fin, rng: rng}
+    var r [16]byte
+    if _, err := io.ReadFull(rng, r[:]); err != nil {
+        return nil, err
+    }
+    a.kb[0] = binary.BigEndian.Uint64(r[0:])
+    a.kb[1] = binary.BigEndian.Uint64(r[8:])
+    a.ka[0] = binary.BigEndian.Uint64(key[0:]) ^ a.kb[0]
+    a.ka[1] = binary.BigEndian.Uint64(key[8:]) ^ a.kb[1]
+    return a, nil
+}
+
+func (a *maskedAEAD) NonceSize() int {
+    return NonceSize
+}
+
+func (a *maskedAEAD) Overhead() int {
+    return TagSize
+}
+
+func (a *maskedAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    var s maskedState
+    a.init(&s, nonce)
+    a.additionalData(&s, additionalData)
+    a.crypt(&s, out[:len(plaintext)], plaintext, false)
+    a.tag(&s, out[len(plaintext):])
+
+    return ret
+}
+
+func (a *maskedAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
+    }
+
+    if len(ciphertext) < TagSize {
+        return nil, errOpenShort
+    }
+
+    tag := ciphertext[len(ciphertext)-TagSize:]
+    ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    var s maskedState
+    a.init(&s, nonce)
+    a.additionalData(&s, additionalData)
+    a.crypt(&s, out, ciphertext, true)
+
+    var expectedTag [TagSize]byte
+    a.tag(&s, expectedTag[:])
+
+    if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+        for i := range out {
+            out[i] = 0
+        }
+
+        runtime.KeepAlive(out)
+        return nil, ErrAuthentication
+    }
+
+    return ret, nil
+}
+
+// maskedState is the ASCON state as two shares, x = a ^ b,
+// together with the per-message key shares.
+type maskedState struct {
+    a, b   [5]uint64
+    ka, kb [2]uint64
+    rng    io.Reader
+    rnd    [12 * 5 * 8]byte
+}
+
+func (a *maskedAEAD) init(s *maskedState, nonce []byte) {
+    s.rng = a.rng
+
+    // Refresh the key shares for this message.
+    var r [16]byte
+    s.random(r[:])
+    for i := range s.ka {
+        m := binary.BigEndian.Uint64(r[8*i:])
+        s.ka[i] = a.ka[i] ^ m
+        s.kb[i] = a.kb[i] ^ m
+    }
+
+    s.a = [5]uint64{
+        a.iv,
+        s.ka[0],
+        s.ka[1],
+        binary.BigEndian.Uint64(nonce[0:]),
+        binary.BigEndian.Uint64(nonce[8:]),
+    }
+    s.b = [5]uint64{0, s.kb[0], s.kb[1], 0, 0}
+    s.permute(12)
+    s.a[3] ^= s.ka[0]
+    s.b[3] ^= s.kb[0]
+    s.a[4] ^= s.ka[1]
+    s.b[4] ^= s.kb[1]
+}
+
+func (a *maskedAEAD) additionalData(s *maskedState, ad []byte) {
+    if len(ad) > 0 {
+        for len(ad) >= a.rate {
+            for i := 0; i < a.rate/8; i++ {
+                s.a[i] ^= binary.BigEndian.Uint64(ad[8*i:])
+            }
+            s.permute(a.b)
+            ad = ad[a.rate:]
+        }
+        for i := 0; i < a.rate/8; i++ {
+            s.a[i] ^= byteorder.BE64n(part(ad, i))
+        }
+        s.a[len(ad)/8] ^= byteorder.Pad(len(ad) % 8)
+        s.permute(a.b)
+    }
+    s.a[4] ^= 1
+}
+
+// crypt encrypts or decrypts src into dst. Data is public, so
+// only the share a is updated with it.
+func (a *maskedAEAD) crypt(s *maskedState, dst, src []byte, decrypt bool) {
+    for {
+        n := len(src)
+        if n > a.rate {
+            n = a.rate
+        }
+        for i := 0; i < a.rate/8; i++ {
+            in := part(src[:n], i)
+            x := s.a[i] ^ s.b[i]
+            if decrypt {
+                p := byteorder.BE64n(in) ^ (x ^ byteorder.Mask(x, len(in)))
+                s.a[i] ^= p
+                byteorder.PutBE64n(part(dst[:n], i), p)
+            } else {
+                s.a[i] ^= byteorder.BE64n(in)
+                byteorder.PutBE64n(part(dst[:n], i), x^byteorder.BE64n(in))
+            }
+        }
+        if n < a.rate {
+            s.a[n/8] ^= byteorder.Pad(n % 8)
+            return
+        }
+        s.permute(a.b)
+        src = src[n:]
+        dst = dst[n:]
+    }
+}
+
+// tag finalizes the state and writes the authenticator to dst.
+func (a *maskedAEAD) tag(s *maskedState, dst []byte) {
+    s.a[a.fin] ^= s.ka[0]
+    s.b[a.fin] ^= s.kb[0]
+    s.a[a.fin+1] ^= s.ka[1]
+    s.b[a.fin+1] ^= s.kb[1]
+    s.permute(12)
+    s.a[3] ^= s.ka[0]
+    s.b[3] ^= s.kb[0]
+    s.a[4] ^= s.ka[1]
+    s.b[4] ^= s.kb[1]
+    binary.BigEndian.PutUint64(dst[0:8], s.a[3]^s.b[3])
+    binary.BigEndian.PutUint64(dst[8:16], s.a[4]^s.b[4])
+}
+
+// part returns the bytes of b that belong to state word i.
+func part(b []byte, i int) []byte {
+    if len(b) <= 8*i {
+        return nil
+    }
+    b = b[8*i:]
+    if len(b) > 8 {
+        b = b[:8]
+    }
+    return b
+}
+
+func (s *maskedState) random(b []byte) {
+    if _, err := io.ReadFull(s.rng, b); err != nil {
+        panic("ascon: masking randomness unavailable: " + err.Error())
+    }
+}
+
+// permute applies the last n rounds of p12 to the shares.
+func (s *maskedState) permute(n int) {
+    rnd := s.rnd[:n*5*8]
+    s.random(rnd)
+    for i, C := range maskedRoundConstants[12-n:] {
+        var r [5]uint64
+        for j := range r {
+            r[j] = binary.BigEndian.Uint64(rnd[(5*i+j)*8:])
+        }
+        maskedRound(&s.a, &s.b, C, &r)
+    }
+}
+
+// maskedRound applies one round to the shares a and b. Linear
+// operations act on each share separately; the nonlinear
+// x_i ^= ^x_{i+1} & x_{i+2} uses a shared AND refreshed with
+// the random words r.
+func maskedRound(a, b *[5]uint64, C uint64, r *[5]uint64) {
+    // Round constant
+    a[2] ^= C
+
+    // Substitution
+    for _, x := range [2]*[5]uint64{a, b} {
+        x[0] ^= x[4]
+        x[4] ^= x[3]
+        x[2] ^= x[1]
+    }
+
+    // Keccak S-box
+    var ta, tb [5]uint64
+    for i := 0; i < 5; i++ {
+        ua, ub := ^a[(i+1)%5], b[(i+1)%5]
+        va, vb := a[(i+2)%5], b[(i+2)%5]
+        ta[i] = a[i] ^ (ua & va) ^ r[i]
+        tb[i] = b[i] ^ (ub & vb) ^ (r[i] ^ (ua & vb)) ^ (ub & va)
+    }
+
+    // Substitution
+    for _, t := range [2]*[5]uint64{&ta, &tb} {
+        t[1] ^= t[0]
+        t[0] ^= t[4]
+        t[3] ^= t[2]
+    }
+    ta[2] = ^ta[2]
+
+    // Linear diffusion
+    for _, x := range [2]*[5]uint64{&ta, &tb} {
+        x[0] ^= bits.RotateLeft64(x[0], -19) ^ bits.RotateLeft64(x[0], -28)
+        x[1] ^= bits.RotateLeft64(x[1], -61) ^ bits.RotateLeft64(x[1], -39)
+        x[2] ^= bits.RotateLeft64(x[2], -1) ^ bits.RotateLeft64(x[2], -6)
+        x[3] ^= bits.RotateLeft64(x[3], -10) ^ bits.RotateLeft64(x[3], -17)
+        x[4] ^= bits.RotateLeft64(x[4], -7) ^ bits.RotateLeft64(x[4], -41)
+    }
+    *a = ta
+    *b = tb
+}
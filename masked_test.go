@@ -0,0 +1,96 @@
+//go:build ascon_masked
+
+package ascon
+
+import (
+    "bytes"
+    "crypto/cipher"
+    "io"
+    "math/rand"
+    "testing"
+)
+
+func TestMaskedRound(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for i := 0; i < 1000; i++ {
+        s := randState(rng)
+        a := [5]uint64{s[0], s[1], s[2], s[3], s[4]}
+        var b, r [5]uint64
+        for j := range b {
+            b[j] = rng.Uint64()
+            r[j] = rng.Uint64()
+            a[j] ^= b[j]
+        }
+        C := uint64(i)
+        roundGeneric(&s, C)
+        maskedRound(&a, &b, C, &r)
+        got := state{
+            0: a[0] ^ b[0],
+            1: a[1] ^ b[1],
+            2: a[2] ^ b[2],
+            3: a[3] ^ b[3],
+            4: a[4] ^ b[4],
+        }
+        if got != s {
+            t.Fatalf("expected %v, got %v", s, got)
+        }
+    }
+}
+
+func TestMasked(t *testing.T) {
+    for _, tc := range []struct {
+        name   string
+        ref    func([]byte) (cipher.AEAD, error)
+        masked func([]byte, io.Reader) (cipher.AEAD, error)
+    }{
+        {"128", New128, NewMasked128},
+        {"128a", New128a, NewMasked128a},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            rng := rand.New(rand.NewSource(0xDEADBEEF))
+            for i := 0; i < 200; i++ {
+                key := make([]byte, KeySize)
+                nonce := make([]byte, NonceSize)
+                pt := make([]byte, rng.Intn(100))
+                ad := make([]byte, rng.Intn(100))
+                rng.Read(key)
+                rng.Read(nonce)
+                rng.Read(pt)
+                rng.Read(ad)
+
+                ref, err := tc.ref(key)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                want := ref.Seal(nil, nonce, pt, ad)
+
+                for seed := int64(0); seed < 2; seed++ {
+                    m, err := tc.masked(key, rand.New(rand.NewSource(seed)))
+                    if err != nil {
+                        t.Fatal(err)
+                    }
+                    got := m.Seal(nil, nonce, pt, ad)
+                    if !bytes.Equal(got, want) {
+                        t.Fatalf("#%d: expected %x, got %x", i, want, got)
+                    }
+                    got, err = m.Open(nil, nonce, want, ad)
+                    if err != nil {
+                        t.Fatalf("#%d: %v", i, err)
+                    }
+                    if !bytes.Equal(got, pt) {
+                        t.Fatalf("#%d: expected %x, got %x", i, pt, got)
+                    }
+
+                    bad := append([]byte(nil), want...)
+                    bad[rng.Intn(len(bad))] ^= 1
+                    if _, err := m.Open(nil, nonce, bad, ad); err == nil {
+                        t.Fatalf("#%d: expected an error", i)
+                    }
+                    if _, err := m.Open(nil, nonce[:15], want, ad); err == nil {
+                        t.Fatalf("#%d: accepted a short nonce", i)
+                    }
+                }
+            }
+        })
+    }
+}
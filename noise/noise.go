@@ -0,0 +1,181 @@
+// Package noise adapts the ASCON AEADs to the cipher functions
+// and CipherState object of the Noise Protocol Framework.
+//
+// Noise does not define ASCON cipher functions, so this package
+// fixes the following rules, which a counterpart in another
+// language must follow to interoperate:
+//
+//    nonce(n)             = 0x00 * 8 || uint64_be(n)
+//    ENCRYPT(k, n, ad, p) = Seal(k, nonce(n), p, ad)
+//    DECRYPT(k, n, ad, c) = Open(k, nonce(n), c, ad)
+//    REKEY(k)             = ENCRYPT(k, 2^64-1, "", 0x00 * len(k))[:len(k)]
+//
+// nonce(n) is the 16-byte nonce of ascon.NonceCounter with an
+// all-zero prefix. k has the KeySize of the variant: 16 bytes,
+// or 20 for Ascon-80pq. Noise handshakes produce 32-byte cipher
+// keys, of which k is the first KeySize bytes, just as Noise
+// truncates 64-byte hash outputs to 32-byte keys. The tests read
+// testdata/vectors.txt, which has vectors of these rules.
+//
+// References:
+//
+//    [noise]: https://noiseprotocol.org/noise.html
+//
+package noise
+
+import (
+    "errors"
+    "math"
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// ErrNonceExhausted is returned by CipherState once the nonce
+// reaches 2^64-1, which Noise reserves for REKEY.
+var ErrNonceExhausted = errors.New("noise: nonce exhausted")
+
+// maxNonce is the nonce reserved for REKEY.
+const maxNonce = math.MaxUint64
+
+// Nonce returns the ASCON nonce of the Noise nonce n.
+func Nonce(n uint64) [ascon.NonceSize]byte {
+    var nonce [ascon.NonceSize]byte
+    binary.BigEndian.PutUint64(nonce[ascon.NonceSize-8:], n)
+    return nonce
+}
+
+// Cipher implements the Noise cipher functions ENCRYPT, DECRYPT
+// and REKEY with a key k. Its Encrypt and Decrypt methods have
+// the signatures that Go Noise libraries commonly expect of a
+// cipher.
+type Cipher struct {
+    v    ascon.Variant
+    aead cipher.AEAD
+}
+
+// NewCipher returns the Cipher of the variant v with key, which
+// must have the KeySize of v.
+func NewCipher(v ascon.Variant, key []byte) (*Cipher, error) {
+    aead, err := ascon.New(key, ascon.WithVariant(v))
+    if err != nil {
+        return nil, err
+    }
+    return &Cipher{v: v, aead: aead}, nil
+}
+
+// Encrypt appends ENCRYPT(k, n, ad, plaintext) to out.
+func (c *Cipher) Encrypt(out []byte, n uint64, ad, plaintext []byte) []byte {
+    nonce := Nonce(n)
+    return c.aead.Seal(out, nonce[:], plaintext, ad)
+}
+
+// Decrypt appends DECRYPT(k, n, ad, ciphertext) to out, or
+// returns an error if the ciphertext is not authentic.
+func (c *Cipher) Decrypt(out []byte, n uint64, ad, ciphertext []byte) ([]byte, error) {
+    nonce := Nonce(n)
+    return c.aead.Open(out, nonce[:], ciphertext, ad)
+}
+
+// Rekey returns the Cipher of the key REKEY(k).
+func (c *Cipher) Rekey() *Cipher {
+    size := c.v.KeySize()
+    key := c.Encrypt(nil, maxNonce, nil, make([]byte, size))[:size]
+    next, err := NewCipher(c.v, key)
+    if err != nil {
+        panic(err)
+    }
+    return next
+}
+
+// CipherState is the CipherState object of Noise: a Cipher, or
+// none before InitializeKey, and the nonce n of the next
+// message.
+//
+// A CipherState is not safe for concurrent use.
+type CipherState struct {
+    v ascon.Variant
+    c *Cipher
+    n uint64
+}
+
+// NewCipherState returns a CipherState of the variant v without
+// a key.
+func NewCipherState(v ascon.Variant) *CipherState {
+    return &CipherState{v: v}
+}
+
+// InitializeKey sets the key to the first KeySize bytes of key,
+// which must have at least the KeySize of the variant, and resets
+// n to zero. A Noise handshake passes a 32-byte key. A nil key
+// removes it.
+func (cs *CipherState) InitializeKey(key []byte) error {
+    cs.n = 0
+    if key == nil {
+        cs.c = nil
+        return nil
+    }
+    if size := cs.v.KeySize(); len(key) > size {
+        key = key[:size]
+    }
+    c, err := NewCipher(cs.v, key)
+    if err != nil {
+        return err
+    }
+    cs.c = c
+    return nil
+}
+
+// HasKey reports whether the CipherState has a key.
+func (cs *CipherState) HasKey() bool {
+    return cs.c != nil
+}
+
+// SetNonce sets the nonce of the next message.
+func (cs *CipherState) SetNonce(n uint64) {
+    cs.n = n
+}
+
+// EncryptWithAd encrypts plaintext with the additional data ad
+// and increments n. Without a key, it returns plaintext. It
+// returns ErrNonceExhausted once n has reached 2^64-1.
+func (cs *CipherState) EncryptWithAd(ad, plaintext []byte) ([]byte, error) {
+    if cs.c == nil {
+        return plaintext, nil
+    }
+    if cs.n == maxNonce {
+        return nil, ErrNonceExhausted
+    }
+    ct := cs.c.Encrypt(nil, cs.n, ad, plaintext)
+    cs.n++
+    return ct, nil
+}
+
+// DecryptWithAd decrypts ciphertext with the additional data ad
+// and increments n. Without a key, it returns ciphertext. If
+// the ciphertext is not authentic, it returns an error and
+// leaves n unchanged. It returns ErrNonceExhausted once n has
+// reached 2^64-1.
+func (cs *CipherState) DecryptWithAd(ad, ciphertext []byte) ([]byte, error) {
+    if cs.c == nil {
+        return ciphertext, nil
+    }
+    if cs.n == maxNonce {
+        return nil, ErrNonceExhausted
+    }
+    pt, err := cs.c.Decrypt(nil, cs.n, ad, ciphertext)
+    if err != nil {
+        return nil, err
+    }
+    cs.n++
+    return pt, nil
+}
+
+// Rekey replaces the key with REKEY(k), and leaves n unchanged.
+// It does nothing without a key.
+func (cs *CipherState) Rekey() {
+    if cs.c != nil {
+        cs.c = cs.c.Rekey()
+    }
+}
@@ -0,0 +1,239 @@
+package noise
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+type vector struct {
+    variant                        ascon.Variant
+    n                              uint64
+    key, nonce, ad, pt, ct, rekey []byte
+}
+
+func readVecs(path string) ([]vector, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var vecs []vector
+
+    s := bufio.NewScanner(f)
+    for n := 1; s.Scan(); n++ {
+        t := s.Text()
+        if t == "" {
+            continue
+        }
+        if strings.HasPrefix(t, "Count = ") {
+            vecs = append(vecs, vector{})
+            continue
+        }
+        field, value, ok := strings.Cut(t, "=")
+        if !ok || len(vecs) == 0 {
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+        field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+        v := &vecs[len(vecs)-1]
+        switch field {
+        case "Variant":
+            if v.variant, err = ascon.ParseVariant(value); err != nil {
+                return nil, fmt.Errorf("malformed line %d: %v", n, err)
+            }
+            continue
+        case "N":
+            if v.n, err = strconv.ParseUint(value, 10, 64); err != nil {
+                return nil, fmt.Errorf("malformed line %d: %v", n, err)
+            }
+            continue
+        }
+        buf, err := hex.DecodeString(value)
+        if err != nil {
+            return nil, fmt.Errorf("malformed line %d: %v", n, err)
+        }
+        switch field {
+        case "Key":
+            v.key = buf
+        case "Nonce":
+            v.nonce = buf
+        case "AD":
+            v.ad = buf
+        case "PT":
+            v.pt = buf
+        case "CT":
+            v.ct = buf
+        case "Rekey":
+            v.rekey = buf
+        default:
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+    }
+    return vecs, s.Err()
+}
+
+func TestVectors(t *testing.T) {
+    vecs, err := readVecs("testdata/vectors.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) == 0 {
+        t.Fatal("no vectors")
+    }
+    for i, v := range vecs {
+        if nonce := Nonce(v.n); !bytes.Equal(nonce[:], v.nonce) {
+            t.Fatalf("#%d: expected nonce %#x, got %#x", i+1, v.nonce, nonce)
+        }
+        c, err := NewCipher(v.variant, v.key)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        ct := c.Encrypt(nil, v.n, v.ad, v.pt)
+        if !bytes.Equal(ct, v.ct) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.ct, ct)
+        }
+        pt, err := c.Decrypt(nil, v.n, v.ad, v.ct)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(pt, v.pt) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.pt, pt)
+        }
+
+        // REKEY(k) is the key of the rekeyed Cipher.
+        want, err := NewCipher(v.variant, v.rekey)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        got := c.Rekey().Encrypt(nil, v.n, v.ad, v.pt)
+        if !bytes.Equal(got, want.Encrypt(nil, v.n, v.ad, v.pt)) {
+            t.Fatalf("#%d: rekeyed Cipher does not use key %#x", i+1, v.rekey)
+        }
+    }
+}
+
+func TestCipherState(t *testing.T) {
+    key := make([]byte, ascon.KeySize)
+    alice := NewCipherState(ascon.AsconAEAD128)
+    bob := NewCipherState(ascon.AsconAEAD128)
+
+    // Without a key, messages pass through unchanged.
+    msg := []byte("handshake payload")
+    if alice.HasKey() {
+        t.Fatal("HasKey before InitializeKey")
+    }
+    ct, err := alice.EncryptWithAd(nil, msg)
+    if err != nil || !bytes.Equal(ct, msg) {
+        t.Fatalf("expected %q, got %q, %v", msg, ct, err)
+    }
+
+    if err := alice.InitializeKey(key); err != nil {
+        t.Fatal(err)
+    }
+    if err := bob.InitializeKey(key); err != nil {
+        t.Fatal(err)
+    }
+    if !alice.HasKey() {
+        t.Fatal("no key after InitializeKey")
+    }
+    c, _ := NewCipher(ascon.AsconAEAD128, key)
+    ad := []byte("h")
+    for n := uint64(0); n < 3; n++ {
+        ct, err := alice.EncryptWithAd(ad, msg)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if want := c.Encrypt(nil, n, ad, msg); !bytes.Equal(ct, want) {
+            t.Fatalf("message %d: expected %#x, got %#x", n, want, ct)
+        }
+
+        // A forgery fails and does not use up the nonce.
+        bad := append([]byte(nil), ct...)
+        bad[0] ^= 1
+        if _, err := bob.DecryptWithAd(ad, bad); err == nil {
+            t.Fatalf("message %d: forgery accepted", n)
+        }
+        pt, err := bob.DecryptWithAd(ad, ct)
+        if err != nil {
+            t.Fatalf("message %d: %v", n, err)
+        }
+        if !bytes.Equal(pt, msg) {
+            t.Fatalf("message %d: expected %q, got %q", n, msg, pt)
+        }
+    }
+
+    alice.Rekey()
+    bob.Rekey()
+    ct, err = alice.EncryptWithAd(ad, msg)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := c.Rekey().Encrypt(nil, 3, ad, msg); !bytes.Equal(ct, want) {
+        t.Fatalf("after Rekey: expected %#x, got %#x", want, ct)
+    }
+    if _, err := bob.DecryptWithAd(ad, ct); err != nil {
+        t.Fatalf("after Rekey: %v", err)
+    }
+
+    if err := alice.InitializeKey(make([]byte, ascon.KeySize-1)); err == nil {
+        t.Fatal("expected an error for a 15-byte key")
+    }
+}
+
+func TestInitializeKeyTruncates(t *testing.T) {
+    // A Noise handshake produces 32-byte keys, of which k is the
+    // first KeySize bytes.
+    key := make([]byte, 32)
+    for i := range key {
+        key[i] = byte(i)
+    }
+    msg := []byte("transport message")
+    for _, v := range []ascon.Variant{ascon.AsconAEAD128, ascon.Ascon80pq} {
+        cs := NewCipherState(v)
+        if err := cs.InitializeKey(key); err != nil {
+            t.Fatalf("%v: %v", v, err)
+        }
+        ct, err := cs.EncryptWithAd(nil, msg)
+        if err != nil {
+            t.Fatalf("%v: %v", v, err)
+        }
+        c, err := NewCipher(v, key[:v.KeySize()])
+        if err != nil {
+            t.Fatalf("%v: %v", v, err)
+        }
+        if want := c.Encrypt(nil, 0, nil, msg); !bytes.Equal(ct, want) {
+            t.Fatalf("%v: expected %#x, got %#x", v, want, ct)
+        }
+    }
+    cs := NewCipherState(ascon.Ascon80pq)
+    if err := cs.InitializeKey(make([]byte, ascon.KeySize)); err == nil {
+        t.Fatal("expected an error for a 16-byte Ascon-80pq key")
+    }
+}
+
+func TestNonceExhausted(t *testing.T) {
+    cs := NewCipherState(ascon.AsconAEAD128)
+    if err := cs.InitializeKey(make([]byte, ascon.KeySize)); err != nil {
+        t.Fatal(err)
+    }
+    cs.SetNonce(maxNonce - 1)
+    ct, err := cs.EncryptWithAd(nil, nil)
+    if err != nil {
+        t.Fatalf("nonce 2^64-2: %v", err)
+    }
+    if _, err := cs.EncryptWithAd(nil, nil); !errors.Is(err, ErrNonceExhausted) {
+        t.Fatalf("expected %v, got %v", ErrNonceExhausted, err)
+    }
+    if _, err := cs.DecryptWithAd(nil, ct); !errors.Is(err, ErrNonceExhausted) {
+        t.Fatalf("expected %v, got %v", ErrNonceExhausted, err)
+    }
+}
@@ -0,0 +1,65 @@
+package ascon
+
+import (
+    "errors"
+    "math"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+var (
+    // ErrCiphertextTooShort is returned when a message is too
+    // short to contain a nonce and an authenticator.
+    ErrCiphertextTooShort = errors.New("ascon: ciphertext too short")
+
+    // ErrInvalidNonceSize is returned for a nonce of the wrong
+    // length by the functions that do not panic for it.
+    ErrInvalidNonceSize = errors.New("ascon: invalid nonce size")
+)
+
+// SealWithNonce is like Seal, but appends nonce || ciphertext ||
+// authenticator to dst, so the result can be opened with
+// OpenWithNonce. It returns an error instead of panicking if
+// the nonce is not NonceSize bytes long.
+//
+// To encrypt in place, store plaintext at offset NonceSize of
+// a buffer and use that buffer's [:0] as dst.
+func (a *AEAD) SealWithNonce(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, ErrInvalidNonceSize
+    }
+
+    n, err := SealedLen(len(plaintext), a.tagSize)
+    if err != nil {
+        return nil, err
+    }
+    if n > math.MaxInt-NonceSize {
+        return nil, ErrMessageTooLarge
+    }
+    ret, out := subtle.SliceForAppend(dst, NonceSize+n)
+    ct := out[NonceSize:]
+    if subtle.InexactOverlap(ct, plaintext) || subtle.AnyOverlap(out[:NonceSize], plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    copy(out, nonce)
+    a.seal(ct[:len(plaintext)], ct[len(plaintext):], out[:NonceSize], plaintext, additionalData)
+
+    return ret, nil
+}
+
+// OpenWithNonce opens a message produced by SealWithNonce,
+// taking the nonce from the first NonceSize bytes of blob, and
+// appends the plaintext to dst. Messages shorter than
+// NonceSize+Overhead() are rejected with ErrCiphertextTooShort.
+//
+// To decrypt in place, use blob[NonceSize:][:0] as dst.
+func (a *AEAD) OpenWithNonce(dst, blob, additionalData []byte) ([]byte, error) {
+    if len(blob) < NonceSize+a.tagSize {
+        return nil, ErrCiphertextTooShort
+    }
+
+    var nonce [NonceSize]byte
+    copy(nonce[:], blob)
+    return a.Open(dst, nonce[:], blob[NonceSize:], additionalData)
+}
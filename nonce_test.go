@@ -0,0 +1,80 @@
+package ascon
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestSealWithNonce(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            c, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            aead := c.(*AEAD)
+            nonce := make([]byte, NonceSize)
+            for i := range nonce {
+                nonce[i] = byte(i)
+            }
+            ad := []byte("header")
+
+            for n := 0; n < 40; n++ {
+                pt := make([]byte, n)
+                for i := range pt {
+                    pt[i] = byte(i)
+                }
+                want := append(append([]byte(nil), nonce...), aead.Seal(nil, nonce, pt, ad)...)
+
+                blob, err := aead.SealWithNonce([]byte("prefix"), nonce, pt, ad)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(blob[6:], want) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, want, blob[6:])
+                }
+                blob = blob[6:]
+
+                got, err := aead.OpenWithNonce(nil, blob, ad)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(got, pt) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, pt, got)
+                }
+
+                // In place.
+                buf := make([]byte, NonceSize+n, NonceSize+n+aead.Overhead())
+                copy(buf[NonceSize:], pt)
+                buf, err = aead.SealWithNonce(buf[:0], nonce, buf[NonceSize:], ad)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(buf, want) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, want, buf)
+                }
+                got, err = aead.OpenWithNonce(buf[NonceSize:][:0], buf, ad)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if !bytes.Equal(got, pt) {
+                    t.Fatalf("%d: expected %#x, got %#x", n, pt, got)
+                }
+
+                blob[0] ^= 1
+                if _, err := aead.OpenWithNonce(nil, blob, ad); err != ErrAuthentication {
+                    t.Fatalf("%d: expected %v, got %v", n, ErrAuthentication, err)
+                }
+            }
+
+            for n := 0; n < NonceSize+aead.Overhead(); n++ {
+                if _, err := aead.OpenWithNonce(nil, make([]byte, n), ad); err != ErrCiphertextTooShort {
+                    t.Fatalf("%d: expected %v, got %v", n, ErrCiphertextTooShort, err)
+                }
+            }
+            if _, err := aead.SealWithNonce(nil, nonce[:NonceSize-1], nil, ad); err == nil {
+                t.Fatal("expected an error")
+            }
+        })
+    }
+}
@@ -0,0 +1,261 @@
+package ascon
+
+import (
+    "errors"
+    "math"
+    "strconv"
+    "crypto/cipher"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// Option configures the AEAD created by New.
+type Option func(*options)
+
+type options struct {
+    variant    Variant
+    tagSize    int
+    hasVariant bool
+    hasTagSize bool
+    // errors selects checkedAEAD.
+    errors bool
+    // err is the first conflict between options.
+    err error
+}
+
+// WithVariant selects the AEAD variant, Ascon-128a by default.
+func WithVariant(v Variant) Option {
+    return func(o *options) {
+        if o.hasVariant && o.variant != v && o.err == nil {
+            o.err = errors.New("ascon: conflicting variants " + o.variant.String() + " and " + v.String())
+        }
+        o.variant, o.hasVariant = v, true
+    }
+}
+
+// WithTagSize truncates the authenticator to n bytes, as
+// New128WithTagSize. n must be in the range [MinTagSize,
+// TagSize], and only ASCON-128 and ASCON-128a accept a size
+// other than TagSize.
+func WithTagSize(n int) Option {
+    return func(o *options) {
+        if o.hasTagSize && o.tagSize != n && o.err == nil {
+            o.err = errors.New("ascon: conflicting tag sizes " + strconv.Itoa(o.tagSize) + " and " + strconv.Itoa(n))
+        }
+        o.tagSize, o.hasTagSize = n, true
+    }
+}
+
+// WithErrorsInsteadOfPanics makes the AEAD report misuse as
+// errors in every method that has an error result, where it
+// would otherwise panic: SealTo, Open, OpenTo, OpenDetached,
+// SealWithNonce, OpenWithNonce, OpenWithPrecomputedAAD and
+// OpenBatch. The misuse is a nonce of the wrong length,
+// inexactly overlapping buffers, a message longer than
+// MaxPlaintextSize, a wiped key, a PrecomputedAAD of another
+// variant and batch slices of different lengths. OpenBatch
+// returns the error before opening any message, with the index
+// of the message at fault, or -1 for the lengths of the slices.
+//
+// Seal, SealDetached, SealBatch and SealWithPrecomputedAAD have
+// no error result, and still panic.
+//
+// The AEAD is then not an *AEAD, but has its methods.
+func WithErrorsInsteadOfPanics() Option {
+    return func(o *options) {
+        o.errors = true
+    }
+}
+
+// New creates an AEAD with key, configured by opts. Without
+// options, it is the same as New128a.
+//
+// It returns an error if the options conflict, such as two
+// WithVariant options with different variants or a truncated
+// tag for a variant that does not support it, or if the key
+// does not have the length required by the variant. Options
+// that are repeated with the same value do not conflict.
+func New(key []byte, opts ...Option) (cipher.AEAD, error) {
+    o := options{variant: Ascon128a, tagSize: TagSize}
+    for _, fn := range opts {
+        fn(&o)
+    }
+    if o.err != nil {
+        return nil, o.err
+    }
+
+    aead, err := newVariant(key, o.variant, o.tagSize)
+    if err != nil {
+        return nil, err
+    }
+    if o.errors {
+        return checkedAEAD{aead.(*AEAD)}, nil
+    }
+    return aead, nil
+}
+
+func newVariant(key []byte, v Variant, tagSize int) (cipher.AEAD, error) {
+    switch v {
+    case Ascon128:
+        return New128WithTagSize(key, tagSize)
+    case Ascon128a:
+        return New128aWithTagSize(key, tagSize)
+    case Ascon80pq, AsconAEAD128:
+        if tagSize != TagSize {
+            return nil, errors.New("ascon: " + v.String() + " does not support truncated tags")
+        }
+        if v == Ascon80pq {
+            return New80pq(key)
+        }
+        return NewAEAD128(key)
+    default:
+        return nil, errors.New("ascon: unknown variant " + v.String())
+    }
+}
+
+var (
+    errNonceSize      = errors.New("ascon: incorrect nonce length")
+    errOverlap        = errors.New("ascon: invalid buffer overlap")
+    errPrecomputedAAD = errors.New("ascon: PrecomputedAAD is for a different variant")
+    errBatchLength    = errors.New("ascon: batch length mismatch")
+)
+
+// checkedAEAD is the AEAD of WithErrorsInsteadOfPanics. Its
+// methods check the arguments that AEAD panics on before
+// calling it.
+type checkedAEAD struct {
+    *AEAD
+}
+
+func (c checkedAEAD) SealTo(out, nonce, plaintext, additionalData []byte) (int, error) {
+    if c.iv == 0 {
+        return 0, ErrWiped
+    }
+    if len(nonce) != NonceSize {
+        return 0, errNonceSize
+    }
+    n, err := SealedLen(len(plaintext), c.tagSize)
+    if err != nil {
+        return 0, err
+    }
+    if tooLarge(uint64(len(plaintext)), uint64(len(additionalData))) {
+        return 0, ErrMessageTooLarge
+    }
+    if len(out) >= n && subtle.InexactOverlap(out[:n], plaintext) {
+        return 0, errOverlap
+    }
+    return c.AEAD.SealTo(out, nonce, plaintext, additionalData)
+}
+
+func (c checkedAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    n, err := OpenedLen(len(ciphertext), c.tagSize)
+    if err != nil || len(nonce) != NonceSize {
+        return c.AEAD.Open(dst, nonce, ciphertext, additionalData)
+    }
+    ret, out := subtle.SliceForAppend(dst, n)
+    if subtle.InexactOverlap(out, ciphertext[:n]) || subtle.AnyOverlap(out, ciphertext[n:]) {
+        return nil, errOverlap
+    }
+    if err := c.open(out, nonce, ciphertext[:n], ciphertext[n:], additionalData); err != nil {
+        return nil, err
+    }
+    return ret, nil
+}
+
+func (c checkedAEAD) OpenTo(out, nonce, ciphertext, additionalData []byte) (int, error) {
+    n, err := OpenedLen(len(ciphertext), c.tagSize)
+    if err == nil && len(out) >= n {
+        o := out[:n]
+        if subtle.InexactOverlap(o, ciphertext[:n]) || subtle.AnyOverlap(o, ciphertext[n:]) {
+            return 0, errOverlap
+        }
+    }
+    return c.AEAD.OpenTo(out, nonce, ciphertext, additionalData)
+}
+
+func (c checkedAEAD) OpenDetached(dst, nonce, ciphertext, tag, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize || len(tag) != c.tagSize {
+        return c.AEAD.OpenDetached(dst, nonce, ciphertext, tag, additionalData)
+    }
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        return nil, errOverlap
+    }
+    if err := c.open(out, nonce, ciphertext, tag, additionalData); err != nil {
+        return nil, err
+    }
+    return ret, nil
+}
+
+func (c checkedAEAD) SealWithNonce(dst, nonce, plaintext, additionalData []byte) ([]byte, error) {
+    if c.iv == 0 {
+        return nil, ErrWiped
+    }
+    if len(nonce) != NonceSize {
+        return nil, ErrInvalidNonceSize
+    }
+    n, err := SealedLen(len(plaintext), c.tagSize)
+    if err != nil {
+        return nil, err
+    }
+    if n > math.MaxInt-NonceSize || tooLarge(uint64(len(plaintext)), uint64(len(additionalData))) {
+        return nil, ErrMessageTooLarge
+    }
+    ret, out := subtle.SliceForAppend(dst, NonceSize+n)
+    ct := out[NonceSize:]
+    if subtle.InexactOverlap(ct, plaintext) || subtle.AnyOverlap(out[:NonceSize], plaintext) {
+        return nil, errOverlap
+    }
+    copy(out, nonce)
+    c.seal(ct[:len(plaintext)], ct[len(plaintext):], out[:NonceSize], plaintext, additionalData)
+    return ret, nil
+}
+
+func (c checkedAEAD) OpenWithNonce(dst, blob, additionalData []byte) ([]byte, error) {
+    if len(blob) < NonceSize+c.tagSize {
+        return nil, ErrCiphertextTooShort
+    }
+    var nonce [NonceSize]byte
+    copy(nonce[:], blob)
+    return c.Open(dst, nonce[:], blob[NonceSize:], additionalData)
+}
+
+func (c checkedAEAD) OpenWithPrecomputedAAD(dst, nonce, ciphertext []byte, ad *PrecomputedAAD) ([]byte, error) {
+    if ad == nil || ad.iv != c.iv {
+        if c.iv == 0 {
+            return nil, ErrWiped
+        }
+        return nil, errPrecomputedAAD
+    }
+    n, err := OpenedLen(len(ciphertext), c.tagSize)
+    if err == nil && len(nonce) == NonceSize && appendOverlaps(dst, n, ciphertext[:n], ciphertext[n:]) {
+        return nil, errOverlap
+    }
+    return c.AEAD.OpenWithPrecomputedAAD(dst, nonce, ciphertext, ad)
+}
+
+func (c checkedAEAD) OpenBatch(dst [][]byte, nonces, ciphertexts, ads [][]byte) (int, error) {
+    if len(nonces) != len(dst) || len(ciphertexts) != len(dst) ||
+        (ads != nil && len(ads) != len(dst)) {
+        return -1, errBatchLength
+    }
+    for i, ciphertext := range ciphertexts {
+        n, err := OpenedLen(len(ciphertext), c.tagSize)
+        if err == nil && len(nonces[i]) == NonceSize && appendOverlaps(dst[i], n, ciphertext[:n], ciphertext[n:]) {
+            return i, errOverlap
+        }
+    }
+    return c.AEAD.OpenBatch(dst, nonces, ciphertexts, ads)
+}
+
+// appendOverlaps reports whether the n bytes that
+// subtle.SliceForAppend would append to dst overlap in inexactly
+// or tag at all, without allocating them if dst lacks the
+// capacity, as they then overlap nothing.
+func appendOverlaps(dst []byte, n int, in, tag []byte) bool {
+    if cap(dst)-len(dst) < n {
+        return false
+    }
+    out := dst[len(dst) : len(dst)+n]
+    return subtle.InexactOverlap(out, in) || subtle.AnyOverlap(out, tag)
+}
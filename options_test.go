@@ -0,0 +1,278 @@
+package ascon
+
+import (
+    "bytes"
+    "crypto/cipher"
+    "reflect"
+    "testing"
+)
+
+func TestNewDefault(t *testing.T) {
+    key := make([]byte, KeySize)
+    for i := range key {
+        key[i] = byte(i)
+    }
+    want, err := New128a(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := New(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Fatalf("expected %#v, got %#v", want, got)
+    }
+    if v, _ := VariantOf(got); v != Ascon128a || got.Overhead() != TagSize || got.NonceSize() != NonceSize {
+        t.Fatalf("got %v with overhead %d and nonce size %d", v, got.Overhead(), got.NonceSize())
+    }
+    nonce := make([]byte, NonceSize)
+    for n := 0; n <= 33; n++ {
+        pt, ad := make([]byte, n), make([]byte, n/2)
+        ct := got.Seal(nil, nonce, pt, ad)
+        if !bytes.Equal(ct, want.Seal(nil, nonce, pt, ad)) {
+            t.Fatalf("%d bytes: output mismatch", n)
+        }
+        if _, err := want.Open(nil, nonce, ct, ad); err != nil {
+            t.Fatalf("%d bytes: %v", n, err)
+        }
+    }
+
+    for _, n := range []int{0, KeySize - 1, KeySize80pq} {
+        _, want := New128a(make([]byte, n))
+        _, got := New(make([]byte, n))
+        if got == nil || got != want {
+            t.Fatalf("%d-byte key: expected %v, got %v", n, want, got)
+        }
+    }
+}
+
+func TestNewOptions(t *testing.T) {
+    nonce := make([]byte, NonceSize)
+    pt, ad := []byte("plaintext"), []byte("header")
+    for _, tc := range []struct {
+        name string
+        opts []Option
+        key  []byte
+        fn   func([]byte) (cipher.AEAD, error)
+    }{
+        {"Ascon-128", []Option{WithVariant(Ascon128)}, make([]byte, KeySize), New128},
+        {"Ascon-128a", []Option{WithVariant(Ascon128a)}, make([]byte, KeySize), New128a},
+        {"Ascon-80pq", []Option{WithVariant(Ascon80pq)}, make([]byte, KeySize80pq), New80pq},
+        {"Ascon-AEAD128", []Option{WithVariant(AsconAEAD128)}, make([]byte, KeySize), NewAEAD128},
+        {"full tag", []Option{WithVariant(Ascon80pq), WithTagSize(TagSize)}, make([]byte, KeySize80pq), New80pq},
+        {"repeated", []Option{WithVariant(Ascon128), WithVariant(Ascon128)}, make([]byte, KeySize), New128},
+        {"truncated", []Option{WithTagSize(MinTagSize)}, make([]byte, KeySize), func(k []byte) (cipher.AEAD, error) {
+            return New128aWithTagSize(k, MinTagSize)
+        }},
+        {"truncated 128", []Option{WithTagSize(12), WithVariant(Ascon128), WithTagSize(12)}, make([]byte, KeySize),
+            func(k []byte) (cipher.AEAD, error) {
+                return New128WithTagSize(k, 12)
+            }},
+    } {
+        want, err := tc.fn(tc.key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        got, err := New(tc.key, tc.opts...)
+        if err != nil {
+            t.Fatalf("%s: %v", tc.name, err)
+        }
+        if !reflect.DeepEqual(got, want) {
+            t.Fatalf("%s: expected %#v, got %#v", tc.name, want, got)
+        }
+        if !bytes.Equal(got.Seal(nil, nonce, pt, ad), want.Seal(nil, nonce, pt, ad)) {
+            t.Fatalf("%s: output mismatch", tc.name)
+        }
+    }
+}
+
+func TestNewConflicts(t *testing.T) {
+    key := make([]byte, KeySize)
+    for _, tc := range []struct {
+        name string
+        opts []Option
+    }{
+        {"two variants", []Option{WithVariant(Ascon128), WithVariant(Ascon128a)}},
+        {"default and other variant", []Option{WithVariant(Ascon128a), WithVariant(AsconAEAD128)}},
+        {"two tag sizes", []Option{WithTagSize(8), WithTagSize(12)}},
+        {"tag size and default", []Option{WithTagSize(12), WithTagSize(TagSize)}},
+        {"truncated Ascon-AEAD128", []Option{WithVariant(AsconAEAD128), WithTagSize(8)}},
+        {"truncated Ascon-80pq", []Option{WithTagSize(8), WithVariant(Ascon80pq)}},
+        {"short tag", []Option{WithTagSize(MinTagSize - 1)}},
+        {"zero tag", []Option{WithTagSize(0)}},
+        {"long tag", []Option{WithTagSize(TagSize + 1)}},
+        {"zero variant", []Option{WithVariant(0)}},
+        {"unknown variant", []Option{WithVariant(AsconAEAD128 + 1)}},
+        {"key of another variant", []Option{WithVariant(Ascon80pq)}},
+    } {
+        if a, err := New(key, tc.opts...); err == nil {
+            t.Errorf("%s: expected an error, got %#v", tc.name, a)
+        }
+    }
+}
+
+func TestErrorsInsteadOfPanics(t *testing.T) {
+    key := make([]byte, KeySize)
+    a, err := New(key, WithErrorsInsteadOfPanics(), WithVariant(Ascon128))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, ok := a.(*AEAD); ok {
+        t.Fatal("got an *AEAD")
+    }
+    if v, ok := VariantOf(a); !ok || v != Ascon128 {
+        t.Fatalf("VariantOf: got %v, %v", v, ok)
+    }
+    want, err := New128(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    nonce := make([]byte, NonceSize)
+    pt, ad := []byte("plaintext"), []byte("header")
+    ct := a.Seal(nil, nonce, pt, ad)
+    if !bytes.Equal(ct, want.Seal(nil, nonce, pt, ad)) {
+        t.Fatal("output mismatch")
+    }
+    c := a.(interface {
+        DetachedAEAD
+        SealTo(out, nonce, plaintext, additionalData []byte) (int, error)
+        OpenTo(out, nonce, ciphertext, additionalData []byte) (int, error)
+        SealWithNonce(dst, nonce, plaintext, additionalData []byte) ([]byte, error)
+        OpenWithNonce(dst, blob, additionalData []byte) ([]byte, error)
+        PrecomputeAAD(additionalData []byte) *PrecomputedAAD
+        OpenWithPrecomputedAAD(dst, nonce, ciphertext []byte, ad *PrecomputedAAD) ([]byte, error)
+        OpenBatch(dst [][]byte, nonces, ciphertexts, ads [][]byte) (int, error)
+        Wipe()
+    })
+    other, err := New128a(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Misuse that panics without the option.
+    buf := make([]byte, 64)
+    copy(buf[1:], pt)
+    for name, fn := range map[string]func() error{
+        "SealTo nonce": func() error {
+            _, err := c.SealTo(buf, nonce[1:], pt, ad)
+            return err
+        },
+        "SealTo overlap": func() error {
+            _, err := c.SealTo(buf, nonce, buf[1:1+len(pt)], ad)
+            return err
+        },
+        "Open overlap": func() error {
+            b := append(make([]byte, 1, 64), ct...)
+            _, err := c.Open(b[:0], nonce, b[1:], ad)
+            return err
+        },
+        "OpenTo overlap": func() error {
+            b := append(make([]byte, 1, 64), ct...)
+            _, err := c.OpenTo(b[2:], nonce, b[1:], ad)
+            return err
+        },
+        "OpenDetached overlap": func() error {
+            b := append([]byte(nil), ct...)
+            n := len(pt)
+            _, err := c.OpenDetached(b[n:n], nonce, b[:n], b[n:], ad)
+            return err
+        },
+        "SealWithNonce overlap": func() error {
+            _, err := c.SealWithNonce(buf[:0], nonce, buf[1:1+len(pt)], ad)
+            return err
+        },
+        "OpenWithNonce overlap": func() error {
+            b := append(append(make([]byte, 1, 64), nonce...), ct...)
+            _, err := c.OpenWithNonce(b[NonceSize:NonceSize], b[1:], ad)
+            return err
+        },
+        "OpenWithPrecomputedAAD overlap": func() error {
+            b := append(make([]byte, 1, 64), ct...)
+            _, err := c.OpenWithPrecomputedAAD(b[:0], nonce, b[1:], c.PrecomputeAAD(ad))
+            return err
+        },
+        "OpenWithPrecomputedAAD variant": func() error {
+            _, err := c.OpenWithPrecomputedAAD(nil, nonce, ct, other.(*AEAD).PrecomputeAAD(ad))
+            return err
+        },
+        "OpenWithPrecomputedAAD nil": func() error {
+            _, err := c.OpenWithPrecomputedAAD(nil, nonce, ct, nil)
+            return err
+        },
+        "OpenBatch lengths": func() error {
+            _, err := c.OpenBatch(make([][]byte, 2), [][]byte{nonce}, [][]byte{ct}, nil)
+            return err
+        },
+        "OpenBatch overlap": func() error {
+            b := append(make([]byte, 1, 64), ct...)
+            i, err := c.OpenBatch([][]byte{nil, b[:0]}, [][]byte{nonce, nonce}, [][]byte{ct, b[1:]}, nil)
+            if err != nil && i != 1 {
+                t.Errorf("OpenBatch overlap: got index %d", i)
+            }
+            return err
+        },
+    } {
+        func() {
+            defer func() {
+                if r := recover(); r != nil {
+                    t.Errorf("%s: panicked with %v", name, r)
+                }
+            }()
+            if err := fn(); err == nil {
+                t.Errorf("%s: expected an error", name)
+            }
+        }()
+    }
+
+    // Correct calls still work, in place too.
+    b := append([]byte(nil), pt...)
+    b = append(b, make([]byte, TagSize)...)
+    if n, err := c.SealTo(b, nonce, b[:len(pt)], ad); err != nil || !bytes.Equal(b[:n], ct) {
+        t.Fatalf("SealTo in place: got %x, %v", b[:n], err)
+    }
+    if got, err := c.Open(b[:0], nonce, b, ad); err != nil || !bytes.Equal(got, pt) {
+        t.Fatalf("Open in place: got %q, %v", got, err)
+    }
+    if _, err := c.Open(nil, nonce, ct, pt); err != ErrAuthentication {
+        t.Fatalf("expected ErrAuthentication, got %v", err)
+    }
+    blob, err := c.SealWithNonce(nil, nonce, pt, ad)
+    if err != nil || !bytes.Equal(blob, append(append([]byte(nil), nonce...), ct...)) {
+        t.Fatalf("SealWithNonce: got %x, %v", blob, err)
+    }
+    if got, err := c.OpenWithNonce(nil, blob, ad); err != nil || !bytes.Equal(got, pt) {
+        t.Fatalf("OpenWithNonce: got %q, %v", got, err)
+    }
+    if got, err := c.OpenWithPrecomputedAAD(nil, nonce, ct, c.PrecomputeAAD(ad)); err != nil || !bytes.Equal(got, pt) {
+        t.Fatalf("OpenWithPrecomputedAAD: got %q, %v", got, err)
+    }
+    dst := make([][]byte, 2)
+    if i, err := c.OpenBatch(dst, [][]byte{nonce, nonce}, [][]byte{ct, ct}, [][]byte{ad, pt}); i != 1 || err != ErrAuthentication || !bytes.Equal(dst[0], pt) {
+        t.Fatalf("OpenBatch: got %d, %v", i, err)
+    }
+
+    c.Wipe()
+    if _, err := c.SealTo(buf, nonce, pt, ad); err != ErrWiped {
+        t.Fatalf("SealTo after Wipe: expected ErrWiped, got %v", err)
+    }
+    if _, err := c.Open(nil, nonce, ct, ad); err != ErrWiped {
+        t.Fatalf("Open after Wipe: expected ErrWiped, got %v", err)
+    }
+    if _, err := c.SealWithNonce(nil, nonce, pt, ad); err != ErrWiped {
+        t.Fatalf("SealWithNonce after Wipe: expected ErrWiped, got %v", err)
+    }
+    if _, err := c.OpenWithPrecomputedAAD(nil, nonce, ct, want.(*AEAD).PrecomputeAAD(ad)); err != ErrWiped {
+        t.Fatalf("OpenWithPrecomputedAAD after Wipe: expected ErrWiped, got %v", err)
+    }
+
+    // Without the option, the same misuse panics.
+    func() {
+        defer func() {
+            if recover() == nil {
+                t.Error("expected SealTo to panic without WithErrorsInsteadOfPanics")
+            }
+        }()
+        want.(*AEAD).SealTo(buf, nonce[1:], pt, ad)
+    }()
+}
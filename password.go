@@ -0,0 +1,145 @@
+package ascon
+
+import (
+    "errors"
+    "io"
+    "crypto/cipher"
+
+    "github.com/pedroalbanese/go-ascon/internal/scrypt"
+)
+
+// Format versions of EncryptWithPassword. Like those of
+// Encrypt, every version fixes the algorithms.
+const (
+    // passwordV1 is scrypt and Ascon-128a.
+    passwordV1 byte = 0x01
+)
+
+const (
+    // passwordSaltSize is the size of the random scrypt salt.
+    passwordSaltSize = 16
+    // passwordHeaderSize is the size of the version,
+    // parameters and salt that precede the ciphertext.
+    passwordHeaderSize = 1 + 3 + passwordSaltSize
+
+    // The default scrypt parameters, which use 32 MiB of memory.
+    defaultPasswordLogN = 15
+    defaultPasswordR    = 8
+    defaultPasswordP    = 1
+
+    // maxPasswordMemory bounds the memory scrypt may use, 128
+    // r N bytes, and maxPasswordWork the work, N r p block
+    // mixes, so that a forged header can exhaust neither. The
+    // work bound is that of the largest memory with p = 1.
+    maxPasswordMemory = 1 << 30
+    maxPasswordWork   = maxPasswordMemory / 128
+)
+
+// PasswordOptions are the scrypt parameters of
+// EncryptWithPassword. The zero value of a field selects its
+// default.
+type PasswordOptions struct {
+    // LogN is the base-2 logarithm of the CPU and memory cost
+    // N, 15 by default.
+    LogN int
+    // R is the block size, 8 by default, and P the
+    // parallelization, 1 by default. Both must be at most 255.
+    R, P int
+}
+
+func (o *PasswordOptions) params() (logN, r, p int, err error) {
+    logN, r, p = defaultPasswordLogN, defaultPasswordR, defaultPasswordP
+    if o != nil {
+        if o.LogN != 0 {
+            logN = o.LogN
+        }
+        if o.R != 0 {
+            r = o.R
+        }
+        if o.P != 0 {
+            p = o.P
+        }
+    }
+    if !validPasswordParams(logN, r, p) {
+        return 0, 0, 0, errors.New("ascon: invalid scrypt parameters")
+    }
+    return logN, r, p, nil
+}
+
+// validPasswordParams reports whether the parameters fit in the
+// header, in maxPasswordMemory and in maxPasswordWork. The bounds
+// are computed in uint64, which the products of parameters that
+// fit in the header cannot overflow.
+func validPasswordParams(logN, r, p int) bool {
+    if logN < 1 || logN >= 30 || r < 1 || r > 255 || p < 1 || p > 255 {
+        return false
+    }
+    rn := uint64(r) << uint(logN)
+    return 128*rn <= maxPasswordMemory && rn*uint64(p) <= maxPasswordWork
+}
+
+// EncryptWithPassword encrypts and authenticates plaintext with a
+// key derived from password, and returns
+//
+//    version || logN || r || p || salt || ciphertext || authenticator
+//
+// The key and nonce of Ascon-128a are the output of scrypt with
+// the parameters of opts, or the defaults if opts is nil, and a
+// random 16-byte salt. The version, parameters and salt are
+// authenticated as additional data, and stored so that
+// DecryptWithPassword needs only the password, even after the
+// defaults change.
+//
+// scrypt with the default parameters takes 32 MiB of memory
+// and a tenth of a second or so. That is what makes guessing
+// passwords slow; still, a weak password remains weak.
+func EncryptWithPassword(password, plaintext []byte, opts *PasswordOptions) ([]byte, error) {
+    logN, r, p, err := opts.params()
+    if err != nil {
+        return nil, err
+    }
+    out := make([]byte, passwordHeaderSize, passwordHeaderSize+len(plaintext)+TagSize)
+    out[0] = passwordV1
+    out[1], out[2], out[3] = byte(logN), byte(r), byte(p)
+    if _, err := io.ReadFull(randReader, out[4:passwordHeaderSize]); err != nil {
+        return nil, err
+    }
+
+    aead, nonce, err := passwordAEAD(password, out)
+    if err != nil {
+        return nil, err
+    }
+    return aead.Seal(out, nonce, plaintext, out), nil
+}
+
+// DecryptWithPassword opens a message produced by
+// EncryptWithPassword with the same password.
+//
+// A wrong password, and any modification of the message, are
+// reported with ErrAuthentication. So are scrypt parameters
+// that would need more than 1 GiB of memory, or more work than
+// 1 GiB with p = 1, which EncryptWithPassword never produces.
+func DecryptWithPassword(password, blob []byte) ([]byte, error) {
+    if len(blob) < passwordHeaderSize+TagSize {
+        return nil, errOpenShort
+    }
+    if blob[0] != passwordV1 || !validPasswordParams(int(blob[1]), int(blob[2]), int(blob[3])) {
+        return nil, ErrAuthentication
+    }
+    header := blob[:passwordHeaderSize]
+    aead, nonce, err := passwordAEAD(password, header)
+    if err != nil {
+        return nil, ErrAuthentication
+    }
+    return aead.Open(nil, nonce, blob[passwordHeaderSize:], header)
+}
+
+// passwordAEAD derives the AEAD and nonce of a message from the
+// password and the validated header.
+func passwordAEAD(password, header []byte) (cipher.AEAD, []byte, error) {
+    out, err := scrypt.Key(password, header[4:passwordHeaderSize], 1<<header[1], int(header[2]), int(header[3]), KeySize+NonceSize)
+    if err != nil {
+        return nil, nil, err
+    }
+    return New128aKey((*[KeySize]byte)(out)), out[KeySize:], nil
+}
@@ -0,0 +1,142 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "testing"
+    "time"
+)
+
+// fastPassword makes the tests quick; the defaults are tested
+// once.
+var fastPassword = &PasswordOptions{LogN: 4, R: 1, P: 1}
+
+func TestPasswordRoundTrip(t *testing.T) {
+    password := []byte("correct horse battery staple")
+    for _, n := range []int{0, 1, 15, 16, 17, 1000} {
+        pt := seq(0, n)
+        blob, err := EncryptWithPassword(password, pt, fastPassword)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(blob) != passwordHeaderSize+n+TagSize {
+            t.Errorf("%d bytes: got %d bytes of output", n, len(blob))
+        }
+        got, err := DecryptWithPassword(password, blob)
+        if err != nil || !bytes.Equal(got, pt) {
+            t.Fatalf("%d bytes: got %x, %v", n, got, err)
+        }
+        other, _ := EncryptWithPassword(password, pt, fastPassword)
+        if bytes.Equal(blob, other) {
+            t.Errorf("%d bytes: two encryptions are equal", n)
+        }
+    }
+}
+
+func TestPasswordDefaults(t *testing.T) {
+    start := time.Now()
+    blob, err := EncryptWithPassword([]byte("pw"), []byte("msg"), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Logf("scrypt with the defaults took %v", time.Since(start))
+    if blob[1] != defaultPasswordLogN || blob[2] != defaultPasswordR || blob[3] != defaultPasswordP {
+        t.Errorf("unexpected parameters %d, %d, %d", blob[1], blob[2], blob[3])
+    }
+    if pt, err := DecryptWithPassword([]byte("pw"), blob); err != nil || string(pt) != "msg" {
+        t.Errorf("got %q, %v", pt, err)
+    }
+}
+
+// TestPasswordParameters checks that a message is decrypted with
+// the parameters in its header, not the current defaults.
+func TestPasswordParameters(t *testing.T) {
+    for _, tc := range []struct {
+        opts   PasswordOptions
+        header [3]byte
+    }{
+        {PasswordOptions{LogN: 3, R: 1}, [3]byte{3, 1, defaultPasswordP}},
+        {PasswordOptions{LogN: 5, R: 2}, [3]byte{5, 2, defaultPasswordP}},
+        {PasswordOptions{LogN: 4, R: 1, P: 3}, [3]byte{4, 1, 3}},
+    } {
+        blob, err := EncryptWithPassword([]byte("pw"), []byte("msg"), &tc.opts)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if [3]byte(blob[1:4]) != tc.header {
+            t.Errorf("%+v: expected the parameters %x, got %x", tc.opts, tc.header, blob[1:4])
+        }
+        if pt, err := DecryptWithPassword([]byte("pw"), blob); err != nil || string(pt) != "msg" {
+            t.Errorf("%+v: got %q, %v", tc.opts, pt, err)
+        }
+    }
+    for _, opts := range []*PasswordOptions{{LogN: -1}, {LogN: 30}, {R: 256}, {P: -1}, {LogN: 21, R: 8}} {
+        if _, err := EncryptWithPassword([]byte("pw"), nil, opts); err == nil {
+            t.Errorf("%+v: accepted", *opts)
+        }
+    }
+}
+
+// TestPasswordBounds checks the memory and work bounds, which
+// must not overflow on 32-bit platforms.
+func TestPasswordBounds(t *testing.T) {
+    for _, tc := range []struct {
+        logN, r, p int
+        ok         bool
+    }{
+        {1, 1, 1, true},
+        {20, 8, 1, true},
+        {21, 8, 1, false},
+        {22, 8, 1, false},
+        {25, 8, 1, false},
+        {29, 255, 1, false},
+        {23, 1, 1, true},
+        {23, 1, 2, false},
+        {23, 1, 255, false},
+        {17, 8, 8, true},
+        {18, 8, 8, false},
+        {15, 1, 255, true},
+        {16, 1, 255, false},
+        {29, 255, 255, false},
+        {0, 1, 1, false},
+        {4, 0, 1, false},
+        {4, 1, 256, false},
+    } {
+        if ok := validPasswordParams(tc.logN, tc.r, tc.p); ok != tc.ok {
+            t.Errorf("validPasswordParams(%d, %d, %d) = %v", tc.logN, tc.r, tc.p, ok)
+        }
+    }
+}
+
+// TestPasswordFailures checks that a wrong password and every
+// modification of the message fail in the same way.
+func TestPasswordFailures(t *testing.T) {
+    password := []byte("hunter2")
+    blob, err := EncryptWithPassword(password, []byte("attack at dawn"), fastPassword)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := DecryptWithPassword([]byte("hunter3"), blob); err != ErrAuthentication {
+        t.Errorf("wrong password: got %v", err)
+    }
+    if _, err := DecryptWithPassword(nil, blob); err != ErrAuthentication {
+        t.Errorf("empty password: got %v", err)
+    }
+    for i := range blob {
+        b := append([]byte(nil), blob...)
+        b[i] ^= 1
+        if pt, err := DecryptWithPassword(password, b); err != ErrAuthentication || pt != nil {
+            t.Errorf("byte %d modified: got %q, %v", i, pt, err)
+        }
+    }
+    for _, params := range [][3]byte{{29, 1, 1}, {22, 8, 1}, {25, 8, 1}, {23, 1, 255}} {
+        forged := append([]byte(nil), blob...)
+        copy(forged[1:4], params[:])
+        if _, err := DecryptWithPassword(password, forged); err != ErrAuthentication {
+            t.Errorf("expensive parameters %v: got %v", params, err)
+        }
+    }
+    if _, err := DecryptWithPassword(password, blob[:passwordHeaderSize+TagSize-1]); !errors.Is(err, ErrCiphertextTooShort) {
+        t.Errorf("short message: got %v", err)
+    }
+}
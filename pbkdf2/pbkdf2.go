@@ -0,0 +1,86 @@
+// Package pbkdf2 implements the PBKDF2 password-based key
+// derivation function of RFC 8018 with Ascon-Mac as the
+// pseudorandom function.
+//
+// RFC 8018 uses HMAC, which accepts keys of any length. Ascon-Mac
+// takes a 16-byte key, so the password is first condensed into
+// one with Ascon-CXOF128:
+//
+//    K = Ascon-CXOF128("go-ascon pbkdf2 v1", password)[:16]
+//    PRF(P, x) = Ascon-Mac(K, x)
+//
+// The rest of the construction is unchanged: block i of the
+// output is U_1 ^ U_2 ^ ... ^ U_c, where U_1 = PRF(P, salt ||
+// BE32(i)) and U_j = PRF(P, U_{j-1}), and each block is 16
+// bytes long.
+//
+// References:
+//
+//    [rfc8018]: https://www.rfc-editor.org/rfc/rfc8018
+//
+package pbkdf2
+
+import (
+    "errors"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// passwordLabel is the customization string used to condense
+// the password into an Ascon-Mac key.
+const passwordLabel = "go-ascon pbkdf2 v1"
+
+// MaxKeyLen is the largest key length accepted by Key.
+const MaxKeyLen = 4096
+
+// Key derives a key of keyLen bytes from the password and the
+// salt using iter iterations.
+//
+// iter must be positive and keyLen must be in the range
+// [1, MaxKeyLen].
+func Key(password, salt []byte, iter, keyLen int) ([]byte, error) {
+    if iter < 1 {
+        return nil, errors.New("pbkdf2: iteration count must be positive")
+    }
+    if keyLen < 1 || keyLen > MaxKeyLen {
+        return nil, errors.New("pbkdf2: bad key length")
+    }
+
+    x, err := ascon.NewCXOF128([]byte(passwordLabel))
+    if err != nil {
+        return nil, err
+    }
+    x.Write(password)
+    var k [ascon.KeySize]byte
+    x.Read(k[:])
+    prf, err := ascon.NewMAC(k[:])
+    if err != nil {
+        return nil, err
+    }
+
+    hashLen := prf.Size()
+    numBlocks := (keyLen + hashLen - 1) / hashLen
+    dk := make([]byte, 0, numBlocks*hashLen)
+    var buf [4]byte
+    U := make([]byte, hashLen)
+    for block := 1; block <= numBlocks; block++ {
+        prf.Reset()
+        prf.Write(salt)
+        binary.BigEndian.PutUint32(buf[:], uint32(block))
+        prf.Write(buf[:])
+        dk = prf.Sum(dk)
+        T := dk[len(dk)-hashLen:]
+        copy(U, T)
+
+        for n := 2; n <= iter; n++ {
+            prf.Reset()
+            prf.Write(U)
+            U = prf.Sum(U[:0])
+            for i := range U {
+                T[i] ^= U[i]
+            }
+        }
+    }
+    return dk[:keyLen], nil
+}
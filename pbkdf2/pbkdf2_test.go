@@ -0,0 +1,58 @@
+package pbkdf2
+
+import (
+    "bytes"
+    "encoding/hex"
+    "testing"
+)
+
+// These vectors use the inputs of RFC 6070.
+func TestVectors(t *testing.T) {
+    for _, tc := range []struct {
+        password, salt string
+        iter           int
+        key            string
+    }{
+        {"password", "salt", 1, "b1e14eb471696372e1fd7c3a2656cace"},
+        {"password", "salt", 2, "ab2e82844f121f6bceb664ba0575c87b"},
+        {"password", "salt", 4096, "ed5885a75fc9aba3073bef25799fc08d"},
+        {
+            "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096,
+            "3b165fc48bd8a1010d61b9fb2cad308755d9de485f10e458b723763742bd4ff1d804fd0e194830a6",
+        },
+        {"pass\x00word", "sa\x00lt", 4096, "8a399506f6b3d384df5a445003e04b4e"},
+    } {
+        want, _ := hex.DecodeString(tc.key)
+        got, err := Key([]byte(tc.password), []byte(tc.salt), tc.iter, len(want))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("%q/%q/%d: expected %x, got %x", tc.password, tc.salt, tc.iter, want, got)
+        }
+    }
+}
+
+func TestErrors(t *testing.T) {
+    for _, tc := range []struct {
+        iter, keyLen int
+    }{
+        {0, 16},
+        {-1, 16},
+        {1, 0},
+        {1, -1},
+        {1, MaxKeyLen + 1},
+    } {
+        if _, err := Key([]byte("password"), []byte("salt"), tc.iter, tc.keyLen); err == nil {
+            t.Fatalf("%d/%d: expected an error", tc.iter, tc.keyLen)
+        }
+    }
+}
+
+func BenchmarkKey(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        if _, err := Key([]byte("password"), []byte("salt"), 4096, 16); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
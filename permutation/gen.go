@@ -0,0 +1,69 @@
+//go:build ignore
+
+// This program generates unrolled.go, the unrolled p12, p8 and
+// p6. Run it with go generate.
+package main
+
+import (
+    "bytes"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+)
+
+var roundConstants = []uint64{
+    0xf0, 0xe1, 0xd2, 0xc3, 0xb4, 0xa5,
+    0x96, 0x87, 0x78, 0x69, 0x5a, 0x4b,
+}
+
+// rotations are the two rotation amounts of the linear layer
+// of each word.
+var rotations = [5][2]int{{19, 28}, {61, 39}, {1, 6}, {10, 17}, {7, 41}}
+
+var output = flag.String("output", "unrolled.go", "output file")
+
+func main() {
+    flag.Parse()
+
+    var b bytes.Buffer
+    fmt.Fprintf(&b, "// Code generated by gen.go. DO NOT EDIT.\n\n")
+    fmt.Fprintf(&b, "package permutation\n\n")
+    fmt.Fprintf(&b, "import (\n    \"math/bits\"\n)\n")
+    for _, n := range []int{12, 8, 6} {
+        permutation(&b, n)
+    }
+    if err := os.WriteFile(*output, b.Bytes(), 0o644); err != nil {
+        log.Fatal(err)
+    }
+}
+
+// permutation writes the function that applies the last n
+// rounds, with the state in local variables.
+func permutation(b *bytes.Buffer, n int) {
+    fmt.Fprintf(b, "\n// p%d is P%d with the rounds unrolled and the state kept in\n", n, n)
+    fmt.Fprintf(b, "// local variables.\n")
+    fmt.Fprintf(b, "func p%d(s *State) {\n", n)
+    fmt.Fprintf(b, "    x0, x1, x2, x3, x4 := s[0], s[1], s[2], s[3], s[4]\n")
+    fmt.Fprintf(b, "    var t0, t1, t2, t3, t4 uint64\n")
+    for _, c := range roundConstants[12-n:] {
+        fmt.Fprintf(b, "\n")
+        fmt.Fprintf(b, "    x2 ^= %#02x\n", c)
+        fmt.Fprintf(b, "    x0 ^= x4\n")
+        fmt.Fprintf(b, "    x4 ^= x3\n")
+        fmt.Fprintf(b, "    x2 ^= x1\n")
+        for i := 0; i < 5; i++ {
+            fmt.Fprintf(b, "    t%d = x%d ^ (^x%d & x%d)\n", i, i, (i+1)%5, (i+2)%5)
+        }
+        fmt.Fprintf(b, "    t1 ^= t0\n")
+        fmt.Fprintf(b, "    t0 ^= t4\n")
+        fmt.Fprintf(b, "    t3 ^= t2\n")
+        fmt.Fprintf(b, "    t2 = ^t2\n")
+        for i, r := range rotations {
+            fmt.Fprintf(b, "    x%d = t%d ^ bits.RotateLeft64(t%d, -%d) ^ bits.RotateLeft64(t%d, -%d)\n",
+                i, i, i, r[0], i, r[1])
+        }
+    }
+    fmt.Fprintf(b, "\n    s[0], s[1], s[2], s[3], s[4] = x0, x1, x2, x3, x4\n")
+    fmt.Fprintf(b, "}\n")
+}
@@ -0,0 +1,161 @@
+// Package permutation implements the ASCON permutation.
+//
+// It is the core shared by package ascon and its subpackages,
+// and is exported for building other sponge and duplex
+// constructions on top of it.
+package permutation
+
+import (
+    "math/bits"
+    "encoding/binary"
+)
+
+// Size is the size in bytes of the ASCON state.
+const Size = 40
+
+// State is the 320-bit ASCON state as the five 64-bit words
+// x0..x4.
+type State [5]uint64
+
+// roundConstants are the constants of the twelve rounds of p12.
+var roundConstants = [12]uint64{
+    0xf0, 0xe1, 0xd2, 0xc3, 0xb4, 0xa5,
+    0x96, 0x87, 0x78, 0x69, 0x5a, 0x4b,
+}
+
+func rotr(x uint64, n int) uint64 {
+    return bits.RotateLeft64(x, -n)
+}
+
+// Round applies a single round of the permutation with the
+// round constant C.
+func Round(s *State, C uint64) {
+    s0 := s[0]
+    s1 := s[1]
+    s2 := s[2]
+    s3 := s[3]
+    s4 := s[4]
+
+    // Round constant
+    s2 ^= C
+
+    // Substitution
+    s0 ^= s4
+    s4 ^= s3
+    s2 ^= s1
+
+    // Keccak S-box
+    t0 := s0 ^ (^s1 & s2)
+    t1 := s1 ^ (^s2 & s3)
+    t2 := s2 ^ (^s3 & s4)
+    t3 := s3 ^ (^s4 & s0)
+    t4 := s4 ^ (^s0 & s1)
+
+    // Substitution
+    t1 ^= t0
+    t0 ^= t4
+    t3 ^= t2
+    t2 = ^t2
+
+    // Linear diffusion
+    //
+    // x0 ← Σ0(x0) = x0 ⊕ (x0 ≫ 19) ⊕ (x0 ≫ 28)
+    s[0] = t0 ^ rotr(t0, 19) ^ rotr(t0, 28)
+    // x1 ← Σ1(x1) = x1 ⊕ (x1 ≫ 61) ⊕ (x1 ≫ 39)
+    s[1] = t1 ^ rotr(t1, 61) ^ rotr(t1, 39)
+    // x2 ← Σ2(x2) = x2 ⊕ (x2 ≫ 1) ⊕ (x2 ≫ 6)
+    s[2] = t2 ^ rotr(t2, 1) ^ rotr(t2, 6)
+    // x3 ← Σ3(x3) = x3 ⊕ (x3 ≫ 10) ⊕ (x3 ≫ 17)
+    s[3] = t3 ^ rotr(t3, 10) ^ rotr(t3, 17)
+    // x4 ← Σ4(x4) = x4 ⊕ (x4 ≫ 7) ⊕ (x4 ≫ 41)
+    s[4] = t4 ^ rotr(t4, 7) ^ rotr(t4, 41)
+}
+
+// Rounds applies the last n rounds of p12, for 1 <= n <= 12.
+// It panics if n is out of range.
+func Rounds(s *State, n int) {
+    if n < 1 || n > 12 {
+        panic("permutation: invalid number of rounds")
+    }
+    for _, C := range roundConstants[12-n:] {
+        Round(s, C)
+    }
+}
+
+// P12, P8 and P6 run the unrolled rounds of unrolled.go, which
+// gen.go generates. Rounds is the reference they are tested
+// against.
+//
+//go:generate go run gen.go
+
+// P12 applies the 12-round permutation.
+func P12(s *State) {
+    p12(s)
+}
+
+// P8 applies the 8-round permutation.
+func P8(s *State) {
+    p8(s)
+}
+
+// P6 applies the 6-round permutation.
+func P6(s *State) {
+    p6(s)
+}
+
+// Permute applies the last rounds rounds of p12, as Rounds
+// does.
+func (s *State) Permute(rounds int) {
+    Rounds(s, rounds)
+}
+
+// Permute12 applies the 12-round permutation.
+func (s *State) Permute12() {
+    P12(s)
+}
+
+// Permute8 applies the 8-round permutation.
+func (s *State) Permute8() {
+    P8(s)
+}
+
+// Permute6 applies the 6-round permutation.
+func (s *State) Permute6() {
+    P6(s)
+}
+
+// Load sets the state from the first Size bytes of b, with each
+// word in big-endian byte order as in ASCON 1.2.
+func (s *State) Load(b []byte) {
+    _ = b[Size-1]
+    for i := range s {
+        s[i] = binary.BigEndian.Uint64(b[8*i:])
+    }
+}
+
+// Store writes the state to the first Size bytes of b, with
+// each word in big-endian byte order as in ASCON 1.2.
+func (s *State) Store(b []byte) {
+    _ = b[Size-1]
+    for i := range s {
+        binary.BigEndian.PutUint64(b[8*i:], s[i])
+    }
+}
+
+// LoadLE is like Load, but with each word in little-endian
+// byte order as in NIST SP 800-232.
+func (s *State) LoadLE(b []byte) {
+    _ = b[Size-1]
+    for i := range s {
+        s[i] = binary.LittleEndian.Uint64(b[8*i:])
+    }
+}
+
+// StoreLE is like Store, but with each word in little-endian
+// byte order as in NIST SP 800-232.
+func (s *State) StoreLE(b []byte) {
+    _ = b[Size-1]
+    for i := range s {
+        binary.LittleEndian.PutUint64(b[8*i:], s[i])
+    }
+}
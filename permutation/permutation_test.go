@@ -0,0 +1,161 @@
+package permutation
+
+import (
+    "bytes"
+    "encoding/hex"
+    "math/rand"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "testing"
+)
+
+// TestRounds checks the unrolled P12, P8 and P6 against the
+// round loop of Rounds.
+func TestRounds(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for _, tc := range []struct {
+        n  int
+        fn func(*State)
+    }{
+        {12, P12},
+        {8, P8},
+        {6, P6},
+    } {
+        for i := 0; i < 100; i++ {
+            s := State{rng.Uint64(), rng.Uint64(), rng.Uint64(), rng.Uint64(), rng.Uint64()}
+            want, got := s, s
+            tc.fn(&want)
+            Rounds(&got, tc.n)
+            if want != got {
+                t.Fatalf("p%d: expected %v, got %v", tc.n, want, got)
+            }
+        }
+    }
+}
+
+// TestGenerated checks that unrolled.go is what gen.go
+// generates.
+func TestGenerated(t *testing.T) {
+    if testing.Short() {
+        t.Skip("runs the go command")
+    }
+    goCmd, err := exec.LookPath("go")
+    if err != nil {
+        t.Skip("go command not found")
+    }
+    output := filepath.Join(t.TempDir(), "unrolled.go")
+    if out, err := exec.Command(goCmd, "run", "gen.go", "-output", output).CombinedOutput(); err != nil {
+        t.Fatalf("gen.go: %v\n%s", err, out)
+    }
+    want, err := os.ReadFile(output)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := os.ReadFile("unrolled.go")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(got, want) {
+        t.Fatal("unrolled.go is out of date, run go generate")
+    }
+}
+
+// TestRoundConstants checks the constants against the table in
+// the ASCON specification, c_i = (0xf - i) << 4 | i.
+func TestRoundConstants(t *testing.T) {
+    table := [12]uint64{
+        0x00000000000000f0, 0x00000000000000e1, 0x00000000000000d2,
+        0x00000000000000c3, 0x00000000000000b4, 0x00000000000000a5,
+        0x0000000000000096, 0x0000000000000087, 0x0000000000000078,
+        0x0000000000000069, 0x000000000000005a, 0x000000000000004b,
+    }
+    for i, c := range roundConstants {
+        if c != table[i] || c != uint64(0xf-i)<<4|uint64(i) {
+            t.Fatalf("round %d: expected %#x, got %#x", i, table[i], c)
+        }
+    }
+}
+
+func TestVectors(t *testing.T) {
+    var in [Size]byte
+    for i := range in {
+        in[i] = byte(i)
+    }
+    for _, tc := range []struct {
+        rounds int
+        out    string
+    }{
+        {12, "060587e2d489dd431cc2b17b0e3c1764957342531844a67496b17175b4cb686329b512d627d906e5"},
+        {8, "830d260d335f3bedda0bba917bcfcad7dd0d88e7dcb5ecd0892a02151f95946e3a69cb3cf982f6f7"},
+        {6, "85556bb4fb7f52d326d56c7be13375ce1d8d513041a1aed9dc9e606b1c443a2d5417aed413129e60"},
+        {1, "e0998673245546f7898989891f898b9a973b3b3b3b3b3b54281f3a7b3dfdbd3747cb4acc49c544c2"},
+    } {
+        var s State
+        s.Load(in[:])
+        s.Permute(tc.rounds)
+        var out [Size]byte
+        s.Store(out[:])
+        if got := hex.EncodeToString(out[:]); got != tc.out {
+            t.Fatalf("%d rounds: expected %s, got %s", tc.rounds, tc.out, got)
+        }
+    }
+
+    var s State
+    s.Load(in[:])
+    s.Permute12()
+    want := s
+    s.Load(in[:])
+    P12(&s)
+    if s != want {
+        t.Fatalf("Permute12: expected %v, got %v", want, s)
+    }
+    s.Load(in[:])
+    s.Permute8()
+    want = s
+    s.Load(in[:])
+    s.Permute(8)
+    if s != want {
+        t.Fatalf("Permute8: expected %v, got %v", want, s)
+    }
+    s.Load(in[:])
+    s.Permute6()
+    want = s
+    s.Load(in[:])
+    s.Permute(6)
+    if s != want {
+        t.Fatalf("Permute6: expected %v, got %v", want, s)
+    }
+}
+
+func TestLoadStore(t *testing.T) {
+    var in [Size]byte
+    for i := range in {
+        in[i] = byte(i)
+    }
+    var s State
+    s.Load(in[:])
+    if s[0] != 0x0001020304050607 || s[4] != 0x2021222324252627 {
+        t.Fatalf("Load: got %#x", s)
+    }
+    s.LoadLE(in[:])
+    if s[0] != 0x0706050403020100 || s[4] != 0x2726252423222120 {
+        t.Fatalf("LoadLE: got %#x", s)
+    }
+    var out [Size]byte
+    s.StoreLE(out[:])
+    if out != in {
+        t.Fatalf("StoreLE: expected %x, got %x", in, out)
+    }
+
+    for _, n := range []int{0, 13, -1} {
+        func() {
+            defer func() {
+                if recover() == nil {
+                    t.Fatalf("%d rounds: expected a panic", n)
+                }
+            }()
+            s.Permute(n)
+        }()
+    }
+}
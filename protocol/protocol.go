@@ -0,0 +1,201 @@
+// Package protocol implements a Strobe-style protocol session
+// on the ASCON duplex.
+//
+// A Session keeps a running transcript of every operation of a
+// protocol—associated data, keys, encrypted messages, MACs and
+// outputs—in a single duplex state. Each operation first
+// absorbs a header made of a one-byte operation tag and the
+// 64-bit little-endian length of its data, so different
+// operation sequences never produce the same transcript. Two
+// parties that perform the same sequence of operations, one
+// calling SendENC and SendMAC where the other calls RecvENC and
+// RecvMAC, stay synchronized, and every output depends on the
+// whole transcript so far.
+//
+// The duplex has the parameters of Ascon-XOF128: a 64-bit rate,
+// a 256-bit capacity and p12 between blocks. Until the first
+// output, the transcript is the message of an Ascon-CXOF128
+// with the customization string "go-ascon protocol v1", and
+// PRF returns that function's output.
+//
+// This is not an implementation of Strobe and does not
+// interoperate with it.
+package protocol
+
+import (
+    "errors"
+    "runtime"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/duplex"
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// MACSize is the size in bytes of the tags returned by
+// SendMAC.
+const MACSize = 16
+
+const (
+    ivCXOF128 uint64 = 0x0000080000cc0004 // Ascon-CXOF128 (SP 800-232)
+
+    customization = "go-ascon protocol v1"
+)
+
+// Operation tags.
+const (
+    opInit byte = iota + 1
+    opAD
+    opKey
+    opPRF
+    opENC
+    opMAC
+    opRatchet
+)
+
+// Session is a protocol transcript. It is not safe for
+// concurrent use.
+type Session struct {
+    d *duplex.Duplex
+}
+
+// New starts a session for the protocol named by the given
+// string, which is absorbed first. Choose a name that is
+// unique to the protocol and its version.
+func New(protocol string) *Session {
+    d, err := duplex.NewLE(8, 12, 12)
+    if err != nil {
+        panic(err)
+    }
+    d.State()[0] = ivCXOF128
+    d.Permute()
+    d.State()[0] ^= uint64(len(customization)) * 8
+    d.Permute()
+    d.Absorb([]byte(customization))
+    d.Pad()
+
+    s := &Session{d: d}
+    s.begin(opInit, len(protocol))
+    s.d.Absorb([]byte(protocol))
+    return s
+}
+
+// begin absorbs the header of an operation on n bytes.
+func (s *Session) begin(op byte, n int) {
+    var h [9]byte
+    h[0] = op
+    binary.LittleEndian.PutUint64(h[1:], uint64(n))
+    s.d.Absorb(h[:])
+}
+
+// AD absorbs associated data, such as a public key or a
+// handshake message sent in the clear.
+func (s *Session) AD(data []byte) {
+    s.begin(opAD, len(data))
+    s.d.Absorb(data)
+}
+
+// Key absorbs a secret key, such as a shared secret. It is the
+// same as AD except for its operation tag, which keeps keys
+// and public data apart in the transcript.
+func (s *Session) Key(key []byte) {
+    s.begin(opKey, len(key))
+    s.d.Absorb(key)
+}
+
+// PRF returns n bytes of output that depend on the whole
+// transcript, for example to derive keys for another protocol.
+func (s *Session) PRF(n int) []byte {
+    s.begin(opPRF, n)
+    s.d.Pad()
+    return s.d.Squeeze(n)
+}
+
+// SendENC encrypts plaintext, appends the ciphertext to dst and
+// returns the resulting slice. The ciphertext is the same
+// length as plaintext and is also absorbed into the
+// transcript.
+//
+// SendENC provides confidentiality only after Key; it does not
+// authenticate the ciphertext, which is the role of SendMAC.
+// To reuse plaintext's storage for the ciphertext, use
+// plaintext[:0] as dst. Otherwise, the remaining capacity of
+// dst must not overlap plaintext.
+func (s *Session) SendENC(dst, plaintext []byte) []byte {
+    ret, out := subtle.SliceForAppend(dst, len(plaintext))
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("protocol: invalid buffer overlap")
+    }
+    s.begin(opENC, len(plaintext))
+    s.d.Pad()
+    s.d.Encrypt(out, plaintext)
+    return ret
+}
+
+// RecvENC decrypts a ciphertext produced by SendENC, appends
+// the plaintext to dst and returns the resulting slice. The
+// plaintext is not authenticated until a following RecvMAC
+// succeeds.
+//
+// To reuse ciphertext's storage for the plaintext, use
+// ciphertext[:0] as dst. Otherwise, the remaining capacity of
+// dst must not overlap ciphertext.
+func (s *Session) RecvENC(dst, ciphertext []byte) []byte {
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) {
+        panic("protocol: invalid buffer overlap")
+    }
+    s.begin(opENC, len(ciphertext))
+    s.d.Pad()
+    s.d.Decrypt(out, ciphertext)
+    return ret
+}
+
+// SendMAC returns a MACSize-byte tag that authenticates the
+// transcript so far.
+func (s *Session) SendMAC() []byte {
+    s.begin(opMAC, MACSize)
+    s.d.Pad()
+    return s.d.Squeeze(MACSize)
+}
+
+// RecvMAC checks a tag produced by SendMAC in constant time.
+//
+// After a failed RecvMAC, the transcripts of the two parties
+// differ and the session must be discarded.
+func (s *Session) RecvMAC(tag []byte) error {
+    want := s.SendMAC()
+    ok := subtle.ConstantTimeCompare(tag, want) == 1
+    for i := range want {
+        want[i] = 0
+    }
+    runtime.KeepAlive(want)
+    if !ok {
+        return errors.New("protocol: message authentication failed")
+    }
+    return nil
+}
+
+// Ratchet replaces the state with a one-way function of
+// itself, so that a later compromise of the session does not
+// reveal earlier keys or outputs.
+func (s *Session) Ratchet() {
+    s.begin(opRatchet, 0)
+    s.d.Pad()
+    st := s.d.State()
+    old := *st
+    s.d.Permute()
+    for i := range st {
+        st[i] ^= old[i]
+    }
+    for i := range old {
+        old[i] = 0
+    }
+    runtime.KeepAlive(&old)
+}
+
+// Clone returns an independent copy of the session, for
+// example to try an operation without committing to it.
+func (s *Session) Clone() *Session {
+    d := *s.d
+    return &Session{d: &d}
+}
@@ -0,0 +1,163 @@
+package protocol
+
+import (
+    "bytes"
+    "testing"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func header(op byte, n int) []byte {
+    h := make([]byte, 9)
+    h[0] = op
+    binary.LittleEndian.PutUint64(h[1:], uint64(n))
+    return h
+}
+
+// TestCXOF checks that a transcript of input operations is
+// the message of Ascon-CXOF128.
+func TestCXOF(t *testing.T) {
+    for _, n := range []int{0, 1, 16, 45} {
+        s := New("test")
+        s.AD([]byte("abc"))
+        s.Key(make([]byte, n))
+        got := s.PRF(40)
+
+        x, err := ascon.NewCXOF128([]byte(customization))
+        if err != nil {
+            t.Fatal(err)
+        }
+        x.Write(header(opInit, 4))
+        x.Write([]byte("test"))
+        x.Write(header(opAD, 3))
+        x.Write([]byte("abc"))
+        x.Write(header(opKey, n))
+        x.Write(make([]byte, n))
+        x.Write(header(opPRF, 40))
+        want := make([]byte, 40)
+        x.Read(want)
+        if !bytes.Equal(got, want) {
+            t.Fatalf("%d: expected %#x, got %#x", n, want, got)
+        }
+    }
+}
+
+// TestHandshake runs a toy handshake in which both parties
+// already share a secret.
+func TestHandshake(t *testing.T) {
+    const name = "go-ascon protocol test v1"
+    client, server := New(name), New(name)
+    for _, s := range []*Session{client, server} {
+        s.AD([]byte("client hello"))
+        s.AD([]byte("server hello"))
+        s.Key([]byte("shared secret"))
+    }
+
+    msg := []byte("the first message")
+    ct := client.SendENC(nil, msg)
+    tag := client.SendMAC()
+    if bytes.Equal(ct, msg) {
+        t.Fatal("message was not encrypted")
+    }
+    pt := server.RecvENC(nil, ct)
+    if err := server.RecvMAC(tag); err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(pt, msg) {
+        t.Fatalf("expected %q, got %q", msg, pt)
+    }
+
+    client.Ratchet()
+    server.Ratchet()
+
+    reply := []byte("a reply")
+    ct = server.SendENC(ct[:0], reply)
+    tag = server.SendMAC()
+    pt = client.RecvENC(nil, ct)
+    if err := client.RecvMAC(tag); err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(pt, reply) {
+        t.Fatalf("expected %q, got %q", reply, pt)
+    }
+
+    a, b := client.PRF(32), server.PRF(32)
+    if !bytes.Equal(a, b) {
+        t.Fatalf("sessions out of sync: %#x and %#x", a, b)
+    }
+}
+
+func TestForgery(t *testing.T) {
+    sender, receiver := New("test"), New("test")
+    sender.Key([]byte("key"))
+    receiver.Key([]byte("key"))
+    ct := sender.SendENC(nil, []byte("message"))
+    tag := sender.SendMAC()
+
+    for i, f := range []func() ([]byte, []byte){
+        func() ([]byte, []byte) { c := append([]byte(nil), ct...); c[0] ^= 1; return c, tag },
+        func() ([]byte, []byte) { g := append([]byte(nil), tag...); g[MACSize-1] ^= 0x80; return ct, g },
+        func() ([]byte, []byte) { return ct, tag[:MACSize-1] },
+        func() ([]byte, []byte) { return ct, nil },
+    } {
+        r := receiver.Clone()
+        c, g := f()
+        r.RecvENC(nil, c)
+        if err := r.RecvMAC(g); err == nil {
+            t.Fatalf("#%d: forgery accepted", i)
+        }
+    }
+
+    receiver.RecvENC(nil, ct)
+    if err := receiver.RecvMAC(tag); err != nil {
+        t.Fatal(err)
+    }
+}
+
+// TestFraming checks that different operation sequences over
+// the same bytes give different outputs.
+func TestFraming(t *testing.T) {
+    seen := make(map[string]int)
+    for i, f := range []func(*Session){
+        func(s *Session) { s.AD([]byte("abc")) },
+        func(s *Session) { s.AD([]byte("ab")); s.AD([]byte("c")) },
+        func(s *Session) { s.AD([]byte("a")); s.AD([]byte("bc")) },
+        func(s *Session) { s.Key([]byte("abc")) },
+        func(s *Session) { s.AD([]byte("abc")); s.Ratchet() },
+        func(s *Session) { s.AD(nil) },
+        func(s *Session) {},
+    } {
+        s := New("test")
+        f(s)
+        out := string(s.PRF(16))
+        if j, ok := seen[out]; ok {
+            t.Fatalf("#%d and #%d collide", j, i)
+        }
+        seen[out] = i
+    }
+
+    a, b := New("test"), New("test2")
+    if bytes.Equal(a.PRF(16), b.PRF(16)) {
+        t.Fatal("protocol names collide")
+    }
+}
+
+func TestInPlace(t *testing.T) {
+    sender, receiver := New("test"), New("test")
+    msg := []byte("in-place message")
+    buf := append([]byte(nil), msg...)
+    buf = sender.SendENC(buf[:0], buf)
+    buf = receiver.RecvENC(buf[:0], buf)
+    if !bytes.Equal(buf, msg) {
+        t.Fatalf("expected %q, got %q", msg, buf)
+    }
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expected a panic")
+        }
+    }()
+    buf = make([]byte, 32)
+    sender.SendENC(buf[:1], buf[:16])
+}
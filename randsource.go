@@ -0,0 +1,89 @@
+package ascon
+
+import (
+    "encoding/binary"
+)
+
+// randNonce is the nonce of the keystream behind RandSource.
+const randNonce = "go-ascon rand v1"
+
+// RandSource is a deterministic pseudorandom source of uint64
+// values. It implements rand.Source from math/rand/v2 and
+// rand.Source64 from math/rand.
+//
+// The values are the ASCON-128a keystream of NewStream for the
+// seed as key and the nonce "go-ascon rand v1", read in
+// consecutive big-endian 64-bit words. This byte stream is
+// fixed: it is the same on every platform and will not change
+// in future releases.
+//
+// RandSource is meant for reproducible simulations and tests.
+// It is only suitable for generating keys when the seed itself
+// comes from crypto/rand, and even then prefer crypto/rand or
+// a DRBG, which ratchets its state. A RandSource is not safe
+// for concurrent use; use Fork to give each goroutine its own.
+type RandSource struct {
+    s state
+    // i is the index of the next unused rate word; 2 means the
+    // block is used up.
+    i int
+}
+
+// NewRandSource returns a RandSource seeded with seed.
+func NewRandSource(seed [KeySize]byte) *RandSource {
+    r := new(RandSource)
+    r.init(seed)
+    return r
+}
+
+func (r *RandSource) init(seed [KeySize]byte) {
+    r.s.init(iv128a,
+        binary.BigEndian.Uint64(seed[0:]), binary.BigEndian.Uint64(seed[8:]),
+        binary.BigEndian.Uint64([]byte(randNonce[0:])), binary.BigEndian.Uint64([]byte(randNonce[8:])))
+    r.s.additionalData128a(nil)
+    r.i = 0
+}
+
+// Uint64 returns the next 64 pseudorandom bits.
+func (r *RandSource) Uint64() uint64 {
+    if r.i == 2 {
+        p8(&r.s)
+        r.i = 0
+    }
+    v := r.s[r.i]
+    r.i++
+    return v
+}
+
+// Int63 returns the next value with the top bit cleared, as
+// required by rand.Source from math/rand.
+func (r *RandSource) Int63() int64 {
+    return int64(r.Uint64() & (1<<63 - 1))
+}
+
+// Seed reseeds the source with the 16-byte seed made of the
+// big-endian encoding of seed followed by eight zero bytes, as
+// required by rand.Source from math/rand.
+func (r *RandSource) Seed(seed int64) {
+    var b [KeySize]byte
+    binary.BigEndian.PutUint64(b[:], uint64(seed))
+    r.init(b)
+}
+
+// Clone returns a copy of the source that produces the same
+// values from this point on.
+func (r *RandSource) Clone() *RandSource {
+    c := *r
+    return &c
+}
+
+// Fork returns a new, independent source seeded with the next
+// 128 bits of r. Forking in a fixed order, for example once per
+// worker before starting them, gives reproducible parallel
+// streams.
+func (r *RandSource) Fork() *RandSource {
+    var seed [KeySize]byte
+    binary.BigEndian.PutUint64(seed[0:], r.Uint64())
+    binary.BigEndian.PutUint64(seed[8:], r.Uint64())
+    return NewRandSource(seed)
+}
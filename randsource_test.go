@@ -0,0 +1,84 @@
+package ascon
+
+import (
+    "testing"
+    "encoding/binary"
+    mrand "math/rand"
+    "math/rand/v2"
+)
+
+var (
+    _ rand.Source    = (*RandSource)(nil)
+    _ mrand.Source64 = (*RandSource)(nil)
+)
+
+func TestRandSource(t *testing.T) {
+    var seed [KeySize]byte
+    copy(seed[:], seq(0, 16))
+    r := NewRandSource(seed)
+    for i, want := range []uint64{
+        0x734a63647435ef12,
+        0x2d2135c9c884bfce,
+        0x915db4faccdd27c5,
+        0x0b64b530372764c0,
+        0x30b8e22278ac5c45,
+        0x9bed3d8da960f1b9,
+    } {
+        if got := r.Uint64(); got != want {
+            t.Fatalf("#%d: expected %#016x, got %#016x", i, want, got)
+        }
+    }
+
+    // The stream is the keystream of NewStream.
+    ks := make([]byte, 8*100)
+    if err := XORKeyStream(ks, ks, seed[:], []byte(randNonce)); err != nil {
+        t.Fatal(err)
+    }
+    r = NewRandSource(seed)
+    for i := 0; i < len(ks); i += 8 {
+        if got, want := r.Uint64(), binary.BigEndian.Uint64(ks[i:]); got != want {
+            t.Fatalf("word %d: expected %#016x, got %#016x", i/8, want, got)
+        }
+    }
+
+    r.Seed(-1)
+    var b [KeySize]byte
+    binary.BigEndian.PutUint64(b[:], ^uint64(0))
+    if got, want := r.Int63(), NewRandSource(b).Int63(); got != want || got < 0 {
+        t.Fatalf("Seed: expected %d, got %d", want, got)
+    }
+}
+
+func TestRandSourceClone(t *testing.T) {
+    r := NewRandSource([KeySize]byte{})
+    r.Uint64()
+    c := r.Clone()
+    for i := 0; i < 10; i++ {
+        if a, b := r.Uint64(), c.Uint64(); a != b {
+            t.Fatalf("#%d: %#x != %#x", i, a, b)
+        }
+    }
+
+    f, g := r.Fork(), r.Fork()
+    a, b, c0 := f.Uint64(), g.Uint64(), r.Uint64()
+    if a == b || a == c0 || b == c0 {
+        t.Fatalf("forks are not independent: %#x, %#x, %#x", a, b, c0)
+    }
+
+    // The stream also drives math/rand/v2 reproducibly.
+    x := rand.New(NewRandSource([KeySize]byte{1}))
+    y := rand.New(NewRandSource([KeySize]byte{1}))
+    for i := 0; i < 10; i++ {
+        if a, b := x.IntN(1000), y.IntN(1000); a != b {
+            t.Fatalf("#%d: %d != %d", i, a, b)
+        }
+    }
+}
+
+func BenchmarkRandSource(b *testing.B) {
+    r := NewRandSource([KeySize]byte{})
+    b.SetBytes(8)
+    for i := 0; i < b.N; i++ {
+        r.Uint64()
+    }
+}
@@ -0,0 +1,75 @@
+package ascon
+
+import (
+    "io"
+)
+
+// readFromBufSize is the size of the buffer used by ReadFrom.
+// It is a multiple of HashBlockSize.
+const readFromBufSize = 16 << 10
+
+// readFrom absorbs the data read from r until EOF. Data is
+// read straight into a block-aligned buffer, so whole blocks
+// are absorbed without being copied through d.buf.
+func (d *sponge) readFrom(r io.Reader) (int64, error) {
+    buf := make([]byte, readFromBufSize)
+    // Start with the pending partial block, so that blocks in
+    // buf stay aligned.
+    off := copy(buf, d.buf[:d.n])
+    d.n = 0
+
+    var total int64
+    for {
+        n, err := r.Read(buf[off:])
+        total += int64(n)
+        off += n
+
+        full := off - off%HashBlockSize
+        for i := 0; i < full; i += HashBlockSize {
+            d.s[0] ^= d.load(buf[i:])
+            d.permute()
+        }
+        off = copy(buf, buf[full:off])
+
+        if err != nil {
+            d.n = copy(d.buf[:], buf[:off])
+            if err == io.EOF {
+                err = nil
+            }
+            return total, err
+        }
+    }
+}
+
+// ReadFrom implements io.ReaderFrom. It absorbs the data read
+// from r until EOF or an error, and returns the number of bytes
+// read. io.Copy uses it automatically.
+//
+// Data read before an error has been absorbed.
+func (d *digest) ReadFrom(r io.Reader) (int64, error) {
+    return d.readFrom(r)
+}
+
+// ReadFrom implements io.ReaderFrom. It absorbs the data read
+// from r until EOF or an error, and returns the number of bytes
+// read. Like Write, it panics if called after Read.
+func (x *XOF) ReadFrom(r io.Reader) (int64, error) {
+    if x.squeezing {
+        panic("ascon: XOF written after read")
+    }
+    return x.readFrom(r)
+}
+
+// SumReader returns the Ascon-Hash digest, as computed by
+// Sum256, of the data read from r until EOF.
+func SumReader(r io.Reader) ([HashSize]byte, error) {
+    var out [HashSize]byte
+    d := sponge{init: hashInit, b: 12}
+    d.reset()
+    if _, err := d.readFrom(r); err != nil {
+        return out, err
+    }
+    d.finish()
+    d.squeeze(out[:])
+    return out, nil
+}
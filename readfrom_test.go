@@ -0,0 +1,121 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "hash"
+    "testing"
+    "testing/iotest"
+)
+
+func TestReadFrom(t *testing.T) {
+    data := make([]byte, 3*readFromBufSize+13)
+    for i := range data {
+        data[i] = byte(i * 7)
+    }
+    want := Sum256(data)
+
+    for _, tc := range []struct {
+        name string
+        r    func([]byte) io.Reader
+    }{
+        {"plain", func(b []byte) io.Reader { return bytes.NewReader(b) }},
+        {"OneByte", func(b []byte) io.Reader { return iotest.OneByteReader(bytes.NewReader(b)) }},
+        {"Half", func(b []byte) io.Reader { return iotest.HalfReader(bytes.NewReader(b)) }},
+        {"DataErr", func(b []byte) io.Reader { return iotest.DataErrReader(bytes.NewReader(b)) }},
+    } {
+        for _, prefix := range []int{0, 3, 8} {
+            h := NewHash()
+            h.Write(data[:prefix])
+            n, err := h.(io.ReaderFrom).ReadFrom(tc.r(data[prefix:]))
+            if err != nil {
+                t.Fatalf("%s/%d: %v", tc.name, prefix, err)
+            }
+            if n != int64(len(data)-prefix) {
+                t.Fatalf("%s/%d: expected %d bytes, got %d", tc.name, prefix, len(data)-prefix, n)
+            }
+            if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+                t.Fatalf("%s/%d: expected %#x, got %#x", tc.name, prefix, want, got)
+            }
+        }
+
+        got, err := SumReader(tc.r(data))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got != want {
+            t.Fatalf("%s: SumReader: expected %#x, got %#x", tc.name, want, got)
+        }
+    }
+
+    x := NewXOF128()
+    if _, err := x.ReadFrom(bytes.NewReader(data)); err != nil {
+        t.Fatal(err)
+    }
+    y := NewXOF128()
+    y.Write(data)
+    a, b := make([]byte, 40), make([]byte, 40)
+    x.Read(a)
+    y.Read(b)
+    if !bytes.Equal(a, b) {
+        t.Fatalf("XOF: expected %#x, got %#x", b, a)
+    }
+}
+
+func TestReadFromError(t *testing.T) {
+    data := []byte("0123456789abcdefghij")
+    r := io.MultiReader(bytes.NewReader(data), iotest.ErrReader(io.ErrUnexpectedEOF))
+
+    h := NewHash()
+    n, err := h.(io.ReaderFrom).ReadFrom(r)
+    if err != io.ErrUnexpectedEOF {
+        t.Fatalf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+    }
+    if n != int64(len(data)) {
+        t.Fatalf("expected %d bytes, got %d", len(data), n)
+    }
+    // The data read before the error has been absorbed.
+    want := Sum256(data)
+    if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+        t.Fatalf("expected %#x, got %#x", want, got)
+    }
+
+    if _, err := SumReader(iotest.TimeoutReader(bytes.NewReader(data))); !errors.Is(err, iotest.ErrTimeout) {
+        t.Fatalf("expected %v, got %v", iotest.ErrTimeout, err)
+    }
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+    clear(p)
+    return len(p), nil
+}
+
+// writerOnly hides the ReadFrom method of a hash from io.Copy.
+type writerOnly struct {
+    io.Writer
+}
+
+func BenchmarkReadFrom(b *testing.B) {
+    benchmarkCopy(b, func(h hash.Hash) io.Writer { return h })
+}
+
+func BenchmarkCopyWrite(b *testing.B) {
+    benchmarkCopy(b, func(h hash.Hash) io.Writer { return writerOnly{h} })
+}
+
+func benchmarkCopy(b *testing.B, w func(hash.Hash) io.Writer) {
+    const size = 1 << 20
+    b.SetBytes(size)
+    b.ReportAllocs()
+    h := NewHash()
+    for i := 0; i < b.N; i++ {
+        h.Reset()
+        if _, err := io.Copy(w(h), io.LimitReader(zeroReader{}, size)); err != nil {
+            b.Fatal(err)
+        }
+        h.Sum(nil)
+    }
+}
@@ -0,0 +1,141 @@
+package ascon
+
+import (
+    "bytes"
+    "math/rand"
+    "testing"
+    "crypto/cipher"
+
+    "github.com/pedroalbanese/go-ascon/ascontest"
+    "github.com/pedroalbanese/go-ascon/permutation"
+)
+
+// The tests in this file compare the package against the
+// reference implementation in package ascontest.
+
+func TestReferencePermutation(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    for i := 0; i < 1000; i++ {
+        s := randState(rng)
+        for _, tc := range []struct {
+            rounds int
+            fn     func(*state)
+        }{
+            {12, p12},
+            {8, p8},
+            {6, p6},
+        } {
+            want := [5]uint64(s)
+            ascontest.Permute(&want, tc.rounds)
+            got := s
+            tc.fn(&got)
+            if got != state(want) {
+                t.Fatalf("p%d(%#x): expected %#x, got %#x", tc.rounds, s, want, got)
+            }
+        }
+        n := 1 + rng.Intn(12)
+        want := [5]uint64(s)
+        ascontest.Permute(&want, n)
+        got := permutation.State(s)
+        permutation.Rounds(&got, n)
+        if got != permutation.State(want) {
+            t.Fatalf("Rounds(%#x, %d): expected %#x, got %#x", s, n, want, got)
+        }
+    }
+}
+
+// referenceSizes returns the lengths around the multiples of
+// rate up to three blocks.
+func referenceSizes(rate int) []int {
+    sizes := []int{0, 1}
+    for n := 1; n <= 3; n++ {
+        sizes = append(sizes, n*rate-1, n*rate, n*rate+1)
+    }
+    return sizes
+}
+
+var referenceVariants = []ascontest.Variant{
+    ascontest.Ascon128,
+    ascontest.Ascon128a,
+    ascontest.Ascon80pq,
+    ascontest.AsconAEAD128,
+}
+
+// checkReference seals pt with aead and the reference and
+// compares the results, then opens the ciphertext with both.
+func checkReference(t *testing.T, v ascontest.Variant, aead cipher.AEAD, key, nonce, ad, pt []byte) {
+    t.Helper()
+    want := ascontest.SealReference(v, key, nonce, ad, pt)
+    got := aead.Seal(nil, nonce, pt, ad)
+    if !bytes.Equal(got, want) {
+        t.Fatalf("%d/%d: Seal: expected %#x, got %#x", len(ad), len(pt), want, got)
+    }
+    out, err := aead.Open(nil, nonce, want, ad)
+    if err != nil || !bytes.Equal(out, pt) {
+        t.Fatalf("%d/%d: Open: %v", len(ad), len(pt), err)
+    }
+    if out, err := ascontest.OpenReference(v, key, nonce, ad, got); err != nil || !bytes.Equal(out, pt) {
+        t.Fatalf("%d/%d: OpenReference: %v", len(ad), len(pt), err)
+    }
+}
+
+func TestReferenceAEAD(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    for _, v := range referenceVariants {
+        t.Run(Variant(v).String(), func(t *testing.T) {
+            key := make([]byte, ascontest.KeySize(v))
+            nonce := make([]byte, NonceSize)
+            rng.Read(key)
+            rng.Read(nonce)
+            aead, err := New(key, WithVariant(Variant(v)))
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            // Every combination of lengths around the block
+            // boundaries.
+            sizes := referenceSizes(ascontest.Rate(v))
+            for _, adLen := range sizes {
+                for _, ptLen := range sizes {
+                    ad := make([]byte, adLen)
+                    pt := make([]byte, ptLen)
+                    rng.Read(ad)
+                    rng.Read(pt)
+                    checkReference(t, v, aead, key, nonce, ad, pt)
+                }
+            }
+
+            // Random keys, nonces and lengths.
+            for i := 0; i < 200; i++ {
+                key := make([]byte, len(key))
+                nonce := make([]byte, len(nonce))
+                rng.Read(key)
+                rng.Read(nonce)
+                aead, err := New(key, WithVariant(Variant(v)))
+                if err != nil {
+                    t.Fatal(err)
+                }
+                ad := make([]byte, rng.Intn(100))
+                pt := make([]byte, rng.Intn(300))
+                rng.Read(ad)
+                rng.Read(pt)
+                checkReference(t, v, aead, key, nonce, ad, pt)
+            }
+
+            // Long inputs next to empty ones, and inputs of a
+            // single repeated byte.
+            long := make([]byte, 1<<16+3)
+            rng.Read(long)
+            for _, in := range [][2][]byte{
+                {long, nil},
+                {nil, long},
+                {long, long[:1]},
+                {long[:1], long},
+                {bytes.Repeat([]byte{0xff}, 1000), bytes.Repeat([]byte{0xff}, 1000)},
+                {make([]byte, 1000), make([]byte, 1000)},
+            } {
+                checkReference(t, v, aead, key, nonce, in[0], in[1])
+            }
+        })
+    }
+}
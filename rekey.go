@@ -0,0 +1,105 @@
+package ascon
+
+import (
+    "strconv"
+    "crypto/cipher"
+    "encoding/binary"
+)
+
+// ivRekey is the IV of the re-keying function: the key size,
+// the absorption rate in bits and the round counts sK=12 and
+// sB=1, as bytes 80 01 0c 01 followed by zeros.
+const ivRekey uint64 = 0x80010c0100000000
+
+type rekeying struct {
+    k0, k1 uint64
+}
+
+var _ cipher.AEAD = (*rekeying)(nil)
+
+// NewRekeying128 creates an ASCON-128 AEAD that encrypts every
+// message under a fresh key derived from the 16-byte master key
+// K and the nonce N, which limits the number of traces of any
+// single key available to a power analysis attacker.
+//
+// The one-time key K* is computed with the leakage-resilient
+// re-keying function of ISAP. The 320-bit state S, written as
+// five 64-bit big-endian words, is initialized to
+//
+//    S = K || IV || 0^128, with IV = 0x80010c0100000000
+//
+// and permuted with p12. The 128 bits of N are then absorbed
+// one at a time, most significant bit of N[0] first, by XORing
+// each bit into the most significant bit of the first word;
+// every bit but the last is followed by one round of the
+// permutation (the round with constant 0x4b) and the last by
+// p12. K* is the first 128 bits of S. The message is then
+// processed by ASCON-128 with key K* and nonce N, so Seal and
+// Open have the same output sizes as New128.
+//
+// Because the master key is only ever combined with one bit of
+// attacker-controlled input per permutation call, a DPA attack
+// on the derivation only sees two possible inputs per step.
+// The same nonce and data limits as New128 apply.
+func NewRekeying128(master []byte) (cipher.AEAD, error) {
+    if len(master) != KeySize {
+        return nil, KeySizeError(len(master))
+    }
+
+    return &rekeying{
+        k0: binary.BigEndian.Uint64(master[0:]),
+        k1: binary.BigEndian.Uint64(master[8:]),
+    }, nil
+}
+
+func (r *rekeying) NonceSize() int {
+    return NonceSize
+}
+
+func (r *rekeying) Overhead() int {
+    return TagSize
+}
+
+func (r *rekeying) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    a := r.derive(nonce)
+    return a.Seal(dst, nonce, plaintext, additionalData)
+}
+
+func (r *rekeying) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
+    }
+
+    a := r.derive(nonce)
+    return a.Open(dst, nonce, ciphertext, additionalData)
+}
+
+// derive returns the ASCON-128 instance keyed with the one-time
+// key for nonce.
+func (r *rekeying) derive(nonce []byte) AEAD {
+    s := state{0: r.k0, 1: r.k1, 2: ivRekey}
+    p12(&s)
+
+    n := [2]uint64{
+        binary.BigEndian.Uint64(nonce[0:]),
+        binary.BigEndian.Uint64(nonce[8:]),
+    }
+    for i := 0; i < 128; i++ {
+        s[0] ^= (n[i/64] >> (63 - i%64) & 1) << 63
+        if i < 127 {
+            round(&s, 0x4b)
+        }
+    }
+    p12(&s)
+
+    return AEAD{
+        k0:      s[0],
+        k1:      s[1],
+        iv:      iv128,
+        tagSize: TagSize,
+    }
+}
@@ -0,0 +1,41 @@
+package ascon
+
+import (
+    "bytes"
+    "path/filepath"
+    "testing"
+)
+
+// testdata/vectors_rekey128.txt is written by testdata/gen.py
+// rekey128, which does not use this package.
+func TestVectorsRekeying128(t *testing.T) {
+    testVectors(t, NewRekeying128, filepath.Join("testdata", "vectors_rekey128.txt"))
+}
+
+func TestRekeyingFreshKeys(t *testing.T) {
+    master := make([]byte, KeySize)
+    r, err := NewRekeying128(master)
+    if err != nil {
+        t.Fatal(err)
+    }
+    rk := r.(*rekeying)
+
+    // Nonces that differ in a single bit give unrelated keys,
+    // none of them the master key.
+    nonce := make([]byte, NonceSize)
+    base := rk.derive(nonce)
+    for i := 0; i < 8*NonceSize; i++ {
+        n := make([]byte, NonceSize)
+        n[i/8] ^= 0x80 >> (i % 8)
+        k := rk.derive(n)
+        if k.k0 == base.k0 || k.k1 == base.k1 || (k.k0 == 0 && k.k1 == 0) {
+            t.Fatalf("bit %d: derived key %x %x", i, k.k0, k.k1)
+        }
+    }
+
+    pt := []byte("sensor reading")
+    plain, _ := New128(master)
+    if bytes.Equal(r.Seal(nil, nonce, pt, nil), plain.Seal(nil, nonce, pt, nil)) {
+        t.Fatal("message encrypted under the master key")
+    }
+}
@@ -0,0 +1,142 @@
+package ascon
+
+import (
+    "crypto/cipher"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// A Session seals or opens a single message whose additional
+// data is supplied in several pieces.
+//
+// Call WriteAdditionalData any number of times, then exactly
+// one of Encrypt or Decrypt. The result is identical to
+// calling Seal or Open with the concatenation of all the
+// additional data.
+type Session struct {
+    a     *AEAD
+    s     state
+    buf   [BlockSize128a]byte
+    n     int
+    adLen uint64
+    done  bool
+    // max is the limit on the length of the plaintext,
+    // MaxPlaintextSize outside of tests.
+    max   uint64
+}
+
+// NewSession starts a Session for nonce using aead, which must
+// have been created by New128, New128a, New80pq, NewAEAD128 or
+// one of their truncated tag variants.
+func NewSession(aead cipher.AEAD, nonce []byte) (*Session, error) {
+    a, err := asASCON(aead, nonce)
+    if err != nil {
+        return nil, err
+    }
+
+    s := &Session{a: a, max: MaxPlaintextSize}
+    a.init(&s.s, nonce)
+    return s, nil
+}
+
+// WriteAdditionalData absorbs the next piece of additional
+// data.
+//
+// WriteAdditionalData panics if called after Encrypt or
+// Decrypt.
+func (s *Session) WriteAdditionalData(p []byte) {
+    if s.done {
+        panic("ascon: WriteAdditionalData after Encrypt or Decrypt")
+    }
+    s.adLen += uint64(len(p))
+
+    // The buffer is only absorbed once more data arrives since
+    // the final block, even if full, must be padded.
+    bs := s.a.blockSize()
+    if s.n > 0 {
+        if s.n+len(p) <= bs {
+            s.n += copy(s.buf[s.n:], p)
+            return
+        }
+        p = p[copy(s.buf[s.n:bs], p):]
+        s.a.additionalDataBlocks(&s.s, s.buf[:bs])
+        s.n = 0
+    }
+    if len(p) > bs {
+        n := (len(p) - 1) &^ (bs - 1)
+        s.a.additionalDataBlocks(&s.s, p[:n])
+        p = p[n:]
+    }
+    s.n = copy(s.buf[:], p)
+}
+
+// finishAdditionalData pads the buffered additional data and
+// applies the domain separation.
+func (s *Session) finishAdditionalData() {
+    if s.done {
+        panic("ascon: Session already used")
+    }
+    s.done = true
+
+    if s.adLen == 0 {
+        s.a.additionalData(&s.s, nil)
+    } else {
+        s.a.additionalData(&s.s, s.buf[:s.n])
+    }
+}
+
+// Encrypt encrypts and authenticates plaintext, appends the
+// result to dst and returns the updated slice, exactly like
+// Seal.
+//
+// Encrypt panics if called more than once or after Decrypt.
+func (s *Session) Encrypt(dst, plaintext []byte) []byte {
+    s.finishAdditionalData()
+
+    n, err := sealedLen(len(plaintext), s.a.tagSize, s.max)
+    if err != nil {
+        panic(err)
+    }
+    if tooLarge(0, s.adLen) {
+        panic("ascon: message too large")
+    }
+    ret, out := subtle.SliceForAppend(dst, n)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    s.a.sealMessage(&s.s, out[:len(plaintext)], out[len(plaintext):], plaintext)
+
+    return ret
+}
+
+// Decrypt decrypts and authenticates ciphertext, appends the
+// plaintext to dst and returns the updated slice, exactly like
+// Open.
+//
+// Decrypt panics if called more than once or after Encrypt.
+func (s *Session) Decrypt(dst, ciphertext []byte) ([]byte, error) {
+    s.finishAdditionalData()
+
+    n, err := openedLen(len(ciphertext), s.a.tagSize, s.max)
+    if err != nil {
+        return nil, err
+    }
+    if tooLarge(0, s.adLen) {
+        return nil, ErrAuthentication
+    }
+
+    tag := ciphertext[n:]
+    ciphertext = ciphertext[:n]
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) || subtle.AnyOverlap(out, tag) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    if err := s.a.openMessage(&s.s, out, ciphertext, tag); err != nil {
+        return nil, err
+    }
+
+    return ret, nil
+}
@@ -0,0 +1,102 @@
+package ascon
+
+import (
+    "bytes"
+    "crypto/cipher"
+    "testing"
+)
+
+var sessionTests = []struct {
+    name string
+    fn   func([]byte) (cipher.AEAD, error)
+    key  int
+}{
+    {"128", New128, KeySize},
+    {"128a", New128a, KeySize},
+    {"80pq", New80pq, KeySize80pq},
+    {"AEAD128", NewAEAD128, KeySize},
+}
+
+// TestSessionSplits splits the additional data at every pair
+// of offsets and compares the result against Seal and Open.
+func TestSessionSplits(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            aead, err := tc.fn(make([]byte, tc.key))
+            if err != nil {
+                t.Fatal(err)
+            }
+            nonce := make([]byte, NonceSize)
+            pt := []byte("plaintext")
+
+            for n := 0; n <= 3*BlockSize128a+1; n++ {
+                ad := make([]byte, n)
+                for i := range ad {
+                    ad[i] = byte(i)
+                }
+                want := aead.Seal(nil, nonce, pt, ad)
+
+                for i := 0; i <= n; i++ {
+                    for j := i; j <= n; j++ {
+                        s, err := NewSession(aead, nonce)
+                        if err != nil {
+                            t.Fatal(err)
+                        }
+                        s.WriteAdditionalData(ad[:i])
+                        s.WriteAdditionalData(ad[i:j])
+                        s.WriteAdditionalData(ad[j:])
+                        got := s.Encrypt(nil, pt)
+                        if !bytes.Equal(got, want) {
+                            t.Fatalf("%d/%d/%d: expected %#x, got %#x", n, i, j, want, got)
+                        }
+
+                        s, err = NewSession(aead, nonce)
+                        if err != nil {
+                            t.Fatal(err)
+                        }
+                        s.WriteAdditionalData(ad[:i])
+                        s.WriteAdditionalData(ad[i:j])
+                        s.WriteAdditionalData(ad[j:])
+                        p, err := s.Decrypt(nil, want)
+                        if err != nil {
+                            t.Fatalf("%d/%d/%d: %v", n, i, j, err)
+                        }
+                        if !bytes.Equal(p, pt) {
+                            t.Fatalf("%d/%d/%d: expected %#x, got %#x", n, i, j, pt, p)
+                        }
+                    }
+                }
+            }
+        })
+    }
+}
+
+func TestSessionMisuse(t *testing.T) {
+    aead, err := New128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := NewSession(aead, make([]byte, NonceSize-1)); err == nil {
+        t.Fatal("expected an error")
+    }
+    siv, err := NewSIV(make([]byte, KeySizeSIV))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := NewSession(siv, make([]byte, NonceSize)); err == nil {
+        t.Fatal("expected an error")
+    }
+
+    s, err := NewSession(aead, make([]byte, NonceSize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s.Encrypt(nil, nil)
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expected a panic")
+        }
+    }()
+    s.WriteAdditionalData([]byte("late"))
+}
@@ -0,0 +1,137 @@
+package ascon
+
+import (
+    "runtime"
+    "strconv"
+    "crypto/cipher"
+    "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/subtle"
+)
+
+// KeySizeSIV is the size in bytes of ASCON-SIV keys.
+const KeySizeSIV = 32
+
+type siv struct {
+    m0, m1 uint64 // Ascon-Mac key
+    k0, k1 uint64 // ASCON-128a key
+}
+
+var _ cipher.AEAD = (*siv)(nil)
+
+// NewSIV creates a nonce-misuse resistant ASCON-SIV AEAD.
+//
+// The 32-byte key is split into a 16-byte authentication key
+// K1 and a 16-byte encryption key K2. For a nonce N,
+// additional data A and plaintext P, Seal computes
+//
+//    V = Ascon-Mac(K1, N || uint64(len(A)) || A || P)
+//    C = ASCON-128a(K2, nonce=V, ad="", P) without its tag
+//
+// and returns C || V, where uint64 is big endian and len(A)
+// is in bytes. Open decrypts C using V and recomputes V over
+// the recovered plaintext, which it compares in constant time.
+//
+// Sealing the same plaintext and additional data with the
+// same nonce always produces the same output, so reusing a
+// nonce only reveals whether two messages are identical. Use
+// unique nonces whenever possible.
+func NewSIV(key []byte) (cipher.AEAD, error) {
+    if len(key) != KeySizeSIV {
+        return nil, KeySizeError(len(key))
+    }
+
+    return &siv{
+        m0: binary.BigEndian.Uint64(key[0:]),
+        m1: binary.BigEndian.Uint64(key[8:]),
+        k0: binary.BigEndian.Uint64(key[16:]),
+        k1: binary.BigEndian.Uint64(key[24:]),
+    }, nil
+}
+
+func (a *siv) NonceSize() int {
+    return NonceSize
+}
+
+func (a *siv) Overhead() int {
+    return TagSize
+}
+
+func (a *siv) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSize {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    ret, out := subtle.SliceForAppend(dst, len(plaintext)+TagSize)
+    if subtle.InexactOverlap(out, plaintext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    var v [TagSize]byte
+    a.syntheticIV(v[:], nonce, plaintext, additionalData)
+
+    var s state
+    s.init(iv128a, a.k0, a.k1,
+        binary.BigEndian.Uint64(v[0:]), binary.BigEndian.Uint64(v[8:]))
+    s.additionalData128a(nil)
+    s.encrypt128a(out[:len(plaintext)], plaintext)
+
+    copy(out[len(plaintext):], v[:])
+
+    return ret
+}
+
+func (a *siv) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSize {
+        return nil, errOpenNonce
+    }
+
+    if len(ciphertext) < TagSize {
+        return nil, errOpenShort
+    }
+
+    var v [TagSize]byte
+    copy(v[:], ciphertext[len(ciphertext)-TagSize:])
+    ciphertext = ciphertext[:len(ciphertext)-TagSize]
+
+    ret, out := subtle.SliceForAppend(dst, len(ciphertext))
+    if subtle.InexactOverlap(out, ciphertext) {
+        panic("ascon: invalid buffer overlap")
+    }
+
+    var s state
+    s.init(iv128a, a.k0, a.k1,
+        binary.BigEndian.Uint64(v[0:]), binary.BigEndian.Uint64(v[8:]))
+    s.additionalData128a(nil)
+    s.decrypt128a(out, ciphertext)
+
+    var expectedV [TagSize]byte
+    a.syntheticIV(expectedV[:], nonce, out, additionalData)
+
+    if subtle.ConstantTimeCompare(expectedV[:], v[:]) != 1 {
+        for i := range out {
+            out[i] = 0
+        }
+
+        runtime.KeepAlive(out)
+        return nil, ErrAuthentication
+    }
+
+    return ret, nil
+}
+
+// syntheticIV writes the 16-byte synthetic IV for the message
+// to dst.
+func (a *siv) syntheticIV(dst, nonce, plaintext, additionalData []byte) {
+    var n [8]byte
+    binary.BigEndian.PutUint64(n[:], uint64(len(additionalData)))
+
+    var m macState
+    m.init(ivMAC, a.m0, a.m1)
+    m.write(nonce)
+    m.write(n[:])
+    m.write(additionalData)
+    m.write(plaintext)
+    m.finish()
+    m.squeeze(dst)
+}
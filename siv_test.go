@@ -0,0 +1,66 @@
+package ascon
+
+import (
+    "bytes"
+    "path/filepath"
+    "testing"
+)
+
+// testdata/vectors_siv.txt is written by testdata/gen.py siv, which
+// does not use this package.
+func TestVectorsSIV(t *testing.T) {
+    testVectors(t, NewSIV, filepath.Join("testdata", "vectors_siv.txt"))
+}
+
+func TestSIVDeterministic(t *testing.T) {
+    key := make([]byte, KeySizeSIV)
+    for i := range key {
+        key[i] = byte(i)
+    }
+    aead, err := NewSIV(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    nonce := make([]byte, NonceSize)
+    pt := []byte("attack at dawn, attack at dawn!")
+    ad := []byte("header")
+
+    c1 := aead.Seal(nil, nonce, pt, ad)
+    c2 := aead.Seal(nil, nonce, pt, ad)
+    if !bytes.Equal(c1, c2) {
+        t.Fatalf("expected %#x, got %#x", c1, c2)
+    }
+
+    nonce[0] ^= 1
+    c3 := aead.Seal(nil, nonce, pt, ad)
+    if bytes.Equal(c1, c3) {
+        t.Fatal("different nonces produced the same output")
+    }
+    nonce[0] ^= 1
+
+    // In place.
+    buf := append([]byte(nil), pt...)
+    buf = aead.Seal(buf[:0], nonce, buf, ad)
+    if !bytes.Equal(buf, c1) {
+        t.Fatalf("expected %#x, got %#x", c1, buf)
+    }
+    buf, err = aead.Open(buf[:0], nonce, buf, ad)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(buf, pt) {
+        t.Fatalf("expected %#x, got %#x", pt, buf)
+    }
+
+    for i := range c1 {
+        c := append([]byte(nil), c1...)
+        c[i] ^= 0x80
+        if _, err := aead.Open(nil, nonce, c, ad); err == nil {
+            t.Fatalf("#%d: expected an error", i)
+        }
+    }
+    if _, err := aead.Open(nil, nonce, c1, ad[1:]); err == nil {
+        t.Fatal("expected an error")
+    }
+}
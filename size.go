@@ -0,0 +1,91 @@
+package ascon
+
+import (
+    "errors"
+    "math"
+)
+
+// ErrMessageTooLarge is returned by SealedLen for a plaintext
+// longer than MaxPlaintextLen, and matched by the error of
+// OpenedLen for a ciphertext that would open to one.
+var ErrMessageTooLarge = errors.New("ascon: message too large")
+
+var (
+    errBadTagSize   = errors.New("ascon: bad tag length")
+    errOpenTooLarge = &openError{ErrMessageTooLarge}
+)
+
+// MaxPlaintextLen returns the length in bytes of the longest
+// plaintext that an AEAD of the variant v can seal on this
+// platform, with any tag size, or 0 if v is unknown. It is
+// MaxPlaintextSize on 64-bit platforms, and just under 2 GiB on
+// 32-bit ones, where the sealed message must fit an int.
+func MaxPlaintextLen(v Variant) int {
+    if v.KeySize() == 0 {
+        return 0
+    }
+    return maxLen(MaxPlaintextSize)
+}
+
+// maxLen is MaxPlaintextLen for a known variant with the limit
+// max instead of MaxPlaintextSize. The limit is a uint64, not
+// the constant, so that it converts to an int on 32-bit
+// platforms, where MaxPlaintextSize overflows one.
+func maxLen(max uint64) int {
+    n := math.MaxInt - TagSize
+    if uint64(n) > max {
+        n = int(max)
+    }
+    return n
+}
+
+// SealedLen returns the length of the output of Seal for a
+// plaintext of plaintextLen bytes and a tag of tagSize bytes,
+// as given by Overhead. It returns ErrMessageTooLarge, instead
+// of an overflowed length, if Seal would panic on such a
+// plaintext.
+func SealedLen(plaintextLen, tagSize int) (int, error) {
+    return sealedLen(plaintextLen, tagSize, MaxPlaintextSize)
+}
+
+// sealedLen is SealedLen with the limit max instead of
+// MaxPlaintextSize, so that tests can reach it.
+func sealedLen(plaintextLen, tagSize int, max uint64) (int, error) {
+    if tagSize < MinTagSize || tagSize > TagSize {
+        return 0, errBadTagSize
+    }
+    if plaintextLen < 0 {
+        return 0, errors.New("ascon: negative plaintext length")
+    }
+    if uint64(plaintextLen) > max || plaintextLen > math.MaxInt-tagSize {
+        return 0, ErrMessageTooLarge
+    }
+    return plaintextLen + tagSize, nil
+}
+
+// OpenedLen returns the length of the plaintext that Open
+// returns for a ciphertext of ciphertextLen bytes, tag included,
+// and a tag of tagSize bytes. Its errors are those of Open for
+// such a ciphertext: they match ErrAuthentication, and
+// ErrCiphertextTooShort if the ciphertext is shorter than the
+// tag or ErrMessageTooLarge if the plaintext would be longer
+// than MaxPlaintextSize.
+func OpenedLen(ciphertextLen, tagSize int) (int, error) {
+    return openedLen(ciphertextLen, tagSize, MaxPlaintextSize)
+}
+
+// openedLen is OpenedLen with the limit max instead of
+// MaxPlaintextSize.
+func openedLen(ciphertextLen, tagSize int, max uint64) (int, error) {
+    if tagSize < MinTagSize || tagSize > TagSize {
+        return 0, errBadTagSize
+    }
+    if ciphertextLen < tagSize {
+        return 0, errOpenShort
+    }
+    n := ciphertextLen - tagSize
+    if uint64(n) > max {
+        return 0, errOpenTooLarge
+    }
+    return n, nil
+}
@@ -0,0 +1,237 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "math"
+    "strconv"
+    "testing"
+)
+
+func TestSealedLen(t *testing.T) {
+    // The limits on this platform: MaxPlaintextSize on 64-bit
+    // ones, and MaxInt less the tag on 32-bit ones.
+    limit := func(tagSize int) int {
+        if strconv.IntSize == 32 {
+            return math.MaxInt - tagSize
+        }
+        max := uint64(MaxPlaintextSize)
+        return int(max)
+    }
+    for _, tagSize := range []int{MinTagSize, 12, TagSize} {
+        for _, tc := range []struct {
+            n    int
+            want int
+            err  error
+        }{
+            {0, tagSize, nil},
+            {1, 1 + tagSize, nil},
+            {limit(tagSize), limit(tagSize) + tagSize, nil},
+            {limit(tagSize) + 1, 0, ErrMessageTooLarge},
+            {math.MaxInt, 0, ErrMessageTooLarge},
+        } {
+            got, err := SealedLen(tc.n, tagSize)
+            if got != tc.want || err != tc.err {
+                t.Errorf("SealedLen(%d, %d) = %d, %v; expected %d, %v", tc.n, tagSize, got, err, tc.want, tc.err)
+            }
+        }
+    }
+    for _, tc := range [][2]int{{-1, TagSize}, {math.MinInt, TagSize}, {0, 0}, {0, MinTagSize - 1}, {0, TagSize + 1}, {0, -1}} {
+        if n, err := SealedLen(tc[0], tc[1]); err == nil {
+            t.Errorf("SealedLen(%d, %d) = %d, expected an error", tc[0], tc[1], n)
+        }
+    }
+
+    for _, v := range []Variant{Ascon128, Ascon128a, Ascon80pq, AsconAEAD128} {
+        max := MaxPlaintextLen(v)
+        if max != limit(TagSize) {
+            t.Errorf("%v: MaxPlaintextLen = %d, expected %d", v, max, limit(TagSize))
+        }
+        for tagSize := MinTagSize; tagSize <= TagSize; tagSize++ {
+            if _, err := SealedLen(max, tagSize); err != nil {
+                t.Errorf("%v: SealedLen(MaxPlaintextLen, %d): %v", v, tagSize, err)
+            }
+        }
+    }
+    if n := MaxPlaintextLen(0); n != 0 {
+        t.Errorf("MaxPlaintextLen(0) = %d", n)
+    }
+}
+
+func TestOpenedLen(t *testing.T) {
+    for _, tagSize := range []int{MinTagSize, 12, TagSize} {
+        for _, tc := range []struct {
+            n    int
+            want int
+            err  error
+        }{
+            {tagSize, 0, nil},
+            {tagSize + 1, 1, nil},
+            {tagSize - 1, 0, ErrCiphertextTooShort},
+            {0, 0, ErrCiphertextTooShort},
+            {-1, 0, ErrCiphertextTooShort},
+            {math.MinInt, 0, ErrCiphertextTooShort},
+        } {
+            got, err := OpenedLen(tc.n, tagSize)
+            if got != tc.want || !errors.Is(err, tc.err) {
+                t.Errorf("OpenedLen(%d, %d) = %d, %v; expected %d, %v", tc.n, tagSize, got, err, tc.want, tc.err)
+            }
+            if err != nil && !errors.Is(err, ErrAuthentication) {
+                t.Errorf("OpenedLen(%d, %d): %v does not match ErrAuthentication", tc.n, tagSize, err)
+            }
+        }
+
+        n, err := OpenedLen(math.MaxInt, tagSize)
+        if strconv.IntSize == 32 {
+            if err != nil || n != math.MaxInt-tagSize {
+                t.Errorf("OpenedLen(MaxInt, %d) = %d, %v", tagSize, n, err)
+            }
+        } else if !errors.Is(err, ErrMessageTooLarge) || !errors.Is(err, ErrAuthentication) {
+            t.Errorf("OpenedLen(MaxInt, %d) = %d, %v", tagSize, n, err)
+        }
+    }
+    for _, tagSize := range []int{0, MinTagSize - 1, TagSize + 1} {
+        if n, err := OpenedLen(TagSize, tagSize); err == nil {
+            t.Errorf("OpenedLen(%d, %d) = %d, expected an error", TagSize, tagSize, n)
+        }
+    }
+}
+
+// TestSizesAgree checks that Seal and Open return the lengths,
+// and Open the errors, of SealedLen and OpenedLen.
+func TestSizesAgree(t *testing.T) {
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    for tagSize := MinTagSize; tagSize <= TagSize; tagSize++ {
+        aead, err := New(key, WithTagSize(tagSize))
+        if err != nil {
+            t.Fatal(err)
+        }
+        a := aead.(*AEAD)
+        for n := 0; n <= 40; n++ {
+            ct := a.Seal(nil, nonce, make([]byte, n), nil)
+            if want, _ := SealedLen(n, tagSize); len(ct) != want {
+                t.Fatalf("tag size %d: Seal of %d bytes returned %d, expected %d", tagSize, n, len(ct), want)
+            }
+            pt, err := a.Open(nil, nonce, ct, nil)
+            if want, _ := OpenedLen(len(ct), tagSize); err != nil || len(pt) != want {
+                t.Fatalf("tag size %d: Open of %d bytes returned %d, %v, expected %d", tagSize, len(ct), len(pt), err, want)
+            }
+        }
+        for n := 0; n < tagSize; n++ {
+            _, want := OpenedLen(n, tagSize)
+            if _, err := a.Open(nil, nonce, make([]byte, n), nil); err != want {
+                t.Fatalf("tag size %d: Open of %d bytes: expected %v, got %v", tagSize, n, want, err)
+            }
+            if _, err := a.OpenTo(make([]byte, n), nonce, make([]byte, n), nil); err != want {
+                t.Fatalf("tag size %d: OpenTo of %d bytes: expected %v, got %v", tagSize, n, want, err)
+            }
+        }
+    }
+}
+
+// TestLimit checks sealedLen, openedLen and maxLen at a limit
+// low enough for a test, which MaxPlaintextSize is not.
+func TestLimit(t *testing.T) {
+    const limit = 40
+    for _, tagSize := range []int{MinTagSize, TagSize} {
+        if n, err := sealedLen(limit, tagSize, limit); n != limit+tagSize || err != nil {
+            t.Errorf("sealedLen(%d, %d) = %d, %v", limit, tagSize, n, err)
+        }
+        if _, err := sealedLen(limit+1, tagSize, limit); err != ErrMessageTooLarge {
+            t.Errorf("sealedLen(%d, %d): expected ErrMessageTooLarge, got %v", limit+1, tagSize, err)
+        }
+        if n, err := openedLen(limit+tagSize, tagSize, limit); n != limit || err != nil {
+            t.Errorf("openedLen(%d, %d) = %d, %v", limit+tagSize, tagSize, n, err)
+        }
+        if _, err := openedLen(limit+1+tagSize, tagSize, limit); err != errOpenTooLarge {
+            t.Errorf("openedLen(%d, %d): expected the too large error, got %v", limit+1+tagSize, tagSize, err)
+        }
+    }
+    if n := maxLen(limit); n != limit {
+        t.Errorf("maxLen(%d) = %d", limit, n)
+    }
+    if n := maxLen(math.MaxUint64); n != math.MaxInt-TagSize {
+        t.Errorf("maxLen(MaxUint64) = %d", n)
+    }
+}
+
+// TestStreamingSizes checks that Session and EncryptWriter apply
+// the limits of SealedLen and OpenedLen. It lowers the limit of
+// each Session and EncryptWriter, as MaxPlaintextSize is out of
+// reach of a test on 64-bit platforms and of an allocation on
+// 32-bit ones, so it runs the same on both; run it with
+// GOARCH=386 too.
+func TestStreamingSizes(t *testing.T) {
+    const limit = 40
+
+    key := make([]byte, KeySize)
+    nonce := make([]byte, NonceSize)
+    for _, tagSize := range []int{MinTagSize, TagSize} {
+        aead, err := New(key, WithTagSize(tagSize))
+        if err != nil {
+            t.Fatal(err)
+        }
+        ct := aead.Seal(nil, nonce, make([]byte, limit), []byte("ad"))
+
+        s, err := NewSession(aead, nonce)
+        if err != nil {
+            t.Fatal(err)
+        }
+        s.max = limit
+        s.WriteAdditionalData([]byte("ad"))
+        if got := s.Encrypt(nil, make([]byte, limit)); !bytes.Equal(got, ct) {
+            t.Fatalf("tag size %d: Session.Encrypt mismatch", tagSize)
+        }
+        func() {
+            defer func() {
+                if r := recover(); r != ErrMessageTooLarge {
+                    t.Errorf("tag size %d: Session.Encrypt of %d bytes: expected a panic with ErrMessageTooLarge, got %v", tagSize, limit+1, r)
+                }
+            }()
+            s, _ := NewSession(aead, nonce)
+            s.max = limit
+            s.Encrypt(nil, make([]byte, limit+1))
+        }()
+
+        for _, tc := range []struct {
+            ct  []byte
+            err error
+        }{
+            {ct, nil},
+            {make([]byte, limit+1+tagSize), ErrMessageTooLarge},
+            {make([]byte, tagSize-1), ErrCiphertextTooShort},
+        } {
+            s, _ := NewSession(aead, nonce)
+            s.max = limit
+            s.WriteAdditionalData([]byte("ad"))
+            _, err := s.Decrypt(nil, tc.ct)
+            _, want := openedLen(len(tc.ct), tagSize, limit)
+            if err != want || (tc.err != nil && !errors.Is(err, tc.err)) {
+                t.Errorf("tag size %d: Session.Decrypt of %d bytes: expected %v, got %v", tagSize, len(tc.ct), want, err)
+            }
+        }
+
+        var buf bytes.Buffer
+        w, err := NewEncryptWriter(aead, nonce, []byte("ad"), &buf)
+        if err != nil {
+            t.Fatal(err)
+        }
+        w.max = limit
+        if _, err := w.Write(make([]byte, limit-10)); err != nil {
+            t.Fatal(err)
+        }
+        if n, err := w.Write(make([]byte, 11)); n != 0 || err != ErrMessageTooLarge {
+            t.Fatalf("tag size %d: EncryptWriter past the limit: got %d, %v", tagSize, n, err)
+        }
+        if _, err := w.Write(make([]byte, 10)); err != nil {
+            t.Fatal(err)
+        }
+        if err := w.Close(); err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(buf.Bytes(), ct) {
+            t.Fatalf("tag size %d: EncryptWriter mismatch", tagSize)
+        }
+    }
+}
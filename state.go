@@ -2,50 +2,74 @@ package ascon
 
 import (
     "encoding/binary"
+
+    "github.com/pedroalbanese/go-ascon/internal/byteorder"
+    "github.com/pedroalbanese/go-ascon/permutation"
 )
 
-type state struct {
-    x0, x1, x2, x3, x4 uint64
-}
+// state is the permutation state x0..x4 as 64-bit words, in
+// which the bytes of the rate are the words x0 and x1.
+//
+// The ASCON v1.2 variants load each 8 bytes of input into a
+// word in big-endian order, so the first byte is the top byte
+// of the word, and the Ascon-AEAD128 functions of SP 800-232
+// load them in little-endian order. The order is always
+// explicit, through encoding/binary or through the partial
+// word helpers in utils.go, and never that of the host, so the
+// code is the same on big- and little-endian CPUs. The
+// compiler turns the encoding/binary calls into single loads
+// and stores, byte swapped where needed.
+type state permutation.State
 
 func (s *state) init(iv, k0, k1, n0, n1 uint64) {
-    s.x0 = iv
-    s.x1 = k0
-    s.x2 = k1
-    s.x3 = n0
-    s.x4 = n1
+    s[0] = iv
+    s[1] = k0
+    s[2] = k1
+    s[3] = n0
+    s[4] = n1
     p12(s)
-    s.x3 ^= k0
-    s.x4 ^= k1
+    s[3] ^= k0
+    s[4] ^= k1
 }
 
 func (s *state) finalize128a(k0, k1 uint64) {
-    s.x2 ^= k0
-    s.x3 ^= k1
+    s[2] ^= k0
+    s[3] ^= k1
     p12(s)
-    s.x3 ^= k0
-    s.x4 ^= k1
+    s[3] ^= k0
+    s[4] ^= k1
 }
 
 func (s *state) additionalData128a(ad []byte) {
-    if len(ad) > 0 {
-        n := len(ad) &^ (BlockSize128a - 1)
-        if n > 0 {
-            additionalData128a(s, ad[:n])
-            ad = ad[n:]
-        }
-        if len(ad) >= 8 {
-            s.x0 ^= binary.BigEndian.Uint64(ad[0:8])
-            s.x1 ^= be64n(ad[8:])
-            s.x1 ^= pad(len(ad) - 8)
-        } else {
-            s.x0 ^= be64n(ad)
-            s.x0 ^= pad(len(ad))
-        }
-        p8(s)
+    if len(ad) == 0 {
+        // Empty additional data is not absorbed at all, only
+        // the domain separation bit is set.
+        s[4] ^= 1
+        return
+    }
+
+    n := len(ad) &^ (BlockSize128a - 1)
+    if n > 0 {
+        additionalData128a(s, ad[:n])
+        ad = ad[n:]
     }
+    s.padBlock128a(ad)
+    p8(s)
 
-    s.x4 ^= 1
+    s[4] ^= 1
+}
+
+// padBlock128a absorbs the final, partial block b of the
+// additional data of ASCON-128a, with padding.
+func (s *state) padBlock128a(b []byte) {
+    if len(b) >= 8 {
+        s[0] ^= binary.BigEndian.Uint64(b[0:8])
+        s[1] ^= byteorder.BE64n(b[8:])
+        s[1] ^= byteorder.Pad(len(b) - 8)
+    } else {
+        s[0] ^= byteorder.BE64n(b)
+        s[0] ^= byteorder.Pad(len(b))
+    }
 }
 
 func (s *state) encrypt128a(dst, src []byte) {
@@ -55,17 +79,22 @@ func (s *state) encrypt128a(dst, src []byte) {
         src = src[n:]
         dst = dst[n:]
     }
+    s.encryptTail128a(dst, src)
+}
 
+// encryptTail128a encrypts the final, partial block of
+// ASCON-128a, with padding.
+func (s *state) encryptTail128a(dst, src []byte) {
     if len(src) >= 8 {
-        s.x0 ^= binary.BigEndian.Uint64(src[0:8])
-        s.x1 ^= be64n(src[8:])
-        s.x1 ^= pad(len(src) - 8)
-        binary.BigEndian.PutUint64(dst[0:8], s.x0)
-        put64n(dst[8:], s.x1)
+        s[0] ^= binary.BigEndian.Uint64(src[0:8])
+        s[1] ^= byteorder.BE64n(src[8:])
+        s[1] ^= byteorder.Pad(len(src) - 8)
+        binary.BigEndian.PutUint64(dst[0:8], s[0])
+        byteorder.PutBE64n(dst[8:], s[1])
     } else {
-        s.x0 ^= be64n(src)
-        put64n(dst, s.x0)
-        s.x0 ^= pad(len(src))
+        s[0] ^= byteorder.BE64n(src)
+        byteorder.PutBE64n(dst, s[0])
+        s[0] ^= byteorder.Pad(len(src))
     }
 }
 
@@ -76,79 +105,294 @@ func (s *state) decrypt128a(dst, src []byte) {
         src = src[n:]
         dst = dst[n:]
     }
+    s.decryptTail128a(dst, src)
+}
 
+// decryptTail128a decrypts the final, partial block of
+// ASCON-128a, with padding.
+func (s *state) decryptTail128a(dst, src []byte) {
     if len(src) >= 8 {
         c0 := binary.BigEndian.Uint64(src[0:8])
-        c1 := be64n(src[8:])
-        binary.BigEndian.PutUint64(dst[0:8], s.x0^c0)
-        put64n(dst[8:], s.x1^c1)
-        s.x0 = c0
-        s.x1 = mask(s.x1, len(src)-8)
-        s.x1 |= c1
-        s.x1 ^= pad(len(src) - 8)
+        c1 := byteorder.BE64n(src[8:])
+        binary.BigEndian.PutUint64(dst[0:8], s[0]^c0)
+        byteorder.PutBE64n(dst[8:], s[1]^c1)
+        s[0] = c0
+        s[1] = byteorder.Mask(s[1], len(src)-8)
+        s[1] |= c1
+        s[1] ^= byteorder.Pad(len(src) - 8)
     } else {
-        c0 := be64n(src)
-        put64n(dst, s.x0^c0)
-        s.x0 = mask(s.x0, len(src))
-        s.x0 |= c0
-        s.x0 ^= pad(len(src))
+        c0 := byteorder.BE64n(src)
+        byteorder.PutBE64n(dst, s[0]^c0)
+        s[0] = byteorder.Mask(s[0], len(src))
+        s[0] |= c0
+        s[0] ^= byteorder.Pad(len(src))
+    }
+}
+
+// encryptShort128a encrypts a message of at most one block of
+// ASCON-128a. A full block is absorbed without the block loop,
+// and its padding goes into the next, empty block.
+func (s *state) encryptShort128a(dst, src []byte) {
+    if len(src) < BlockSize128a {
+        s.encryptTail128a(dst, src)
+        return
     }
+    s[0] ^= binary.BigEndian.Uint64(src[0:8])
+    s[1] ^= binary.BigEndian.Uint64(src[8:16])
+    binary.BigEndian.PutUint64(dst[0:8], s[0])
+    binary.BigEndian.PutUint64(dst[8:16], s[1])
+    p8(s)
+    s[0] ^= byteorder.Pad(0)
+}
+
+// decryptShort128a is the inverse of encryptShort128a.
+func (s *state) decryptShort128a(dst, src []byte) {
+    if len(src) < BlockSize128a {
+        s.decryptTail128a(dst, src)
+        return
+    }
+    c0 := binary.BigEndian.Uint64(src[0:8])
+    c1 := binary.BigEndian.Uint64(src[8:16])
+    binary.BigEndian.PutUint64(dst[0:8], s[0]^c0)
+    binary.BigEndian.PutUint64(dst[8:16], s[1]^c1)
+    s[0] = c0
+    s[1] = c1
+    p8(s)
+    s[0] ^= byteorder.Pad(0)
 }
 
 func (s *state) finalize128(k0, k1 uint64) {
-    s.x1 ^= k0
-    s.x2 ^= k1
+    s[1] ^= k0
+    s[2] ^= k1
     p12(s)
-    s.x3 ^= k0
-    s.x4 ^= k1
+    s[3] ^= k0
+    s[4] ^= k1
 }
 
 func (s *state) additionalData128(ad []byte) {
-    if len(ad) > 0 {
-        for len(ad) >= BlockSize128 {
-            s.x0 ^= binary.BigEndian.Uint64(ad[0:8])
-            p6(s)
-            ad = ad[BlockSize128:]
-        }
-        s.x0 ^= be64n(ad)
-        s.x0 ^= pad(len(ad))
+    if len(ad) == 0 {
+        s[4] ^= 1
+        return
+    }
+
+    n := len(ad) &^ (BlockSize128 - 1)
+    if n > 0 {
+        s.additionalDataBlocks128(ad[:n])
+        ad = ad[n:]
+    }
+    s[0] ^= byteorder.BE64n(ad)
+    s[0] ^= byteorder.Pad(len(ad))
+    p6(s)
+
+    s[4] ^= 1
+}
+
+func (s *state) additionalDataBlocks128(ad []byte) {
+    for len(ad) >= BlockSize128 {
+        s[0] ^= be64(ad[0:8])
         p6(s)
+        ad = ad[BlockSize128:]
     }
-    s.x4 ^= 1
 }
 
-func (s *state) encrypt128(dst, src []byte) {
+func (s *state) encryptBlocks128(dst, src []byte) {
     for len(src) >= BlockSize128 {
-        s.x0 ^= binary.BigEndian.Uint64(src[0:8])
-        binary.BigEndian.PutUint64(dst[0:8], s.x0)
+        s[0] ^= be64(src[0:8])
+        putbe64(dst[0:8], s[0])
         p6(s)
         src = src[BlockSize128:]
         dst = dst[BlockSize128:]
     }
+}
 
-    s.x0 ^= be64n(src)
-    put64n(dst, s.x0)
-    s.x0 ^= pad(len(src))
+func (s *state) encrypt128(dst, src []byte) {
+    n := len(src) &^ (BlockSize128 - 1)
+    if n > 0 {
+        s.encryptBlocks128(dst[:n], src[:n])
+        src = src[n:]
+        dst = dst[n:]
+    }
+
+    s[0] ^= byteorder.BE64n(src)
+    byteorder.PutBE64n(dst, s[0])
+    s[0] ^= byteorder.Pad(len(src))
 }
 
-func (s *state) decrypt128(dst, src []byte) {
+func (s *state) decryptBlocks128(dst, src []byte) {
     for len(src) >= BlockSize128 {
-        c := binary.BigEndian.Uint64(src[0:8])
-        binary.BigEndian.PutUint64(dst[0:8], s.x0^c)
-        s.x0 = c
+        c := be64(src[0:8])
+        putbe64(dst[0:8], s[0]^c)
+        s[0] = c
         p6(s)
         src = src[BlockSize128:]
         dst = dst[BlockSize128:]
     }
+}
+
+func (s *state) decrypt128(dst, src []byte) {
+    n := len(src) &^ (BlockSize128 - 1)
+    if n > 0 {
+        s.decryptBlocks128(dst[:n], src[:n])
+        src = src[n:]
+        dst = dst[n:]
+    }
+
+    c := byteorder.BE64n(src)
+    byteorder.PutBE64n(dst, s[0]^c)
+    s[0] = byteorder.Mask(s[0], len(src))
+    s[0] |= c
+    s[0] ^= byteorder.Pad(len(src))
+}
+
+// init80pq initializes the state for ASCON-80pq, where k0 is
+// the top 32 bits of the 160-bit key and k1, k2 are the
+// remaining 128 bits.
+func (s *state) init80pq(iv, k0, k1, k2, n0, n1 uint64) {
+    s[0] = iv | k0
+    s[1] = k1
+    s[2] = k2
+    s[3] = n0
+    s[4] = n1
+    p12(s)
+    s[2] ^= k0
+    s[3] ^= k1
+    s[4] ^= k2
+}
 
-    c := be64n(src)
-    put64n(dst, s.x0^c)
-    s.x0 = mask(s.x0, len(src))
-    s.x0 |= c
-    s.x0 ^= pad(len(src))
+func (s *state) finalize80pq(k0, k1, k2 uint64) {
+    s[1] ^= k0<<32 | k1>>32
+    s[2] ^= k1<<32 | k2>>32
+    s[3] ^= k2 << 32
+    p12(s)
+    s[3] ^= k1
+    s[4] ^= k2
 }
 
 func (s *state) tag(dst []byte) {
-    binary.BigEndian.PutUint64(dst[0:8], s.x3)
-    binary.BigEndian.PutUint64(dst[8:16], s.x4)
+    binary.BigEndian.PutUint64(dst[0:8], s[3])
+    binary.BigEndian.PutUint64(dst[8:16], s[4])
+}
+
+// truncatedTag writes the first len(dst) bytes of the
+// authenticator to dst, directly if it is not truncated.
+func (s *state) truncatedTag(dst []byte) {
+    if len(dst) == TagSize {
+        s.tag(dst)
+        return
+    }
+    var t [TagSize]byte
+    s.tag(t[:])
+    copy(dst, t[:])
+}
+
+// The Ascon-AEAD128 routines below mirror the ASCON-128a ones,
+// but bytes are loaded into the state words in little-endian
+// order as defined by SP 800-232.
+
+func (s *state) additionalDataAEAD128(ad []byte) {
+    if len(ad) > 0 {
+        n := len(ad) &^ (BlockSize128a - 1)
+        if n > 0 {
+            s.additionalDataBlocksAEAD128(ad[:n])
+            ad = ad[n:]
+        }
+        if len(ad) >= 8 {
+            s[0] ^= binary.LittleEndian.Uint64(ad[0:8])
+            s[1] ^= byteorder.LE64n(ad[8:])
+            s[1] ^= byteorder.PadLE(len(ad) - 8)
+        } else {
+            s[0] ^= byteorder.LE64n(ad)
+            s[0] ^= byteorder.PadLE(len(ad))
+        }
+        p8(s)
+    }
+
+    s[4] ^= 1 << 63
+}
+
+func (s *state) additionalDataBlocksAEAD128(ad []byte) {
+    for len(ad) >= BlockSize128a {
+        s[0] ^= le64(ad[0:8])
+        s[1] ^= le64(ad[8:16])
+        p8(s)
+        ad = ad[BlockSize128a:]
+    }
+}
+
+func (s *state) encryptBlocksAEAD128(dst, src []byte) {
+    for len(src) >= BlockSize128a {
+        s[0] ^= le64(src[0:8])
+        s[1] ^= le64(src[8:16])
+        putle64(dst[0:8], s[0])
+        putle64(dst[8:16], s[1])
+        p8(s)
+        src = src[BlockSize128a:]
+        dst = dst[BlockSize128a:]
+    }
+}
+
+func (s *state) encryptAEAD128(dst, src []byte) {
+    n := len(src) &^ (BlockSize128a - 1)
+    if n > 0 {
+        s.encryptBlocksAEAD128(dst[:n], src[:n])
+        src = src[n:]
+        dst = dst[n:]
+    }
+
+    if len(src) >= 8 {
+        s[0] ^= binary.LittleEndian.Uint64(src[0:8])
+        s[1] ^= byteorder.LE64n(src[8:])
+        s[1] ^= byteorder.PadLE(len(src) - 8)
+        binary.LittleEndian.PutUint64(dst[0:8], s[0])
+        byteorder.PutLE64n(dst[8:], s[1])
+    } else {
+        s[0] ^= byteorder.LE64n(src)
+        byteorder.PutLE64n(dst, s[0])
+        s[0] ^= byteorder.PadLE(len(src))
+    }
+}
+
+func (s *state) decryptBlocksAEAD128(dst, src []byte) {
+    for len(src) >= BlockSize128a {
+        c0 := le64(src[0:8])
+        c1 := le64(src[8:16])
+        putle64(dst[0:8], s[0]^c0)
+        putle64(dst[8:16], s[1]^c1)
+        s[0] = c0
+        s[1] = c1
+        p8(s)
+        src = src[BlockSize128a:]
+        dst = dst[BlockSize128a:]
+    }
+}
+
+func (s *state) decryptAEAD128(dst, src []byte) {
+    n := len(src) &^ (BlockSize128a - 1)
+    if n > 0 {
+        s.decryptBlocksAEAD128(dst[:n], src[:n])
+        src = src[n:]
+        dst = dst[n:]
+    }
+
+    if len(src) >= 8 {
+        c0 := binary.LittleEndian.Uint64(src[0:8])
+        c1 := byteorder.LE64n(src[8:])
+        binary.LittleEndian.PutUint64(dst[0:8], s[0]^c0)
+        byteorder.PutLE64n(dst[8:], s[1]^c1)
+        s[0] = c0
+        s[1] = byteorder.MaskLE(s[1], len(src)-8)
+        s[1] |= c1
+        s[1] ^= byteorder.PadLE(len(src) - 8)
+    } else {
+        c0 := byteorder.LE64n(src)
+        byteorder.PutLE64n(dst, s[0]^c0)
+        s[0] = byteorder.MaskLE(s[0], len(src))
+        s[0] |= c0
+        s[0] ^= byteorder.PadLE(len(src))
+    }
+}
+
+func (s *state) tagAEAD128(dst []byte) {
+    binary.LittleEndian.PutUint64(dst[0:8], s[3])
+    binary.LittleEndian.PutUint64(dst[8:16], s[4])
 }
@@ -0,0 +1,86 @@
+package stream
+
+import (
+    "io"
+)
+
+// ReadFrom encrypts everything read from r until io.EOF, as if
+// it were passed to Write. It reads directly into the chunk
+// buffer, so io.Copy to a Writer does not copy the data through
+// a buffer of its own. Like Write, it does not write the final
+// chunk: Close must still be called.
+func (sw *Writer) ReadFrom(r io.Reader) (int64, error) {
+    if sw.err != nil {
+        return 0, sw.err
+    }
+
+    var total int64
+    for {
+        if len(sw.buf) == cap(sw.buf) {
+            // The buffered chunk is not the final one, since more
+            // data may follow, so it is sealed only once the
+            // reader still has something.
+            var b [1]byte
+            n, err := r.Read(b[:])
+            if n == 0 {
+                if err == io.EOF {
+                    return total, nil
+                }
+                if err != nil {
+                    return total, err
+                }
+                continue
+            }
+            if err := sw.seal(false); err != nil {
+                return total, err
+            }
+            sw.buf = append(sw.buf, b[0])
+            total++
+            if err == io.EOF {
+                return total, nil
+            }
+            if err != nil {
+                return total, err
+            }
+        }
+
+        n, err := r.Read(sw.buf[len(sw.buf):cap(sw.buf)])
+        sw.buf = sw.buf[:len(sw.buf)+n]
+        total += int64(n)
+        if err == io.EOF {
+            return total, nil
+        }
+        if err != nil {
+            return total, err
+        }
+    }
+}
+
+// WriteTo writes the rest of the decrypted stream to w, chunk
+// by chunk, without copying it through the buffer of Read. It
+// returns nil after the final chunk, and otherwise the error of
+// w or of the stream, once every chunk before a corrupted one
+// has been written.
+func (sr *Reader) WriteTo(w io.Writer) (int64, error) {
+    var total int64
+    for {
+        if len(sr.out) > 0 {
+            n, err := w.Write(sr.out)
+            sr.out = sr.out[n:]
+            total += int64(n)
+            if err != nil {
+                return total, err
+            }
+            if len(sr.out) > 0 {
+                return total, io.ErrShortWrite
+            }
+        }
+        if sr.err != nil {
+            if sr.err == io.EOF {
+                return total, nil
+            }
+            return total, sr.err
+        }
+        sr.next()
+    }
+}
@@ -0,0 +1,204 @@
+package stream
+
+import (
+    "bytes"
+    "io"
+    "math/rand"
+    "testing"
+    "testing/iotest"
+)
+
+func TestCopy(t *testing.T) {
+    aead := newAEAD(t)
+    prefix := make([]byte, PrefixSize)
+    rng := rand.New(rand.NewSource(2))
+
+    const chunkSize = 100
+    for _, size := range []int{0, 1, 99, 100, 101, 200, 1234} {
+        pt := make([]byte, size)
+        rng.Read(pt)
+        want := seal(t, aead, prefix, chunkSize, pt)
+
+        for _, workers := range []int{0, 3} {
+            // io.Copy uses ReadFrom, here with short reads.
+            var buf bytes.Buffer
+            w, err := NewWriter(aead, prefix, chunkSize, &buf, WithWorkers(workers))
+            if err != nil {
+                t.Fatal(err)
+            }
+            n, err := io.Copy(w, iotest.HalfReader(bytes.NewReader(pt)))
+            if err != nil || n != int64(size) {
+                t.Fatalf("%d/%d: ReadFrom: %d, %v", size, workers, n, err)
+            }
+            if err := w.Close(); err != nil {
+                t.Fatal(err)
+            }
+            if !bytes.Equal(buf.Bytes(), want) {
+                t.Fatalf("%d/%d: ReadFrom output differs from Write", size, workers)
+            }
+
+            // io.Copy uses WriteTo, after a partial Read.
+            r, err := NewReader(aead, bytes.NewReader(want), WithWorkers(workers))
+            if err != nil {
+                t.Fatal(err)
+            }
+            got := make([]byte, 7)
+            m, _ := io.ReadFull(r, got)
+            got = got[:m]
+            var out bytes.Buffer
+            n, err = io.Copy(&out, r)
+            if err != nil || n != int64(size-m) {
+                t.Fatalf("%d/%d: WriteTo: %d, %v", size, workers, n, err)
+            }
+            if got = append(got, out.Bytes()...); !bytes.Equal(got, pt) {
+                t.Fatalf("%d/%d: plaintext mismatch", size, workers)
+            }
+            if n, err := r.WriteTo(&out); n != 0 || err != nil {
+                t.Fatalf("%d/%d: WriteTo after the end: %d, %v", size, workers, n, err)
+            }
+        }
+    }
+}
+
+func TestCopyInterleaved(t *testing.T) {
+    aead := newAEAD(t)
+    prefix := make([]byte, PrefixSize)
+    rng := rand.New(rand.NewSource(3))
+
+    const chunkSize = 16
+    pt := make([]byte, 50*chunkSize)
+    rng.Read(pt)
+    want := seal(t, aead, prefix, chunkSize, pt)
+
+    for _, workers := range []int{0, 4} {
+        for i := 0; i < 20; i++ {
+            var buf bytes.Buffer
+            w, err := NewWriter(aead, prefix, chunkSize, &buf, WithWorkers(workers))
+            if err != nil {
+                t.Fatal(err)
+            }
+            for p := pt; len(p) > 0; {
+                n := rng.Intn(3*chunkSize) + 1
+                if n > len(p) {
+                    n = len(p)
+                }
+                if rng.Intn(2) == 0 {
+                    _, err = w.Write(p[:n])
+                } else {
+                    _, err = w.ReadFrom(bytes.NewReader(p[:n]))
+                }
+                if err != nil {
+                    t.Fatal(err)
+                }
+                p = p[n:]
+            }
+            if err := w.Close(); err != nil {
+                t.Fatal(err)
+            }
+            if !bytes.Equal(buf.Bytes(), want) {
+                t.Fatalf("%d: output differs from the sequential Writer", workers)
+            }
+        }
+    }
+}
+
+func TestCopyErrors(t *testing.T) {
+    aead := newAEAD(t)
+    const chunkSize = 16
+    record := chunkSize + aead.Overhead()
+    pt := bytes.Repeat([]byte{'x'}, 10*chunkSize+3)
+    ct := seal(t, aead, make([]byte, PrefixSize), chunkSize, pt)
+
+    // Every chunk before a corrupted one is written.
+    bad := append([]byte(nil), ct...)
+    bad[HeaderSize+5*record+1] ^= 1
+    for _, workers := range []int{0, 4} {
+        r, err := NewReader(aead, bytes.NewReader(bad), WithWorkers(workers))
+        if err != nil {
+            t.Fatal(err)
+        }
+        var out bytes.Buffer
+        n, err := r.WriteTo(&out)
+        if err != ErrInvalidChunk {
+            t.Fatalf("%d: expected %v, got %v", workers, ErrInvalidChunk, err)
+        }
+        if n != 5*chunkSize || !bytes.Equal(out.Bytes(), pt[:5*chunkSize]) {
+            t.Fatalf("%d: expected %d bytes, got %d", workers, 5*chunkSize, n)
+        }
+    }
+
+    // The errors of the source are returned, and the stream can
+    // go on.
+    var buf bytes.Buffer
+    w, err := NewWriter(aead, make([]byte, PrefixSize), chunkSize, &buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    n, err := w.ReadFrom(iotest.TimeoutReader(bytes.NewReader(pt)))
+    if err != iotest.ErrTimeout || n != chunkSize {
+        t.Fatalf("expected %d, %v, got %d, %v", chunkSize, iotest.ErrTimeout, n, err)
+    }
+    if _, err := w.Write(pt[n:]); err != nil {
+        t.Fatal(err)
+    }
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(buf.Bytes(), ct) {
+        t.Fatal("output differs from Write")
+    }
+}
+
+// pipeSize is the size of the stream of BenchmarkPipe.
+const pipeSize = 1 << 30
+
+// BenchmarkPipe encrypts a stream into an io.Pipe and decrypts it
+// on the other end with io.Copy. The buffered sub-benchmark hides
+// ReadFrom and WriteTo, so that io.Copy goes through its own
+// buffer instead.
+func BenchmarkPipe(b *testing.B) {
+    for _, direct := range []bool{true, false} {
+        name := "direct"
+        if !direct {
+            name = "buffered"
+        }
+        b.Run(name, func(b *testing.B) {
+            aead := newAEAD(b)
+            b.SetBytes(pipeSize)
+            for i := 0; i < b.N; i++ {
+                pr, pw := io.Pipe()
+                go func() {
+                    w, _ := NewWriter(aead, make([]byte, PrefixSize), DefaultChunkSize, pw)
+                    var dst io.Writer = w
+                    if !direct {
+                        dst = struct{ io.Writer }{w}
+                    }
+                    io.Copy(dst, io.LimitReader(zeros{}, pipeSize))
+                    pw.CloseWithError(w.Close())
+                }()
+
+                r, err := NewReader(aead, pr)
+                if err != nil {
+                    b.Fatal(err)
+                }
+                var src io.Reader = r
+                if !direct {
+                    src = struct{ io.Reader }{r}
+                }
+                if n, err := io.Copy(struct{ io.Writer }{io.Discard}, src); err != nil || n != pipeSize {
+                    b.Fatal(n, err)
+                }
+            }
+        })
+    }
+}
+
+// zeros is an endless stream of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+    for i := range p {
+        p[i] = 0
+    }
+    return len(p), nil
+}
@@ -0,0 +1,163 @@
+package stream
+
+import (
+    "io"
+)
+
+// An Option configures NewWriter and NewReader.
+type Option func(*options)
+
+type options struct {
+    workers int
+    ad      []byte
+}
+
+// WithWorkers seals or opens up to n chunks concurrently. The
+// stream format does not depend on n: a stream written with
+// any number of workers is byte for byte the same, and can be
+// read with any number of workers. At most n chunks are in
+// flight at once, so a Writer or Reader holds about n+1 chunks
+// of memory.
+//
+// The AEAD must be safe for concurrent use, as those of
+// package ascon are. n of 1 or less processes chunks
+// sequentially, which is the default.
+//
+// The errors of later chunks may only be reported once the
+// earlier ones are written or read: a failed write to the
+// underlying io.Writer is returned by a later Write or by
+// Close, and a Reader returns every chunk before a corrupted
+// one before failing.
+func WithWorkers(n int) Option {
+    return func(o *options) {
+        o.workers = n
+    }
+}
+
+// WithAdditionalData authenticates ad, followed by the header,
+// as the additional data of every chunk, instead of the header
+// alone. ad is not written to the stream, so the Reader or
+// SeekReader must be given the same ad to open it. It lets a
+// format that wraps the stream, such as package container,
+// authenticate its own header.
+func WithAdditionalData(ad []byte) Option {
+    return func(o *options) {
+        o.ad = ad
+    }
+}
+
+// additionalData returns the additional data of the chunks of
+// a stream with header.
+func (o *options) additionalData(header []byte) []byte {
+    ad := make([]byte, 0, len(o.ad)+len(header))
+    return append(append(ad, o.ad...), header...)
+}
+
+// chunkJob is a chunk being sealed or opened by a worker.
+type chunkJob struct {
+    nonce [NonceSize]byte
+    buf   []byte
+    out   []byte
+    last  bool
+    err   error
+    done  chan struct{}
+}
+
+func newChunkJob(size int) *chunkJob {
+    return &chunkJob{
+        buf:  make([]byte, 0, size),
+        done: make(chan struct{}, 1),
+    }
+}
+
+// job returns a recycled chunkJob, or a new one.
+func job(free *[]*chunkJob, size int) *chunkJob {
+    if n := len(*free); n > 0 {
+        j := (*free)[n-1]
+        *free = (*free)[:n-1]
+        return j
+    }
+    return newChunkJob(size)
+}
+
+// sealAsync seals the buffered chunk on a new goroutine and
+// writes out the oldest chunks to keep at most workers of them
+// in flight, or all of them if last is set.
+func (sw *Writer) sealAsync(last bool) error {
+    if sw.counter >= maxChunks {
+        sw.err = errTooManyChunks
+        return sw.err
+    }
+    j := job(&sw.free, cap(sw.buf))
+    j.nonce = sw.nonce
+    setCounter(&j.nonce, sw.counter, last)
+    sw.counter++
+
+    // The job takes the buffered plaintext, and gives its empty
+    // buffer back for the next chunk.
+    j.buf, sw.buf = sw.buf, j.buf[:0]
+    go func() {
+        j.out = sw.aead.Seal(j.out[:0], j.nonce[:], j.buf, sw.ad)
+        j.done <- struct{}{}
+    }()
+    sw.pending = append(sw.pending, j)
+
+    for len(sw.pending) >= sw.workers || last && len(sw.pending) > 0 {
+        j := sw.pending[0]
+        <-j.done
+        sw.pending = sw.pending[1:]
+        if _, err := sw.w.Write(j.out); err != nil {
+            sw.err = err
+            return err
+        }
+        sw.free = append(sw.free, j)
+    }
+    return nil
+}
+
+// nextAsync reads ahead and opens up to workers chunks, and
+// returns the oldest one.
+func (sr *Reader) nextAsync() {
+    if sr.cur != nil {
+        sr.free = append(sr.free, sr.cur)
+        sr.cur = nil
+    }
+
+    for len(sr.pending) < sr.workers && sr.readErr == nil {
+        j := job(&sr.free, sr.chunk+sr.aead.Overhead())
+        n, last, err := sr.readChunk(j.buf[:cap(j.buf)])
+        if err != nil {
+            sr.readErr = err
+            sr.free = append(sr.free, j)
+            break
+        }
+        j.nonce = sr.nonce
+        j.last = last
+        go func() {
+            j.out, j.err = sr.aead.Open(j.buf[:0], j.nonce[:], j.buf[:n], sr.ad)
+            j.done <- struct{}{}
+        }()
+        sr.pending = append(sr.pending, j)
+        if last {
+            // Nothing may follow the final chunk.
+            sr.readErr = io.EOF
+        }
+    }
+
+    if len(sr.pending) == 0 {
+        sr.err = sr.readErr
+        return
+    }
+    j := sr.pending[0]
+    <-j.done
+    sr.pending = sr.pending[1:]
+    if j.err != nil {
+        sr.err = ErrInvalidChunk
+        return
+    }
+    sr.cur = j
+    sr.out = j.out
+    if j.last {
+        sr.err = io.EOF
+    }
+}
@@ -0,0 +1,214 @@
+package stream
+
+import (
+    "bytes"
+    "crypto/cipher"
+    "errors"
+    "io"
+    "math/rand"
+    "strconv"
+    "sync"
+    "testing"
+    "testing/iotest"
+    "time"
+)
+
+func TestWorkers(t *testing.T) {
+    aead := newAEAD(t)
+    prefix := make([]byte, PrefixSize)
+    rng := rand.New(rand.NewSource(1))
+
+    const chunkSize = 100
+    for _, size := range []int{0, 1, 99, 100, 101, 1000, 1234} {
+        pt := make([]byte, size)
+        rng.Read(pt)
+        want := seal(t, aead, prefix, chunkSize, pt)
+
+        for _, workers := range []int{0, 1, 2, 3, 8} {
+            var buf bytes.Buffer
+            w, err := NewWriter(aead, prefix, chunkSize, &buf, WithWorkers(workers))
+            if err != nil {
+                t.Fatal(err)
+            }
+            for p := pt; len(p) > 0; {
+                n := rng.Intn(3*chunkSize) + 1
+                if n > len(p) {
+                    n = len(p)
+                }
+                if _, err := w.Write(p[:n]); err != nil {
+                    t.Fatal(err)
+                }
+                p = p[n:]
+            }
+            if err := w.Close(); err != nil {
+                t.Fatal(err)
+            }
+            if !bytes.Equal(buf.Bytes(), want) {
+                t.Fatalf("%d/%d: output differs from the sequential Writer", size, workers)
+            }
+
+            r, err := NewReader(aead, iotest.HalfReader(bytes.NewReader(want)), WithWorkers(workers))
+            if err != nil {
+                t.Fatal(err)
+            }
+            got, err := io.ReadAll(r)
+            if err != nil {
+                t.Fatalf("%d/%d: %v", size, workers, err)
+            }
+            if !bytes.Equal(got, pt) {
+                t.Fatalf("%d/%d: plaintext mismatch", size, workers)
+            }
+        }
+    }
+}
+
+func TestWorkersErrors(t *testing.T) {
+    aead := newAEAD(t)
+    const chunkSize = 16
+    record := chunkSize + aead.Overhead()
+    pt := bytes.Repeat([]byte{'x'}, 10*chunkSize+3)
+    ct := seal(t, aead, make([]byte, PrefixSize), chunkSize, pt)
+
+    openWorkers := func(ct []byte) ([]byte, error) {
+        r, err := NewReader(aead, bytes.NewReader(ct), WithWorkers(4))
+        if err != nil {
+            return nil, err
+        }
+        return io.ReadAll(r)
+    }
+
+    // Every chunk before a corrupted one is returned.
+    bad := append([]byte(nil), ct...)
+    bad[HeaderSize+5*record+1] ^= 1
+    got, err := openWorkers(bad)
+    if err != ErrInvalidChunk {
+        t.Fatalf("expected %v, got %v", ErrInvalidChunk, err)
+    }
+    if !bytes.Equal(got, pt[:5*chunkSize]) {
+        t.Fatalf("expected %d bytes, got %d", 5*chunkSize, len(got))
+    }
+
+    got, err = openWorkers(ct[:HeaderSize+7*record])
+    if err != ErrInvalidChunk {
+        t.Fatalf("truncated: expected %v, got %v", ErrInvalidChunk, err)
+    }
+    if !bytes.Equal(got, pt[:6*chunkSize]) {
+        t.Fatalf("truncated: expected %d bytes, got %d", 6*chunkSize, len(got))
+    }
+
+    // A failing io.Writer is reported by Write or Close.
+    errWrite := errors.New("write failed")
+    w, err := NewWriter(aead, make([]byte, PrefixSize), chunkSize,
+        &failingWriter{n: HeaderSize + 3*record, err: errWrite}, WithWorkers(4))
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, err = w.Write(pt)
+    if err == nil {
+        err = w.Close()
+    }
+    if err != errWrite {
+        t.Fatalf("expected %v, got %v", errWrite, err)
+    }
+    if err := w.Close(); err != errWrite {
+        t.Fatalf("Close: expected %v, got %v", errWrite, err)
+    }
+}
+
+// failingWriter fails once more than n bytes have been written
+// to it.
+type failingWriter struct {
+    n   int
+    err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+    if len(p) > w.n {
+        return 0, w.err
+    }
+    w.n -= len(p)
+    return len(p), nil
+}
+
+// countingAEAD records the largest number of concurrent Seal
+// and Open calls.
+type countingAEAD struct {
+    cipher.AEAD
+    mu       sync.Mutex
+    cur, max int
+}
+
+func (c *countingAEAD) enter() {
+    c.mu.Lock()
+    c.cur++
+    if c.cur > c.max {
+        c.max = c.cur
+    }
+    c.mu.Unlock()
+    // Give the other workers time to start.
+    time.Sleep(time.Millisecond)
+}
+
+func (c *countingAEAD) leave() {
+    c.mu.Lock()
+    c.cur--
+    c.mu.Unlock()
+}
+
+func (c *countingAEAD) Seal(dst, nonce, plaintext, ad []byte) []byte {
+    c.enter()
+    defer c.leave()
+    return c.AEAD.Seal(dst, nonce, plaintext, ad)
+}
+
+func (c *countingAEAD) Open(dst, nonce, ciphertext, ad []byte) ([]byte, error) {
+    c.enter()
+    defer c.leave()
+    return c.AEAD.Open(dst, nonce, ciphertext, ad)
+}
+
+func TestWorkersInFlight(t *testing.T) {
+    const workers = 3
+    aead := &countingAEAD{AEAD: newAEAD(t)}
+    pt := make([]byte, 50*16)
+
+    var buf bytes.Buffer
+    w, err := NewWriter(aead, make([]byte, PrefixSize), 16, &buf, WithWorkers(workers))
+    if err != nil {
+        t.Fatal(err)
+    }
+    w.Write(pt)
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if aead.max > workers {
+        t.Fatalf("Writer: %d chunks in flight, expected at most %d", aead.max, workers)
+    }
+
+    aead.max = 0
+    r, err := NewReader(aead, &buf, WithWorkers(workers))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := io.ReadAll(r); err != nil {
+        t.Fatal(err)
+    }
+    if aead.max > workers {
+        t.Fatalf("Reader: %d chunks in flight, expected at most %d", aead.max, workers)
+    }
+}
+
+func BenchmarkWriter(b *testing.B) {
+    for _, workers := range []int{1, 4} {
+        b.Run(strconv.Itoa(workers), func(b *testing.B) {
+            aead := newAEAD(b)
+            pt := make([]byte, 16*DefaultChunkSize)
+            b.SetBytes(int64(len(pt)))
+            for i := 0; i < b.N; i++ {
+                w, _ := NewWriter(aead, make([]byte, PrefixSize), DefaultChunkSize, io.Discard, WithWorkers(workers))
+                w.Write(pt)
+                w.Close()
+            }
+        })
+    }
+}
@@ -0,0 +1,195 @@
+package stream
+
+import (
+    "errors"
+    "io"
+    "crypto/cipher"
+    "encoding/binary"
+)
+
+// SeekReader decrypts a stream written by Writer with random
+// access.
+//
+// Read and Seek decrypt and verify the chunk enclosing the
+// current position and serve reads from it until the position
+// leaves the chunk. ReadAt does not use that state and is safe
+// for concurrent use; Read and Seek are not. Several
+// SeekReaders may share the same underlying io.ReaderAt.
+type SeekReader struct {
+    aead   cipher.AEAD
+    r      io.ReaderAt
+    header [HeaderSize]byte
+    ad     []byte
+    chunk  int
+    chunks int64
+    size   int64
+
+    off int64
+    idx int64
+    buf []byte
+    pt  []byte
+}
+
+// NewSeekReader returns a SeekReader that decrypts the stream of
+// size bytes in r with aead.
+//
+// NewSeekReader reads and verifies the final chunk, so the
+// plaintext size reported by Size is authenticated and
+// truncation at a chunk boundary is detected up front. Of the
+// options, only WithAdditionalData applies.
+func NewSeekReader(aead cipher.AEAD, r io.ReaderAt, size int64, opts ...Option) (*SeekReader, error) {
+    if aead.NonceSize() != NonceSize {
+        return nil, errors.New("stream: unsupported nonce size")
+    }
+
+    var o options
+    for _, fn := range opts {
+        fn(&o)
+    }
+
+    sr := &SeekReader{aead: aead, r: r, idx: -1}
+    if size < HeaderSize {
+        return nil, io.ErrUnexpectedEOF
+    }
+    if n, err := r.ReadAt(sr.header[:], 0); n < HeaderSize {
+        if err == nil || err == io.EOF {
+            err = io.ErrUnexpectedEOF
+        }
+        return nil, err
+    }
+    if sr.header[0] != Version1 {
+        return nil, ErrUnsupportedVersion
+    }
+    chunkSize := binary.BigEndian.Uint32(sr.header[1:])
+    if chunkSize == 0 || chunkSize > MaxChunkSize {
+        return nil, errors.New("stream: bad chunk size")
+    }
+    sr.chunk = int(chunkSize)
+    sr.ad = o.additionalData(sr.header[:])
+
+    overhead := int64(aead.Overhead())
+    record := int64(sr.chunk) + overhead
+    n := size - HeaderSize
+    sr.chunks = (n + record - 1) / record
+    if sr.chunks == 0 || n-(sr.chunks-1)*record < overhead {
+        return nil, ErrInvalidChunk
+    }
+    if sr.chunks > maxChunks {
+        return nil, errTooManyChunks
+    }
+    sr.size = n - sr.chunks*overhead
+
+    sr.buf = make([]byte, record)
+    pt, err := sr.open(sr.buf, sr.chunks-1)
+    if err != nil {
+        return nil, err
+    }
+    sr.idx = sr.chunks - 1
+    sr.pt = pt
+    return sr, nil
+}
+
+// Size returns the size in bytes of the plaintext.
+func (sr *SeekReader) Size() int64 {
+    return sr.size
+}
+
+// Read decrypts plaintext at the current position into p. Only
+// authenticated plaintext is returned.
+func (sr *SeekReader) Read(p []byte) (int, error) {
+    if sr.off >= sr.size {
+        return 0, io.EOF
+    }
+
+    idx := sr.off / int64(sr.chunk)
+    if idx != sr.idx {
+        pt, err := sr.open(sr.buf, idx)
+        if err != nil {
+            sr.idx = -1
+            return 0, err
+        }
+        sr.idx = idx
+        sr.pt = pt
+    }
+    n := copy(p, sr.pt[sr.off-idx*int64(sr.chunk):])
+    sr.off += int64(n)
+    return n, nil
+}
+
+// Seek sets the position for the next Read, as described by
+// io.Seeker. Seeking past the end is allowed, and the next
+// Read then returns io.EOF.
+func (sr *SeekReader) Seek(offset int64, whence int) (int64, error) {
+    switch whence {
+    case io.SeekStart:
+    case io.SeekCurrent:
+        offset += sr.off
+    case io.SeekEnd:
+        offset += sr.size
+    default:
+        return 0, errors.New("stream: invalid whence")
+    }
+    if offset < 0 {
+        return 0, errors.New("stream: negative position")
+    }
+    sr.off = offset
+    return offset, nil
+}
+
+// ReadAt decrypts len(p) bytes of plaintext starting at off, as
+// described by io.ReaderAt.
+func (sr *SeekReader) ReadAt(p []byte, off int64) (int, error) {
+    if off < 0 {
+        return 0, errors.New("stream: negative offset")
+    }
+
+    var buf []byte
+    total := 0
+    for len(p) > 0 {
+        if off >= sr.size {
+            return total, io.EOF
+        }
+        if buf == nil {
+            buf = make([]byte, sr.chunk+sr.aead.Overhead())
+        }
+
+        idx := off / int64(sr.chunk)
+        pt, err := sr.open(buf, idx)
+        if err != nil {
+            return total, err
+        }
+        n := copy(p, pt[off-idx*int64(sr.chunk):])
+        p = p[n:]
+        off += int64(n)
+        total += n
+    }
+    return total, nil
+}
+
+// open reads chunk idx into buf and decrypts it in place.
+func (sr *SeekReader) open(buf []byte, idx int64) ([]byte, error) {
+    record := int64(len(buf))
+    pos := HeaderSize + idx*record
+    n := record
+    last := idx == sr.chunks-1
+    if last {
+        n = sr.size - idx*int64(sr.chunk) + int64(sr.aead.Overhead())
+    }
+
+    m, err := sr.r.ReadAt(buf[:n], pos)
+    if int64(m) < n {
+        if err == nil || err == io.EOF {
+            err = io.ErrUnexpectedEOF
+        }
+        return nil, err
+    }
+
+    var nonce [NonceSize]byte
+    copy(nonce[:], sr.header[5:])
+    setCounter(&nonce, uint64(idx), last)
+    pt, err := sr.aead.Open(buf[:0], nonce[:], buf[:n], sr.ad)
+    if err != nil {
+        return nil, ErrInvalidChunk
+    }
+    return pt, nil
+}
@@ -0,0 +1,189 @@
+package stream
+
+import (
+    "bytes"
+    "io"
+    "math/rand"
+    "sync"
+    "testing"
+)
+
+func newSeekReader(t *testing.T, ct []byte) *SeekReader {
+    sr, err := NewSeekReader(newAEAD(t), bytes.NewReader(ct), int64(len(ct)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return sr
+}
+
+func TestSeekReader(t *testing.T) {
+    aead := newAEAD(t)
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    for _, chunkSize := range []int{1, 7, 16, 100} {
+        for _, size := range []int{0, 1, 7, 8, 100, 101, 1000} {
+            pt := make([]byte, size)
+            rng.Read(pt)
+            sr := newSeekReader(t, seal(t, aead, make([]byte, PrefixSize), chunkSize, pt))
+            if sr.Size() != int64(size) {
+                t.Fatalf("%d/%d: expected size %d, got %d", chunkSize, size, size, sr.Size())
+            }
+
+            got, err := io.ReadAll(sr)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !bytes.Equal(got, pt) {
+                t.Fatalf("%d/%d: plaintext mismatch", chunkSize, size)
+            }
+
+            for i := 0; i < 20 && size > 0; i++ {
+                off := rng.Intn(size)
+                n := rng.Intn(3 * chunkSize)
+                if _, err := sr.Seek(int64(off), io.SeekStart); err != nil {
+                    t.Fatal(err)
+                }
+                got := make([]byte, n)
+                m, err := io.ReadFull(sr, got)
+                want := pt[off:]
+                if len(want) > n {
+                    want = want[:n]
+                }
+                if !bytes.Equal(got[:m], want) {
+                    t.Fatalf("%d/%d: Read at %d mismatch", chunkSize, size, off)
+                }
+                if m < n && err != io.ErrUnexpectedEOF {
+                    t.Fatalf("%d/%d: expected %v, got %v", chunkSize, size, io.ErrUnexpectedEOF, err)
+                }
+
+                m, err = sr.ReadAt(got, int64(off))
+                if !bytes.Equal(got[:m], want) {
+                    t.Fatalf("%d/%d: ReadAt %d mismatch", chunkSize, size, off)
+                }
+                if m < n && err != io.EOF {
+                    t.Fatalf("%d/%d: expected %v, got %v", chunkSize, size, io.EOF, err)
+                }
+            }
+        }
+    }
+}
+
+func TestSeek(t *testing.T) {
+    pt := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+    sr := newSeekReader(t, seal(t, newAEAD(t), make([]byte, PrefixSize), 8, pt))
+
+    read := func(n int) string {
+        b := make([]byte, n)
+        m, _ := io.ReadFull(sr, b)
+        return string(b[:m])
+    }
+
+    for _, tc := range []struct {
+        offset int64
+        whence int
+        pos    int64
+        want   string
+    }{
+        {10, io.SeekStart, 10, "abcd"},
+        {-12, io.SeekCurrent, 2, "23456789ab"},
+        {-3, io.SeekEnd, 33, "xyz"},
+        {-6, io.SeekEnd, 30, "uvwx"},
+        {0, io.SeekStart, 0, "01"},
+        {100, io.SeekStart, 100, ""},
+        {1, io.SeekEnd, 37, ""},
+        {0, io.SeekEnd, 36, ""},
+    } {
+        pos, err := sr.Seek(tc.offset, tc.whence)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if pos != tc.pos {
+            t.Fatalf("Seek(%d, %d): expected %d, got %d", tc.offset, tc.whence, tc.pos, pos)
+        }
+        n := len(tc.want)
+        if n == 0 {
+            n = 1
+        }
+        if got := read(n); got != tc.want {
+            t.Fatalf("Seek(%d, %d): expected %q, got %q", tc.offset, tc.whence, tc.want, got)
+        }
+    }
+
+    if _, err := sr.Seek(-1, io.SeekStart); err == nil {
+        t.Fatal("expected an error")
+    }
+    if _, err := sr.ReadAt(make([]byte, 1), -1); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+func TestSeekReaderConcurrent(t *testing.T) {
+    pt := make([]byte, 10000)
+    rand.New(rand.NewSource(0xDEADBEEF)).Read(pt)
+    ct := seal(t, newAEAD(t), make([]byte, PrefixSize), 64, pt)
+    sr := newSeekReader(t, ct)
+
+    var wg sync.WaitGroup
+    errs := make(chan error, 8)
+    for g := 0; g < 8; g++ {
+        wg.Add(1)
+        go func(seed int64) {
+            defer wg.Done()
+            rng := rand.New(rand.NewSource(seed))
+            for i := 0; i < 100; i++ {
+                off := rng.Intn(len(pt))
+                b := make([]byte, rng.Intn(200)+1)
+                n, err := sr.ReadAt(b, int64(off))
+                if err != nil && err != io.EOF {
+                    errs <- err
+                    return
+                }
+                if !bytes.Equal(b[:n], pt[off:off+n]) {
+                    errs <- io.ErrShortBuffer
+                    return
+                }
+            }
+        }(int64(g))
+    }
+    wg.Wait()
+    close(errs)
+    for err := range errs {
+        t.Fatal(err)
+    }
+}
+
+func TestSeekReaderTampering(t *testing.T) {
+    aead := newAEAD(t)
+    const chunkSize = 8
+    record := chunkSize + aead.Overhead()
+    pt := bytes.Repeat([]byte("abcdefgh"), 4)
+    pt = append(pt, "tail"...)
+    ct := seal(t, aead, make([]byte, PrefixSize), chunkSize, pt)
+
+    for _, tc := range [][]byte{
+        ct[:HeaderSize+4*record],
+        ct[:HeaderSize+2*record+3],
+        ct[:HeaderSize],
+        append(append([]byte(nil), ct...), ct[HeaderSize:HeaderSize+record]...),
+    } {
+        if _, err := NewSeekReader(aead, bytes.NewReader(tc), int64(len(tc))); err == nil {
+            t.Fatalf("%d bytes: expected an error", len(tc))
+        }
+    }
+
+    bad := append([]byte(nil), ct...)
+    bad[HeaderSize+record+1] ^= 1
+    sr := newSeekReader(t, bad)
+    b := make([]byte, chunkSize)
+    if _, err := sr.ReadAt(b, 0); err != nil {
+        t.Fatal(err)
+    }
+    if n, err := sr.ReadAt(b, chunkSize+2); err != ErrInvalidChunk || n != 0 {
+        t.Fatalf("expected %v, got %d, %v", ErrInvalidChunk, n, err)
+    }
+    if _, err := sr.Seek(chunkSize, io.SeekStart); err != nil {
+        t.Fatal(err)
+    }
+    if n, err := sr.Read(b); err != ErrInvalidChunk || n != 0 {
+        t.Fatalf("expected %v, got %d, %v", ErrInvalidChunk, n, err)
+    }
+}
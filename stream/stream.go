@@ -0,0 +1,324 @@
+// Package stream implements a chunked STREAM construction for
+// encrypting large messages with an ASCON AEAD.
+//
+// Unlike ascon.EncryptWriter, which authenticates the message
+// only once at the end, every chunk is sealed separately, so
+// Reader never returns unauthenticated plaintext. SeekReader
+// decrypts individual chunks for random access.
+//
+// Format
+//
+// A stream is a header followed by one or more chunks:
+//
+//    header = version || chunkSize || prefix
+//    chunk  = Seal(nonce_i, plaintext_i, ad=header)
+//    nonce_i = prefix || uint32(i) || last
+//
+// version is a single byte, currently Version1. chunkSize is a
+// big-endian uint32 giving the plaintext size of every chunk
+// but the last, which may be shorter (including empty). prefix
+// is PrefixSize bytes chosen by the writer, and must be unique
+// for every stream encrypted under the same key. i counts
+// chunks from zero, and last is 0x01 for the final chunk and
+// 0x00 otherwise.
+//
+// The header is authenticated as additional data of every
+// chunk. Reordering, dropping or substituting chunks, and
+// truncating the stream at a chunk boundary, all cause Read to
+// fail. With WithAdditionalData(extra), the additional data is
+// extra || header instead.
+package stream
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "crypto/cipher"
+    "encoding/binary"
+)
+
+const (
+    // Version1 is the only stream format version.
+    Version1 = 1
+    // PrefixSize is the size in bytes of the nonce prefix.
+    PrefixSize = 11
+    // NonceSize is the nonce size the AEAD must use.
+    NonceSize = PrefixSize + 5
+    // HeaderSize is the size in bytes of the stream header.
+    HeaderSize = 1 + 4 + PrefixSize
+    // DefaultChunkSize is a reasonable chunk size for most
+    // uses.
+    DefaultChunkSize = 64 * 1024
+    // MaxChunkSize is the largest chunk size accepted by
+    // NewWriter and NewReader.
+    MaxChunkSize = 16 * 1024 * 1024
+)
+
+var (
+    // ErrInvalidChunk is returned by Reader when a chunk fails
+    // authentication or the stream has been truncated.
+    ErrInvalidChunk = errors.New("stream: chunk authentication failed")
+    // ErrUnsupportedVersion is returned by NewReader when the
+    // header has an unknown version.
+    ErrUnsupportedVersion = errors.New("stream: unsupported version")
+
+    errTooManyChunks = errors.New("stream: too many chunks")
+    errClosed        = errors.New("stream: write to closed Writer")
+)
+
+// maxChunks is the number of chunks addressable by the 32-bit
+// counter.
+const maxChunks = 1 << 32
+
+// Writer encrypts a stream.
+type Writer struct {
+    aead    cipher.AEAD
+    w       io.Writer
+    header  [HeaderSize]byte
+    ad      []byte
+    nonce   [NonceSize]byte
+    counter uint64
+    buf     []byte
+    out     []byte
+    err     error
+
+    workers int
+    pending []*chunkJob
+    free    []*chunkJob
+}
+
+// NewWriter writes the stream header to w and returns a Writer
+// that encrypts everything written to it with aead in chunks
+// of chunkSize bytes.
+//
+// aead must use NonceSize byte nonces, and prefix must be
+// PrefixSize bytes and never be reused with the same key.
+// Close must be called to write the final chunk.
+func NewWriter(aead cipher.AEAD, prefix []byte, chunkSize int, w io.Writer, opts ...Option) (*Writer, error) {
+    if aead.NonceSize() != NonceSize {
+        return nil, errors.New("stream: unsupported nonce size")
+    }
+    if len(prefix) != PrefixSize {
+        return nil, errors.New("stream: bad prefix length")
+    }
+    if chunkSize <= 0 || chunkSize > MaxChunkSize {
+        return nil, errors.New("stream: bad chunk size")
+    }
+    var o options
+    for _, fn := range opts {
+        fn(&o)
+    }
+
+    sw := &Writer{
+        aead:    aead,
+        w:       w,
+        buf:     make([]byte, 0, chunkSize),
+        workers: o.workers,
+    }
+    if sw.workers <= 1 {
+        sw.out = make([]byte, 0, chunkSize+aead.Overhead())
+    }
+    sw.header[0] = Version1
+    binary.BigEndian.PutUint32(sw.header[1:], uint32(chunkSize))
+    copy(sw.header[5:], prefix)
+    copy(sw.nonce[:], prefix)
+    sw.ad = o.additionalData(sw.header[:])
+
+    if _, err := w.Write(sw.header[:]); err != nil {
+        return nil, err
+    }
+    return sw, nil
+}
+
+// Write buffers p and seals every chunk that is known not to
+// be the final one.
+func (sw *Writer) Write(p []byte) (int, error) {
+    if sw.err != nil {
+        return 0, sw.err
+    }
+
+    total := len(p)
+    for len(p) > 0 {
+        if len(sw.buf) == cap(sw.buf) {
+            if err := sw.seal(false); err != nil {
+                return total - len(p), err
+            }
+        }
+        n := copy(sw.buf[len(sw.buf):cap(sw.buf)], p)
+        sw.buf = sw.buf[:len(sw.buf)+n]
+        p = p[n:]
+    }
+    return total, nil
+}
+
+// Close seals and writes the final chunk. It does not close
+// the underlying io.Writer.
+func (sw *Writer) Close() error {
+    if sw.err != nil {
+        if sw.err == errClosed {
+            return nil
+        }
+        return sw.err
+    }
+    if err := sw.seal(true); err != nil {
+        return err
+    }
+    sw.err = errClosed
+    return nil
+}
+
+func (sw *Writer) seal(last bool) error {
+    if sw.workers > 1 {
+        return sw.sealAsync(last)
+    }
+    if sw.counter >= maxChunks {
+        sw.err = errTooManyChunks
+        return sw.err
+    }
+    setCounter(&sw.nonce, sw.counter, last)
+    sw.counter++
+
+    sw.out = sw.aead.Seal(sw.out[:0], sw.nonce[:], sw.buf, sw.ad)
+    sw.buf = sw.buf[:0]
+    if _, err := sw.w.Write(sw.out); err != nil {
+        sw.err = err
+        return err
+    }
+    return nil
+}
+
+// Reader decrypts a stream written by Writer.
+type Reader struct {
+    aead    cipher.AEAD
+    r       *bufio.Reader
+    header  [HeaderSize]byte
+    ad      []byte
+    nonce   [NonceSize]byte
+    counter uint64
+    chunk   int
+    buf     []byte
+    out     []byte
+    err     error
+
+    workers int
+    pending []*chunkJob
+    free    []*chunkJob
+    // cur is the job whose plaintext is in out, and readErr
+    // stops the read-ahead of nextAsync.
+    cur     *chunkJob
+    readErr error
+}
+
+// NewReader reads the stream header from r and returns a Reader
+// that decrypts the stream with aead.
+func NewReader(aead cipher.AEAD, r io.Reader, opts ...Option) (*Reader, error) {
+    if aead.NonceSize() != NonceSize {
+        return nil, errors.New("stream: unsupported nonce size")
+    }
+    var o options
+    for _, fn := range opts {
+        fn(&o)
+    }
+
+    sr := &Reader{aead: aead, workers: o.workers}
+    if _, err := io.ReadFull(r, sr.header[:]); err != nil {
+        if err == io.EOF {
+            err = io.ErrUnexpectedEOF
+        }
+        return nil, err
+    }
+    if sr.header[0] != Version1 {
+        return nil, ErrUnsupportedVersion
+    }
+    chunkSize := binary.BigEndian.Uint32(sr.header[1:])
+    if chunkSize == 0 || chunkSize > MaxChunkSize {
+        return nil, errors.New("stream: bad chunk size")
+    }
+    sr.chunk = int(chunkSize)
+    copy(sr.nonce[:], sr.header[5:])
+    sr.ad = o.additionalData(sr.header[:])
+    if sr.workers <= 1 {
+        sr.buf = make([]byte, sr.chunk+aead.Overhead())
+    }
+    sr.r = bufio.NewReader(r)
+    return sr, nil
+}
+
+// Read decrypts the next chunk into p as needed. Only
+// authenticated plaintext is returned, and Read returns io.EOF
+// after the final chunk.
+func (sr *Reader) Read(p []byte) (int, error) {
+    for len(sr.out) == 0 {
+        if sr.err != nil {
+            return 0, sr.err
+        }
+        sr.next()
+    }
+    n := copy(p, sr.out)
+    sr.out = sr.out[n:]
+    return n, nil
+}
+
+// next reads and opens the next chunk.
+func (sr *Reader) next() {
+    if sr.workers > 1 {
+        sr.nextAsync()
+        return
+    }
+    n, last, err := sr.readChunk(sr.buf)
+    if err != nil {
+        sr.err = err
+        return
+    }
+
+    out, err := sr.aead.Open(sr.buf[:0], sr.nonce[:], sr.buf[:n], sr.ad)
+    if err != nil {
+        sr.err = ErrInvalidChunk
+        return
+    }
+    sr.out = out
+    if last {
+        sr.err = io.EOF
+    }
+}
+
+// readChunk reads the next chunk into buf, and sets the nonce
+// for it. It returns the size of the chunk and whether it is
+// the final one.
+func (sr *Reader) readChunk(buf []byte) (int, bool, error) {
+    n, err := io.ReadFull(sr.r, buf)
+    var last bool
+    switch err {
+    case nil:
+        // A full chunk is the last one only if nothing
+        // follows it.
+        if _, err := sr.r.Peek(1); err == io.EOF {
+            last = true
+        } else if err != nil {
+            return 0, false, err
+        }
+    case io.ErrUnexpectedEOF:
+        last = true
+    case io.EOF:
+        // The previous chunk was not marked as the last one.
+        return 0, false, ErrInvalidChunk
+    default:
+        return 0, false, err
+    }
+
+    if sr.counter >= maxChunks {
+        return 0, false, errTooManyChunks
+    }
+    setCounter(&sr.nonce, sr.counter, last)
+    sr.counter++
+    return n, last, nil
+}
+
+func setCounter(nonce *[NonceSize]byte, i uint64, last bool) {
+    binary.BigEndian.PutUint32(nonce[PrefixSize:], uint32(i))
+    if last {
+        nonce[NonceSize-1] = 1
+    } else {
+        nonce[NonceSize-1] = 0
+    }
+}
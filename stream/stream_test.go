@@ -0,0 +1,236 @@
+package stream
+
+import (
+    "bytes"
+    "crypto/cipher"
+    "encoding/hex"
+    "io"
+    "math/rand"
+    "testing"
+    "testing/iotest"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func newAEAD(t testing.TB) cipher.AEAD {
+    key := make([]byte, ascon.KeySize)
+    for i := range key {
+        key[i] = byte(i)
+    }
+    aead, err := ascon.New128a(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return aead
+}
+
+func seal(t testing.TB, aead cipher.AEAD, prefix []byte, chunkSize int, pt []byte) []byte {
+    var buf bytes.Buffer
+    w, err := NewWriter(aead, prefix, chunkSize, &buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := w.Write(pt); err != nil {
+        t.Fatal(err)
+    }
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func open(aead cipher.AEAD, ct []byte) ([]byte, error) {
+    r, err := NewReader(aead, bytes.NewReader(ct))
+    if err != nil {
+        return nil, err
+    }
+    return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+    aead := newAEAD(t)
+    prefix := make([]byte, PrefixSize)
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+
+    for _, chunkSize := range []int{1, 7, 16, 100, 4096} {
+        for _, size := range []int{0, 1, 6, 7, 8, 99, 100, 101, 300, 10000} {
+            pt := make([]byte, size)
+            rng.Read(pt)
+
+            var buf bytes.Buffer
+            w, err := NewWriter(aead, prefix, chunkSize, &buf)
+            if err != nil {
+                t.Fatal(err)
+            }
+            for p := pt; len(p) > 0; {
+                n := rng.Intn(2*chunkSize) + 1
+                if n > len(p) {
+                    n = len(p)
+                }
+                if _, err := w.Write(p[:n]); err != nil {
+                    t.Fatal(err)
+                }
+                p = p[n:]
+            }
+            if err := w.Close(); err != nil {
+                t.Fatal(err)
+            }
+            ct := buf.Bytes()
+
+            // The final chunk is only empty for an empty stream.
+            chunks := (size + chunkSize - 1) / chunkSize
+            if chunks == 0 {
+                chunks = 1
+            }
+            if want := HeaderSize + size + chunks*aead.Overhead(); len(ct) != want {
+                t.Fatalf("%d/%d: expected %d bytes, got %d", chunkSize, size, want, len(ct))
+            }
+
+            r, err := NewReader(aead, iotest.HalfReader(bytes.NewReader(ct)))
+            if err != nil {
+                t.Fatal(err)
+            }
+            got, err := io.ReadAll(r)
+            if err != nil {
+                t.Fatalf("%d/%d: %v", chunkSize, size, err)
+            }
+            if !bytes.Equal(got, pt) {
+                t.Fatalf("%d/%d: plaintext mismatch", chunkSize, size)
+            }
+        }
+    }
+}
+
+// TestFormat locks down the byte format.
+func TestFormat(t *testing.T) {
+    aead := newAEAD(t)
+    prefix := []byte("0123456789a")
+    got := seal(t, aead, prefix, 4, []byte("hello, world"))
+    want, _ := hex.DecodeString(goldenStream)
+    if !bytes.Equal(got, want) {
+        t.Fatalf("expected %x, got %x", want, got)
+    }
+}
+
+const goldenStream = "01000000043031323334353637383961" +
+    "25da50973cb581cb2fc202c878cd1fca575ec071" +
+    "164d136907ea141752fc028d87831d0ec2e30bfe" +
+    "93bf9f9b13773ccf57e180efc2f19749081210a8"
+
+func TestTampering(t *testing.T) {
+    aead := newAEAD(t)
+    const chunkSize = 8
+    overhead := aead.Overhead()
+    record := chunkSize + overhead
+    pt := bytes.Repeat([]byte("abcdefgh"), 4)
+    pt = append(pt, "tail"...)
+    ct := seal(t, aead, make([]byte, PrefixSize), chunkSize, pt)
+    chunk := func(i int) []byte {
+        return ct[HeaderSize+i*record : HeaderSize+(i+1)*record]
+    }
+
+    cat := func(parts ...[]byte) []byte {
+        var b []byte
+        for _, p := range parts {
+            b = append(b, p...)
+        }
+        return b
+    }
+    hdr := ct[:HeaderSize]
+
+    other := seal(t, aead, []byte("other prefx"), chunkSize, pt)
+
+    for _, tc := range []struct {
+        name string
+        ct   []byte
+    }{
+        {"truncated at boundary", ct[:HeaderSize+4*record]},
+        {"truncated mid-chunk", ct[:HeaderSize+2*record+3]},
+        {"header only", hdr},
+        {"reordered", cat(hdr, chunk(1), chunk(0), ct[HeaderSize+2*record:])},
+        {"dropped", cat(hdr, chunk(0), ct[HeaderSize+2*record:])},
+        {"duplicated", cat(hdr, chunk(0), chunk(0), ct[HeaderSize+2*record:])},
+        {"substituted", cat(hdr, other[HeaderSize:HeaderSize+record], ct[HeaderSize+record:])},
+        {"extended", cat(ct, chunk(0))},
+        {"chunk size", cat([]byte{Version1, 0, 0, 0, 9}, ct[5:])},
+    } {
+        if _, err := open(aead, tc.ct); err == nil {
+            t.Fatalf("%s: expected an error", tc.name)
+        }
+    }
+
+    bad := append([]byte(nil), ct...)
+    bad[0] = 2
+    if _, err := open(aead, bad); err != ErrUnsupportedVersion {
+        t.Fatalf("expected %v, got %v", ErrUnsupportedVersion, err)
+    }
+}
+
+// TestNoUnauthenticatedPlaintext checks that chunks before a
+// corrupted one are returned, but nothing from it.
+func TestNoUnauthenticatedPlaintext(t *testing.T) {
+    aead := newAEAD(t)
+    pt := bytes.Repeat([]byte{'x'}, 40)
+    ct := seal(t, aead, make([]byte, PrefixSize), 16, pt)
+    ct[HeaderSize+(16+aead.Overhead())+1] ^= 1
+
+    got, err := open(aead, ct)
+    if err != ErrInvalidChunk {
+        t.Fatalf("expected %v, got %v", ErrInvalidChunk, err)
+    }
+    if !bytes.Equal(got, pt[:16]) {
+        t.Fatalf("expected %q, got %q", pt[:16], got)
+    }
+}
+
+func TestAdditionalData(t *testing.T) {
+    aead := newAEAD(t)
+    prefix := make([]byte, PrefixSize)
+    pt := bytes.Repeat([]byte("abcdefgh"), 5)
+    ad := []byte("outer header")
+
+    var buf bytes.Buffer
+    for _, workers := range []int{1, 3} {
+        buf.Reset()
+        w, err := NewWriter(aead, prefix, 8, &buf, WithAdditionalData(ad), WithWorkers(workers))
+        if err != nil {
+            t.Fatal(err)
+        }
+        w.Write(pt)
+        if err := w.Close(); err != nil {
+            t.Fatal(err)
+        }
+        ct := buf.Bytes()
+        if bytes.Equal(ct, seal(t, aead, prefix, 8, pt)) {
+            t.Fatal("the additional data does not change the stream")
+        }
+
+        r, err := NewReader(aead, bytes.NewReader(ct), WithAdditionalData(ad), WithWorkers(workers))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, pt) {
+            t.Fatalf("%d workers: got %q, %v", workers, got, err)
+        }
+        sr, err := NewSeekReader(aead, bytes.NewReader(ct), int64(len(ct)), WithAdditionalData(ad))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got, err := io.ReadAll(sr); err != nil || !bytes.Equal(got, pt) {
+            t.Fatalf("SeekReader: got %q, %v", got, err)
+        }
+
+        for _, opts := range [][]Option{nil, {WithAdditionalData(ad[1:])}} {
+            r, err := NewReader(aead, bytes.NewReader(ct), opts...)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got, err := io.ReadAll(r); err != ErrInvalidChunk || len(got) != 0 {
+                t.Fatalf("other additional data: got %q, %v", got, err)
+            }
+            if _, err := NewSeekReader(aead, bytes.NewReader(ct), int64(len(ct)), opts...); err != ErrInvalidChunk {
+                t.Fatalf("SeekReader with other additional data: got %v", err)
+            }
+        }
+    }
+}
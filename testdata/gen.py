@@ -0,0 +1,247 @@
+#!/usr/bin/env python3
+"""Generates the vector files of this directory.
+
+Usage: gen.py [-check] NAME ...
+
+Every NAME, from the list below, is written to its file, by default
+testdata/vectors_NAME.txt. With -check, the files are compared with
+what would be written, and the first one that differs is reported.
+
+The script does not use the Go code. It is a transcription of the
+Ascon v1.2 specification, in which the state is five big-endian 64-bit
+words, and of the constructions documented in the Go files named below.
+Before writing anything it checks its Ascon-128 and Ascon-128a against
+the known answer tests of ascontest/kat, which come from the reference
+C implementation.
+
+    siv       ASCON-SIV, siv.go
+    x128a     XASCON-128a, xascon.go
+    rekey128  NewRekeying128, rekey.go
+    insecure  round-reduced Ascon-128 and Ascon-128a, insecure/insecure.go,
+              written to insecure/testdata/vectors.txt
+"""
+
+import os
+import sys
+
+M = (1 << 64) - 1
+ROOT = os.path.dirname(os.path.dirname(os.path.abspath(__file__)))
+
+
+def rotr(x, n):
+    return ((x >> n) | (x << (64 - n))) & M
+
+
+def perm(s, rounds):
+    """Applies the last rounds rounds of the permutation to s."""
+    for r in range(12 - rounds, 12):
+        s[2] ^= 0xF0 - r * 0x10 + r
+        s[0] ^= s[4]
+        s[4] ^= s[3]
+        s[2] ^= s[1]
+        t = [(s[i] ^ M) & s[(i + 1) % 5] for i in range(5)]
+        for i in range(5):
+            s[i] ^= t[(i + 1) % 5]
+        s[1] ^= s[0]
+        s[0] ^= s[4]
+        s[3] ^= s[2]
+        s[2] ^= M
+        s[0] ^= rotr(s[0], 19) ^ rotr(s[0], 28)
+        s[1] ^= rotr(s[1], 61) ^ rotr(s[1], 39)
+        s[2] ^= rotr(s[2], 1) ^ rotr(s[2], 6)
+        s[3] ^= rotr(s[3], 10) ^ rotr(s[3], 17)
+        s[4] ^= rotr(s[4], 7) ^ rotr(s[4], 41)
+
+
+def word(b):
+    return int.from_bytes(b, "big")
+
+
+def wbytes(x):
+    return x.to_bytes(8, "big")
+
+
+def state(b):
+    return [word(b[8 * i:8 * i + 8]) for i in range(5)]
+
+
+def pad(b, rate):
+    return b + b"\x80" + bytes(rate - len(b) % rate - 1)
+
+
+def aead(key, nonce, ad, pt, variant, rounds=None):
+    """Returns the ciphertext and the tag of Ascon-128, Ascon-128a or
+    Ascon-80pq. rounds, if given, replaces the round counts a and b of
+    the permutation calls, but not those of the IV."""
+    a, b, rate = 12, 6, 8
+    if variant == "128a":
+        b, rate = 8, 16
+    iv = bytes([len(key) * 8, rate * 8, a, b])
+    if rounds:
+        a, b = rounds
+    s = state(iv + bytes(20 - len(key)) + key + nonce)
+    perm(s, a)
+    k = state(bytes(40 - len(key)) + key)
+    s = [x ^ y for x, y in zip(s, k)]
+    if ad:
+        ad = pad(ad, rate)
+        for i in range(0, len(ad), rate):
+            for j in range(rate // 8):
+                s[j] ^= word(ad[i + 8 * j:i + 8 * j + 8])
+            perm(s, b)
+    s[4] ^= 1
+    last = len(pt) % rate
+    pt = pad(pt, rate)
+    ct = b""
+    for i in range(0, len(pt), rate):
+        block = b""
+        for j in range(rate // 8):
+            s[j] ^= word(pt[i + 8 * j:i + 8 * j + 8])
+            block += wbytes(s[j])
+        if i + rate < len(pt):
+            ct += block
+            perm(s, b)
+        else:
+            ct += block[:last]
+    k = state(key + bytes(40 - len(key)))
+    for j in range(5 - rate // 8):
+        s[rate // 8 + j] ^= k[j]
+    perm(s, a)
+    s[3] ^= word(key[-16:-8])
+    s[4] ^= word(key[-8:])
+    return ct, wbytes(s[3]) + wbytes(s[4])
+
+
+def mac(key, msg, variant="mac", outlen=16):
+    """Returns the tag of Ascon-Mac, or the output of Ascon-Prf."""
+    a = b = 12
+    inrate, rate = 32, 16
+    taglen = (128 if variant == "mac" else 0).to_bytes(4, "big")
+    s = state(bytes([len(key) * 8, rate * 8, a + 128, a - b]) + taglen + key + bytes(16))
+    perm(s, a)
+    msg = pad(msg, inrate)
+    for i in range(0, len(msg), inrate):
+        for j in range(4):
+            s[j] ^= word(msg[i + 8 * j:i + 8 * j + 8])
+        if i + inrate < len(msg):
+            perm(s, b)
+    s[4] ^= 1
+    perm(s, a)
+    out = b""
+    while len(out) < outlen:
+        out += wbytes(s[0]) + wbytes(s[1])
+        perm(s, b)
+    return out[:outlen]
+
+
+def read_vecs(path):
+    vecs = []
+    with open(path) as f:
+        for line in f:
+            line = line.strip()
+            if not line:
+                continue
+            name, _, value = line.partition("=")
+            name, value = name.strip(), value.strip()
+            if name == "Count":
+                vecs.append({})
+            else:
+                vecs[-1][name] = bytes.fromhex(value)
+    return vecs
+
+
+def self_check():
+    for variant in ("128", "128a"):
+        path = os.path.join(ROOT, "ascontest", "kat", "ascon%s.txt" % variant)
+        for n, v in enumerate(read_vecs(path)):
+            ct, tag = aead(v["Key"], v["Nonce"], v["AD"], v["PT"], variant)
+            if ct + tag != v["CT"]:
+                sys.exit("gen.py: Ascon-%s does not match %s, count %d" % (variant, path, n + 1))
+
+
+def aead_kat(seal, keylen, noncelen=16):
+    """Returns the records of seal for every plaintext and additional
+    data of 0 to 32 bytes, in the layout of the LWC known answer tests."""
+    out = []
+    key, nonce = bytes(range(keylen)), bytes(range(noncelen))
+    for ptlen in range(33):
+        for adlen in range(33):
+            pt, ad = bytes(range(ptlen)), bytes(range(adlen))
+            out.append("Count = %d\nKey = %s\nNonce = %s\nPT = %s\nAD = %s\nCT = %s\n\n" % (
+                len(out) + 1, key.hex().upper(), nonce.hex().upper(),
+                pt.hex().upper(), ad.hex().upper(), seal(key, nonce, ad, pt).hex().upper()))
+    return "".join(out)
+
+
+def siv(key, nonce, ad, pt):
+    v = mac(key[:16], nonce + len(ad).to_bytes(8, "big") + ad + pt)
+    ct, _ = aead(key[16:], v, b"", pt, "128a")
+    return ct + v
+
+
+def x128a(key, nonce, ad, pt):
+    subkey = mac(key, nonce[:16], "prf")
+    ct, tag = aead(subkey, bytes(8) + nonce[16:], ad, pt, "128a")
+    return ct + tag
+
+
+def rekey128(key, nonce, ad, pt):
+    s = state(key + bytes([0x80, 0x01, 0x0C, 0x01]) + bytes(20))
+    perm(s, 12)
+    bits = [(nonce[i // 8] >> (7 - i % 8)) & 1 for i in range(len(nonce) * 8)]
+    for i, bit in enumerate(bits):
+        s[0] ^= bit << 63
+        perm(s, 1 if i + 1 < len(bits) else 12)
+    ct, tag = aead(wbytes(s[0]) + wbytes(s[1]), nonce, ad, pt, "128")
+    return ct + tag
+
+
+def reduced_kat():
+    """Returns the records of round-reduced Ascon-128 and Ascon-128a,
+    which have the IV of the full round counts."""
+    out = []
+    key, nonce = bytes(range(16)), bytes(range(16))
+    for variant, rate in (("128", 8), ("128a", 16)):
+        for rounds in ((12, 6 if rate == 8 else 8), (6, 4), (4, 2), (1, 1)):
+            for ptlen, adlen in ((0, 0), (0, 3), (20, 0), (20, 3)):
+                pt, ad = bytes(range(ptlen)), bytes(range(adlen))
+                ct, tag = aead(key, nonce, ad, pt, variant, rounds)
+                out.append("Count = %d\nRate = %d\nA = %d\nB = %d\nKey = %s\nNonce = %s\nPT = %s\nAD = %s\nCT = %s\n\n" % (
+                    len(out) + 1, rate * 8, rounds[0], rounds[1], key.hex().upper(), nonce.hex().upper(),
+                    pt.hex().upper(), ad.hex().upper(), (ct + tag).hex().upper()))
+    return "".join(out)
+
+
+FILES = {
+    "siv": lambda: aead_kat(siv, 32),
+    "x128a": lambda: aead_kat(x128a, 16, 24),
+    "rekey128": lambda: aead_kat(rekey128, 16),
+    "insecure": reduced_kat,
+}
+
+PATHS = {
+    "insecure": os.path.join("insecure", "testdata", "vectors.txt"),
+}
+
+
+def main(args):
+    check = args[:1] == ["-check"]
+    if check:
+        args = args[1:]
+    if not args or any(name not in FILES for name in args):
+        sys.exit(__doc__)
+    self_check()
+    for name in args:
+        path = os.path.join(ROOT, PATHS.get(name, os.path.join("testdata", "vectors_%s.txt" % name)))
+        text = FILES[name]()
+        if check:
+            with open(path) as f:
+                if f.read() != text:
+                    sys.exit("gen.py: %s differs" % path)
+        else:
+            with open(path, "w") as f:
+                f.write(text)
+
+
+if __name__ == "__main__":
+    main(sys.argv[1:])
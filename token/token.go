@@ -0,0 +1,239 @@
+// Package token implements encrypted and authenticated tokens in
+// the manner of PASETO's local tokens, with Ascon-128a.
+//
+// A token is
+//
+//    v1a.local.<payload>
+//    v1a.local.<payload>.<footer>
+//
+// where payload and footer are unpadded base64url, and the footer
+// part is omitted if the footer is empty. With a 16-byte key,
+// Encrypt computes
+//
+//    h       = "v1a.local."
+//    n       = 16 random bytes
+//    m       = the JSON encoding of the claims, an object
+//    ad      = PAE(h, n, footer)
+//    c       = Ascon-128a(key, n, m, ad)
+//    payload = base64url(n || c)
+//
+// in which c ends with the 16-byte tag, so the payload decodes to
+// at least 32 bytes. PAE is the pre-authentication encoding of
+// PASETO: for the pieces p1, ..., pk,
+//
+//    PAE(p1, ..., pk) = LE64(k) || LE64(len(p1)) || p1 || ... || LE64(len(pk)) || pk
+//
+// where LE64(x) is the 8-byte little-endian encoding of x with
+// the top bit cleared. It makes every split of the pieces a
+// different additional data, so that the footer, the nonce and
+// the version are all authenticated. The footer is not
+// encrypted: it is meant for a key identifier, which Footer
+// returns before the key is known. The tests read
+// testdata/vectors.txt, which has tokens with their keys, nonces,
+// messages, footers and additional data.
+//
+// Decrypt rejects tokens whose claims have expired. The "exp"
+// and "nbf" claims, if present, are RFC 3339 strings, as in
+// PASETO, and a token is valid from nbf up to but excluding exp.
+// Encrypt stores a time.Time claim in that form.
+//
+// Every token that fails to decode or authenticate, including
+// one encrypted with another key, gives the same error,
+// ErrInvalidToken.
+//
+// References:
+//
+//    [paseto]: https://github.com/paseto-standard/paseto-spec
+//    [rfc3339]: https://www.rfc-editor.org/rfc/rfc3339
+//
+package token
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "strings"
+    "time"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/json"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+const (
+    // KeySize is the size in bytes of the key.
+    KeySize = ascon.KeySize
+
+    // header is the version and purpose prefix of every token.
+    header = "v1a.local."
+)
+
+var (
+    // ErrInvalidToken is returned by Decrypt for a token that is
+    // malformed, was modified, or was encrypted with another key.
+    ErrInvalidToken = errors.New("token: invalid token")
+
+    // ErrExpired is returned by Decrypt for an authentic token
+    // whose exp claim is not after the time of the check.
+    ErrExpired = errors.New("token: token has expired")
+
+    // ErrNotYetValid is returned by Decrypt for an authentic
+    // token whose nbf claim is after the time of the check.
+    ErrNotYetValid = errors.New("token: token is not valid yet")
+
+    // errClaim is returned by Decrypt for an authentic token
+    // with an exp or nbf claim that is not an RFC 3339 string.
+    errClaim = errors.New("token: malformed time claim")
+)
+
+// randReader is the source of nonces, replaced in tests.
+var randReader io.Reader = rand.Reader
+
+// b64 is the encoding of the payload and footer. It is strict,
+// so that every token has a single encoding.
+var b64 = base64.RawURLEncoding.Strict()
+
+// PAE returns the pre-authentication encoding of pieces.
+func PAE(pieces ...[]byte) []byte {
+    n := 8
+    for _, p := range pieces {
+        n += 8 + len(p)
+    }
+    out := make([]byte, 0, n)
+    out = appendLE64(out, len(pieces))
+    for _, p := range pieces {
+        out = appendLE64(out, len(p))
+        out = append(out, p...)
+    }
+    return out
+}
+
+func appendLE64(b []byte, x int) []byte {
+    return binary.LittleEndian.AppendUint64(b, uint64(x)&^(1<<63))
+}
+
+// Encrypt encrypts claims with key into a token, authenticating
+// footer, which it holds in the clear. Values of claims are
+// encoded as by encoding/json, and time.Time values as RFC 3339
+// strings.
+func Encrypt(key []byte, claims map[string]any, footer []byte) (string, error) {
+    m, err := json.Marshal(claims)
+    if err != nil {
+        return "", err
+    }
+    var nonce [ascon.NonceSize]byte
+    if _, err := io.ReadFull(randReader, nonce[:]); err != nil {
+        return "", err
+    }
+    return encrypt(key, nonce[:], m, footer)
+}
+
+// encrypt is Encrypt with the nonce nonce and the encoded claims
+// m.
+func encrypt(key, nonce, m, footer []byte) (string, error) {
+    aead, err := ascon.New(key, ascon.WithVariant(ascon.Ascon128a))
+    if err != nil {
+        return "", err
+    }
+    payload := make([]byte, 0, len(nonce)+len(m)+ascon.TagSize)
+    payload = append(payload, nonce...)
+    payload = aead.Seal(payload, nonce, m, PAE([]byte(header), nonce, footer))
+    s := header + b64.EncodeToString(payload)
+    if len(footer) > 0 {
+        s += "." + b64.EncodeToString(footer)
+    }
+    return s, nil
+}
+
+// split returns the decoded payload and footer of token.
+func split(token string) (payload, footer []byte, err error) {
+    rest, ok := strings.CutPrefix(token, header)
+    if !ok {
+        return nil, nil, ErrInvalidToken
+    }
+    p, f, hasFooter := strings.Cut(rest, ".")
+    if hasFooter {
+        // An empty footer has no part, so that every token has a
+        // single encoding.
+        if footer, err = b64.DecodeString(f); err != nil || len(footer) == 0 {
+            return nil, nil, ErrInvalidToken
+        }
+    }
+    if payload, err = b64.DecodeString(p); err != nil {
+        return nil, nil, ErrInvalidToken
+    }
+    if len(payload) < ascon.NonceSize+ascon.TagSize {
+        return nil, nil, ErrInvalidToken
+    }
+    return payload, footer, nil
+}
+
+// Footer returns the footer of token, without authenticating it,
+// so that the key can be chosen by a key identifier in it.
+func Footer(token string) ([]byte, error) {
+    _, footer, err := split(token)
+    return footer, err
+}
+
+// Decrypt authenticates and decrypts token with key, checks its
+// time claims against the current time, and returns its claims
+// and footer. It is DecryptAt with time.Now().
+func Decrypt(key []byte, token string) (map[string]any, []byte, error) {
+    return DecryptAt(key, token, time.Now())
+}
+
+// DecryptAt is Decrypt with the claims checked against now
+// instead of the current time. Numbers in the claims are
+// json.Number values, so that no integer loses precision.
+func DecryptAt(key []byte, token string, now time.Time) (map[string]any, []byte, error) {
+    aead, err := ascon.New(key, ascon.WithVariant(ascon.Ascon128a))
+    if err != nil {
+        return nil, nil, err
+    }
+    payload, footer, err := split(token)
+    if err != nil {
+        return nil, nil, err
+    }
+    nonce := payload[:ascon.NonceSize]
+    m, err := aead.Open(nil, nonce, payload[ascon.NonceSize:], PAE([]byte(header), nonce, footer))
+    if err != nil {
+        return nil, nil, ErrInvalidToken
+    }
+    var claims map[string]any
+    d := json.NewDecoder(bytes.NewReader(m))
+    d.UseNumber()
+    if err := d.Decode(&claims); err != nil || claims == nil || d.More() {
+        return nil, nil, ErrInvalidToken
+    }
+    if err := checkTime(claims, now); err != nil {
+        return nil, nil, err
+    }
+    return claims, footer, nil
+}
+
+// checkTime checks the exp and nbf claims against now.
+func checkTime(claims map[string]any, now time.Time) error {
+    for _, name := range []string{"exp", "nbf"} {
+        v, ok := claims[name]
+        if !ok {
+            continue
+        }
+        s, ok := v.(string)
+        if !ok {
+            return errClaim
+        }
+        t, err := time.Parse(time.RFC3339, s)
+        if err != nil {
+            return errClaim
+        }
+        if name == "exp" && !now.Before(t) {
+            return ErrExpired
+        }
+        if name == "nbf" && now.Before(t) {
+            return ErrNotYetValid
+        }
+    }
+    return nil
+}
@@ -0,0 +1,281 @@
+package token
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strings"
+    "testing"
+    "time"
+)
+
+type vector struct {
+    key, nonce, msg []byte
+    footer, ad      []byte
+    token           string
+}
+
+func readVecs(path string) ([]vector, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var vecs []vector
+
+    s := bufio.NewScanner(f)
+    for n := 1; s.Scan(); n++ {
+        t := s.Text()
+        if t == "" {
+            continue
+        }
+        if strings.HasPrefix(t, "Count = ") {
+            vecs = append(vecs, vector{})
+            continue
+        }
+        i := strings.IndexByte(t, '=')
+        if i < 0 || len(vecs) == 0 {
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+        v := &vecs[len(vecs)-1]
+        name, val := strings.TrimSpace(t[:i]), strings.TrimSpace(t[i+1:])
+        if name == "Token" {
+            v.token = val
+            continue
+        }
+        buf, err := hex.DecodeString(val)
+        if err != nil {
+            return nil, fmt.Errorf("malformed line %d: %v", n, err)
+        }
+        switch name {
+        case "Key":
+            v.key = buf
+        case "Nonce":
+            v.nonce = buf
+        case "Msg":
+            v.msg = buf
+        case "Footer":
+            v.footer = buf
+        case "AD":
+            v.ad = buf
+        default:
+            return nil, fmt.Errorf("malformed line %d: %q", n, t)
+        }
+    }
+    return vecs, s.Err()
+}
+
+// testNow is a time at which all the tokens of the vectors are
+// valid.
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVectors(t *testing.T) {
+    vecs, err := readVecs("testdata/vectors.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(vecs) == 0 {
+        t.Fatal("no vectors")
+    }
+    for i, v := range vecs {
+        if ad := PAE([]byte(header), v.nonce, v.footer); !bytes.Equal(ad, v.ad) {
+            t.Fatalf("#%d: expected additional data %#x, got %#x", i+1, v.ad, ad)
+        }
+        s, err := encrypt(v.key, v.nonce, v.msg, v.footer)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if s != v.token {
+            t.Fatalf("#%d: expected %s, got %s", i+1, v.token, s)
+        }
+        claims, footer, err := DecryptAt(v.key, v.token, testNow)
+        if err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if !bytes.Equal(footer, v.footer) {
+            t.Fatalf("#%d: expected footer %q, got %q", i+1, v.footer, footer)
+        }
+        var want map[string]any
+        d := json.NewDecoder(bytes.NewReader(v.msg))
+        d.UseNumber()
+        if err := d.Decode(&want); err != nil {
+            t.Fatalf("#%d: %v", i+1, err)
+        }
+        if fmt.Sprint(claims) != fmt.Sprint(want) {
+            t.Fatalf("#%d: expected claims %v, got %v", i+1, want, claims)
+        }
+    }
+}
+
+func TestPAE(t *testing.T) {
+    // The examples of the PASETO specification.
+    for _, tc := range []struct {
+        pieces []string
+        want   string
+    }{
+        {nil, "\x00\x00\x00\x00\x00\x00\x00\x00"},
+        {[]string{""}, "\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"},
+        {[]string{"test"}, "\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"},
+    } {
+        var pieces [][]byte
+        for _, p := range tc.pieces {
+            pieces = append(pieces, []byte(p))
+        }
+        if got := PAE(pieces...); string(got) != tc.want {
+            t.Errorf("%q: expected %q, got %q", tc.pieces, tc.want, got)
+        }
+    }
+}
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func TestRoundTrip(t *testing.T) {
+    exp := testNow.Add(time.Hour)
+    claims := map[string]any{"sub": "alice", "exp": exp, "n": uint64(1) << 60}
+    for _, footer := range [][]byte{nil, []byte(`{"kid":"k1"}`)} {
+        s, err := Encrypt(testKey, claims, footer)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !strings.HasPrefix(s, "v1a.local.") {
+            t.Fatalf("unexpected token %s", s)
+        }
+        if f, err := Footer(s); err != nil || !bytes.Equal(f, footer) {
+            t.Fatalf("Footer: got %q, %v", f, err)
+        }
+        got, f, err := DecryptAt(testKey, s, testNow)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(f, footer) {
+            t.Fatalf("expected footer %q, got %q", footer, f)
+        }
+        if got["sub"] != "alice" || got["n"] != json.Number("1152921504606846976") || got["exp"] != exp.Format(time.RFC3339Nano) {
+            t.Fatalf("unexpected claims %v", got)
+        }
+    }
+    a, _ := Encrypt(testKey, claims, nil)
+    b, _ := Encrypt(testKey, claims, nil)
+    if a == b {
+        t.Fatal("two tokens have the same nonce")
+    }
+    if _, err := Encrypt(testKey[1:], claims, nil); err == nil {
+        t.Fatal("expected an error for a short key")
+    }
+    if _, err := Encrypt(testKey, map[string]any{"f": func() {}}, nil); err == nil {
+        t.Fatal("expected an error for a claim without a JSON encoding")
+    }
+}
+
+func TestTime(t *testing.T) {
+    for _, tc := range []struct {
+        claims map[string]any
+        err    error
+    }{
+        {map[string]any{}, nil},
+        {map[string]any{"exp": testNow.Add(time.Second)}, nil},
+        {map[string]any{"exp": testNow}, ErrExpired},
+        {map[string]any{"exp": testNow.Add(-time.Hour)}, ErrExpired},
+        {map[string]any{"nbf": testNow}, nil},
+        {map[string]any{"nbf": testNow.Add(time.Second)}, ErrNotYetValid},
+        {map[string]any{"nbf": testNow.Add(-time.Hour), "exp": testNow.Add(time.Hour)}, nil},
+        {map[string]any{"exp": "2030-01-01T02:00:00+02:00"}, nil},
+        {map[string]any{"exp": "2024-12-31T23:00:00-02:00"}, nil},
+        {map[string]any{"exp": "2025-01-01T01:00:00+02:00"}, ErrExpired},
+        {map[string]any{"exp": testNow.Unix() + 60}, errClaim},
+        {map[string]any{"nbf": "yesterday"}, errClaim},
+        {map[string]any{"exp": nil}, errClaim},
+    } {
+        s, err := Encrypt(testKey, tc.claims, nil)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if _, _, err := DecryptAt(testKey, s, testNow); err != tc.err {
+            t.Errorf("%v: expected %v, got %v", tc.claims, tc.err, err)
+        }
+    }
+
+    s, err := Encrypt(testKey, map[string]any{"exp": time.Now().Add(time.Hour)}, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, _, err := Decrypt(testKey, s); err != nil {
+        t.Fatal(err)
+    }
+    if _, _, err := DecryptAt(testKey, s, time.Now().Add(2*time.Hour)); err != ErrExpired {
+        t.Fatalf("expected ErrExpired, got %v", err)
+    }
+}
+
+func TestInvalid(t *testing.T) {
+    footer := []byte("key-1")
+    s, err := Encrypt(testKey, map[string]any{"sub": "alice"}, footer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    payload, f, _ := strings.Cut(strings.TrimPrefix(s, header), ".")
+
+    other := bytes.Repeat([]byte{0x43}, KeySize)
+    otherFooter, err := Encrypt(testKey, map[string]any{"sub": "alice"}, []byte("key-2"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    p2, _, _ := strings.Cut(strings.TrimPrefix(otherFooter, header), ".")
+    for _, tc := range []struct {
+        key   []byte
+        token string
+    }{
+        {other, s},
+        {testKey, ""},
+        {testKey, "v1a.local."},
+        {testKey, "v1a.public." + payload + "." + f},
+        {testKey, "v2.local." + payload + "." + f},
+        {testKey, "V1A.LOCAL." + payload + "." + f},
+        // The footer is authenticated, and so is its absence.
+        {testKey, header + payload},
+        {testKey, header + payload + "." + b64.EncodeToString([]byte("key-2"))},
+        {testKey, header + p2 + "." + f},
+        {testKey, header + payload + "."},
+        {testKey, header + payload + "." + f + "."},
+        {testKey, header + payload + "=." + f},
+        {testKey, header + payload[:len(payload)-1] + "." + f},
+        {testKey, header + payload[:42] + "." + f},
+        {testKey, header + "+" + payload[1:] + "." + f},
+    } {
+        if _, _, err := DecryptAt(tc.key, tc.token, testNow); err != ErrInvalidToken {
+            t.Errorf("%q: expected ErrInvalidToken, got %v", tc.token, err)
+        }
+    }
+
+    raw, err := b64.DecodeString(payload)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := range raw {
+        raw[i] ^= 0x80
+        m := header + b64.EncodeToString(raw) + "." + f
+        if _, _, err := DecryptAt(testKey, m, testNow); err != ErrInvalidToken {
+            t.Fatalf("byte %d: expected ErrInvalidToken, got %v", i, err)
+        }
+        raw[i] ^= 0x80
+    }
+
+    // A token with claims that are not a JSON object, sealed as
+    // Encrypt would.
+    for _, m := range []string{"null", "[]", `"sub"`, `{"sub":"alice"} {}`, `{`} {
+        s, err := encrypt(testKey, make([]byte, 16), []byte(m), nil)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if _, _, err := DecryptAt(testKey, s, testNow); err != ErrInvalidToken {
+            t.Errorf("%s: expected ErrInvalidToken, got %v", m, err)
+        }
+    }
+    if _, _, err := DecryptAt(testKey[1:], s, testNow); err == nil || err == ErrInvalidToken {
+        t.Fatalf("expected a key size error, got %v", err)
+    }
+}
@@ -0,0 +1,157 @@
+// Package treehash implements a parallel tree hash built on
+// Ascon-Hash256.
+//
+// The input is split into leaves of a fixed size, which are
+// hashed independently and then combined pairwise into a
+// binary tree, so a large input can be hashed on several cores
+// at once. The digest only depends on the input and the leaf
+// size, never on the number of workers.
+//
+// The input of length L is split into leaves of leafSize bytes;
+// the last leaf may be shorter, and an empty input is a single
+// empty leaf. The digest is that of the Merkle tree of the
+// leaves defined in internal/merkle, framed by the parameters
+// leafSize and L, so that the same input hashed with different
+// leaf sizes yields unrelated digests.
+package treehash
+
+import (
+    "io"
+    "hash"
+    "runtime"
+    "sync"
+
+    "github.com/pedroalbanese/go-ascon"
+    "github.com/pedroalbanese/go-ascon/internal/merkle"
+)
+
+const (
+    // Size is the size in bytes of a tree hash digest.
+    Size = ascon.HashSize
+    // DefaultLeafSize is the leaf size used by SumTree.
+    DefaultLeafSize = 1 << 20
+)
+
+type tree struct {
+    leafSize int
+    workers  int
+    // buf holds the input that has not been hashed yet, up to
+    // one leaf per worker.
+    buf []byte
+    // leaves holds the leaves hashed so far.
+    leaves merkle.Tree
+    // hashes is scratch space for the leaf hashes of a batch.
+    hashes []merkle.Node
+}
+
+var _ hash.Hash = (*tree)(nil)
+
+// NewTree returns a new hash.Hash computing the tree hash with
+// the given leaf size, hashing up to workers leaves in
+// parallel. If workers is zero or negative, runtime.GOMAXPROCS
+// is used.
+//
+// NewTree panics if leafSize is not positive.
+func NewTree(leafSize, workers int) hash.Hash {
+    if leafSize <= 0 {
+        panic("treehash: leaf size must be positive")
+    }
+    if workers <= 0 {
+        workers = runtime.GOMAXPROCS(0)
+    }
+    return &tree{
+        leafSize: leafSize,
+        workers:  workers,
+        buf:      make([]byte, 0, leafSize*workers),
+        hashes:   make([]merkle.Node, workers),
+    }
+}
+
+// SumTree returns the tree hash of the data read from r until
+// EOF, using DefaultLeafSize and runtime.GOMAXPROCS workers.
+func SumTree(r io.Reader) ([Size]byte, error) {
+    var d [Size]byte
+    t := NewTree(DefaultLeafSize, 0)
+    if _, err := io.Copy(t, r); err != nil {
+        return d, err
+    }
+    t.Sum(d[:0])
+    return d, nil
+}
+
+func (t *tree) Write(p []byte) (int, error) {
+    n := len(p)
+    for len(p) > 0 {
+        m := copy(t.buf[len(t.buf):cap(t.buf)], p)
+        t.buf = t.buf[:len(t.buf)+m]
+        p = p[m:]
+        if len(t.buf) == cap(t.buf) {
+            t.hashLeaves(&t.leaves, t.buf)
+            t.buf = t.buf[:0]
+        }
+    }
+    return n, nil
+}
+
+func (t *tree) Sum(b []byte) []byte {
+    // Work on a copy so that the caller can keep writing.
+    leaves := t.leaves.Clone()
+    if len(t.buf) > 0 || leaves.Len() == 0 {
+        t.hashLeaves(leaves, t.buf)
+    }
+    root := leaves.Root()
+    total := t.leaves.Len()*uint64(t.leafSize) + uint64(len(t.buf))
+    d := merkle.Digest(&root, uint64(t.leafSize), total)
+    return append(b, d[:]...)
+}
+
+func (t *tree) Reset() {
+    t.buf = t.buf[:0]
+    t.leaves.Reset()
+}
+
+func (t *tree) Size() int {
+    return Size
+}
+
+func (t *tree) BlockSize() int {
+    return t.leafSize
+}
+
+// hashLeaves splits data into leaves, hashes them in parallel
+// and pushes them onto leaves. At most one leaf per worker may
+// be passed at a time; an empty data is a single empty leaf.
+func (t *tree) hashLeaves(leaves *merkle.Tree, data []byte) {
+    n := (len(data) + t.leafSize - 1) / t.leafSize
+    if n == 0 {
+        n = 1
+    }
+    first := leaves.Len()
+    hashes := t.hashes[:n]
+    leaf := func(i int) {
+        lo := i * t.leafSize
+        hi := lo + t.leafSize
+        if hi > len(data) {
+            hi = len(data)
+        }
+        hashes[i] = merkle.Leaf(first+uint64(i), data[lo:hi])
+    }
+
+    if n == 1 {
+        leaf(0)
+    } else {
+        var wg sync.WaitGroup
+        wg.Add(n)
+        for i := 0; i < n; i++ {
+            go func(i int) {
+                defer wg.Done()
+                leaf(i)
+            }(i)
+        }
+        wg.Wait()
+    }
+
+    for i := range hashes {
+        leaves.Push(hashes[i])
+    }
+}
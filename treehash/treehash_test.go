@@ -0,0 +1,127 @@
+package treehash
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/hex"
+    "math/rand"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+func h(parts ...[]byte) []byte {
+    d := ascon.NewHash256()
+    for _, p := range parts {
+        d.Write(p)
+    }
+    return d.Sum(nil)
+}
+
+func be64(x int) []byte {
+    return binary.BigEndian.AppendUint64(nil, uint64(x))
+}
+
+// reference is a direct, sequential transcription of the tree
+// layout in the package documentation.
+func reference(data []byte, leafSize int) []byte {
+    var level [][]byte
+    for i := 0; i == 0 || i*leafSize < len(data); i++ {
+        hi := (i + 1) * leafSize
+        if hi > len(data) {
+            hi = len(data)
+        }
+        level = append(level, h([]byte{0x00}, be64(i), data[i*leafSize:hi]))
+    }
+    for len(level) > 1 {
+        var next [][]byte
+        for i := 0; i+1 < len(level); i += 2 {
+            next = append(next, h([]byte{0x01}, level[i], level[i+1]))
+        }
+        if len(level)%2 == 1 {
+            next = append(next, level[len(level)-1])
+        }
+        level = next
+    }
+    return h([]byte{0x02}, be64(leafSize), be64(len(data)), level[0])
+}
+
+func TestTree(t *testing.T) {
+    rng := rand.New(rand.NewSource(0xDEADBEEF))
+    data := make([]byte, 1000)
+    rng.Read(data)
+    for _, leafSize := range []int{1, 7, 64} {
+        for _, size := range []int{0, 1, 6, 7, 8, 63, 64, 65, 448, 1000} {
+            want := reference(data[:size], leafSize)
+            for _, workers := range []int{1, 2, 3, 8} {
+                tr := NewTree(leafSize, workers)
+                for p := data[:size]; len(p) > 0; {
+                    n := rng.Intn(100) + 1
+                    if n > len(p) {
+                        n = len(p)
+                    }
+                    tr.Write(p[:n])
+                    p = p[n:]
+                }
+                if got := tr.Sum(nil); !bytes.Equal(got, want) {
+                    t.Fatalf("%d/%d/%d: expected %x, got %x", leafSize, size, workers, want, got)
+                }
+                // Sum must not change the state.
+                if got := tr.Sum(nil); !bytes.Equal(got, want) {
+                    t.Fatalf("%d/%d/%d: second Sum: expected %x, got %x", leafSize, size, workers, want, got)
+                }
+                tr.Reset()
+                tr.Write(data[:size])
+                if got := tr.Sum(nil); !bytes.Equal(got, want) {
+                    t.Fatalf("%d/%d/%d: Reset: expected %x, got %x", leafSize, size, workers, want, got)
+                }
+            }
+        }
+    }
+}
+
+func TestVectors(t *testing.T) {
+    data := make([]byte, 3000)
+    for i := range data {
+        data[i] = byte(i)
+    }
+    for _, tc := range []struct {
+        size, leafSize int
+        digest         string
+    }{
+        {0, 1024, "ab6d865843bdda26ae2a997cfa3fff13c211b20acd3edd9ea8ec1d5bd1d3d37b"},
+        {1, 1024, "9b73164cc8c4b0c301dc37d0a3a3e85998417afaa1faf0de778c5184ae28fd21"},
+        {3000, 1024, "12dc48c8a92f9caf96a0d61a6eb0499ae7239b05297db3da1eb4191feca404d5"},
+        {3000, DefaultLeafSize, "73ad77eb4323169bad2550613223868156442a137c9fc0df74442b3f05d6f6b2"},
+    } {
+        tr := NewTree(tc.leafSize, 0)
+        tr.Write(data[:tc.size])
+        got := hex.EncodeToString(tr.Sum(nil))
+        if got != tc.digest {
+            t.Fatalf("%d/%d: expected %s, got %s", tc.size, tc.leafSize, tc.digest, got)
+        }
+    }
+
+    d, err := SumTree(bytes.NewReader(data))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := reference(data, DefaultLeafSize); !bytes.Equal(d[:], want) {
+        t.Fatalf("SumTree: expected %x, got %x", want, d)
+    }
+}
+
+func BenchmarkTree(b *testing.B) {
+    data := make([]byte, 8<<20)
+    for _, workers := range []int{1, 0} {
+        b.Run(map[int]string{1: "1", 0: "GOMAXPROCS"}[workers], func(b *testing.B) {
+            b.SetBytes(int64(len(data)))
+            tr := NewTree(64<<10, workers)
+            for i := 0; i < b.N; i++ {
+                tr.Reset()
+                tr.Write(data)
+                tr.Sum(nil)
+            }
+        })
+    }
+}
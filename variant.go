@@ -0,0 +1,107 @@
+package ascon
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "crypto/cipher"
+)
+
+// Variant identifies an ASCON AEAD variant.
+//
+// Variants are small integers that are stable across releases,
+// so they can be stored in configuration files or message
+// headers.
+type Variant uint8
+
+const (
+    // Ascon128 is ASCON-128, created by New128.
+    Ascon128 Variant = 1 + iota
+    // Ascon128a is ASCON-128a, created by New128a.
+    Ascon128a
+    // Ascon80pq is ASCON-80pq, created by New80pq.
+    Ascon80pq
+    // AsconAEAD128 is Ascon-AEAD128 from NIST SP 800-232,
+    // created by NewAEAD128.
+    AsconAEAD128
+)
+
+var variantNames = [...]string{
+    Ascon128:     "Ascon-128",
+    Ascon128a:    "Ascon-128a",
+    Ascon80pq:    "Ascon-80pq",
+    AsconAEAD128: "Ascon-AEAD128",
+}
+
+// String returns the name of the variant, such as
+// "Ascon-128a".
+func (v Variant) String() string {
+    if v == 0 || int(v) >= len(variantNames) {
+        return "Variant(" + strconv.Itoa(int(v)) + ")"
+    }
+    return variantNames[v]
+}
+
+var variantKeySizes = [...]int{
+    Ascon128:     KeySize,
+    Ascon128a:    KeySize,
+    Ascon80pq:    KeySize80pq,
+    AsconAEAD128: KeySize,
+}
+
+// KeySize returns the size in bytes of the keys of the variant,
+// or 0 if it is unknown.
+func (v Variant) KeySize() int {
+    if int(v) >= len(variantKeySizes) {
+        return 0
+    }
+    return variantKeySizes[v]
+}
+
+// ParseVariant returns the variant named s, as returned by
+// Variant.String. The comparison is case-insensitive.
+func ParseVariant(s string) (Variant, error) {
+    for v, name := range variantNames {
+        if v != 0 && strings.EqualFold(s, name) {
+            return Variant(v), nil
+        }
+    }
+    return 0, errors.New("ascon: unknown variant " + strconv.Quote(s))
+}
+
+// Variant returns the variant of a, whose String method gives
+// a stable name such as "Ascon-128a" for logs and metrics. It
+// returns 0 after Wipe.
+func (a *AEAD) Variant() Variant {
+    switch a.iv {
+    case iv128:
+        return Ascon128
+    case iv128a:
+        return Ascon128a
+    case iv80pq:
+        return Ascon80pq
+    case ivAEAD128:
+        return AsconAEAD128
+    default:
+        return 0
+    }
+}
+
+// KeySize returns the size in bytes of the key of a, KeySize or
+// KeySize80pq.
+func (a *AEAD) KeySize() int {
+    return a.Variant().KeySize()
+}
+
+// VariantOf returns the variant of aead and true if it is an
+// AEAD of this package, possibly wrapped by NewLimited, and
+// false otherwise.
+func VariantOf(aead cipher.AEAD) (Variant, bool) {
+    if l, ok := aead.(*Limited); ok {
+        aead = l.AEAD
+    }
+    if a, ok := aead.(interface{ Variant() Variant }); ok {
+        return a.Variant(), a.Variant() != 0
+    }
+    return 0, false
+}
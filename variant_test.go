@@ -0,0 +1,98 @@
+package ascon
+
+import (
+    "bytes"
+    "crypto/aes"
+    "crypto/cipher"
+    "testing"
+)
+
+func TestVariant(t *testing.T) {
+    nonce := make([]byte, NonceSize)
+    pt := []byte("plaintext")
+    for _, tc := range []struct {
+        v       Variant
+        name    string
+        keySize int
+        fn      func([]byte) (cipher.AEAD, error)
+    }{
+        {Ascon128, "Ascon-128", KeySize, New128},
+        {Ascon128a, "Ascon-128a", KeySize, New128a},
+        {Ascon80pq, "Ascon-80pq", KeySize80pq, New80pq},
+        {AsconAEAD128, "Ascon-AEAD128", KeySize, NewAEAD128},
+    } {
+        if got := tc.v.String(); got != tc.name {
+            t.Fatalf("expected %q, got %q", tc.name, got)
+        }
+        for _, s := range []string{tc.name, "ascon-" + tc.name[6:]} {
+            v, err := ParseVariant(s)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if v != tc.v {
+                t.Fatalf("%q: expected %v, got %v", s, tc.v, v)
+            }
+        }
+
+        key := make([]byte, tc.keySize)
+        want, err := tc.fn(key)
+        if err != nil {
+            t.Fatal(err)
+        }
+        got, err := New(key, WithVariant(tc.v))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !bytes.Equal(got.Seal(nil, nonce, pt, nil), want.Seal(nil, nonce, pt, nil)) {
+            t.Fatalf("%v: output mismatch", tc.v)
+        }
+        if _, err := New(key[1:], WithVariant(tc.v)); err == nil {
+            t.Fatalf("%v: expected an error", tc.v)
+        }
+
+        if tc.v.KeySize() != tc.keySize {
+            t.Fatalf("%v: expected a key size of %d, got %d", tc.v, tc.keySize, tc.v.KeySize())
+        }
+        a := want.(*AEAD)
+        if a.Variant() != tc.v || a.KeySize() != tc.keySize {
+            t.Fatalf("%v: got %v with a key size of %d", tc.v, a.Variant(), a.KeySize())
+        }
+        for _, aead := range []cipher.AEAD{want, NewLimited(want, 100, 1)} {
+            if v, ok := VariantOf(aead); !ok || v != tc.v {
+                t.Fatalf("%v: VariantOf: got %v, %v", tc.v, v, ok)
+            }
+        }
+        a.Wipe()
+        if v, ok := VariantOf(a); ok || v != 0 {
+            t.Fatalf("%v: VariantOf after Wipe: got %v, %v", tc.v, v, ok)
+        }
+    }
+
+    for _, s := range []string{"", "Ascon", "Ascon-128b", "Variant(0)"} {
+        if _, err := ParseVariant(s); err == nil {
+            t.Fatalf("%q: expected an error", s)
+        }
+    }
+    for _, v := range []Variant{0, AsconAEAD128 + 1} {
+        if _, err := New(make([]byte, KeySize), WithVariant(v)); err == nil {
+            t.Fatalf("%v: expected an error", v)
+        }
+    }
+    block, err := aes.NewCipher(make([]byte, 16))
+    if err != nil {
+        t.Fatal(err)
+    }
+    gcm, err := cipher.NewGCM(block)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, ok := VariantOf(gcm); ok {
+        t.Fatal("VariantOf accepted an AEAD of another package")
+    }
+    if n := (AsconAEAD128 + 1).KeySize(); n != 0 {
+        t.Fatalf("expected a key size of 0, got %d", n)
+    }
+    if got := Variant(0).String(); got != "Variant(0)" {
+        t.Fatalf("expected %q, got %q", "Variant(0)", got)
+    }
+}
@@ -0,0 +1,111 @@
+#!/usr/bin/env python3
+"""Checks the vector files of this directory against pyascon.
+
+Usage: check_pyascon.py [-stamp] PYASCON_DIR [FILE ...]
+
+PYASCON_DIR is a git checkout of https://github.com/meichlseder/pyascon.
+Every record of every file (by default, all *.txt files here) is
+recomputed with its ascon.py. The first mismatch of a file is
+reported with the file name and count, so the first failing file
+names the primitive that diverged.
+
+With -stamp, the "pyascon:" header line of each file whose records
+all match is set to the commit of the checkout. Files of primitives
+that the checkout does not implement are reported as skipped and left
+unchecked.
+"""
+
+import glob
+import os
+import subprocess
+import sys
+
+
+def read_file(path):
+    header, records = {}, []
+    with open(path) as f:
+        for line in f:
+            line = line.strip()
+            if not line:
+                continue
+            if line.startswith("#"):
+                key, sep, value = line[1:].partition(":")
+                if sep and not records:
+                    header[key.strip()] = value.strip()
+                continue
+            name, _, value = line.partition("=")
+            name, value = name.strip(), value.strip()
+            if name == "Count":
+                records.append({"Count": int(value)})
+            else:
+                records[-1][name] = bytes.fromhex(value)
+    return header, records
+
+
+def compute(ascon, primitive, r):
+    if primitive == "Ascon-80pq":
+        return "CT", ascon.ascon_encrypt(r["Key"], r["Nonce"], r["AD"], r["PT"], variant=primitive)
+    if "Msg" in r and "Key" not in r:
+        if "Z" in r:
+            return "MD", ascon.ascon_hash(r["Msg"], variant=primitive, hashlength=len(r["MD"]), customization=r["Z"])
+        return "MD", ascon.ascon_hash(r["Msg"], variant=primitive, hashlength=len(r["MD"]))
+    return "Tag", ascon.ascon_mac(r["Key"], r["Msg"], variant=primitive, taglength=len(r["Tag"]))
+
+
+def check(ascon, path):
+    """Returns True if every record matches, False on a mismatch and
+    None if pyascon does not implement the primitive."""
+    name = os.path.basename(path)
+    header, records = read_file(path)
+    primitive = header["primitive"]
+    for r in records:
+        try:
+            field, got = compute(ascon, primitive, r)
+        except (AssertionError, TypeError, ValueError) as e:
+            print("%s: skipped, pyascon does not implement %s (%s)" % (name, primitive, e))
+            return None
+        if got != r[field]:
+            print("%s: Count %d: expected %s %s, got %s" % (name, r["Count"], field, r[field].hex().upper(), got.hex().upper()))
+            return False
+    print("%s: %d records match" % (name, len(records)))
+    return True
+
+
+def stamp(path, commit):
+    with open(path) as f:
+        lines = f.readlines()
+    for i, line in enumerate(lines):
+        if line.startswith("# pyascon:"):
+            lines[i] = "# pyascon: %s\n" % commit
+            break
+    with open(path, "w") as f:
+        f.writelines(lines)
+
+
+def main(args):
+    do_stamp = args[:1] == ["-stamp"]
+    if do_stamp:
+        args = args[1:]
+    if not args:
+        print(__doc__, file=sys.stderr)
+        return 2
+    pyascon, files = args[0], args[1:]
+    if not files:
+        files = sorted(glob.glob(os.path.join(os.path.dirname(os.path.abspath(__file__)), "*.txt")))
+    commit = subprocess.check_output(["git", "-C", pyascon, "rev-parse", "HEAD"], text=True).strip()
+    sys.path.insert(0, pyascon)
+    import ascon
+
+    print("pyascon %s" % commit)
+    status = 0
+    for path in files:
+        ok = check(ascon, path)
+        if ok is False:
+            status = 1
+        elif ok and do_stamp:
+            stamp(path, commit)
+    return status
+
+
+if __name__ == "__main__":
+    sys.exit(main(sys.argv[1:]))
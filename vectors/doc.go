@@ -0,0 +1,18 @@
+// Package vectors holds test vectors of Ascon-80pq, the hash and
+// XOF functions and the MAC and PRF modes, for checking other
+// implementations, such as pyascon and the reference C code,
+// against this module byte for byte. Only the files checked
+// against pyascon are committed; none is yet.
+//
+// Each primitive has its own file, so that a mismatch points at
+// the primitive that diverged, and each record of a file holds
+// all of its inputs and outputs, so that it can be checked on
+// its own. README.md describes the format. The tests of this
+// package check the files against the module.
+//
+// gen.go writes the files, and check_pyascon.py checks them
+// against a checkout of pyascon and records its commit in their
+// headers.
+package vectors
+
+//go:generate go run gen.go
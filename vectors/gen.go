@@ -0,0 +1,199 @@
+//go:build ignore
+
+// This program generates the vector files of this directory
+// with the module's implementations. Run it with go generate.
+// Every file it writes is marked unchecked against pyascon,
+// until check_pyascon.py -stamp checks it again.
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "log"
+    "os"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// seq returns the n bytes 00 01 02 ..., as in the reference
+// genkat programs.
+func seq(n int) []byte {
+    b := make([]byte, n)
+    for i := range b {
+        b[i] = byte(i)
+    }
+    return b
+}
+
+// file is a vector file being written.
+type file struct {
+    name  string
+    b     bytes.Buffer
+    count int
+}
+
+// newFile starts the file name of vectors of primitive, which
+// names a pyascon variant.
+func newFile(name, title, primitive string) *file {
+    f := &file{name: name}
+    fmt.Fprintf(&f.b, "# %s\n", title)
+    fmt.Fprintf(&f.b, "# primitive: %s\n", primitive)
+    fmt.Fprintf(&f.b, "# generator: vectors/gen.go\n")
+    fmt.Fprintf(&f.b, "# pyascon: unchecked\n")
+    return f
+}
+
+// record writes a record of the fields and values, alternately
+// names and byte slices.
+func (f *file) record(fields ...any) {
+    f.count++
+    fmt.Fprintf(&f.b, "\nCount = %d\n", f.count)
+    for i := 0; i < len(fields); i += 2 {
+        fmt.Fprintf(&f.b, "%s = %X\n", fields[i], fields[i+1])
+    }
+}
+
+func (f *file) write() {
+    if err := os.WriteFile(f.name, f.b.Bytes(), 0o644); err != nil {
+        log.Fatal(err)
+    }
+}
+
+func must[T any](v T, err error) T {
+    if err != nil {
+        log.Fatal(err)
+    }
+    return v
+}
+
+// lengths are the message lengths of the vectors: every length
+// around the 8- and 16-byte rates, and a few longer ones.
+var lengths = []int{0, 1, 2, 7, 8, 9, 15, 16, 17, 23, 24, 25, 31, 32, 33, 63, 64, 65, 255, 1024}
+
+func aead80pq() {
+    f := newFile("aead_ascon80pq.txt", "Ascon-80pq authenticated encryption", "Ascon-80pq")
+    key, nonce := seq(ascon.KeySize80pq), seq(ascon.NonceSize)
+    aead := must(ascon.New80pq(key))
+    for _, n := range lengths {
+        for _, m := range []int{0, 1, 8, 17} {
+            pt, ad := seq(n), seq(m)
+            f.record("Key", key, "Nonce", nonce, "PT", pt, "AD", ad, "CT", aead.Seal(nil, nonce, pt, ad))
+        }
+    }
+    // Keys and nonces whose every byte matters.
+    for i := 0; i < 4; i++ {
+        k, n := seq(ascon.KeySize80pq), seq(ascon.NonceSize)
+        k[len(k)-1-i] ^= 0x80
+        n[i] ^= 0x01
+        aead := must(ascon.New80pq(k))
+        f.record("Key", k, "Nonce", n, "PT", seq(16), "AD", seq(16), "CT", aead.Seal(nil, n, seq(16), seq(16)))
+    }
+    f.write()
+}
+
+func hashes() {
+    for _, h := range []struct {
+        name, title, primitive string
+        sum                    func([]byte) []byte
+    }{
+        {"hash_ascon-hash.txt", "Ascon-Hash", "Ascon-Hash", func(b []byte) []byte {
+            x := ascon.NewHash()
+            x.Write(b)
+            return x.Sum(nil)
+        }},
+        {"hash_ascon-hasha.txt", "Ascon-Hasha", "Ascon-Hasha", func(b []byte) []byte {
+            s := ascon.SumHasha(b)
+            return s[:]
+        }},
+        {"hash_ascon-hash256.txt", "Ascon-Hash256 (SP 800-232)", "Ascon-Hash256", func(b []byte) []byte {
+            x := ascon.NewHash256()
+            x.Write(b)
+            return x.Sum(nil)
+        }},
+    } {
+        f := newFile(h.name, h.title, h.primitive)
+        for _, n := range lengths {
+            f.record("Msg", seq(n), "MD", h.sum(seq(n)))
+        }
+        f.write()
+    }
+}
+
+// outLengths are the output lengths of the XOF and PRF vectors.
+var outLengths = []int{1, 15, 16, 17, 32, 33, 64, 100}
+
+func xofs() {
+    for _, x := range []struct {
+        name, title, primitive string
+        new                    func() *ascon.XOF
+    }{
+        {"xof_ascon-xof.txt", "Ascon-Xof", "Ascon-Xof", ascon.NewXOF},
+        {"xof_ascon-xofa.txt", "Ascon-Xofa", "Ascon-Xofa", ascon.NewXOFa},
+        {"xof_ascon-xof128.txt", "Ascon-XOF128 (SP 800-232)", "Ascon-XOF128", ascon.NewXOF128},
+    } {
+        f := newFile(x.name, x.title, x.primitive)
+        for _, n := range lengths {
+            for _, l := range outLengths {
+                d := x.new()
+                d.Write(seq(n))
+                md := make([]byte, l)
+                d.Read(md)
+                f.record("Msg", seq(n), "MD", md)
+            }
+        }
+        f.write()
+    }
+
+    f := newFile("xof_ascon-cxof128.txt", "Ascon-CXOF128 (SP 800-232)", "Ascon-CXOF128")
+    for _, z := range []int{0, 1, 8, 16, 17, 64, 256} {
+        for _, n := range []int{0, 1, 16, 33} {
+            for _, l := range []int{16, 32, 33} {
+                d := must(ascon.NewCXOF128(seq(z)))
+                d.Write(seq(n))
+                md := make([]byte, l)
+                d.Read(md)
+                f.record("Z", seq(z), "Msg", seq(n), "MD", md)
+            }
+        }
+    }
+    f.write()
+}
+
+func macs() {
+    key := seq(ascon.KeySize)
+
+    f := newFile("mac_ascon-mac.txt", "Ascon-Mac", "Ascon-Mac")
+    for _, n := range lengths {
+        m := must(ascon.NewMAC(key))
+        m.Write(seq(n))
+        f.record("Key", key, "Msg", seq(n), "Tag", m.Sum(nil))
+    }
+    f.write()
+
+    f = newFile("mac_ascon-prf.txt", "Ascon-Prf", "Ascon-Prf")
+    for _, n := range lengths {
+        for _, l := range outLengths {
+            p := must(ascon.NewPRF(key))
+            p.Write(seq(n))
+            tag := make([]byte, l)
+            p.Read(tag)
+            f.record("Key", key, "Msg", seq(n), "Tag", tag)
+        }
+    }
+    f.write()
+
+    f = newFile("mac_ascon-prfshort.txt", "Ascon-PrfShort", "Ascon-PrfShort")
+    for n := 0; n <= 16; n++ {
+        for _, l := range []int{1, 8, 15, 16} {
+            f.record("Key", key, "Msg", seq(n), "Tag", must(ascon.PRFShort(key, seq(n), l)))
+        }
+    }
+    f.write()
+}
+
+func main() {
+    aead80pq()
+    hashes()
+    xofs()
+    macs()
+}
@@ -0,0 +1,283 @@
+package vectors
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/pedroalbanese/go-ascon"
+)
+
+// record is a record of a vector file: its count and its fields
+// in order.
+type record struct {
+    count  int
+    names  []string
+    fields map[string][]byte
+}
+
+// file is a parsed vector file.
+type file struct {
+    header  map[string]string
+    records []record
+}
+
+// readFile parses a vector file, or a reference KAT file, which
+// has no header.
+func readFile(path string) (*file, error) {
+    fh, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer fh.Close()
+
+    f := &file{header: map[string]string{}}
+    s := bufio.NewScanner(fh)
+    s.Buffer(nil, 1<<20)
+    for n := 1; s.Scan(); n++ {
+        t := s.Text()
+        if t == "" {
+            continue
+        }
+        if strings.HasPrefix(t, "#") {
+            if k, v, ok := strings.Cut(t[1:], ":"); ok && len(f.records) == 0 {
+                f.header[strings.TrimSpace(k)] = strings.TrimSpace(v)
+            }
+            continue
+        }
+        name, value, ok := strings.Cut(t, "=")
+        name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+        if !ok {
+            return nil, fmt.Errorf("%s: malformed line %d: %q", path, n, t)
+        }
+        if name == "Count" {
+            c, err := strconv.Atoi(value)
+            if err != nil {
+                return nil, fmt.Errorf("%s: malformed line %d: %v", path, n, err)
+            }
+            f.records = append(f.records, record{count: c, fields: map[string][]byte{}})
+            continue
+        }
+        if len(f.records) == 0 {
+            return nil, fmt.Errorf("%s: malformed line %d: %q", path, n, t)
+        }
+        buf, err := hex.DecodeString(value)
+        if err != nil {
+            return nil, fmt.Errorf("%s: malformed line %d: %v", path, n, err)
+        }
+        r := &f.records[len(f.records)-1]
+        if _, ok := r.fields[name]; ok {
+            return nil, fmt.Errorf("%s: duplicate field on line %d: %q", path, n, t)
+        }
+        r.names = append(r.names, name)
+        r.fields[name] = buf
+    }
+    return f, s.Err()
+}
+
+// primitive computes the output of a record of one primitive
+// from its inputs.
+type primitive struct {
+    // output is the name of the output field.
+    output string
+    // compute returns the output, of the length of want.
+    compute func(r record, want int) ([]byte, error)
+    // reference is the reference KAT file of the primitive,
+    // whose records with the same inputs must agree.
+    reference string
+}
+
+func sum(x interface{ Write([]byte) (int, error) }, msg []byte, out func() []byte) []byte {
+    x.Write(msg)
+    return out()
+}
+
+func read(x interface {
+    Write([]byte) (int, error)
+    Read([]byte) (int, error)
+}, msg []byte, n int) []byte {
+    x.Write(msg)
+    b := make([]byte, n)
+    x.Read(b)
+    return b
+}
+
+var primitives = map[string]primitive{
+    "Ascon-80pq": {"CT", func(r record, _ int) ([]byte, error) {
+        aead, err := ascon.New80pq(r.fields["Key"])
+        if err != nil {
+            return nil, err
+        }
+        return aead.Seal(nil, r.fields["Nonce"], r.fields["PT"], r.fields["AD"]), nil
+    }, "../ascontest/kat/ascon80pq.txt"},
+    "Ascon-Hash": {"MD", func(r record, _ int) ([]byte, error) {
+        h := ascon.NewHash()
+        return sum(h, r.fields["Msg"], func() []byte { return h.Sum(nil) }), nil
+    }, "../testdata/vectors_hash.txt"},
+    "Ascon-Hasha": {"MD", func(r record, _ int) ([]byte, error) {
+        h := ascon.NewHasha()
+        return sum(h, r.fields["Msg"], func() []byte { return h.Sum(nil) }), nil
+    }, "../testdata/vectors_hasha.txt"},
+    "Ascon-Hash256": {"MD", func(r record, _ int) ([]byte, error) {
+        h := ascon.NewHash256()
+        return sum(h, r.fields["Msg"], func() []byte { return h.Sum(nil) }), nil
+    }, "../testdata/vectors_hash256.txt"},
+    "Ascon-Xof": {"MD", func(r record, n int) ([]byte, error) {
+        return read(ascon.NewXOF(), r.fields["Msg"], n), nil
+    }, "../testdata/vectors_xof.txt"},
+    "Ascon-Xofa": {"MD", func(r record, n int) ([]byte, error) {
+        return read(ascon.NewXOFa(), r.fields["Msg"], n), nil
+    }, "../testdata/vectors_xofa.txt"},
+    "Ascon-XOF128": {"MD", func(r record, n int) ([]byte, error) {
+        return read(ascon.NewXOF128(), r.fields["Msg"], n), nil
+    }, "../testdata/vectors_xof128.txt"},
+    "Ascon-CXOF128": {"MD", func(r record, n int) ([]byte, error) {
+        x, err := ascon.NewCXOF128(r.fields["Z"])
+        if err != nil {
+            return nil, err
+        }
+        return read(x, r.fields["Msg"], n), nil
+    }, "../testdata/vectors_cxof128.txt"},
+    "Ascon-Mac": {"Tag", func(r record, _ int) ([]byte, error) {
+        m, err := ascon.NewMAC(r.fields["Key"])
+        if err != nil {
+            return nil, err
+        }
+        return sum(m, r.fields["Msg"], func() []byte { return m.Sum(nil) }), nil
+    }, "../testdata/vectors_mac.txt"},
+    "Ascon-Prf": {"Tag", func(r record, n int) ([]byte, error) {
+        p, err := ascon.NewPRF(r.fields["Key"])
+        if err != nil {
+            return nil, err
+        }
+        return read(p, r.fields["Msg"], n), nil
+    }, "../testdata/vectors_prf.txt"},
+    "Ascon-PrfShort": {"Tag", func(r record, n int) ([]byte, error) {
+        return ascon.PRFShort(r.fields["Key"], r.fields["Msg"], n)
+    }, "../testdata/vectors_prfshort.txt"},
+}
+
+// inputs returns the inputs of r, all of its fields but output,
+// and the length of the output, as a map key.
+func inputs(r record, output string) string {
+    names := append([]string(nil), r.names...)
+    sort.Strings(names)
+    var b strings.Builder
+    for _, name := range names {
+        if name != output {
+            fmt.Fprintf(&b, "%s=%x;", name, r.fields[name])
+        }
+    }
+    fmt.Fprintf(&b, "%d", len(r.fields[output]))
+    return b.String()
+}
+
+// TestVectors checks the records of every vector file of the
+// directory against the module. Only the files stamped by
+// check_pyascon.py are committed, so the directory can be empty.
+func TestVectors(t *testing.T) {
+    paths, err := filepath.Glob("*.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, path := range paths {
+        t.Run(path, func(t *testing.T) {
+            f, err := readFile(path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            name := f.header["primitive"]
+            p, ok := primitives[name]
+            if !ok {
+                t.Fatalf("unknown primitive %q", name)
+            }
+            if len(f.records) == 0 {
+                t.Fatal("no records")
+            }
+            for i, r := range f.records {
+                if r.count != i+1 {
+                    t.Fatalf("record %d has count %d", i+1, r.count)
+                }
+                want, ok := r.fields[p.output]
+                if !ok {
+                    t.Fatalf("Count %d: no %s", r.count, p.output)
+                }
+                got, err := p.compute(r, len(want))
+                if err != nil {
+                    t.Fatalf("Count %d: %v", r.count, err)
+                }
+                if !bytes.Equal(got, want) {
+                    t.Errorf("Count %d: expected %s %X, got %X", r.count, p.output, want, got)
+                }
+            }
+        })
+    }
+}
+
+// TestReference checks the records whose inputs also appear in
+// the reference KAT files against them.
+func TestReference(t *testing.T) {
+    paths, err := filepath.Glob("*.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, path := range paths {
+        t.Run(path, func(t *testing.T) {
+            f, err := readFile(path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            p := primitives[f.header["primitive"]]
+            ref, err := readFile(p.reference)
+            if err != nil {
+                t.Fatal(err)
+            }
+            known := map[string][]byte{}
+            for _, r := range ref.records {
+                known[inputs(r, p.output)] = r.fields[p.output]
+            }
+            matched := 0
+            for _, r := range f.records {
+                want, ok := known[inputs(r, p.output)]
+                if !ok {
+                    continue
+                }
+                matched++
+                if !bytes.Equal(r.fields[p.output], want) {
+                    t.Errorf("Count %d: %s %X differs from %s: %X", r.count, p.output, r.fields[p.output], p.reference, want)
+                }
+            }
+            if matched == 0 {
+                t.Fatalf("no record in common with %s", p.reference)
+            }
+        })
+    }
+}
+
+// TestChecked fails for every file that check_pyascon.py -stamp
+// has not checked against pyascon, so that the vectors are not
+// taken for interoperability vectors before they are. Its header
+// must name the pyascon commit that the file matches.
+func TestChecked(t *testing.T) {
+    paths, err := filepath.Glob("*.txt")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, path := range paths {
+        f, err := readFile(path)
+        if err != nil {
+            t.Fatal(err)
+        }
+        commit := f.header["pyascon"]
+        if _, err := hex.DecodeString(commit); err != nil || len(commit) != 40 {
+            t.Errorf("%s: not checked against pyascon (pyascon: %q); run check_pyascon.py -stamp", path, commit)
+        }
+    }
+}
@@ -0,0 +1,27 @@
+package ascon
+
+import (
+    "errors"
+)
+
+// ErrWiped is returned by the opening methods of an AEAD after
+// Wipe, and is the panic value of its sealing methods.
+var ErrWiped = errors.New("ascon: AEAD used after Wipe")
+
+// Wipe zeroes the key of a and makes it unusable, so nothing is
+// ever produced under the zeroed key. Afterwards the sealing
+// methods of a panic with ErrWiped and the opening methods
+// return it. The Sessions, EncryptWriters and DecryptReaders
+// created from a before fail the same way when they reach the
+// authenticator: Session.Encrypt panics, and the others return
+// ErrWiped. Wipe may be called more than once.
+//
+// Wipe must not be called concurrently with any other use of a
+// or of the values created from it. Wiping is best effort: it
+// does not reach copies of the key made elsewhere, such as the
+// key passed to the constructor or an AEAD copied by value.
+func (a *AEAD) Wipe() {
+    a.k0, a.k1, a.k2 = 0, 0, 0
+    // An IV of zero marks the AEAD as wiped.
+    a.iv = 0
+}
@@ -0,0 +1,118 @@
+package ascon
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "sync"
+    "testing"
+)
+
+// expectWiped fails unless fn panics with ErrWiped.
+func expectWiped(t *testing.T, name string, fn func()) {
+    t.Helper()
+    defer func() {
+        if err, _ := recover().(error); !errors.Is(err, ErrWiped) {
+            t.Fatalf("%s: expected a panic with %v, got %v", name, ErrWiped, err)
+        }
+    }()
+    fn()
+}
+
+func TestWipe(t *testing.T) {
+    for _, tc := range sessionTests {
+        t.Run(tc.name, func(t *testing.T) {
+            key := bytes.Repeat([]byte{0xa5}, tc.key)
+            c, err := tc.fn(key)
+            if err != nil {
+                t.Fatal(err)
+            }
+            a := c.(*AEAD)
+            nonce := make([]byte, NonceSize)
+            pt, ad := []byte("a secret message, longer than a block"), []byte("ad")
+            ct := a.Seal(nil, nonce, pt, ad)
+
+            // Streams started before Wipe fail at the end.
+            s, err := NewSession(a, nonce)
+            if err != nil {
+                t.Fatal(err)
+            }
+            s2, _ := NewSession(a, nonce)
+            var buf bytes.Buffer
+            w, err := NewEncryptWriter(a, nonce, ad, &buf)
+            if err != nil {
+                t.Fatal(err)
+            }
+            w.Write(pt)
+            r, err := NewDecryptReader(a, nonce, ad, bytes.NewReader(ct))
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            a.Wipe()
+            a.Wipe()
+            if a.k0 != 0 || a.k1 != 0 || a.k2 != 0 {
+                t.Fatal("key not zeroed")
+            }
+            if a.Overhead() != TagSize || a.NonceSize() != NonceSize {
+                t.Fatal("sizes changed")
+            }
+
+            expectWiped(t, "Seal", func() { a.Seal(nil, nonce, pt, ad) })
+            expectWiped(t, "SealDetached", func() { a.SealDetached(nil, nil, nonce, pt, ad) })
+            expectWiped(t, "SealTo", func() { a.SealTo(make([]byte, len(ct)), nonce, pt, ad) })
+            expectWiped(t, "SealWithNonce", func() { a.SealWithNonce(nil, nonce, pt, ad) })
+            expectWiped(t, "SealWithPrecomputedAAD", func() { a.SealWithPrecomputedAAD(nil, nonce, pt, a.PrecomputeAAD(ad)) })
+            expectWiped(t, "SealBatch", func() {
+                nonces, pts, _ := batchInputs(8, 16)
+                a.SealBatch(make([][]byte, 8), nonces, pts, nil)
+            })
+            expectWiped(t, "Session.Encrypt", func() { s.Encrypt(nil, pt) })
+
+            for name, err := range map[string]error{
+                "Open":                   func() error { _, err := a.Open(nil, nonce, ct, ad); return err }(),
+                "OpenDetached":           func() error { _, err := a.OpenDetached(nil, nonce, ct[:len(pt)], ct[len(pt):], ad); return err }(),
+                "OpenTo":                 func() error { _, err := a.OpenTo(make([]byte, len(pt)), nonce, ct, ad); return err }(),
+                "OpenWithPrecomputedAAD": func() error { _, err := a.OpenWithPrecomputedAAD(nil, nonce, ct, a.PrecomputeAAD(ad)); return err }(),
+                "OpenBatch":              func() error { _, err := a.OpenBatch(make([][]byte, 4), [][]byte{nonce, nonce, nonce, nonce}, [][]byte{ct, ct, ct, ct}, nil); return err }(),
+                "Session.Decrypt":        func() error { _, err := s2.Decrypt(nil, ct); return err }(),
+                "EncryptWriter":          w.Close(),
+                "DecryptReader":          func() error { _, err := io.ReadAll(r); return err }(),
+                "NewSession":             func() error { _, err := NewSession(a, nonce); return err }(),
+                "NewEncryptWriter":       func() error { _, err := NewEncryptWriter(a, nonce, ad, io.Discard); return err }(),
+            } {
+                if !errors.Is(err, ErrWiped) {
+                    t.Fatalf("%s: expected %v, got %v", name, ErrWiped, err)
+                }
+            }
+            if bytes.Contains(buf.Bytes(), ct[len(pt):]) {
+                t.Fatal("EncryptWriter wrote the authenticator")
+            }
+        })
+    }
+}
+
+// TestWipeCopies checks that Wipe leaves other instances with
+// the same key alone.
+func TestWipeCopies(t *testing.T) {
+    key := [KeySize]byte{1, 2, 3}
+    a, b := New128aKey(&key), New128aKey(&key)
+    nonce := make([]byte, NonceSize)
+    want := b.Seal(nil, nonce, nil, nil)
+    a.Wipe()
+
+    var wg sync.WaitGroup
+    for i := 0; i < 4; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if got := b.Seal(nil, nonce, nil, nil); !bytes.Equal(got, want) {
+                t.Errorf("expected %#x, got %#x", want, got)
+            }
+            if _, err := a.Open(nil, nonce, want, nil); err != ErrWiped {
+                t.Errorf("expected %v, got %v", ErrWiped, err)
+            }
+        }()
+    }
+    wg.Wait()
+}
@@ -0,0 +1,89 @@
+package ascon
+
+import (
+    "strconv"
+    "crypto/cipher"
+    "encoding/binary"
+)
+
+// NonceSizeX is the size in bytes of XASCON-128a nonces.
+const NonceSizeX = 24
+
+type xascon struct {
+    k0, k1 uint64
+}
+
+var _ cipher.AEAD = (*xascon)(nil)
+
+// NewX128a creates an XASCON-128a AEAD, ASCON-128a with an
+// extended 24-byte nonce.
+//
+// For a key K and nonce N, each message is sealed with
+//
+//    K' = Ascon-Prf(K, N[0:16]), truncated to 16 bytes
+//    N' = uint64(0) || N[16:24]
+//
+// using ASCON-128a under K' and N'. The leading eight zero
+// bytes of N' are a reserved block counter. The extended nonce
+// makes it safe to select nonces at random for a very large
+// number of messages under the same key.
+func NewX128a(key []byte) (cipher.AEAD, error) {
+    if len(key) != KeySize {
+        return nil, KeySizeError(len(key))
+    }
+
+    return &xascon{
+        k0: binary.BigEndian.Uint64(key[0:]),
+        k1: binary.BigEndian.Uint64(key[8:]),
+    }, nil
+}
+
+func (x *xascon) NonceSize() int {
+    return NonceSizeX
+}
+
+func (x *xascon) Overhead() int {
+    return TagSize
+}
+
+func (x *xascon) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+    if len(nonce) != NonceSizeX {
+        panic("ascon: incorrect nonce length: " + strconv.Itoa(len(nonce)))
+    }
+
+    var n [NonceSize]byte
+    a := x.subkey(n[:], nonce)
+    return a.Seal(dst, n[:], plaintext, additionalData)
+}
+
+func (x *xascon) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+    if len(nonce) != NonceSizeX {
+        return nil, errOpenNonce
+    }
+
+    var n [NonceSize]byte
+    a := x.subkey(n[:], nonce)
+    return a.Open(dst, n[:], ciphertext, additionalData)
+}
+
+// subkey writes the ASCON-128a nonce derived from the extended
+// nonce to dst and returns the ASCON-128a instance keyed with
+// the derived subkey.
+func (x *xascon) subkey(dst, nonce []byte) *AEAD {
+    var m macState
+    m.init(ivPRF, x.k0, x.k1)
+    m.write(nonce[:16])
+    m.finish()
+
+    var k [KeySize]byte
+    m.squeeze(k[:])
+
+    copy(dst[8:], nonce[16:])
+
+    return &AEAD{
+        k0:      binary.BigEndian.Uint64(k[0:]),
+        k1:      binary.BigEndian.Uint64(k[8:]),
+        iv:      iv128a,
+        tagSize: TagSize,
+    }
+}
@@ -0,0 +1,31 @@
+package ascon
+
+import (
+    "path/filepath"
+    "testing"
+)
+
+// testdata/vectors_x128a.txt is written by testdata/gen.py x128a,
+// which does not use this package.
+func TestVectorsX128a(t *testing.T) {
+    testVectors(t, NewX128a, filepath.Join("testdata", "vectors_x128a.txt"))
+}
+
+func TestX128aNonceSize(t *testing.T) {
+    aead, err := NewX128a(make([]byte, KeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if aead.NonceSize() != NonceSizeX {
+        t.Fatalf("expected %d, got %d", NonceSizeX, aead.NonceSize())
+    }
+
+    ct := aead.Seal(nil, make([]byte, NonceSizeX), nil, nil)
+    for i := 0; i < NonceSizeX; i++ {
+        nonce := make([]byte, NonceSizeX)
+        nonce[i] = 1
+        if _, err := aead.Open(nil, nonce, ct, nil); err == nil {
+            t.Fatalf("#%d: expected an error", i)
+        }
+    }
+}
@@ -0,0 +1,153 @@
+package ascon
+
+import (
+    "errors"
+    "io"
+)
+
+const (
+    ivXOF  uint64 = 0x00400c0000000000 // Ascon-Xof
+    ivXOFa uint64 = 0x00400c0400000000 // Ascon-Xofa
+
+    ivXOF128  uint64 = 0x0000080000cc0003 // Ascon-XOF128 (SP 800-232)
+    ivCXOF128 uint64 = 0x0000080000cc0004 // Ascon-CXOF128 (SP 800-232)
+)
+
+// MaxCustomizationSize is the maximum size in bytes of an
+// Ascon-CXOF128 customization string.
+const MaxCustomizationSize = 256
+
+var (
+    xofInit  = spongeInit(ivXOF)
+    xofaInit = spongeInit(ivXOFa)
+
+    xof128Init  = spongeInit(ivXOF128)
+    cxof128Init = spongeInit(ivCXOF128)
+)
+
+// XOF is an extendable-output function: input is written with
+// Write and an output of any length is read with Read.
+//
+// Reading n bytes and then m bytes produces the same output as
+// reading n+m bytes at once. Calling Write after the first
+// Read panics; use Reset to start over.
+type XOF struct {
+    sponge
+    // squeezing is set by the first Read, after which buf
+    // holds the current output block and off the number of
+    // bytes of it already read.
+    squeezing bool
+    off       int
+}
+
+var _ io.ReadWriter = (*XOF)(nil)
+
+// NewXOF returns a new Ascon-Xof.
+//
+// Ascon-Xof is the extendable-output function of the ASCON
+// 1.2 submission. It does not interoperate with Ascon-XOF128
+// from NIST SP 800-232.
+func NewXOF() *XOF {
+    x := &XOF{sponge: sponge{id: idXOF, init: xofInit, b: 12}}
+    x.Reset()
+    return x
+}
+
+// NewXOFa returns a new Ascon-Xofa.
+//
+// Ascon-Xofa is like Ascon-Xof, but uses the 8-round
+// permutation between blocks, both when absorbing and when
+// squeezing. It is faster and produces different output.
+func NewXOFa() *XOF {
+    x := &XOF{sponge: sponge{id: idXOFa, init: xofaInit, b: 8}}
+    x.Reset()
+    return x
+}
+
+// NewXOF128 returns a new Ascon-XOF128 as standardized by NIST
+// SP 800-232.
+//
+// Ascon-XOF128 is derived from Ascon-Xof, but loads and stores
+// data in little-endian byte order and uses a different IV,
+// so its output differs from that of NewXOF.
+func NewXOF128() *XOF {
+    x := &XOF{sponge: sponge{id: idXOF128, init: xof128Init, b: 12, le: true}}
+    x.Reset()
+    return x
+}
+
+// NewCXOF128 returns a new Ascon-CXOF128 as standardized by
+// NIST SP 800-232, customized with the given string.
+//
+// Ascon-CXOF128 is Ascon-XOF128 with the bit length of the
+// customization string and the string itself absorbed before
+// the message, so that different customization strings yield
+// independent functions. An empty (or nil) customization
+// string is the default. Customization strings longer than
+// MaxCustomizationSize bytes are rejected.
+//
+// Reset returns to the state after the customization string
+// has been absorbed.
+func NewCXOF128(customization []byte) (*XOF, error) {
+    if len(customization) > MaxCustomizationSize {
+        return nil, errors.New("ascon: customization string too long")
+    }
+
+    x := &XOF{sponge: sponge{id: idCXOF128, init: cxof128Init, b: 12, le: true}}
+    x.reset()
+    x.s[0] ^= uint64(len(customization)) * 8
+    p12(&x.s)
+    x.write(customization)
+    x.finish()
+    x.init = x.s
+    x.Reset()
+    return x, nil
+}
+
+// Write absorbs more data. It never returns an error, but
+// panics if called after Read.
+func (x *XOF) Write(p []byte) (int, error) {
+    if x.squeezing {
+        panic("ascon: XOF written after read")
+    }
+    x.write(p)
+    return len(p), nil
+}
+
+// Read squeezes len(p) bytes of output. It never returns an
+// error.
+func (x *XOF) Read(p []byte) (int, error) {
+    if !x.squeezing {
+        x.finish()
+        x.store(x.buf[:])
+        x.squeezing = true
+        x.off = 0
+    }
+
+    n := len(p)
+    for len(p) > 0 {
+        if x.off == HashBlockSize {
+            x.permute()
+            x.store(x.buf[:])
+            x.off = 0
+        }
+        c := copy(p, x.buf[x.off:])
+        x.off += c
+        p = p[c:]
+    }
+    return n, nil
+}
+
+// Reset resets the XOF to its initial state.
+func (x *XOF) Reset() {
+    x.reset()
+    x.squeezing = false
+}
+
+// Clone returns an independent copy of the XOF in its current
+// state. Both absorbing and squeezing XOFs can be cloned; the
+// copy continues from the same position.
+func (x *XOF) Clone() *XOF {
+    c := *x
+    return &c
+}
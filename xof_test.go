@@ -0,0 +1,223 @@
+package ascon
+
+import (
+    "bytes"
+    "encoding/hex"
+    "path/filepath"
+    "testing"
+)
+
+func TestVectorsXOF(t *testing.T) {
+    testXOFVectors(t, NewXOF, "vectors_xof.txt")
+}
+
+func TestVectorsXOFa(t *testing.T) {
+    testXOFVectors(t, NewXOFa, "vectors_xofa.txt")
+}
+
+func TestVectorsXOF128(t *testing.T) {
+    testXOFVectors(t, NewXOF128, "vectors_xof128.txt")
+}
+
+func TestVectorsCXOF128(t *testing.T) {
+    vecs, err := readVecs(filepath.Join("testdata", "vectors_cxof128.txt"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, v := range vecs {
+        x, err := NewCXOF128(v.z)
+        if err != nil {
+            t.Fatal(err)
+        }
+        for j := 0; j < 2; j++ {
+            x.Write(v.msg)
+            got := make([]byte, len(v.md))
+            x.Read(got)
+            if !bytes.Equal(got, v.md) {
+                t.Fatalf("#%d: expected %#x, got %#x", i+1, v.md, got)
+            }
+            x.Reset()
+        }
+    }
+}
+
+func testXOFVectors(t *testing.T, fn func() *XOF, name string) {
+    vecs, err := readVecs(filepath.Join("testdata", name))
+    if err != nil {
+        t.Fatal(err)
+    }
+    x := fn()
+    for i, v := range vecs {
+        x.Reset()
+        x.Write(v.msg)
+        got := make([]byte, len(v.md))
+        x.Read(got)
+        if !bytes.Equal(got, v.md) {
+            t.Fatalf("#%d: expected %#x, got %#x", i+1, v.md, got)
+        }
+    }
+}
+
+func TestXOF(t *testing.T) {
+    testXOF(t, NewXOF, "c90213a9e93b192c1d47f8aa20545f6f86686527896cb8d6530bbae9554e6dc5"+
+        "9b037c848e1cb3aa369cf29746226495939c448fd7f0a8e2770042be2ff78905"+
+        "116ad05fd8c697c22031871e95d186e7e3c51d10d08208c7943e404fbd7a0e3b"+
+        "7591ed67")
+}
+
+func TestXOFa(t *testing.T) {
+    testXOF(t, NewXOFa, "71820bddaf77da5f608f10b193ed55184680fec2bcb564fc19958f9bef195016"+
+        "97f64d4a0a3be29659c5ccd3c483661cdd9ae7bbbb07f3f2a6b052610e0e31f3"+
+        "1b2717434cb4c1b3ef6394b1ec798e836f3faad6b170f074a1ea3f55b7852fce"+
+        "f5dfa277")
+}
+
+func TestXOF128(t *testing.T) {
+    testXOF(t, NewXOF128, "b87198613d724232505baa68187f925708c009fe6ec13d19ce3c7aa6b20b2f0b"+
+        "23b7aa1a12d7d7b2f5b4ab654b142711ba3acfddc02bc9f5d467c6c5a7745462"+
+        "148264bf3426f4d638a2fade4b4fe6d14759ad92518c0738aab943ee5c6a275b"+
+        "6c1802a8")
+}
+
+func TestCXOF128(t *testing.T) {
+    testXOF(t, func() *XOF {
+        x, err := NewCXOF128([]byte("email"))
+        if err != nil {
+            t.Fatal(err)
+        }
+        return x
+    }, "08a696232769346f49388b2b7b7fb6bd8d8159272c45825604f80dd28e96f3f245260e9f4fa47625")
+
+    max, err := NewCXOF128(make([]byte, MaxCustomizationSize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    max.Write([]byte("abc"))
+    got := make([]byte, 40)
+    max.Read(got)
+    want, _ := hex.DecodeString("1deafa00ab91e268b95d639047d4d61f8ddc46c616eab6183c9c198b58b7cfcef5d9a91f1108abeb")
+    if !bytes.Equal(got, want) {
+        t.Fatalf("expected %#x, got %#x", want, got)
+    }
+    if _, err := NewCXOF128(make([]byte, MaxCustomizationSize+1)); err == nil {
+        t.Fatal("expected an error")
+    }
+
+    // nil and empty customization strings are the default.
+    want, _ = hex.DecodeString("4f50159ef70bb3dad8807e034eaebd44c4fa2cbbc8cf1f05511ab66cdcc52990")
+    for _, z := range [][]byte{nil, {}} {
+        x, err := NewCXOF128(z)
+        if err != nil {
+            t.Fatal(err)
+        }
+        got := make([]byte, len(want))
+        x.Read(got)
+        if !bytes.Equal(got, want) {
+            t.Fatalf("%#v: expected %#x, got %#x", z, want, got)
+        }
+    }
+}
+
+// testXOF checks the output of fn for the message "abc", which
+// must be a prefix of the hex string out.
+func testXOF(t *testing.T, fn func() *XOF, out string) {
+    want, _ := hex.DecodeString(out)
+
+    for _, n := range []int{0, 1, 7, 8, 9, 33, len(want)} {
+        x := fn()
+        x.Write([]byte("abc"))
+        got := make([]byte, n)
+        x.Read(got)
+        if !bytes.Equal(got, want[:n]) {
+            t.Fatalf("%d bytes: expected %#x, got %#x", n, want[:n], got)
+        }
+    }
+
+    // Reading in pieces must match reading at once.
+    for _, step := range []int{1, 3, 8, 13} {
+        x := fn()
+        for _, c := range []byte("abc") {
+            x.Write([]byte{c})
+        }
+        var got []byte
+        for len(got) < len(want) {
+            n := step
+            if n > len(want)-len(got) {
+                n = len(want) - len(got)
+            }
+            b := make([]byte, n)
+            x.Read(b)
+            got = append(got, b...)
+        }
+        if !bytes.Equal(got, want) {
+            t.Fatalf("step %d: expected %#x, got %#x", step, want, got)
+        }
+    }
+
+    x := fn()
+    x.Write([]byte("abc"))
+    c := x.Clone()
+    a, b := make([]byte, 20), make([]byte, 20)
+    x.Read(a)
+    c.Read(b)
+    if !bytes.Equal(a, b) {
+        t.Fatalf("Clone: expected %#x, got %#x", a, b)
+    }
+
+    x.Reset()
+    x.Write([]byte("abc"))
+    x.Read(a)
+    if !bytes.Equal(a, want[:20]) {
+        t.Fatalf("Reset: expected %#x, got %#x", want[:20], a)
+    }
+
+    defer func() {
+        if recover() == nil {
+            t.Fatal("expected a panic")
+        }
+    }()
+    x.Write([]byte("more"))
+}
+
+func BenchmarkXOF1K(b *testing.B) {
+    benchmarkXOF(b, NewXOF, 1024)
+}
+
+func BenchmarkXOFa1K(b *testing.B) {
+    benchmarkXOF(b, NewXOFa, 1024)
+}
+
+func BenchmarkXOF128_1K(b *testing.B) {
+    benchmarkXOF(b, NewXOF128, 1024)
+}
+
+func benchmarkXOF(b *testing.B, fn func() *XOF, size int) {
+    b.SetBytes(int64(size))
+    b.ReportAllocs()
+    x := fn()
+    buf := make([]byte, size)
+
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        x.Reset()
+        x.Write(buf[:32])
+        x.Read(buf)
+    }
+}
+
+func TestXOFCloneSqueezing(t *testing.T) {
+    x := NewXOF128()
+    x.Write([]byte("abc"))
+    want := make([]byte, 50)
+    x.Clone().Read(want)
+
+    head := make([]byte, 13)
+    x.Read(head)
+    c := x.Clone()
+    a, b := make([]byte, 37), make([]byte, 37)
+    x.Read(a)
+    c.Read(b)
+    if !bytes.Equal(a, want[13:]) || !bytes.Equal(b, want[13:]) {
+        t.Fatalf("expected %#x, got %#x and %#x", want[13:], a, b)
+    }
+}